@@ -45,6 +45,8 @@ type Alert struct {
 	ResourceName string `json:"resourceName"`
 	// Status is the status of this rule, can be pending, firing, or recovered
 	Status Status `json:"status"`
+	// Channel is the channel to send the alert to instead of the notifier's channel, routed by the namespace of the resource
+	Channel string `json:"channel,omitempty"`
 	// Error is the err from any issues for sending message to external system
 	Error string `json:"error"`
 	// Violated indicates if the alert is from rule violations, since all alerts stored in status should come from
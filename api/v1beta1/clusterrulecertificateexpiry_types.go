@@ -0,0 +1,79 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/mercari/merlin/alert"
+)
+
+// CertificateExpiryThreshold is the number of days before a certificate expires and the severity to use when reached
+type CertificateExpiryThreshold struct {
+	// Days is the number of days left before the certificate expires for this threshold to apply
+	Days int64 `json:"days"`
+	// Severity is the severity of the alert when this threshold is reached, one of info, warning, critical, or fatal
+	Severity alert.Severity `json:"severity"`
+}
+
+// ClusterRuleCertificateExpirySpec defines the desired state of ClusterRuleCertificateExpiry
+type ClusterRuleCertificateExpirySpec struct {
+	// IgnoreNamespaces is the list of namespaces to ignore for this rule
+	IgnoreNamespaces []string `json:"ignoreNamespaces,omitempty"`
+	// Notification contains the channels and messages to send out to external system, such as slack or pagerduty.
+	Notification Notification `json:"notification"`
+	// Keys is the list of extra secret data keys holding PEM encoded certificates to check, `tls.crt` of kubernetes.io/tls secrets is always checked.
+	Keys []string `json:"keys,omitempty"`
+	// Thresholds is the list of thresholds for certificate expiry, the smallest reached threshold decides the severity,
+	// default to warning at 30 days and critical at 7 days.
+	Thresholds []CertificateExpiryThreshold `json:"thresholds,omitempty"`
+	// RecheckIntervalSeconds is the interval to re-evaluate all secrets since expiry depends on time, default to 3600
+	RecheckIntervalSeconds int64 `json:"recheckIntervalSeconds,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterRuleCertificateExpiryList contains a list of ClusterRuleCertificateExpiry
+type ClusterRuleCertificateExpiryList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterRuleCertificateExpiry `json:"items"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="Violations",type="integer",JSONPath=".status.violationCount"
+// +kubebuilder:printcolumn:name="Last Evaluated",type="date",JSONPath=".status.lastEvaluatedAt"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// ClusterRuleCertificateExpiry is the Schema for the clusterrulecertificateexpiries API
+type ClusterRuleCertificateExpiry struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ClusterRuleCertificateExpirySpec `json:"spec,omitempty"`
+	Status RuleStatus                       `json:"status,omitempty"`
+}
+
+func (r *ClusterRuleCertificateExpiry) GetRuleStatus() *RuleStatus {
+	return &r.Status
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterRuleCertificateExpiry{}, &ClusterRuleCertificateExpiryList{})
+}
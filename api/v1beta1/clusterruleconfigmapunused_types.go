@@ -30,13 +30,23 @@ type ClusterRuleConfigMapUnusedSpec struct {
 }
 
 // +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="Violations",type="integer",JSONPath=".status.violationCount"
+// +kubebuilder:printcolumn:name="Last Evaluated",type="date",JSONPath=".status.lastEvaluatedAt"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // ClusterRuleConfigMapUnused is the Schema for the clusterruleconfigmapunuseds API
 type ClusterRuleConfigMapUnused struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec ClusterRuleConfigMapUnusedSpec `json:"spec,omitempty"`
+	Spec   ClusterRuleConfigMapUnusedSpec `json:"spec,omitempty"`
+	Status RuleStatus                     `json:"status,omitempty"`
+}
+
+func (r *ClusterRuleConfigMapUnused) GetRuleStatus() *RuleStatus {
+	return &r.Status
 }
 
 // +kubebuilder:object:root=true
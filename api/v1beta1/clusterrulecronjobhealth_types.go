@@ -0,0 +1,68 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ClusterRuleCronJobHealthSpec defines the desired state of ClusterRuleCronJobHealth
+type ClusterRuleCronJobHealthSpec struct {
+	// IgnoreNamespaces is the list of namespaces to ignore for this rule
+	IgnoreNamespaces []string `json:"ignoreNamespaces,omitempty"`
+	// Notification contains the channels and messages to send out to external system, such as slack or pagerduty.
+	Notification Notification `json:"notification"`
+	// MissedSchedules is the number of schedule periods a CronJob can go without a successful run before raising violation, default to 3
+	MissedSchedules int64 `json:"missedSchedules,omitempty"`
+	// SuspendedSeconds is how long a CronJob can be suspended before raising violation, default to 86400
+	SuspendedSeconds int64 `json:"suspendedSeconds,omitempty"`
+	// RecheckIntervalSeconds is the interval to re-evaluate all CronJobs since schedules depend on time, default to 300
+	RecheckIntervalSeconds int64 `json:"recheckIntervalSeconds,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterRuleCronJobHealthList contains a list of ClusterRuleCronJobHealth
+type ClusterRuleCronJobHealthList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterRuleCronJobHealth `json:"items"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="Violations",type="integer",JSONPath=".status.violationCount"
+// +kubebuilder:printcolumn:name="Last Evaluated",type="date",JSONPath=".status.lastEvaluatedAt"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// ClusterRuleCronJobHealth is the Schema for the clusterrulecronjobhealths API
+type ClusterRuleCronJobHealth struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ClusterRuleCronJobHealthSpec `json:"spec,omitempty"`
+	Status RuleStatus                   `json:"status,omitempty"`
+}
+
+func (r *ClusterRuleCronJobHealth) GetRuleStatus() *RuleStatus {
+	return &r.Status
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterRuleCronJobHealth{}, &ClusterRuleCronJobHealthList{})
+}
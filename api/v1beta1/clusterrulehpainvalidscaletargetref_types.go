@@ -38,13 +38,23 @@ type ClusterRuleHPAInvalidScaleTargetRefList struct {
 
 // +kubebuilder:object:root=true
 // +kubebuilder:resource:scope=Cluster
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="Violations",type="integer",JSONPath=".status.violationCount"
+// +kubebuilder:printcolumn:name="Last Evaluated",type="date",JSONPath=".status.lastEvaluatedAt"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // ClusterRuleHPAInvalidScaleTargetRef is the Schema for the cluster rule hpa invalid scale target refs API
 type ClusterRuleHPAInvalidScaleTargetRef struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec ClusterRuleHPAInvalidScaleTargetRefSpec `json:"spec,omitempty"`
+	Spec   ClusterRuleHPAInvalidScaleTargetRefSpec `json:"spec,omitempty"`
+	Status RuleStatus                              `json:"status,omitempty"`
+}
+
+func (r *ClusterRuleHPAInvalidScaleTargetRef) GetRuleStatus() *RuleStatus {
+	return &r.Status
 }
 
 func init() {
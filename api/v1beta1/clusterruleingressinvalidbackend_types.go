@@ -0,0 +1,64 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ClusterRuleIngressInvalidBackendSpec defines the desired state of ClusterRuleIngressInvalidBackend
+type ClusterRuleIngressInvalidBackendSpec struct {
+	// IgnoreNamespaces is the list of namespaces to ignore for this rule
+	IgnoreNamespaces []string `json:"ignoreNamespaces,omitempty"`
+	// Notification contains the channels and messages to send out to external system, such as slack or pagerduty.
+	Notification Notification `json:"notification"`
+	// RecheckIntervalSeconds is the interval to re-evaluate all ingresses, since deleted services and secrets can't be evaluated, default to 300
+	RecheckIntervalSeconds int64 `json:"recheckIntervalSeconds,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterRuleIngressInvalidBackendList contains a list of ClusterRuleIngressInvalidBackend
+type ClusterRuleIngressInvalidBackendList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterRuleIngressInvalidBackend `json:"items"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="Violations",type="integer",JSONPath=".status.violationCount"
+// +kubebuilder:printcolumn:name="Last Evaluated",type="date",JSONPath=".status.lastEvaluatedAt"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// ClusterRuleIngressInvalidBackend is the Schema for the clusterruleingressinvalidbackends API
+type ClusterRuleIngressInvalidBackend struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ClusterRuleIngressInvalidBackendSpec `json:"spec,omitempty"`
+	Status RuleStatus                           `json:"status,omitempty"`
+}
+
+func (r *ClusterRuleIngressInvalidBackend) GetRuleStatus() *RuleStatus {
+	return &r.Status
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterRuleIngressInvalidBackend{}, &ClusterRuleIngressInvalidBackendList{})
+}
@@ -0,0 +1,72 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ClusterRuleNamespaceQuotaSpec defines the desired state of ClusterRuleNamespaceQuota
+type ClusterRuleNamespaceQuotaSpec struct {
+	// IgnoreNamespaces is the list of namespaces to ignore for this rule
+	IgnoreNamespaces []string `json:"ignoreNamespaces,omitempty"`
+	// NamespaceSelector selects the namespaces to check by labels, all namespaces are checked if it's not set
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+	// RequireResourceQuota enables the check for namespaces without any ResourceQuota
+	RequireResourceQuota bool `json:"requireResourceQuota,omitempty"`
+	// RequireLimitRange enables the check for namespaces without any LimitRange
+	RequireLimitRange bool `json:"requireLimitRange,omitempty"`
+	// Percent is the threshold of percentage for a ResourceQuota used resource divided by hard limit to be considered as an issue.
+	Percent int32 `json:"percent"`
+	// RecheckIntervalSeconds is the interval to re-evaluate all namespaces and quotas, since deleted quotas and limit ranges can't be evaluated, default to 300
+	RecheckIntervalSeconds int64 `json:"recheckIntervalSeconds,omitempty"`
+	// Notification contains the channels and messages to send out to external system, such as slack or pagerduty.
+	Notification Notification `json:"notification"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterRuleNamespaceQuotaList contains a list of ClusterRuleNamespaceQuota
+type ClusterRuleNamespaceQuotaList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterRuleNamespaceQuota `json:"items"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster,path=clusterrulenamespacequotas
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="Violations",type="integer",JSONPath=".status.violationCount"
+// +kubebuilder:printcolumn:name="Last Evaluated",type="date",JSONPath=".status.lastEvaluatedAt"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// ClusterRuleNamespaceQuota is the Schema for the clusterrulenamespacequotas API
+type ClusterRuleNamespaceQuota struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ClusterRuleNamespaceQuotaSpec `json:"spec,omitempty"`
+	Status RuleStatus                    `json:"status,omitempty"`
+}
+
+func (r *ClusterRuleNamespaceQuota) GetRuleStatus() *RuleStatus {
+	return &r.Status
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterRuleNamespaceQuota{}, &ClusterRuleNamespaceQuotaList{})
+}
@@ -27,6 +27,8 @@ type ClusterRuleNamespaceRequiredLabelSpec struct {
 	Notification Notification `json:"notification"`
 	// Label is the required label for this namespace, specified key, value, and a match
 	Label RequiredLabel `json:"label"`
+	// Enforcement is the action of the admission webhook for resources violating the rule, one of audit, warn or deny, default to audit.
+	Enforcement Enforcement `json:"enforcement,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -40,13 +42,23 @@ type ClusterRuleNamespaceRequiredLabelList struct {
 
 // +kubebuilder:object:root=true
 // +kubebuilder:resource:scope=Cluster
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="Violations",type="integer",JSONPath=".status.violationCount"
+// +kubebuilder:printcolumn:name="Last Evaluated",type="date",JSONPath=".status.lastEvaluatedAt"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // ClusterRuleNamespaceRequiredLabel is the Schema for the clusterrulenamespacerequiredlabels API
 type ClusterRuleNamespaceRequiredLabel struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec ClusterRuleNamespaceRequiredLabelSpec `json:"spec,omitempty"`
+	Spec   ClusterRuleNamespaceRequiredLabelSpec `json:"spec,omitempty"`
+	Status RuleStatus                            `json:"status,omitempty"`
+}
+
+func (r *ClusterRuleNamespaceRequiredLabel) GetRuleStatus() *RuleStatus {
+	return &r.Status
 }
 
 func init() {
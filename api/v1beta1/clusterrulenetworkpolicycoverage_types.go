@@ -0,0 +1,68 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ClusterRuleNetworkPolicyCoverageSpec defines the desired state of ClusterRuleNetworkPolicyCoverage
+type ClusterRuleNetworkPolicyCoverageSpec struct {
+	// IgnoreNamespaces is the list of namespaces to ignore for this rule
+	IgnoreNamespaces []string `json:"ignoreNamespaces,omitempty"`
+	// NamespaceSelector selects the namespaces to check by labels, all namespaces are checked if it's not set
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+	// DefaultDenyPolicyTypes is the policy types, Ingress or Egress, that namespaces must deny by default, default to Ingress
+	DefaultDenyPolicyTypes []string `json:"defaultDenyPolicyTypes,omitempty"`
+	// RecheckIntervalSeconds is the interval to re-evaluate all namespaces and policies, since deleted policies and pods can't be evaluated, default to 300
+	RecheckIntervalSeconds int64 `json:"recheckIntervalSeconds,omitempty"`
+	// Notification contains the channels and messages to send out to external system, such as slack or pagerduty.
+	Notification Notification `json:"notification"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterRuleNetworkPolicyCoverageList contains a list of ClusterRuleNetworkPolicyCoverage
+type ClusterRuleNetworkPolicyCoverageList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterRuleNetworkPolicyCoverage `json:"items"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="Violations",type="integer",JSONPath=".status.violationCount"
+// +kubebuilder:printcolumn:name="Last Evaluated",type="date",JSONPath=".status.lastEvaluatedAt"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// ClusterRuleNetworkPolicyCoverage is the Schema for the clusterrulenetworkpolicycoverages API
+type ClusterRuleNetworkPolicyCoverage struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ClusterRuleNetworkPolicyCoverageSpec `json:"spec,omitempty"`
+	Status RuleStatus                           `json:"status,omitempty"`
+}
+
+func (r *ClusterRuleNetworkPolicyCoverage) GetRuleStatus() *RuleStatus {
+	return &r.Status
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterRuleNetworkPolicyCoverage{}, &ClusterRuleNetworkPolicyCoverageList{})
+}
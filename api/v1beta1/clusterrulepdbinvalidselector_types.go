@@ -38,13 +38,23 @@ type ClusterRulePDBInvalidSelectorList struct {
 
 // +kubebuilder:object:root=true
 // +kubebuilder:resource:scope=Cluster
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="Violations",type="integer",JSONPath=".status.violationCount"
+// +kubebuilder:printcolumn:name="Last Evaluated",type="date",JSONPath=".status.lastEvaluatedAt"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // ClusterRulePDBInvalidSelector is the Schema for the clusterrulepdbinvalidselectors API
 type ClusterRulePDBInvalidSelector struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec ClusterRulePDBInvalidSelectorSpec `json:"spec,omitempty"`
+	Spec   ClusterRulePDBInvalidSelectorSpec `json:"spec,omitempty"`
+	Status RuleStatus                        `json:"status,omitempty"`
+}
+
+func (r *ClusterRulePDBInvalidSelector) GetRuleStatus() *RuleStatus {
+	return &r.Status
 }
 
 func init() {
@@ -0,0 +1,70 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ClusterRulePodHealthSpec defines the desired state of ClusterRulePodHealth
+type ClusterRulePodHealthSpec struct {
+	// IgnoreNamespaces is the list of namespaces to ignore for this rule
+	IgnoreNamespaces []string `json:"ignoreNamespaces,omitempty"`
+	// Notification contains the channels and messages to send out to external system, such as slack or pagerduty.
+	Notification Notification `json:"notification"`
+	// RestartThreshold is the number of restarts of a container within the window to raise violation, restarts are not checked if it's 0
+	RestartThreshold int32 `json:"restartThreshold,omitempty"`
+	// OOMKilledThreshold is the number of OOMKilled terminations of a container within the window to raise violation, default to 2
+	OOMKilledThreshold int32 `json:"oomKilledThreshold,omitempty"`
+	// WindowSeconds is the time window to count restarts and OOMKilled terminations, default to 3600
+	WindowSeconds int64 `json:"windowSeconds,omitempty"`
+	// RecheckIntervalSeconds is the interval to re-evaluate all pods so violations recover once restarts are out of the window, default to 300
+	RecheckIntervalSeconds int64 `json:"recheckIntervalSeconds,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterRulePodHealthList contains a list of ClusterRulePodHealth
+type ClusterRulePodHealthList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterRulePodHealth `json:"items"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="Violations",type="integer",JSONPath=".status.violationCount"
+// +kubebuilder:printcolumn:name="Last Evaluated",type="date",JSONPath=".status.lastEvaluatedAt"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// ClusterRulePodHealth is the Schema for the clusterrulepodhealths API
+type ClusterRulePodHealth struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ClusterRulePodHealthSpec `json:"spec,omitempty"`
+	Status RuleStatus               `json:"status,omitempty"`
+}
+
+func (r *ClusterRulePodHealth) GetRuleStatus() *RuleStatus {
+	return &r.Status
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterRulePodHealth{}, &ClusterRulePodHealthList{})
+}
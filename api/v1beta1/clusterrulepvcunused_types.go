@@ -0,0 +1,66 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ClusterRulePVCUnusedSpec defines the desired state of ClusterRulePVCUnused
+type ClusterRulePVCUnusedSpec struct {
+	// IgnoreNamespaces is the list of namespaces to ignore for this rule
+	IgnoreNamespaces []string `json:"ignoreNamespaces,omitempty"`
+	// Notification contains the channels and messages to send out to external system, such as slack or pagerduty.
+	Notification Notification `json:"notification"`
+	// InitialDelaySeconds is the time a PersistentVolumeClaim can be unused after it's created
+	InitialDelaySeconds int64 `json:"initialDelaySeconds,omitempty"`
+	// IgnoreReleasedPVs disables the check for PersistentVolumes stuck in Released or Failed phase
+	IgnoreReleasedPVs bool `json:"ignoreReleasedPVs,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterRulePVCUnusedList contains a list of ClusterRulePVCUnused
+type ClusterRulePVCUnusedList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterRulePVCUnused `json:"items"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="Violations",type="integer",JSONPath=".status.violationCount"
+// +kubebuilder:printcolumn:name="Last Evaluated",type="date",JSONPath=".status.lastEvaluatedAt"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// ClusterRulePVCUnused is the Schema for the clusterrulepvcunuseds API
+type ClusterRulePVCUnused struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ClusterRulePVCUnusedSpec `json:"spec,omitempty"`
+	Status RuleStatus               `json:"status,omitempty"`
+}
+
+func (r *ClusterRulePVCUnused) GetRuleStatus() *RuleStatus {
+	return &r.Status
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterRulePVCUnused{}, &ClusterRulePVCUnusedList{})
+}
@@ -0,0 +1,91 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// ClusterRuleRegoSpec defines the desired state of ClusterRuleRego
+type ClusterRuleRegoSpec struct {
+	// IgnoreNamespaces is the list of namespaces to ignore for this rule
+	IgnoreNamespaces []string `json:"ignoreNamespaces,omitempty"`
+	// Selector selects name or matched labels for a resource to apply this rule
+	Selector Selector `json:"selector,omitempty"`
+	// TargetKinds are the group, version and kind of the resources to evaluate, the resources are watched when the rule is created.
+	// +kubebuilder:validation:MinItems=1
+	TargetKinds []metav1.GroupVersionKind `json:"targetKinds"`
+	// Module is the Rego module of the policy, either module or moduleConfigMap is required.
+	Module string `json:"module,omitempty"`
+	// ModuleConfigMap is the ConfigMap key containing the Rego module of the policy, it's loaded again when the rule is rechecked.
+	ModuleConfigMap *RegoModuleConfigMap `json:"moduleConfigMap,omitempty"`
+	// Query is the query of the violations, e.g. `data.k8srequiredlabels.violation`, each result is a message as string,
+	// or an object with the message as `msg` like Gatekeeper's `violation[{"msg": msg}]`.
+	// The resource can be accessed as `input.review.object` and the parameters as `input.parameters`, the same as Gatekeeper.
+	Query string `json:"query"`
+	// Parameters are the parameters of the policy, which can be accessed as `input.parameters`.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Parameters *runtime.RawExtension `json:"parameters,omitempty"`
+	// RecheckIntervalSeconds is the interval to evaluate all resources again, the module in ConfigMap is also loaded again, default to 300
+	RecheckIntervalSeconds int64 `json:"recheckIntervalSeconds,omitempty"`
+	// Notification contains the channels and messages to send out to external system, such as slack or pagerduty.
+	Notification Notification `json:"notification"`
+}
+
+// RegoModuleConfigMap is the ConfigMap key of a Rego module
+type RegoModuleConfigMap struct {
+	// Namespace is the namespace of the ConfigMap
+	Namespace string `json:"namespace"`
+	// Name is the name of the ConfigMap
+	Name string `json:"name"`
+	// Key is the key of the module in the ConfigMap data
+	Key string `json:"key"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterRuleRegoList contains a list of ClusterRuleRego
+type ClusterRuleRegoList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterRuleRego `json:"items"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="Violations",type="integer",JSONPath=".status.violationCount"
+// +kubebuilder:printcolumn:name="Last Evaluated",type="date",JSONPath=".status.lastEvaluatedAt"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// ClusterRuleRego is the Schema for the clusterruleregoes API
+type ClusterRuleRego struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ClusterRuleRegoSpec `json:"spec,omitempty"`
+	Status RuleStatus          `json:"status,omitempty"`
+}
+
+func (r *ClusterRuleRego) GetRuleStatus() *RuleStatus {
+	return &r.Status
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterRuleRego{}, &ClusterRuleRegoList{})
+}
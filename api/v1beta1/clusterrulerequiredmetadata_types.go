@@ -0,0 +1,72 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ClusterRuleRequiredMetadataSpec defines the desired state of ClusterRuleRequiredMetadata
+type ClusterRuleRequiredMetadataSpec struct {
+	// IgnoreNamespaces is the list of namespaces to ignore for this rule
+	IgnoreNamespaces []string `json:"ignoreNamespaces,omitempty"`
+	// Kinds is the list of group, version and kind of the resources to check, version is used to list the resources.
+	Kinds []metav1.GroupVersionKind `json:"kinds"`
+	// Labels is the list of required labels for the resources, specified key, value, and a match
+	Labels []RequiredLabel `json:"labels,omitempty"`
+	// Annotations is the list of required annotations for the resources, specified key, value, and a match
+	Annotations []RequiredLabel `json:"annotations,omitempty"`
+	// RecheckIntervalSeconds is the interval to re-evaluate all resources, since resources of kinds without reconcilers are not watched, default to 300
+	RecheckIntervalSeconds int64 `json:"recheckIntervalSeconds,omitempty"`
+	// Notification contains the channels and messages to send out to external system, such as slack or pagerduty.
+	Notification Notification `json:"notification"`
+	// Enforcement is the action of the admission webhook for resources violating the rule, one of audit, warn or deny, default to audit.
+	Enforcement Enforcement `json:"enforcement,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterRuleRequiredMetadataList contains a list of ClusterRuleRequiredMetadata
+type ClusterRuleRequiredMetadataList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterRuleRequiredMetadata `json:"items"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="Violations",type="integer",JSONPath=".status.violationCount"
+// +kubebuilder:printcolumn:name="Last Evaluated",type="date",JSONPath=".status.lastEvaluatedAt"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// ClusterRuleRequiredMetadata is the Schema for the clusterrulerequiredmetadata API
+type ClusterRuleRequiredMetadata struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ClusterRuleRequiredMetadataSpec `json:"spec,omitempty"`
+	Status RuleStatus                      `json:"status,omitempty"`
+}
+
+func (r *ClusterRuleRequiredMetadata) GetRuleStatus() *RuleStatus {
+	return &r.Status
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterRuleRequiredMetadata{}, &ClusterRuleRequiredMetadataList{})
+}
@@ -40,13 +40,23 @@ type ClusterRuleSecretUnusedList struct {
 
 // +kubebuilder:object:root=true
 // +kubebuilder:resource:scope=Cluster
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="Violations",type="integer",JSONPath=".status.violationCount"
+// +kubebuilder:printcolumn:name="Last Evaluated",type="date",JSONPath=".status.lastEvaluatedAt"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // ClusterRuleSecretUnused is the Schema for the clusterrulesecretunuseds API
 type ClusterRuleSecretUnused struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec ClusterRuleSecretUnusedSpec `json:"spec,omitempty"`
+	Spec   ClusterRuleSecretUnusedSpec `json:"spec,omitempty"`
+	Status RuleStatus                  `json:"status,omitempty"`
+}
+
+func (r *ClusterRuleSecretUnused) GetRuleStatus() *RuleStatus {
+	return &r.Status
 }
 
 func init() {
@@ -38,13 +38,23 @@ type ClusterRuleServiceInvalidSelectorList struct {
 
 // +kubebuilder:object:root=true
 // +kubebuilder:resource:scope=Cluster
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="Violations",type="integer",JSONPath=".status.violationCount"
+// +kubebuilder:printcolumn:name="Last Evaluated",type="date",JSONPath=".status.lastEvaluatedAt"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // ClusterRuleServiceInvalidSelector is the Schema for the clusterruleserviceinvalidselector API
 type ClusterRuleServiceInvalidSelector struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec ClusterRuleServiceInvalidSelectorSpec `json:"spec,omitempty"`
+	Spec   ClusterRuleServiceInvalidSelectorSpec `json:"spec,omitempty"`
+	Status RuleStatus                            `json:"status,omitempty"`
+}
+
+func (r *ClusterRuleServiceInvalidSelector) GetRuleStatus() *RuleStatus {
+	return &r.Status
 }
 
 func init() {
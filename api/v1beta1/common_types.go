@@ -1,17 +1,32 @@
 package v1beta1
 
 import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
 	"github.com/mercari/merlin/alert"
 )
 
+const (
+	// AnnotationNotifier is the annotation of namespaces for routing alerts of the resources in the namespace to other
+	// notifiers as well, as comma separated notifier names, they're added to the notifiers of rules.
+	AnnotationNotifier = "merlin.mercari.com/notifier"
+	// AnnotationSlackChannel is the annotation of namespaces for sending alerts of the resources in the namespace to
+	// another slack channel than the notifiers' channels, as a plain channel for all the notifiers, and comma separated
+	// "<notifier>=<channel>" for the channels of specific notifiers.
+	AnnotationSlackChannel = "merlin.mercari.com/slack-channel"
+)
+
 // RequiredLabel is the
 type RequiredLabel struct {
 	// Key is the label key name
 	Key string `json:"key"`
 	// Value is the label value, when match is set as "regexp", the acceptable syntax of regex is RE2 (https://github.com/google/re2/wiki/Syntax)
-	Value string `json:"value"`
+	Value string `json:"value,omitempty"`
 	// Match is the way of matching, default to "exact" match, can also use "regexp" and set value to a regular express for matching.
 	Match string `json:"match,omitempty"`
+	// OneOf is the set of acceptable values, value and match are ignored when this is set.
+	OneOf []string `json:"oneOf,omitempty"`
 }
 
 // Selector is the resource selector that used when listing kubernetes objects, only namespaced rules have this since cluster rules apply for all objects.
@@ -32,3 +47,110 @@ type Notification struct {
 	// CustomMessageTemplate can used for customized message, variables can be used are "ResourceName, Severity, and Message"
 	CustomMessageTemplate string `json:"customMessageTemplate,omitempty"`
 }
+
+// Enforcement is the action of the admission webhook for the resources violating the rule
+// +kubebuilder:validation:Enum=audit;warn;deny
+type Enforcement string
+
+const (
+	// EnforcementAudit only notifies the violations, this is the default
+	EnforcementAudit Enforcement = "audit"
+	// EnforcementWarn returns admission warnings for the violating resources, which are shown by kubectl
+	EnforcementWarn Enforcement = "warn"
+	// EnforcementDeny rejects the violating resources
+	EnforcementDeny Enforcement = "deny"
+)
+
+// RuleConditionType is the type of rule conditions
+type RuleConditionType string
+
+const (
+	// RuleConditionReady means the rule is loaded and all applicable resources are evaluated
+	RuleConditionReady RuleConditionType = "Ready"
+	// RuleConditionDegraded means the rule failed to evaluate the applicable resources, the reason and message have the details
+	RuleConditionDegraded RuleConditionType = "Degraded"
+)
+
+// RuleCondition is the condition of a rule
+type RuleCondition struct {
+	// Type is the type of the condition, Ready or Degraded
+	Type RuleConditionType `json:"type"`
+	// Status is the status of the condition, one of True, False, or Unknown
+	Status metav1.ConditionStatus `json:"status"`
+	// Reason is the reason in CamelCase for the condition's last transition
+	Reason string `json:"reason,omitempty"`
+	// Message is the human readable message with details about the transition
+	Message string `json:"message,omitempty"`
+	// LastTransitionTime is the last time the condition changed from one status to another
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+}
+
+// RuleStatus defines the observed state of rules, it's updated by the controller when the rule is evaluated for all applicable resources
+type RuleStatus struct {
+	// ObservedGeneration is the generation of the rule last evaluated
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// LastEvaluatedAt is the last time all applicable resources were evaluated successfully
+	LastEvaluatedAt *metav1.Time `json:"lastEvaluatedAt,omitempty"`
+	// ViolationCount is the number of resources violating the rule
+	ViolationCount int `json:"violationCount"`
+	// Violations is the list of violating resources as <namespace>/<name>, or <name> for cluster scoped resources, sorted by names and limited to the first 10 resources
+	Violations []string `json:"violations,omitempty"`
+	// Conditions are the Ready and Degraded conditions of the rule
+	Conditions []RuleCondition `json:"conditions,omitempty"`
+	// ViolationStates are the first and last seen times of violating resources and resources in the grace periods of the
+	// rule, they're restored when the rule is loaded again, e.g., after the controller restarts, so the violations are
+	// kept until the resources are evaluated and the grace periods continue. Limited to the first 1000 states, violating
+	// resources first.
+	ViolationStates []ViolationState `json:"violationStates,omitempty"`
+}
+
+// ViolationState is the persisted state of a resource violating the rule, or in the grace period of the rule
+type ViolationState struct {
+	// Key is the object key of the resource as <namespace>/<name>, the namespace is empty for cluster scoped resources,
+	// keys of pending states can have other forms given by the rule, e.g., with the kind of the resource
+	Key string `json:"key"`
+	// FirstSeen is the first time the resource was found violating the rule, or the start of the grace period
+	FirstSeen metav1.Time `json:"firstSeen"`
+	// LastSeen is the last time the resource was evaluated and still violating the rule, or still in the grace period
+	LastSeen metav1.Time `json:"lastSeen"`
+	// Pending is true if the resource is in the grace period of the rule and not violating it yet, e.g., rollouts in progress
+	Pending bool `json:"pending,omitempty"`
+	// Kind is the kind of the resource, for rules evaluating resources of different kinds
+	Kind string `json:"kind,omitempty"`
+	// Count is the count observed at the first seen time, e.g., the restart count of a container
+	Count int32 `json:"count,omitempty"`
+	// Reason is the reason observed at the first seen time, e.g., OOMKilled for the restart of a container
+	Reason string `json:"reason,omitempty"`
+}
+
+// GetCondition returns the condition of the type, nil if the rule doesn't have it
+func (s *RuleStatus) GetCondition(t RuleConditionType) *RuleCondition {
+	for i := range s.Conditions {
+		if s.Conditions[i].Type == t {
+			return &s.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// SetCondition adds or updates the condition of the type, the last transition time is only changed when the status changes
+func (s *RuleStatus) SetCondition(t RuleConditionType, status metav1.ConditionStatus, reason, message string) {
+	c := s.GetCondition(t)
+	if c == nil {
+		s.Conditions = append(s.Conditions, RuleCondition{Type: t})
+		c = &s.Conditions[len(s.Conditions)-1]
+	}
+	if c.Status != status {
+		c.LastTransitionTime = metav1.Now()
+	}
+	c.Status, c.Reason, c.Message = status, reason, message
+}
+
+// RuleObject is the interface of rule resources, which have RuleStatus as status
+// +kubebuilder:object:generate=false
+type RuleObject interface {
+	runtime.Object
+	metav1.Object
+	// GetRuleStatus returns the pointer to the status of the rule
+	GetRuleStatus() *RuleStatus
+}
@@ -45,20 +45,17 @@ type NotifierSpec struct {
 	// PagerDuty will be another notifier for slack
 }
 
-// NotifierStatus defines the observed state of Notifier, example:
+// NotifierStatus defines the observed state of Notifier, the alerts of the notifier are stored as Violation resources, example:
 // status:
-//   alerts:
-//     <RuleKind>/<RuleName>/<ResourceNamespacedName>:
-//       resourceKind: HorizontalPodAutoscaler
-//       resourceName: default/nginx
-//       severity: warning
-//       status: firing
-//       suppressed: false
 //   checkedAt: 2006-01-02T15:04:05Z07:00
+//   violations: 3
 type NotifierStatus struct {
 	// CheckedAt is the last check time of the notifier
 	CheckedAt string `json:"checkedAt"`
-	// Alerts are the map of alerts currently firing/pending for objects violate the rule
+	// Violations is the number of violations the notifier has alerts for, the alerts are in the violations' status
+	Violations int `json:"violations,omitempty"`
+	// Alerts are the alerts stored by previous versions, they're moved to Violation resources when the notifier is loaded.
+	// Deprecated: use Violation resources instead
 	Alerts map[string]alert.Alert `json:"alerts,omitempty"`
 }
 
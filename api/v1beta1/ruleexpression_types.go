@@ -0,0 +1,69 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RuleExpressionSpec defines the desired state of RuleExpression
+type RuleExpressionSpec struct {
+	// Notification contains the channels and messages to send out to external system, such as slack or pagerduty.
+	Notification Notification `json:"notification"`
+	// Enforcement is the action of the admission webhook for resources violating the rule, one of audit, warn or deny, default to audit.
+	Enforcement Enforcement `json:"enforcement,omitempty"`
+	// Selector selects name or matched labels for a resource to apply this rule
+	Selector Selector `json:"selector,omitempty"`
+	// TargetKind is the group, version and kind of the resources to evaluate, the resources are watched when the rule is created.
+	TargetKind metav1.GroupVersionKind `json:"targetKind"`
+	// Expression is the CEL expression that the resources should satisfy, the resource can be accessed as `object`, e.g. `object.spec.replicas >= 2`.
+	Expression string `json:"expression"`
+	// Message is the message when a resource doesn't satisfy the expression, CEL expressions in `{{ }}` are replaced with their values, e.g. `replicas {{ object.spec.replicas }} is less than 2`.
+	Message string `json:"message,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// RuleExpressionList contains a list of RuleExpression
+type RuleExpressionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RuleExpression `json:"items"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="Violations",type="integer",JSONPath=".status.violationCount"
+// +kubebuilder:printcolumn:name="Last Evaluated",type="date",JSONPath=".status.lastEvaluatedAt"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// RuleExpression is the Schema for the ruleexpressions API
+type RuleExpression struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RuleExpressionSpec `json:"spec,omitempty"`
+	Status RuleStatus         `json:"status,omitempty"`
+}
+
+func (r *RuleExpression) GetRuleStatus() *RuleStatus {
+	return &r.Status
+}
+
+func init() {
+	SchemeBuilder.Register(&RuleExpression{}, &RuleExpressionList{})
+}
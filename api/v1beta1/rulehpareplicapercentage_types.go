@@ -39,13 +39,23 @@ type RuleHPAReplicaPercentageList struct {
 }
 
 // +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="Violations",type="integer",JSONPath=".status.violationCount"
+// +kubebuilder:printcolumn:name="Last Evaluated",type="date",JSONPath=".status.lastEvaluatedAt"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // RuleHPAReplicaPercentage is the Schema for the rulehpareplicapercentage API
 type RuleHPAReplicaPercentage struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec RuleHPAReplicaPercentageSpec `json:"spec,omitempty"`
+	Spec   RuleHPAReplicaPercentageSpec `json:"spec,omitempty"`
+	Status RuleStatus                   `json:"status,omitempty"`
+}
+
+func (r *RuleHPAReplicaPercentage) GetRuleStatus() *RuleStatus {
+	return &r.Status
 }
 
 func init() {
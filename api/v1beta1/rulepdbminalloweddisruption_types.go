@@ -39,13 +39,23 @@ type RulePDBMinAllowedDisruptionList struct {
 }
 
 // +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="Violations",type="integer",JSONPath=".status.violationCount"
+// +kubebuilder:printcolumn:name="Last Evaluated",type="date",JSONPath=".status.lastEvaluatedAt"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // RulePDBMinAllowedDisruption is the Schema for the rulepdbminalloweddisruptions API
 type RulePDBMinAllowedDisruption struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec RulePDBMinAllowedDisruptionSpec `json:"spec,omitempty"`
+	Spec   RulePDBMinAllowedDisruptionSpec `json:"spec,omitempty"`
+	Status RuleStatus                      `json:"status,omitempty"`
+}
+
+func (r *RulePDBMinAllowedDisruption) GetRuleStatus() *RuleStatus {
+	return &r.Status
 }
 
 func init() {
@@ -0,0 +1,102 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/mercari/merlin/alert"
+)
+
+// ViolationSpec defines the rule violated by the resource, it's written by the controller.
+type ViolationSpec struct {
+	// Rule is the violated rule as <RuleKind>/<RuleName>
+	Rule string `json:"rule"`
+	// ResourceKind is the kind of the violating resource
+	ResourceKind string `json:"resourceKind"`
+	// ResourceName is the name of the violating resource as <namespace>/<name>, the namespace is empty for cluster scoped resources
+	ResourceName string `json:"resourceName"`
+	// Severity is the severity of the violation
+	Severity alert.Severity `json:"severity,omitempty"`
+	// Message is the message of the violation
+	Message string `json:"message"`
+	// Suppressed means the notifications of the violation are suppressed
+	Suppressed bool `json:"suppressed,omitempty"`
+}
+
+// ViolationNotifierStatus is the delivery state of the violation for a notifier
+type ViolationNotifierStatus struct {
+	// Name is the name of the notifier
+	Name string `json:"name"`
+	// Status is the alert status of the notifier, one of pending, firing, or recovering
+	Status alert.Status `json:"status"`
+	// Channel is the channel routed by the namespace annotation, empty for the notifier's channel
+	Channel string `json:"channel,omitempty"`
+	// Error is the error of the last delivery to the notifier
+	Error string `json:"error,omitempty"`
+	// LastTransitionTime is the last time the status changed
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+}
+
+// ViolationStatus defines the delivery state of the violation for each notifier of the rule
+type ViolationStatus struct {
+	// FirstSeen is the first time the violation was recorded
+	FirstSeen metav1.Time `json:"firstSeen,omitempty"`
+	// Notifiers are the delivery states of the violation for the notifiers
+	Notifiers []ViolationNotifierStatus `json:"notifiers,omitempty"`
+}
+
+// GetNotifier returns the delivery state of the notifier, nil if the violation isn't sent to the notifier
+func (s *ViolationStatus) GetNotifier(name string) *ViolationNotifierStatus {
+	for i := range s.Notifiers {
+		if s.Notifiers[i].Name == name {
+			return &s.Notifiers[i]
+		}
+	}
+	return nil
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Rule",type="string",JSONPath=".spec.rule"
+// +kubebuilder:printcolumn:name="Kind",type="string",JSONPath=".spec.resourceKind"
+// +kubebuilder:printcolumn:name="Resource",type="string",JSONPath=".spec.resourceName"
+// +kubebuilder:printcolumn:name="Severity",type="string",JSONPath=".spec.severity"
+// +kubebuilder:printcolumn:name="Message",type="string",JSONPath=".spec.message",priority=1
+// +kubebuilder:printcolumn:name="First Seen",type="date",JSONPath=".status.firstSeen"
+
+// Violation is the Schema for the violations API, each violation is a resource violating a rule, it lives in the
+// namespace of the resource, and the violations of cluster scoped resources live in the controller's namespace.
+type Violation struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ViolationSpec   `json:"spec,omitempty"`
+	Status ViolationStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ViolationList contains a list of Violation
+type ViolationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Violation `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Violation{}, &ViolationList{})
+}
@@ -21,29 +21,46 @@ package v1beta1
 
 import (
 	"github.com/mercari/merlin/alert"
-	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ClusterRuleConfigMapUnused) DeepCopyInto(out *ClusterRuleConfigMapUnused) {
+func (in *CertificateExpiryThreshold) DeepCopyInto(out *CertificateExpiryThreshold) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateExpiryThreshold.
+func (in *CertificateExpiryThreshold) DeepCopy() *CertificateExpiryThreshold {
+	if in == nil {
+		return nil
+	}
+	out := new(CertificateExpiryThreshold)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterRuleCertificateExpiry) DeepCopyInto(out *ClusterRuleCertificateExpiry) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleConfigMapUnused.
-func (in *ClusterRuleConfigMapUnused) DeepCopy() *ClusterRuleConfigMapUnused {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleCertificateExpiry.
+func (in *ClusterRuleCertificateExpiry) DeepCopy() *ClusterRuleCertificateExpiry {
 	if in == nil {
 		return nil
 	}
-	out := new(ClusterRuleConfigMapUnused)
+	out := new(ClusterRuleCertificateExpiry)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ClusterRuleConfigMapUnused) DeepCopyObject() runtime.Object {
+func (in *ClusterRuleCertificateExpiry) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -51,31 +68,31 @@ func (in *ClusterRuleConfigMapUnused) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ClusterRuleConfigMapUnusedList) DeepCopyInto(out *ClusterRuleConfigMapUnusedList) {
+func (in *ClusterRuleCertificateExpiryList) DeepCopyInto(out *ClusterRuleCertificateExpiryList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]ClusterRuleConfigMapUnused, len(*in))
+		*out = make([]ClusterRuleCertificateExpiry, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleConfigMapUnusedList.
-func (in *ClusterRuleConfigMapUnusedList) DeepCopy() *ClusterRuleConfigMapUnusedList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleCertificateExpiryList.
+func (in *ClusterRuleCertificateExpiryList) DeepCopy() *ClusterRuleCertificateExpiryList {
 	if in == nil {
 		return nil
 	}
-	out := new(ClusterRuleConfigMapUnusedList)
+	out := new(ClusterRuleCertificateExpiryList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ClusterRuleConfigMapUnusedList) DeepCopyObject() runtime.Object {
+func (in *ClusterRuleCertificateExpiryList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -83,7 +100,7 @@ func (in *ClusterRuleConfigMapUnusedList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ClusterRuleConfigMapUnusedSpec) DeepCopyInto(out *ClusterRuleConfigMapUnusedSpec) {
+func (in *ClusterRuleCertificateExpirySpec) DeepCopyInto(out *ClusterRuleCertificateExpirySpec) {
 	*out = *in
 	if in.IgnoreNamespaces != nil {
 		in, out := &in.IgnoreNamespaces, &out.IgnoreNamespaces
@@ -91,38 +108,49 @@ func (in *ClusterRuleConfigMapUnusedSpec) DeepCopyInto(out *ClusterRuleConfigMap
 		copy(*out, *in)
 	}
 	in.Notification.DeepCopyInto(&out.Notification)
+	if in.Keys != nil {
+		in, out := &in.Keys, &out.Keys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Thresholds != nil {
+		in, out := &in.Thresholds, &out.Thresholds
+		*out = make([]CertificateExpiryThreshold, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleConfigMapUnusedSpec.
-func (in *ClusterRuleConfigMapUnusedSpec) DeepCopy() *ClusterRuleConfigMapUnusedSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleCertificateExpirySpec.
+func (in *ClusterRuleCertificateExpirySpec) DeepCopy() *ClusterRuleCertificateExpirySpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ClusterRuleConfigMapUnusedSpec)
+	out := new(ClusterRuleCertificateExpirySpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ClusterRuleHPAInvalidScaleTargetRef) DeepCopyInto(out *ClusterRuleHPAInvalidScaleTargetRef) {
+func (in *ClusterRuleConfigMapUnused) DeepCopyInto(out *ClusterRuleConfigMapUnused) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleHPAInvalidScaleTargetRef.
-func (in *ClusterRuleHPAInvalidScaleTargetRef) DeepCopy() *ClusterRuleHPAInvalidScaleTargetRef {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleConfigMapUnused.
+func (in *ClusterRuleConfigMapUnused) DeepCopy() *ClusterRuleConfigMapUnused {
 	if in == nil {
 		return nil
 	}
-	out := new(ClusterRuleHPAInvalidScaleTargetRef)
+	out := new(ClusterRuleConfigMapUnused)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ClusterRuleHPAInvalidScaleTargetRef) DeepCopyObject() runtime.Object {
+func (in *ClusterRuleConfigMapUnused) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -130,31 +158,31 @@ func (in *ClusterRuleHPAInvalidScaleTargetRef) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ClusterRuleHPAInvalidScaleTargetRefList) DeepCopyInto(out *ClusterRuleHPAInvalidScaleTargetRefList) {
+func (in *ClusterRuleConfigMapUnusedList) DeepCopyInto(out *ClusterRuleConfigMapUnusedList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]ClusterRuleHPAInvalidScaleTargetRef, len(*in))
+		*out = make([]ClusterRuleConfigMapUnused, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleHPAInvalidScaleTargetRefList.
-func (in *ClusterRuleHPAInvalidScaleTargetRefList) DeepCopy() *ClusterRuleHPAInvalidScaleTargetRefList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleConfigMapUnusedList.
+func (in *ClusterRuleConfigMapUnusedList) DeepCopy() *ClusterRuleConfigMapUnusedList {
 	if in == nil {
 		return nil
 	}
-	out := new(ClusterRuleHPAInvalidScaleTargetRefList)
+	out := new(ClusterRuleConfigMapUnusedList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ClusterRuleHPAInvalidScaleTargetRefList) DeepCopyObject() runtime.Object {
+func (in *ClusterRuleConfigMapUnusedList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -162,7 +190,7 @@ func (in *ClusterRuleHPAInvalidScaleTargetRefList) DeepCopyObject() runtime.Obje
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ClusterRuleHPAInvalidScaleTargetRefSpec) DeepCopyInto(out *ClusterRuleHPAInvalidScaleTargetRefSpec) {
+func (in *ClusterRuleConfigMapUnusedSpec) DeepCopyInto(out *ClusterRuleConfigMapUnusedSpec) {
 	*out = *in
 	if in.IgnoreNamespaces != nil {
 		in, out := &in.IgnoreNamespaces, &out.IgnoreNamespaces
@@ -172,36 +200,37 @@ func (in *ClusterRuleHPAInvalidScaleTargetRefSpec) DeepCopyInto(out *ClusterRule
 	in.Notification.DeepCopyInto(&out.Notification)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleHPAInvalidScaleTargetRefSpec.
-func (in *ClusterRuleHPAInvalidScaleTargetRefSpec) DeepCopy() *ClusterRuleHPAInvalidScaleTargetRefSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleConfigMapUnusedSpec.
+func (in *ClusterRuleConfigMapUnusedSpec) DeepCopy() *ClusterRuleConfigMapUnusedSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ClusterRuleHPAInvalidScaleTargetRefSpec)
+	out := new(ClusterRuleConfigMapUnusedSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ClusterRuleHPAReplicaPercentage) DeepCopyInto(out *ClusterRuleHPAReplicaPercentage) {
+func (in *ClusterRuleCronJobHealth) DeepCopyInto(out *ClusterRuleCronJobHealth) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleHPAReplicaPercentage.
-func (in *ClusterRuleHPAReplicaPercentage) DeepCopy() *ClusterRuleHPAReplicaPercentage {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleCronJobHealth.
+func (in *ClusterRuleCronJobHealth) DeepCopy() *ClusterRuleCronJobHealth {
 	if in == nil {
 		return nil
 	}
-	out := new(ClusterRuleHPAReplicaPercentage)
+	out := new(ClusterRuleCronJobHealth)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ClusterRuleHPAReplicaPercentage) DeepCopyObject() runtime.Object {
+func (in *ClusterRuleCronJobHealth) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -209,31 +238,31 @@ func (in *ClusterRuleHPAReplicaPercentage) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ClusterRuleHPAReplicaPercentageList) DeepCopyInto(out *ClusterRuleHPAReplicaPercentageList) {
+func (in *ClusterRuleCronJobHealthList) DeepCopyInto(out *ClusterRuleCronJobHealthList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]ClusterRuleHPAReplicaPercentage, len(*in))
+		*out = make([]ClusterRuleCronJobHealth, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleHPAReplicaPercentageList.
-func (in *ClusterRuleHPAReplicaPercentageList) DeepCopy() *ClusterRuleHPAReplicaPercentageList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleCronJobHealthList.
+func (in *ClusterRuleCronJobHealthList) DeepCopy() *ClusterRuleCronJobHealthList {
 	if in == nil {
 		return nil
 	}
-	out := new(ClusterRuleHPAReplicaPercentageList)
+	out := new(ClusterRuleCronJobHealthList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ClusterRuleHPAReplicaPercentageList) DeepCopyObject() runtime.Object {
+func (in *ClusterRuleCronJobHealthList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -241,7 +270,7 @@ func (in *ClusterRuleHPAReplicaPercentageList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ClusterRuleHPAReplicaPercentageSpec) DeepCopyInto(out *ClusterRuleHPAReplicaPercentageSpec) {
+func (in *ClusterRuleCronJobHealthSpec) DeepCopyInto(out *ClusterRuleCronJobHealthSpec) {
 	*out = *in
 	if in.IgnoreNamespaces != nil {
 		in, out := &in.IgnoreNamespaces, &out.IgnoreNamespaces
@@ -251,36 +280,37 @@ func (in *ClusterRuleHPAReplicaPercentageSpec) DeepCopyInto(out *ClusterRuleHPAR
 	in.Notification.DeepCopyInto(&out.Notification)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleHPAReplicaPercentageSpec.
-func (in *ClusterRuleHPAReplicaPercentageSpec) DeepCopy() *ClusterRuleHPAReplicaPercentageSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleCronJobHealthSpec.
+func (in *ClusterRuleCronJobHealthSpec) DeepCopy() *ClusterRuleCronJobHealthSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ClusterRuleHPAReplicaPercentageSpec)
+	out := new(ClusterRuleCronJobHealthSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ClusterRuleNamespaceRequiredLabel) DeepCopyInto(out *ClusterRuleNamespaceRequiredLabel) {
+func (in *ClusterRuleDeprecatedAPI) DeepCopyInto(out *ClusterRuleDeprecatedAPI) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleNamespaceRequiredLabel.
-func (in *ClusterRuleNamespaceRequiredLabel) DeepCopy() *ClusterRuleNamespaceRequiredLabel {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleDeprecatedAPI.
+func (in *ClusterRuleDeprecatedAPI) DeepCopy() *ClusterRuleDeprecatedAPI {
 	if in == nil {
 		return nil
 	}
-	out := new(ClusterRuleNamespaceRequiredLabel)
+	out := new(ClusterRuleDeprecatedAPI)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ClusterRuleNamespaceRequiredLabel) DeepCopyObject() runtime.Object {
+func (in *ClusterRuleDeprecatedAPI) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -288,31 +318,31 @@ func (in *ClusterRuleNamespaceRequiredLabel) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ClusterRuleNamespaceRequiredLabelList) DeepCopyInto(out *ClusterRuleNamespaceRequiredLabelList) {
+func (in *ClusterRuleDeprecatedAPIList) DeepCopyInto(out *ClusterRuleDeprecatedAPIList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]ClusterRuleNamespaceRequiredLabel, len(*in))
+		*out = make([]ClusterRuleDeprecatedAPI, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleNamespaceRequiredLabelList.
-func (in *ClusterRuleNamespaceRequiredLabelList) DeepCopy() *ClusterRuleNamespaceRequiredLabelList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleDeprecatedAPIList.
+func (in *ClusterRuleDeprecatedAPIList) DeepCopy() *ClusterRuleDeprecatedAPIList {
 	if in == nil {
 		return nil
 	}
-	out := new(ClusterRuleNamespaceRequiredLabelList)
+	out := new(ClusterRuleDeprecatedAPIList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ClusterRuleNamespaceRequiredLabelList) DeepCopyObject() runtime.Object {
+func (in *ClusterRuleDeprecatedAPIList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -320,7 +350,7 @@ func (in *ClusterRuleNamespaceRequiredLabelList) DeepCopyObject() runtime.Object
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ClusterRuleNamespaceRequiredLabelSpec) DeepCopyInto(out *ClusterRuleNamespaceRequiredLabelSpec) {
+func (in *ClusterRuleDeprecatedAPISpec) DeepCopyInto(out *ClusterRuleDeprecatedAPISpec) {
 	*out = *in
 	if in.IgnoreNamespaces != nil {
 		in, out := &in.IgnoreNamespaces, &out.IgnoreNamespaces
@@ -328,39 +358,39 @@ func (in *ClusterRuleNamespaceRequiredLabelSpec) DeepCopyInto(out *ClusterRuleNa
 		copy(*out, *in)
 	}
 	in.Notification.DeepCopyInto(&out.Notification)
-	out.Label = in.Label
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleNamespaceRequiredLabelSpec.
-func (in *ClusterRuleNamespaceRequiredLabelSpec) DeepCopy() *ClusterRuleNamespaceRequiredLabelSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleDeprecatedAPISpec.
+func (in *ClusterRuleDeprecatedAPISpec) DeepCopy() *ClusterRuleDeprecatedAPISpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ClusterRuleNamespaceRequiredLabelSpec)
+	out := new(ClusterRuleDeprecatedAPISpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ClusterRulePDBInvalidSelector) DeepCopyInto(out *ClusterRulePDBInvalidSelector) {
+func (in *ClusterRuleExpression) DeepCopyInto(out *ClusterRuleExpression) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRulePDBInvalidSelector.
-func (in *ClusterRulePDBInvalidSelector) DeepCopy() *ClusterRulePDBInvalidSelector {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleExpression.
+func (in *ClusterRuleExpression) DeepCopy() *ClusterRuleExpression {
 	if in == nil {
 		return nil
 	}
-	out := new(ClusterRulePDBInvalidSelector)
+	out := new(ClusterRuleExpression)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ClusterRulePDBInvalidSelector) DeepCopyObject() runtime.Object {
+func (in *ClusterRuleExpression) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -368,31 +398,31 @@ func (in *ClusterRulePDBInvalidSelector) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ClusterRulePDBInvalidSelectorList) DeepCopyInto(out *ClusterRulePDBInvalidSelectorList) {
+func (in *ClusterRuleExpressionList) DeepCopyInto(out *ClusterRuleExpressionList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]ClusterRulePDBInvalidSelector, len(*in))
+		*out = make([]ClusterRuleExpression, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRulePDBInvalidSelectorList.
-func (in *ClusterRulePDBInvalidSelectorList) DeepCopy() *ClusterRulePDBInvalidSelectorList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleExpressionList.
+func (in *ClusterRuleExpressionList) DeepCopy() *ClusterRuleExpressionList {
 	if in == nil {
 		return nil
 	}
-	out := new(ClusterRulePDBInvalidSelectorList)
+	out := new(ClusterRuleExpressionList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ClusterRulePDBInvalidSelectorList) DeepCopyObject() runtime.Object {
+func (in *ClusterRuleExpressionList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -400,46 +430,49 @@ func (in *ClusterRulePDBInvalidSelectorList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ClusterRulePDBInvalidSelectorSpec) DeepCopyInto(out *ClusterRulePDBInvalidSelectorSpec) {
+func (in *ClusterRuleExpressionSpec) DeepCopyInto(out *ClusterRuleExpressionSpec) {
 	*out = *in
 	if in.IgnoreNamespaces != nil {
 		in, out := &in.IgnoreNamespaces, &out.IgnoreNamespaces
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	in.Selector.DeepCopyInto(&out.Selector)
+	out.TargetKind = in.TargetKind
 	in.Notification.DeepCopyInto(&out.Notification)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRulePDBInvalidSelectorSpec.
-func (in *ClusterRulePDBInvalidSelectorSpec) DeepCopy() *ClusterRulePDBInvalidSelectorSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleExpressionSpec.
+func (in *ClusterRuleExpressionSpec) DeepCopy() *ClusterRuleExpressionSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ClusterRulePDBInvalidSelectorSpec)
+	out := new(ClusterRuleExpressionSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ClusterRulePDBMinAllowedDisruption) DeepCopyInto(out *ClusterRulePDBMinAllowedDisruption) {
+func (in *ClusterRuleHPAInvalidScaleTargetRef) DeepCopyInto(out *ClusterRuleHPAInvalidScaleTargetRef) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRulePDBMinAllowedDisruption.
-func (in *ClusterRulePDBMinAllowedDisruption) DeepCopy() *ClusterRulePDBMinAllowedDisruption {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleHPAInvalidScaleTargetRef.
+func (in *ClusterRuleHPAInvalidScaleTargetRef) DeepCopy() *ClusterRuleHPAInvalidScaleTargetRef {
 	if in == nil {
 		return nil
 	}
-	out := new(ClusterRulePDBMinAllowedDisruption)
+	out := new(ClusterRuleHPAInvalidScaleTargetRef)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ClusterRulePDBMinAllowedDisruption) DeepCopyObject() runtime.Object {
+func (in *ClusterRuleHPAInvalidScaleTargetRef) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -447,31 +480,31 @@ func (in *ClusterRulePDBMinAllowedDisruption) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ClusterRulePDBMinAllowedDisruptionList) DeepCopyInto(out *ClusterRulePDBMinAllowedDisruptionList) {
+func (in *ClusterRuleHPAInvalidScaleTargetRefList) DeepCopyInto(out *ClusterRuleHPAInvalidScaleTargetRefList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]ClusterRulePDBMinAllowedDisruption, len(*in))
+		*out = make([]ClusterRuleHPAInvalidScaleTargetRef, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRulePDBMinAllowedDisruptionList.
-func (in *ClusterRulePDBMinAllowedDisruptionList) DeepCopy() *ClusterRulePDBMinAllowedDisruptionList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleHPAInvalidScaleTargetRefList.
+func (in *ClusterRuleHPAInvalidScaleTargetRefList) DeepCopy() *ClusterRuleHPAInvalidScaleTargetRefList {
 	if in == nil {
 		return nil
 	}
-	out := new(ClusterRulePDBMinAllowedDisruptionList)
+	out := new(ClusterRuleHPAInvalidScaleTargetRefList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ClusterRulePDBMinAllowedDisruptionList) DeepCopyObject() runtime.Object {
+func (in *ClusterRuleHPAInvalidScaleTargetRefList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -479,7 +512,7 @@ func (in *ClusterRulePDBMinAllowedDisruptionList) DeepCopyObject() runtime.Objec
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ClusterRulePDBMinAllowedDisruptionSpec) DeepCopyInto(out *ClusterRulePDBMinAllowedDisruptionSpec) {
+func (in *ClusterRuleHPAInvalidScaleTargetRefSpec) DeepCopyInto(out *ClusterRuleHPAInvalidScaleTargetRefSpec) {
 	*out = *in
 	if in.IgnoreNamespaces != nil {
 		in, out := &in.IgnoreNamespaces, &out.IgnoreNamespaces
@@ -489,36 +522,37 @@ func (in *ClusterRulePDBMinAllowedDisruptionSpec) DeepCopyInto(out *ClusterRuleP
 	in.Notification.DeepCopyInto(&out.Notification)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRulePDBMinAllowedDisruptionSpec.
-func (in *ClusterRulePDBMinAllowedDisruptionSpec) DeepCopy() *ClusterRulePDBMinAllowedDisruptionSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleHPAInvalidScaleTargetRefSpec.
+func (in *ClusterRuleHPAInvalidScaleTargetRefSpec) DeepCopy() *ClusterRuleHPAInvalidScaleTargetRefSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ClusterRulePDBMinAllowedDisruptionSpec)
+	out := new(ClusterRuleHPAInvalidScaleTargetRefSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ClusterRuleSecretUnused) DeepCopyInto(out *ClusterRuleSecretUnused) {
+func (in *ClusterRuleHPAReplicaPercentage) DeepCopyInto(out *ClusterRuleHPAReplicaPercentage) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleSecretUnused.
-func (in *ClusterRuleSecretUnused) DeepCopy() *ClusterRuleSecretUnused {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleHPAReplicaPercentage.
+func (in *ClusterRuleHPAReplicaPercentage) DeepCopy() *ClusterRuleHPAReplicaPercentage {
 	if in == nil {
 		return nil
 	}
-	out := new(ClusterRuleSecretUnused)
+	out := new(ClusterRuleHPAReplicaPercentage)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ClusterRuleSecretUnused) DeepCopyObject() runtime.Object {
+func (in *ClusterRuleHPAReplicaPercentage) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -526,31 +560,31 @@ func (in *ClusterRuleSecretUnused) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ClusterRuleSecretUnusedList) DeepCopyInto(out *ClusterRuleSecretUnusedList) {
+func (in *ClusterRuleHPAReplicaPercentageList) DeepCopyInto(out *ClusterRuleHPAReplicaPercentageList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]ClusterRuleSecretUnused, len(*in))
+		*out = make([]ClusterRuleHPAReplicaPercentage, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleSecretUnusedList.
-func (in *ClusterRuleSecretUnusedList) DeepCopy() *ClusterRuleSecretUnusedList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleHPAReplicaPercentageList.
+func (in *ClusterRuleHPAReplicaPercentageList) DeepCopy() *ClusterRuleHPAReplicaPercentageList {
 	if in == nil {
 		return nil
 	}
-	out := new(ClusterRuleSecretUnusedList)
+	out := new(ClusterRuleHPAReplicaPercentageList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ClusterRuleSecretUnusedList) DeepCopyObject() runtime.Object {
+func (in *ClusterRuleHPAReplicaPercentageList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -558,7 +592,7 @@ func (in *ClusterRuleSecretUnusedList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ClusterRuleSecretUnusedSpec) DeepCopyInto(out *ClusterRuleSecretUnusedSpec) {
+func (in *ClusterRuleHPAReplicaPercentageSpec) DeepCopyInto(out *ClusterRuleHPAReplicaPercentageSpec) {
 	*out = *in
 	if in.IgnoreNamespaces != nil {
 		in, out := &in.IgnoreNamespaces, &out.IgnoreNamespaces
@@ -568,36 +602,37 @@ func (in *ClusterRuleSecretUnusedSpec) DeepCopyInto(out *ClusterRuleSecretUnused
 	in.Notification.DeepCopyInto(&out.Notification)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleSecretUnusedSpec.
-func (in *ClusterRuleSecretUnusedSpec) DeepCopy() *ClusterRuleSecretUnusedSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleHPAReplicaPercentageSpec.
+func (in *ClusterRuleHPAReplicaPercentageSpec) DeepCopy() *ClusterRuleHPAReplicaPercentageSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ClusterRuleSecretUnusedSpec)
+	out := new(ClusterRuleHPAReplicaPercentageSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ClusterRuleServiceInvalidSelector) DeepCopyInto(out *ClusterRuleServiceInvalidSelector) {
+func (in *ClusterRuleIngressInvalidBackend) DeepCopyInto(out *ClusterRuleIngressInvalidBackend) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleServiceInvalidSelector.
-func (in *ClusterRuleServiceInvalidSelector) DeepCopy() *ClusterRuleServiceInvalidSelector {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleIngressInvalidBackend.
+func (in *ClusterRuleIngressInvalidBackend) DeepCopy() *ClusterRuleIngressInvalidBackend {
 	if in == nil {
 		return nil
 	}
-	out := new(ClusterRuleServiceInvalidSelector)
+	out := new(ClusterRuleIngressInvalidBackend)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ClusterRuleServiceInvalidSelector) DeepCopyObject() runtime.Object {
+func (in *ClusterRuleIngressInvalidBackend) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -605,31 +640,31 @@ func (in *ClusterRuleServiceInvalidSelector) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ClusterRuleServiceInvalidSelectorList) DeepCopyInto(out *ClusterRuleServiceInvalidSelectorList) {
+func (in *ClusterRuleIngressInvalidBackendList) DeepCopyInto(out *ClusterRuleIngressInvalidBackendList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]ClusterRuleServiceInvalidSelector, len(*in))
+		*out = make([]ClusterRuleIngressInvalidBackend, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleServiceInvalidSelectorList.
-func (in *ClusterRuleServiceInvalidSelectorList) DeepCopy() *ClusterRuleServiceInvalidSelectorList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleIngressInvalidBackendList.
+func (in *ClusterRuleIngressInvalidBackendList) DeepCopy() *ClusterRuleIngressInvalidBackendList {
 	if in == nil {
 		return nil
 	}
-	out := new(ClusterRuleServiceInvalidSelectorList)
+	out := new(ClusterRuleIngressInvalidBackendList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ClusterRuleServiceInvalidSelectorList) DeepCopyObject() runtime.Object {
+func (in *ClusterRuleIngressInvalidBackendList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -637,7 +672,7 @@ func (in *ClusterRuleServiceInvalidSelectorList) DeepCopyObject() runtime.Object
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ClusterRuleServiceInvalidSelectorSpec) DeepCopyInto(out *ClusterRuleServiceInvalidSelectorSpec) {
+func (in *ClusterRuleIngressInvalidBackendSpec) DeepCopyInto(out *ClusterRuleIngressInvalidBackendSpec) {
 	*out = *in
 	if in.IgnoreNamespaces != nil {
 		in, out := &in.IgnoreNamespaces, &out.IgnoreNamespaces
@@ -647,57 +682,37 @@ func (in *ClusterRuleServiceInvalidSelectorSpec) DeepCopyInto(out *ClusterRuleSe
 	in.Notification.DeepCopyInto(&out.Notification)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleServiceInvalidSelectorSpec.
-func (in *ClusterRuleServiceInvalidSelectorSpec) DeepCopy() *ClusterRuleServiceInvalidSelectorSpec {
-	if in == nil {
-		return nil
-	}
-	out := new(ClusterRuleServiceInvalidSelectorSpec)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Notification) DeepCopyInto(out *Notification) {
-	*out = *in
-	if in.Notifiers != nil {
-		in, out := &in.Notifiers, &out.Notifiers
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Notification.
-func (in *Notification) DeepCopy() *Notification {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleIngressInvalidBackendSpec.
+func (in *ClusterRuleIngressInvalidBackendSpec) DeepCopy() *ClusterRuleIngressInvalidBackendSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(Notification)
+	out := new(ClusterRuleIngressInvalidBackendSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Notifier) DeepCopyInto(out *Notifier) {
+func (in *ClusterRuleNamespaceQuota) DeepCopyInto(out *ClusterRuleNamespaceQuota) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Notifier.
-func (in *Notifier) DeepCopy() *Notifier {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleNamespaceQuota.
+func (in *ClusterRuleNamespaceQuota) DeepCopy() *ClusterRuleNamespaceQuota {
 	if in == nil {
 		return nil
 	}
-	out := new(Notifier)
+	out := new(ClusterRuleNamespaceQuota)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *Notifier) DeepCopyObject() runtime.Object {
+func (in *ClusterRuleNamespaceQuota) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -705,31 +720,31 @@ func (in *Notifier) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NotifierList) DeepCopyInto(out *NotifierList) {
+func (in *ClusterRuleNamespaceQuotaList) DeepCopyInto(out *ClusterRuleNamespaceQuotaList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]Notifier, len(*in))
+		*out = make([]ClusterRuleNamespaceQuota, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotifierList.
-func (in *NotifierList) DeepCopy() *NotifierList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleNamespaceQuotaList.
+func (in *ClusterRuleNamespaceQuotaList) DeepCopy() *ClusterRuleNamespaceQuotaList {
 	if in == nil {
 		return nil
 	}
-	out := new(NotifierList)
+	out := new(ClusterRuleNamespaceQuotaList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *NotifierList) DeepCopyObject() runtime.Object {
+func (in *ClusterRuleNamespaceQuotaList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -737,46 +752,1582 @@ func (in *NotifierList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NotifierSpec) DeepCopyInto(out *NotifierSpec) {
+func (in *ClusterRuleNamespaceQuotaSpec) DeepCopyInto(out *ClusterRuleNamespaceQuotaSpec) {
 	*out = *in
-	out.Slack = in.Slack
+	if in.IgnoreNamespaces != nil {
+		in, out := &in.IgnoreNamespaces, &out.IgnoreNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	in.Notification.DeepCopyInto(&out.Notification)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotifierSpec.
-func (in *NotifierSpec) DeepCopy() *NotifierSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleNamespaceQuotaSpec.
+func (in *ClusterRuleNamespaceQuotaSpec) DeepCopy() *ClusterRuleNamespaceQuotaSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(NotifierSpec)
+	out := new(ClusterRuleNamespaceQuotaSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NotifierStatus) DeepCopyInto(out *NotifierStatus) {
+func (in *ClusterRuleNamespaceRequiredLabel) DeepCopyInto(out *ClusterRuleNamespaceRequiredLabel) {
 	*out = *in
-	if in.Alerts != nil {
-		in, out := &in.Alerts, &out.Alerts
-		*out = make(map[string]alert.Alert, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotifierStatus.
-func (in *NotifierStatus) DeepCopy() *NotifierStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleNamespaceRequiredLabel.
+func (in *ClusterRuleNamespaceRequiredLabel) DeepCopy() *ClusterRuleNamespaceRequiredLabel {
 	if in == nil {
 		return nil
 	}
-	out := new(NotifierStatus)
+	out := new(ClusterRuleNamespaceRequiredLabel)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RequiredLabel) DeepCopyInto(out *RequiredLabel) {
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterRuleNamespaceRequiredLabel) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterRuleNamespaceRequiredLabelList) DeepCopyInto(out *ClusterRuleNamespaceRequiredLabelList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterRuleNamespaceRequiredLabel, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleNamespaceRequiredLabelList.
+func (in *ClusterRuleNamespaceRequiredLabelList) DeepCopy() *ClusterRuleNamespaceRequiredLabelList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterRuleNamespaceRequiredLabelList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterRuleNamespaceRequiredLabelList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterRuleNamespaceRequiredLabelSpec) DeepCopyInto(out *ClusterRuleNamespaceRequiredLabelSpec) {
+	*out = *in
+	if in.IgnoreNamespaces != nil {
+		in, out := &in.IgnoreNamespaces, &out.IgnoreNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.Notification.DeepCopyInto(&out.Notification)
+	in.Label.DeepCopyInto(&out.Label)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleNamespaceRequiredLabelSpec.
+func (in *ClusterRuleNamespaceRequiredLabelSpec) DeepCopy() *ClusterRuleNamespaceRequiredLabelSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterRuleNamespaceRequiredLabelSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterRuleNetworkPolicyCoverage) DeepCopyInto(out *ClusterRuleNetworkPolicyCoverage) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleNetworkPolicyCoverage.
+func (in *ClusterRuleNetworkPolicyCoverage) DeepCopy() *ClusterRuleNetworkPolicyCoverage {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterRuleNetworkPolicyCoverage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterRuleNetworkPolicyCoverage) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterRuleNetworkPolicyCoverageList) DeepCopyInto(out *ClusterRuleNetworkPolicyCoverageList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterRuleNetworkPolicyCoverage, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleNetworkPolicyCoverageList.
+func (in *ClusterRuleNetworkPolicyCoverageList) DeepCopy() *ClusterRuleNetworkPolicyCoverageList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterRuleNetworkPolicyCoverageList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterRuleNetworkPolicyCoverageList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterRuleNetworkPolicyCoverageSpec) DeepCopyInto(out *ClusterRuleNetworkPolicyCoverageSpec) {
+	*out = *in
+	if in.IgnoreNamespaces != nil {
+		in, out := &in.IgnoreNamespaces, &out.IgnoreNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DefaultDenyPolicyTypes != nil {
+		in, out := &in.DefaultDenyPolicyTypes, &out.DefaultDenyPolicyTypes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.Notification.DeepCopyInto(&out.Notification)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleNetworkPolicyCoverageSpec.
+func (in *ClusterRuleNetworkPolicyCoverageSpec) DeepCopy() *ClusterRuleNetworkPolicyCoverageSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterRuleNetworkPolicyCoverageSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterRulePDBInvalidSelector) DeepCopyInto(out *ClusterRulePDBInvalidSelector) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRulePDBInvalidSelector.
+func (in *ClusterRulePDBInvalidSelector) DeepCopy() *ClusterRulePDBInvalidSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterRulePDBInvalidSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterRulePDBInvalidSelector) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterRulePDBInvalidSelectorList) DeepCopyInto(out *ClusterRulePDBInvalidSelectorList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterRulePDBInvalidSelector, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRulePDBInvalidSelectorList.
+func (in *ClusterRulePDBInvalidSelectorList) DeepCopy() *ClusterRulePDBInvalidSelectorList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterRulePDBInvalidSelectorList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterRulePDBInvalidSelectorList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterRulePDBInvalidSelectorSpec) DeepCopyInto(out *ClusterRulePDBInvalidSelectorSpec) {
+	*out = *in
+	if in.IgnoreNamespaces != nil {
+		in, out := &in.IgnoreNamespaces, &out.IgnoreNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.Notification.DeepCopyInto(&out.Notification)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRulePDBInvalidSelectorSpec.
+func (in *ClusterRulePDBInvalidSelectorSpec) DeepCopy() *ClusterRulePDBInvalidSelectorSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterRulePDBInvalidSelectorSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterRulePDBMinAllowedDisruption) DeepCopyInto(out *ClusterRulePDBMinAllowedDisruption) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRulePDBMinAllowedDisruption.
+func (in *ClusterRulePDBMinAllowedDisruption) DeepCopy() *ClusterRulePDBMinAllowedDisruption {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterRulePDBMinAllowedDisruption)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterRulePDBMinAllowedDisruption) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterRulePDBMinAllowedDisruptionList) DeepCopyInto(out *ClusterRulePDBMinAllowedDisruptionList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterRulePDBMinAllowedDisruption, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRulePDBMinAllowedDisruptionList.
+func (in *ClusterRulePDBMinAllowedDisruptionList) DeepCopy() *ClusterRulePDBMinAllowedDisruptionList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterRulePDBMinAllowedDisruptionList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterRulePDBMinAllowedDisruptionList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterRulePDBMinAllowedDisruptionSpec) DeepCopyInto(out *ClusterRulePDBMinAllowedDisruptionSpec) {
+	*out = *in
+	if in.IgnoreNamespaces != nil {
+		in, out := &in.IgnoreNamespaces, &out.IgnoreNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.Notification.DeepCopyInto(&out.Notification)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRulePDBMinAllowedDisruptionSpec.
+func (in *ClusterRulePDBMinAllowedDisruptionSpec) DeepCopy() *ClusterRulePDBMinAllowedDisruptionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterRulePDBMinAllowedDisruptionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterRulePDBOverlap) DeepCopyInto(out *ClusterRulePDBOverlap) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRulePDBOverlap.
+func (in *ClusterRulePDBOverlap) DeepCopy() *ClusterRulePDBOverlap {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterRulePDBOverlap)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterRulePDBOverlap) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterRulePDBOverlapList) DeepCopyInto(out *ClusterRulePDBOverlapList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterRulePDBOverlap, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRulePDBOverlapList.
+func (in *ClusterRulePDBOverlapList) DeepCopy() *ClusterRulePDBOverlapList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterRulePDBOverlapList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterRulePDBOverlapList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterRulePDBOverlapSpec) DeepCopyInto(out *ClusterRulePDBOverlapSpec) {
+	*out = *in
+	if in.IgnoreNamespaces != nil {
+		in, out := &in.IgnoreNamespaces, &out.IgnoreNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.Notification.DeepCopyInto(&out.Notification)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRulePDBOverlapSpec.
+func (in *ClusterRulePDBOverlapSpec) DeepCopy() *ClusterRulePDBOverlapSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterRulePDBOverlapSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterRulePVCUnused) DeepCopyInto(out *ClusterRulePVCUnused) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRulePVCUnused.
+func (in *ClusterRulePVCUnused) DeepCopy() *ClusterRulePVCUnused {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterRulePVCUnused)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterRulePVCUnused) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterRulePVCUnusedList) DeepCopyInto(out *ClusterRulePVCUnusedList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterRulePVCUnused, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRulePVCUnusedList.
+func (in *ClusterRulePVCUnusedList) DeepCopy() *ClusterRulePVCUnusedList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterRulePVCUnusedList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterRulePVCUnusedList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterRulePVCUnusedSpec) DeepCopyInto(out *ClusterRulePVCUnusedSpec) {
+	*out = *in
+	if in.IgnoreNamespaces != nil {
+		in, out := &in.IgnoreNamespaces, &out.IgnoreNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.Notification.DeepCopyInto(&out.Notification)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRulePVCUnusedSpec.
+func (in *ClusterRulePVCUnusedSpec) DeepCopy() *ClusterRulePVCUnusedSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterRulePVCUnusedSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterRulePodHealth) DeepCopyInto(out *ClusterRulePodHealth) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRulePodHealth.
+func (in *ClusterRulePodHealth) DeepCopy() *ClusterRulePodHealth {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterRulePodHealth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterRulePodHealth) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterRulePodHealthList) DeepCopyInto(out *ClusterRulePodHealthList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterRulePodHealth, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRulePodHealthList.
+func (in *ClusterRulePodHealthList) DeepCopy() *ClusterRulePodHealthList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterRulePodHealthList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterRulePodHealthList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterRulePodHealthSpec) DeepCopyInto(out *ClusterRulePodHealthSpec) {
+	*out = *in
+	if in.IgnoreNamespaces != nil {
+		in, out := &in.IgnoreNamespaces, &out.IgnoreNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.Notification.DeepCopyInto(&out.Notification)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRulePodHealthSpec.
+func (in *ClusterRulePodHealthSpec) DeepCopy() *ClusterRulePodHealthSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterRulePodHealthSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterRulePodPending) DeepCopyInto(out *ClusterRulePodPending) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRulePodPending.
+func (in *ClusterRulePodPending) DeepCopy() *ClusterRulePodPending {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterRulePodPending)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterRulePodPending) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterRulePodPendingList) DeepCopyInto(out *ClusterRulePodPendingList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterRulePodPending, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRulePodPendingList.
+func (in *ClusterRulePodPendingList) DeepCopy() *ClusterRulePodPendingList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterRulePodPendingList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterRulePodPendingList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterRulePodPendingSpec) DeepCopyInto(out *ClusterRulePodPendingSpec) {
+	*out = *in
+	if in.IgnoreNamespaces != nil {
+		in, out := &in.IgnoreNamespaces, &out.IgnoreNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.Notification.DeepCopyInto(&out.Notification)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRulePodPendingSpec.
+func (in *ClusterRulePodPendingSpec) DeepCopy() *ClusterRulePodPendingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterRulePodPendingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterRuleRBACDanglingBinding) DeepCopyInto(out *ClusterRuleRBACDanglingBinding) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleRBACDanglingBinding.
+func (in *ClusterRuleRBACDanglingBinding) DeepCopy() *ClusterRuleRBACDanglingBinding {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterRuleRBACDanglingBinding)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterRuleRBACDanglingBinding) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterRuleRBACDanglingBindingList) DeepCopyInto(out *ClusterRuleRBACDanglingBindingList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterRuleRBACDanglingBinding, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleRBACDanglingBindingList.
+func (in *ClusterRuleRBACDanglingBindingList) DeepCopy() *ClusterRuleRBACDanglingBindingList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterRuleRBACDanglingBindingList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterRuleRBACDanglingBindingList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterRuleRBACDanglingBindingSpec) DeepCopyInto(out *ClusterRuleRBACDanglingBindingSpec) {
+	*out = *in
+	if in.IgnoreNamespaces != nil {
+		in, out := &in.IgnoreNamespaces, &out.IgnoreNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.Notification.DeepCopyInto(&out.Notification)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleRBACDanglingBindingSpec.
+func (in *ClusterRuleRBACDanglingBindingSpec) DeepCopy() *ClusterRuleRBACDanglingBindingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterRuleRBACDanglingBindingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterRuleRego) DeepCopyInto(out *ClusterRuleRego) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleRego.
+func (in *ClusterRuleRego) DeepCopy() *ClusterRuleRego {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterRuleRego)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterRuleRego) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterRuleRegoList) DeepCopyInto(out *ClusterRuleRegoList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterRuleRego, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleRegoList.
+func (in *ClusterRuleRegoList) DeepCopy() *ClusterRuleRegoList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterRuleRegoList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterRuleRegoList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterRuleRegoSpec) DeepCopyInto(out *ClusterRuleRegoSpec) {
+	*out = *in
+	if in.IgnoreNamespaces != nil {
+		in, out := &in.IgnoreNamespaces, &out.IgnoreNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.Selector.DeepCopyInto(&out.Selector)
+	if in.TargetKinds != nil {
+		in, out := &in.TargetKinds, &out.TargetKinds
+		*out = make([]v1.GroupVersionKind, len(*in))
+		copy(*out, *in)
+	}
+	if in.ModuleConfigMap != nil {
+		in, out := &in.ModuleConfigMap, &out.ModuleConfigMap
+		*out = new(RegoModuleConfigMap)
+		**out = **in
+	}
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = new(runtime.RawExtension)
+		(*in).DeepCopyInto(*out)
+	}
+	in.Notification.DeepCopyInto(&out.Notification)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleRegoSpec.
+func (in *ClusterRuleRegoSpec) DeepCopy() *ClusterRuleRegoSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterRuleRegoSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterRuleRequiredMetadata) DeepCopyInto(out *ClusterRuleRequiredMetadata) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleRequiredMetadata.
+func (in *ClusterRuleRequiredMetadata) DeepCopy() *ClusterRuleRequiredMetadata {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterRuleRequiredMetadata)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterRuleRequiredMetadata) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterRuleRequiredMetadataList) DeepCopyInto(out *ClusterRuleRequiredMetadataList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterRuleRequiredMetadata, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleRequiredMetadataList.
+func (in *ClusterRuleRequiredMetadataList) DeepCopy() *ClusterRuleRequiredMetadataList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterRuleRequiredMetadataList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterRuleRequiredMetadataList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterRuleRequiredMetadataSpec) DeepCopyInto(out *ClusterRuleRequiredMetadataSpec) {
+	*out = *in
+	if in.IgnoreNamespaces != nil {
+		in, out := &in.IgnoreNamespaces, &out.IgnoreNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Kinds != nil {
+		in, out := &in.Kinds, &out.Kinds
+		*out = make([]v1.GroupVersionKind, len(*in))
+		copy(*out, *in)
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make([]RequiredLabel, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make([]RequiredLabel, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.Notification.DeepCopyInto(&out.Notification)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleRequiredMetadataSpec.
+func (in *ClusterRuleRequiredMetadataSpec) DeepCopy() *ClusterRuleRequiredMetadataSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterRuleRequiredMetadataSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterRuleRolloutProgress) DeepCopyInto(out *ClusterRuleRolloutProgress) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleRolloutProgress.
+func (in *ClusterRuleRolloutProgress) DeepCopy() *ClusterRuleRolloutProgress {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterRuleRolloutProgress)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterRuleRolloutProgress) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterRuleRolloutProgressList) DeepCopyInto(out *ClusterRuleRolloutProgressList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterRuleRolloutProgress, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleRolloutProgressList.
+func (in *ClusterRuleRolloutProgressList) DeepCopy() *ClusterRuleRolloutProgressList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterRuleRolloutProgressList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterRuleRolloutProgressList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterRuleRolloutProgressSpec) DeepCopyInto(out *ClusterRuleRolloutProgressSpec) {
+	*out = *in
+	if in.IgnoreNamespaces != nil {
+		in, out := &in.IgnoreNamespaces, &out.IgnoreNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.Notification.DeepCopyInto(&out.Notification)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleRolloutProgressSpec.
+func (in *ClusterRuleRolloutProgressSpec) DeepCopy() *ClusterRuleRolloutProgressSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterRuleRolloutProgressSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterRuleSecretUnused) DeepCopyInto(out *ClusterRuleSecretUnused) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleSecretUnused.
+func (in *ClusterRuleSecretUnused) DeepCopy() *ClusterRuleSecretUnused {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterRuleSecretUnused)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterRuleSecretUnused) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterRuleSecretUnusedList) DeepCopyInto(out *ClusterRuleSecretUnusedList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterRuleSecretUnused, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleSecretUnusedList.
+func (in *ClusterRuleSecretUnusedList) DeepCopy() *ClusterRuleSecretUnusedList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterRuleSecretUnusedList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterRuleSecretUnusedList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterRuleSecretUnusedSpec) DeepCopyInto(out *ClusterRuleSecretUnusedSpec) {
+	*out = *in
+	if in.IgnoreNamespaces != nil {
+		in, out := &in.IgnoreNamespaces, &out.IgnoreNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.Notification.DeepCopyInto(&out.Notification)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleSecretUnusedSpec.
+func (in *ClusterRuleSecretUnusedSpec) DeepCopy() *ClusterRuleSecretUnusedSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterRuleSecretUnusedSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterRuleServiceAccountUnused) DeepCopyInto(out *ClusterRuleServiceAccountUnused) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleServiceAccountUnused.
+func (in *ClusterRuleServiceAccountUnused) DeepCopy() *ClusterRuleServiceAccountUnused {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterRuleServiceAccountUnused)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterRuleServiceAccountUnused) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterRuleServiceAccountUnusedList) DeepCopyInto(out *ClusterRuleServiceAccountUnusedList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterRuleServiceAccountUnused, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleServiceAccountUnusedList.
+func (in *ClusterRuleServiceAccountUnusedList) DeepCopy() *ClusterRuleServiceAccountUnusedList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterRuleServiceAccountUnusedList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterRuleServiceAccountUnusedList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterRuleServiceAccountUnusedSpec) DeepCopyInto(out *ClusterRuleServiceAccountUnusedSpec) {
+	*out = *in
+	if in.IgnoreNamespaces != nil {
+		in, out := &in.IgnoreNamespaces, &out.IgnoreNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.Notification.DeepCopyInto(&out.Notification)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleServiceAccountUnusedSpec.
+func (in *ClusterRuleServiceAccountUnusedSpec) DeepCopy() *ClusterRuleServiceAccountUnusedSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterRuleServiceAccountUnusedSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterRuleServiceInvalidSelector) DeepCopyInto(out *ClusterRuleServiceInvalidSelector) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleServiceInvalidSelector.
+func (in *ClusterRuleServiceInvalidSelector) DeepCopy() *ClusterRuleServiceInvalidSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterRuleServiceInvalidSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterRuleServiceInvalidSelector) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterRuleServiceInvalidSelectorList) DeepCopyInto(out *ClusterRuleServiceInvalidSelectorList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterRuleServiceInvalidSelector, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleServiceInvalidSelectorList.
+func (in *ClusterRuleServiceInvalidSelectorList) DeepCopy() *ClusterRuleServiceInvalidSelectorList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterRuleServiceInvalidSelectorList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterRuleServiceInvalidSelectorList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterRuleServiceInvalidSelectorSpec) DeepCopyInto(out *ClusterRuleServiceInvalidSelectorSpec) {
+	*out = *in
+	if in.IgnoreNamespaces != nil {
+		in, out := &in.IgnoreNamespaces, &out.IgnoreNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.Notification.DeepCopyInto(&out.Notification)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleServiceInvalidSelectorSpec.
+func (in *ClusterRuleServiceInvalidSelectorSpec) DeepCopy() *ClusterRuleServiceInvalidSelectorSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterRuleServiceInvalidSelectorSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterRuleServiceOverlap) DeepCopyInto(out *ClusterRuleServiceOverlap) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleServiceOverlap.
+func (in *ClusterRuleServiceOverlap) DeepCopy() *ClusterRuleServiceOverlap {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterRuleServiceOverlap)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterRuleServiceOverlap) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterRuleServiceOverlapList) DeepCopyInto(out *ClusterRuleServiceOverlapList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterRuleServiceOverlap, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleServiceOverlapList.
+func (in *ClusterRuleServiceOverlapList) DeepCopy() *ClusterRuleServiceOverlapList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterRuleServiceOverlapList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterRuleServiceOverlapList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterRuleServiceOverlapSpec) DeepCopyInto(out *ClusterRuleServiceOverlapSpec) {
+	*out = *in
+	if in.IgnoreNamespaces != nil {
+		in, out := &in.IgnoreNamespaces, &out.IgnoreNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.Notification.DeepCopyInto(&out.Notification)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleServiceOverlapSpec.
+func (in *ClusterRuleServiceOverlapSpec) DeepCopy() *ClusterRuleServiceOverlapSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterRuleServiceOverlapSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterRuleWorkloadAvailability) DeepCopyInto(out *ClusterRuleWorkloadAvailability) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleWorkloadAvailability.
+func (in *ClusterRuleWorkloadAvailability) DeepCopy() *ClusterRuleWorkloadAvailability {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterRuleWorkloadAvailability)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterRuleWorkloadAvailability) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterRuleWorkloadAvailabilityList) DeepCopyInto(out *ClusterRuleWorkloadAvailabilityList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterRuleWorkloadAvailability, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleWorkloadAvailabilityList.
+func (in *ClusterRuleWorkloadAvailabilityList) DeepCopy() *ClusterRuleWorkloadAvailabilityList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterRuleWorkloadAvailabilityList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterRuleWorkloadAvailabilityList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterRuleWorkloadAvailabilitySpec) DeepCopyInto(out *ClusterRuleWorkloadAvailabilitySpec) {
+	*out = *in
+	if in.IgnoreNamespaces != nil {
+		in, out := &in.IgnoreNamespaces, &out.IgnoreNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	in.Notification.DeepCopyInto(&out.Notification)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRuleWorkloadAvailabilitySpec.
+func (in *ClusterRuleWorkloadAvailabilitySpec) DeepCopy() *ClusterRuleWorkloadAvailabilitySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterRuleWorkloadAvailabilitySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Notification) DeepCopyInto(out *Notification) {
+	*out = *in
+	if in.Notifiers != nil {
+		in, out := &in.Notifiers, &out.Notifiers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Notification.
+func (in *Notification) DeepCopy() *Notification {
+	if in == nil {
+		return nil
+	}
+	out := new(Notification)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Notifier) DeepCopyInto(out *Notifier) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Notifier.
+func (in *Notifier) DeepCopy() *Notifier {
+	if in == nil {
+		return nil
+	}
+	out := new(Notifier)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Notifier) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotifierList) DeepCopyInto(out *NotifierList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Notifier, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotifierList.
+func (in *NotifierList) DeepCopy() *NotifierList {
+	if in == nil {
+		return nil
+	}
+	out := new(NotifierList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NotifierList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotifierSpec) DeepCopyInto(out *NotifierSpec) {
+	*out = *in
+	out.Slack = in.Slack
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotifierSpec.
+func (in *NotifierSpec) DeepCopy() *NotifierSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NotifierSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotifierStatus) DeepCopyInto(out *NotifierStatus) {
+	*out = *in
+	if in.Alerts != nil {
+		in, out := &in.Alerts, &out.Alerts
+		*out = make(map[string]alert.Alert, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotifierStatus.
+func (in *NotifierStatus) DeepCopy() *NotifierStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NotifierStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RegoModuleConfigMap) DeepCopyInto(out *RegoModuleConfigMap) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegoModuleConfigMap.
+func (in *RegoModuleConfigMap) DeepCopy() *RegoModuleConfigMap {
+	if in == nil {
+		return nil
+	}
+	out := new(RegoModuleConfigMap)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RequiredLabel) DeepCopyInto(out *RequiredLabel) {
 	*out = *in
+	if in.OneOf != nil {
+		in, out := &in.OneOf, &out.OneOf
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RequiredLabel.
@@ -789,12 +2340,106 @@ func (in *RequiredLabel) DeepCopy() *RequiredLabel {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RuleCondition) DeepCopyInto(out *RuleCondition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RuleCondition.
+func (in *RuleCondition) DeepCopy() *RuleCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(RuleCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RuleExpression) DeepCopyInto(out *RuleExpression) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RuleExpression.
+func (in *RuleExpression) DeepCopy() *RuleExpression {
+	if in == nil {
+		return nil
+	}
+	out := new(RuleExpression)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RuleExpression) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RuleExpressionList) DeepCopyInto(out *RuleExpressionList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]RuleExpression, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RuleExpressionList.
+func (in *RuleExpressionList) DeepCopy() *RuleExpressionList {
+	if in == nil {
+		return nil
+	}
+	out := new(RuleExpressionList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RuleExpressionList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RuleExpressionSpec) DeepCopyInto(out *RuleExpressionSpec) {
+	*out = *in
+	in.Notification.DeepCopyInto(&out.Notification)
+	in.Selector.DeepCopyInto(&out.Selector)
+	out.TargetKind = in.TargetKind
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RuleExpressionSpec.
+func (in *RuleExpressionSpec) DeepCopy() *RuleExpressionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RuleExpressionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RuleHPAReplicaPercentage) DeepCopyInto(out *RuleHPAReplicaPercentage) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RuleHPAReplicaPercentage.
@@ -870,6 +2515,7 @@ func (in *RulePDBMinAllowedDisruption) DeepCopyInto(out *RulePDBMinAllowedDisrup
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RulePDBMinAllowedDisruption.
@@ -1027,6 +2673,44 @@ func (in *RulePodResourcesStatus) DeepCopy() *RulePodResourcesStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RuleStatus) DeepCopyInto(out *RuleStatus) {
+	*out = *in
+	if in.LastEvaluatedAt != nil {
+		in, out := &in.LastEvaluatedAt, &out.LastEvaluatedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.Violations != nil {
+		in, out := &in.Violations, &out.Violations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]RuleCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ViolationStates != nil {
+		in, out := &in.ViolationStates, &out.ViolationStates
+		*out = make([]ViolationState, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RuleStatus.
+func (in *RuleStatus) DeepCopy() *RuleStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RuleStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Selector) DeepCopyInto(out *Selector) {
 	*out = *in
@@ -1048,3 +2732,133 @@ func (in *Selector) DeepCopy() *Selector {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Violation) DeepCopyInto(out *Violation) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Violation.
+func (in *Violation) DeepCopy() *Violation {
+	if in == nil {
+		return nil
+	}
+	out := new(Violation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Violation) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ViolationList) DeepCopyInto(out *ViolationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Violation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ViolationList.
+func (in *ViolationList) DeepCopy() *ViolationList {
+	if in == nil {
+		return nil
+	}
+	out := new(ViolationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ViolationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ViolationNotifierStatus) DeepCopyInto(out *ViolationNotifierStatus) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ViolationNotifierStatus.
+func (in *ViolationNotifierStatus) DeepCopy() *ViolationNotifierStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ViolationNotifierStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ViolationSpec) DeepCopyInto(out *ViolationSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ViolationSpec.
+func (in *ViolationSpec) DeepCopy() *ViolationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ViolationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ViolationState) DeepCopyInto(out *ViolationState) {
+	*out = *in
+	in.FirstSeen.DeepCopyInto(&out.FirstSeen)
+	in.LastSeen.DeepCopyInto(&out.LastSeen)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ViolationState.
+func (in *ViolationState) DeepCopy() *ViolationState {
+	if in == nil {
+		return nil
+	}
+	out := new(ViolationState)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ViolationStatus) DeepCopyInto(out *ViolationStatus) {
+	*out = *in
+	in.FirstSeen.DeepCopyInto(&out.FirstSeen)
+	if in.Notifiers != nil {
+		in, out := &in.Notifiers, &out.Notifiers
+		*out = make([]ViolationNotifierStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ViolationStatus.
+func (in *ViolationStatus) DeepCopy() *ViolationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ViolationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,119 @@
+// merlin-scan evaluates Merlin rules against Kubernetes manifests without a cluster, e.g., in CI before the manifests
+// are applied. Rules and the objects are loaded from the same manifests into an in-memory client, and each rule
+// evaluates all its objects same as the rule reconcilers do.
+//
+// Usage:
+//
+//	merlin-scan -f rules/ -f manifests/ [-o text|json|junit|sarif|html] [-fail-severity warning]
+//	kustomize build overlays/production | merlin-scan -f rules.yaml -f -
+//
+// The exit code is 1 if there are violations at or above the fail severity, and 2 for any errors.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/go-logr/zapr"
+	"go.uber.org/zap"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+
+	"github.com/mercari/merlin/alert"
+	merlinv1beta1 "github.com/mercari/merlin/api/v1beta1"
+)
+
+const (
+	exitViolated = 1
+	exitError    = 2
+)
+
+type pathsFlag []string
+
+func (p *pathsFlag) String() string {
+	return strings.Join(*p, ",")
+}
+
+func (p *pathsFlag) Set(value string) error {
+	*p = append(*p, value)
+	return nil
+}
+
+func main() {
+	os.Exit(run())
+}
+
+func run() int {
+	var paths pathsFlag
+	var output, failSeverity, namespace string
+	var verbose bool
+	flag.Var(&paths, "f", "The manifest file or directory of rules and objects, can be repeated, '-' reads from stdin (default '-').")
+	flag.StringVar(&output, "o", formatText, "The output format, one of "+strings.Join(formats, ", ")+".")
+	flag.StringVar(&failSeverity, "fail-severity", string(alert.SeverityWarning), "Exits with non-zero code for violations at or above this severity, one of info, warning, critical and fatal.")
+	flag.StringVar(&namespace, "namespace", "default", "The namespace for namespaced objects without namespace in the manifests.")
+	flag.BoolVar(&verbose, "v", false, "Writes the rules' logs to stderr.")
+	flag.Parse()
+	if len(paths) == 0 {
+		paths = pathsFlag{"-"}
+	}
+
+	severity, err := parseSeverity(failSeverity)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+
+	// the fake client decodes objects with the client-go scheme, so merlin types need to be in the same scheme.
+	scheme := clientgoscheme.Scheme
+	if err := merlinv1beta1.AddToScheme(scheme); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+	log := logr.Discard()
+	if verbose {
+		zapLog, err := zap.NewDevelopment()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitError
+		}
+		log = zapr.NewLogger(zapLog)
+	}
+
+	m := newManifests(namespace)
+	if err := m.load(paths); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+	s, err := newScanner(scheme, log, m)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+	if len(s.ruleObjs) == 0 {
+		fmt.Fprintln(os.Stderr, "no rule found in the manifests")
+		return exitError
+	}
+
+	r, errs := s.scan(context.Background())
+	r.GeneratedAt = time.Now()
+	if err := writeReport(os.Stdout, output, r); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+	for _, err := range errs {
+		fmt.Fprintln(os.Stderr, err)
+	}
+	if len(errs) > 0 {
+		return exitError
+	}
+	for _, result := range r.Results {
+		if isFailed(result, severity) {
+			return exitViolated
+		}
+	}
+	return 0
+}
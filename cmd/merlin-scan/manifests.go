@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/mercari/merlin/report"
+)
+
+// clusterScopedKinds are the kinds without namespace, objects of other kinds without namespace in the manifests are put
+// into the default namespace, same as kubectl apply does.
+var clusterScopedKinds = map[string]bool{
+	"Namespace":                true,
+	"Node":                     true,
+	"PersistentVolume":         true,
+	"ClusterRole":              true,
+	"ClusterRoleBinding":       true,
+	"StorageClass":             true,
+	"PriorityClass":            true,
+	"CustomResourceDefinition": true,
+	"Notifier":                 true,
+}
+
+func isClusterScoped(kind string) bool {
+	return clusterScopedKinds[kind] || strings.HasPrefix(kind, "ClusterRule")
+}
+
+// resourceKey is the key of objects by the resource kind and name of alerts, names are same as client.ObjectKey.String().
+func resourceKey(kind, name string) string {
+	return kind + " " + name
+}
+
+// manifests are the objects loaded from manifests, and where they are defined.
+type manifests struct {
+	defaultNamespace string
+	objects          []*unstructured.Unstructured
+	// locations are the locations of objects by their resourceKey, objects from stdin don't have locations.
+	locations map[string]*report.Location
+}
+
+func newManifests(defaultNamespace string) *manifests {
+	return &manifests{defaultNamespace: defaultNamespace, locations: map[string]*report.Location{}}
+}
+
+// load reads the objects from the yaml or json files, directories are walked for files with yaml or json extensions,
+// and "-" reads from stdin, e.g., the output of kustomize build.
+func (m *manifests) load(paths []string) error {
+	for _, path := range paths {
+		if path == "-" {
+			if err := m.decode(os.Stdin, ""); err != nil {
+				return fmt.Errorf("unable to read manifests from stdin: %w", err)
+			}
+			continue
+		}
+		err := filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() || (p != path && !isManifestFile(p)) {
+				return nil
+			}
+			f, err := os.Open(p)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			if err := m.decode(f, p); err != nil {
+				return fmt.Errorf("unable to read manifests from %s: %w", p, err)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isManifestFile(path string) bool {
+	switch filepath.Ext(path) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
+
+// decode decodes all documents from the reader, items of lists are flattened and empty documents are skipped.
+// Documents are split by the "---" lines to know their lines in the file.
+func (m *manifests) decode(r io.Reader, file string) error {
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	for _, doc := range splitDocuments(content) {
+		decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(doc.content), 4096)
+		for {
+			obj := &unstructured.Unstructured{}
+			if err := decoder.Decode(&obj.Object); err != nil {
+				if err == io.EOF {
+					break
+				}
+				return err
+			}
+			if len(obj.Object) == 0 {
+				continue
+			}
+			var location *report.Location
+			if file != "" {
+				location = &report.Location{File: file, Line: doc.line}
+			}
+			if obj.IsList() {
+				if err := obj.EachListItem(func(item runtime.Object) error {
+					return m.add(item.(*unstructured.Unstructured), location)
+				}); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := m.add(obj, location); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (m *manifests) add(obj *unstructured.Unstructured, location *report.Location) error {
+	if obj.GetKind() == "" || obj.GetName() == "" {
+		return fmt.Errorf("object without kind or name: %v", obj.Object)
+	}
+	if isClusterScoped(obj.GetKind()) {
+		obj.SetNamespace("")
+	} else if obj.GetNamespace() == "" {
+		obj.SetNamespace(m.defaultNamespace)
+	}
+	m.objects = append(m.objects, obj)
+	if location != nil {
+		m.locations[resourceKey(obj.GetKind(), client.ObjectKey{Namespace: obj.GetNamespace(), Name: obj.GetName()}.String())] = location
+	}
+	return nil
+}
+
+type document struct {
+	content []byte
+	// line is the first line of the document content, starts from 1.
+	line int
+}
+
+// splitDocuments splits the yaml documents by the separator lines, the line of a document is its first line that is
+// not empty or comment.
+func splitDocuments(content []byte) (docs []document) {
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(content)+1)
+	doc := document{}
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := scanner.Text()
+		if strings.HasPrefix(line, "---") && strings.TrimSpace(strings.TrimPrefix(line, "---")) == "" {
+			docs = append(docs, doc)
+			doc = document{}
+			continue
+		}
+		if doc.line == 0 {
+			if trimmed := strings.TrimSpace(line); trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+				doc.line = lineNumber
+			}
+		}
+		doc.content = append(doc.content, scanner.Bytes()...)
+		doc.content = append(doc.content, '\n')
+	}
+	return append(docs, doc)
+}
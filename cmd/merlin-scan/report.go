@@ -0,0 +1,174 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mercari/merlin/alert"
+	"github.com/mercari/merlin/report"
+)
+
+const (
+	formatText  = "text"
+	formatJSON  = "json"
+	formatJUnit = "junit"
+	formatSARIF = "sarif"
+	formatHTML  = "html"
+)
+
+var formats = []string{formatText, formatJSON, formatJUnit, formatSARIF, formatHTML}
+
+// severityLevels orders the severities, alerts without severity from the rule or its notifiers are taken as warning.
+var severityLevels = map[alert.Severity]int{
+	alert.SeverityInfo:     1,
+	alert.SeverityDefault:  2,
+	alert.SeverityWarning:  2,
+	alert.SeverityCritical: 3,
+	alert.SeverityFatal:    4,
+}
+
+func parseSeverity(s string) (alert.Severity, error) {
+	severity := alert.Severity(strings.ToLower(s))
+	if _, ok := severityLevels[severity]; !ok || severity == alert.SeverityDefault {
+		return "", fmt.Errorf("unknown severity '%s', should be one of info, warning, critical and fatal", s)
+	}
+	return severity, nil
+}
+
+// isFailed checks if the result is a violation at or above the severity, suppressed violations never fail.
+func isFailed(r report.Result, severity alert.Severity) bool {
+	return r.Violated && !r.Suppressed && severityLevels[r.Severity] >= severityLevels[severity]
+}
+
+// violation is the json output of a violated result.
+type violation struct {
+	Rule         string           `json:"rule"`
+	Severity     alert.Severity   `json:"severity"`
+	ResourceKind string           `json:"resourceKind"`
+	ResourceName string           `json:"resourceName"`
+	Message      string           `json:"message"`
+	Suppressed   bool             `json:"suppressed"`
+	Location     *report.Location `json:"location,omitempty"`
+}
+
+func writeReport(w io.Writer, format string, r report.Report) error {
+	switch format {
+	case formatText:
+		return writeText(w, r.Results)
+	case formatJSON:
+		return writeJSON(w, r.Results)
+	case formatJUnit:
+		return writeJUnit(w, r.Results)
+	case formatSARIF:
+		return r.WriteSARIF(w)
+	case formatHTML:
+		return r.WriteHTML(w)
+	}
+	return fmt.Errorf("unknown output format '%s', should be one of %s", format, strings.Join(formats, ", "))
+}
+
+// writeText writes the violations with the rules' message templates, same messages as sent by notifiers.
+func writeText(w io.Writer, results []report.Result) error {
+	count := 0
+	for _, r := range results {
+		if !r.Violated {
+			continue
+		}
+		count++
+		msg, err := r.ParseMessage()
+		if err != nil {
+			return err
+		}
+		suppressed := ""
+		if r.Suppressed {
+			suppressed = " (suppressed)"
+		}
+		if _, err := fmt.Fprintf(w, "%s: %s%s\n", r.Rule, msg, suppressed); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "%d violation(s) found in %d evaluation(s)\n", count, len(results))
+	return err
+}
+
+func writeJSON(w io.Writer, results []report.Result) error {
+	violations := []violation{}
+	for _, r := range results {
+		if !r.Violated {
+			continue
+		}
+		violations = append(violations, violation{
+			Rule:         r.Rule,
+			Severity:     r.Severity,
+			ResourceKind: r.ResourceKind,
+			ResourceName: r.ResourceName,
+			Message:      r.Message,
+			Suppressed:   r.Suppressed,
+			Location:     r.Location,
+		})
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(violations)
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+}
+
+// writeJUnit writes one test suite per rule and one test case per evaluated object, violations are the failures.
+func writeJUnit(w io.Writer, results []report.Result) error {
+	suites := junitTestSuites{}
+	for _, r := range results {
+		if len(suites.Suites) == 0 || suites.Suites[len(suites.Suites)-1].Name != r.Rule {
+			suites.Suites = append(suites.Suites, junitTestSuite{Name: r.Rule})
+		}
+		suite := &suites.Suites[len(suites.Suites)-1]
+		testCase := junitTestCase{
+			Name:      r.ResourceKind + " " + r.ResourceName,
+			ClassName: r.Rule,
+		}
+		if r.Violated {
+			testCase.Failure = &junitFailure{Message: r.Message, Type: string(r.Severity)}
+			suite.Failures++
+			suites.Failures++
+		}
+		suite.TestCases = append(suite.TestCases, testCase)
+		suite.Tests++
+		suites.Tests++
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suites); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/mercari/merlin/alert"
+	merlinv1beta1 "github.com/mercari/merlin/api/v1beta1"
+	"github.com/mercari/merlin/report"
+	"github.com/mercari/merlin/rules"
+)
+
+// ruleFactories creates the rule factory for each kind of rule, factories keep the rule they created so a new one is
+// needed for every rule object.
+var ruleFactories = map[string]func() rules.RuleFactory{
+	"ClusterRuleSecretUnused":             func() rules.RuleFactory { return &rules.SecretUnusedRule{} },
+	"ClusterRuleConfigMapUnused":          func() rules.RuleFactory { return &rules.ConfigMapUnusedRule{} },
+	"ClusterRuleHPAReplicaPercentage":     func() rules.RuleFactory { return &rules.HPAReplicaPercentageRule{} },
+	"RuleHPAReplicaPercentage":            func() rules.RuleFactory { return &rules.HPAReplicaPercentageRule{} },
+	"ClusterRuleHPAInvalidScaleTargetRef": func() rules.RuleFactory { return &rules.HPAInvalidScaleTargetRefRule{} },
+	"ClusterRuleNamespaceRequiredLabel":   func() rules.RuleFactory { return &rules.NamespaceRequiredLabelRule{} },
+	"ClusterRuleServiceInvalidSelector":   func() rules.RuleFactory { return &rules.ServiceInvalidSelectorRule{} },
+	"ClusterRulePDBInvalidSelector":       func() rules.RuleFactory { return &rules.PDBInvalidSelectorRule{} },
+	"ClusterRulePDBMinAllowedDisruption":  func() rules.RuleFactory { return &rules.PDBMinAllowedDisruptionRule{} },
+	"RulePDBMinAllowedDisruption":         func() rules.RuleFactory { return &rules.PDBMinAllowedDisruptionRule{} },
+	"ClusterRuleIngressInvalidBackend":    func() rules.RuleFactory { return &rules.IngressInvalidBackendRule{} },
+	"ClusterRuleCertificateExpiry":        func() rules.RuleFactory { return &rules.CertificateExpiryRule{} },
+	"ClusterRuleWorkloadAvailability":     func() rules.RuleFactory { return &rules.WorkloadAvailabilityRule{} },
+	"ClusterRulePDBOverlap":               func() rules.RuleFactory { return &rules.PDBOverlapRule{} },
+	"ClusterRuleServiceOverlap":           func() rules.RuleFactory { return &rules.ServiceOverlapRule{} },
+	"ClusterRulePodHealth":                func() rules.RuleFactory { return &rules.PodHealthRule{} },
+	"ClusterRulePodPending":               func() rules.RuleFactory { return &rules.PodPendingRule{} },
+	"ClusterRuleRolloutProgress":          func() rules.RuleFactory { return &rules.RolloutProgressRule{} },
+	"ClusterRuleCronJobHealth":            func() rules.RuleFactory { return &rules.CronJobHealthRule{} },
+	"ClusterRulePVCUnused":                func() rules.RuleFactory { return &rules.PVCUnusedRule{} },
+	"ClusterRuleServiceAccountUnused":     func() rules.RuleFactory { return &rules.ServiceAccountUnusedRule{} },
+	"ClusterRuleRBACDanglingBinding":      func() rules.RuleFactory { return &rules.RBACDanglingBindingRule{} },
+	"ClusterRuleNetworkPolicyCoverage":    func() rules.RuleFactory { return &rules.NetworkPolicyCoverageRule{} },
+	"ClusterRuleNamespaceQuota":           func() rules.RuleFactory { return &rules.NamespaceQuotaRule{} },
+	"ClusterRuleDeprecatedAPI":            func() rules.RuleFactory { return &rules.DeprecatedAPIRule{} },
+	"ClusterRuleRequiredMetadata":         func() rules.RuleFactory { return &rules.RequiredMetadataRule{} },
+	"ClusterRuleExpression":               func() rules.RuleFactory { return &rules.ExpressionRule{} },
+	"RuleExpression":                      func() rules.RuleFactory { return &rules.ExpressionRule{} },
+	"ClusterRuleRego":                     func() rules.RuleFactory { return &rules.RegoRule{} },
+}
+
+// scanner evaluates the rules against the objects loaded from manifests.
+type scanner struct {
+	cli       client.Client
+	log       logr.Logger
+	scheme    *runtime.Scheme
+	ruleObjs  []*unstructured.Unstructured
+	notifiers map[string]*merlinv1beta1.Notifier
+	locations map[string]*report.Location
+}
+
+// newScanner creates the in-memory client with the objects, objects with kinds known to the scheme are stored as typed
+// objects so rules get the same objects as from the api server.
+func newScanner(scheme *runtime.Scheme, log logr.Logger, m *manifests) (*scanner, error) {
+	s := &scanner{log: log, scheme: scheme, notifiers: map[string]*merlinv1beta1.Notifier{}, locations: m.locations}
+	var initObjs []runtime.Object
+	for _, obj := range m.objects {
+		var o runtime.Object = obj
+		if typed, err := scheme.New(obj.GroupVersionKind()); err == nil {
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, typed); err != nil {
+				return nil, fmt.Errorf("unable to convert %s %s: %w", obj.GetKind(), obj.GetName(), err)
+			}
+			o = typed
+		}
+		if notifier, ok := o.(*merlinv1beta1.Notifier); ok {
+			s.notifiers[notifier.Name] = notifier
+		}
+		if _, ok := ruleFactories[obj.GetKind()]; ok && obj.GroupVersionKind().Group == merlinv1beta1.GroupVersion.Group {
+			s.ruleObjs = append(s.ruleObjs, obj)
+		}
+		initObjs = append(initObjs, o)
+	}
+	s.cli = &scanClient{Client: fake.NewFakeClientWithScheme(scheme, initObjs...)}
+	return s, nil
+}
+
+// scan evaluates all rules and returns the report, cluster rules' results are dropped for the namespaces having
+// namespaced rules of the same kind overriding them, same as the resource reconcilers do. Errors of rules don't stop
+// other rules.
+func (s *scanner) scan(ctx context.Context) (r report.Report, errs []error) {
+	var created []rules.Rule
+	var createdObjs []*unstructured.Unstructured
+	// namespacedRules are the namespaced rules by <RuleKind without Rule prefix>/<namespace>.
+	namespacedRules := map[string][]rules.Rule{}
+	for _, obj := range s.ruleObjs {
+		key := client.ObjectKey{Namespace: obj.GetNamespace(), Name: obj.GetName()}
+		l := s.log.WithValues("rule", obj.GetKind()+rules.Separator+key.String())
+		rule, err := ruleFactories[obj.GetKind()]().New(ctx, s.cli, l, key)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("unable to create rule %s %s: %w", obj.GetKind(), key, err))
+			continue
+		}
+		created, createdObjs = append(created, rule), append(createdObjs, obj)
+		if obj.GetNamespace() != "" {
+			kindKey := strings.TrimPrefix(obj.GetKind(), "Rule") + rules.Separator + obj.GetNamespace()
+			namespacedRules[kindKey] = append(namespacedRules[kindKey], rule)
+		}
+	}
+
+	for i, rule := range created {
+		obj := createdObjs[i]
+		ruleMeta, err := report.NewRule(rule)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("unable to get metadata of rule %s: %w", rule.GetName(), err))
+			continue
+		}
+		r.Rules = append(r.Rules, ruleMeta)
+		alerts, err := rule.EvaluateAll(ctx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("unable to evaluate rule %s: %w", rule.GetName(), err))
+			continue
+		}
+		for _, a := range alerts {
+			if obj.GetNamespace() == "" {
+				namespace := strings.SplitN(a.ResourceName, rules.Separator, 2)[0]
+				kindKey := strings.TrimPrefix(obj.GetKind(), "ClusterRule") + rules.Separator + namespace
+				if rules.IsOverridden(rule, namespacedRules[kindKey]) {
+					continue
+				}
+			}
+			if a.Severity == alert.SeverityDefault {
+				a.Severity = s.notifierSeverity(rule.GetNotification())
+			}
+			r.Results = append(r.Results, report.Result{
+				Rule:     rule.GetName(),
+				Alert:    a,
+				Location: s.locations[resourceKey(a.ResourceKind, a.ResourceName)],
+			})
+		}
+	}
+	sort.SliceStable(r.Results, func(i, j int) bool {
+		if r.Results[i].Rule != r.Results[j].Rule {
+			return r.Results[i].Rule < r.Results[j].Rule
+		}
+		return r.Results[i].ResourceName < r.Results[j].ResourceName
+	})
+	return
+}
+
+// notifierSeverity returns the default severity of the rule's notifiers, same as the notifiers set for alerts without
+// severity, the notifiers need to be in the manifests as well.
+func (s *scanner) notifierSeverity(notification merlinv1beta1.Notification) alert.Severity {
+	for _, name := range notification.Notifiers {
+		if n, ok := s.notifiers[name]; ok && n.Spec.Slack.Severity != alert.SeverityDefault {
+			return n.Spec.Slack.Severity
+		}
+	}
+	return alert.SeverityDefault
+}
+
+// scanClient filters the list by the name and namespace field selectors, since the fake client only supports label selectors.
+type scanClient struct {
+	client.Client
+}
+
+func (c *scanClient) List(ctx context.Context, list runtime.Object, opts ...client.ListOption) error {
+	if err := c.Client.List(ctx, list, opts...); err != nil {
+		return err
+	}
+	listOpts := &client.ListOptions{}
+	listOpts.ApplyOptions(opts)
+	if listOpts.FieldSelector == nil || listOpts.FieldSelector.Empty() {
+		return nil
+	}
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return err
+	}
+	var filtered []runtime.Object
+	for _, item := range items {
+		obj, err := meta.Accessor(item)
+		if err != nil {
+			return err
+		}
+		if listOpts.FieldSelector.Matches(fields.Set{".metadata.name": obj.GetName(), ".metadata.namespace": obj.GetNamespace()}) {
+			filtered = append(filtered, item)
+		}
+	}
+	return meta.SetList(list, filtered)
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/zapr"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/mercari/merlin/alert"
+	merlinv1beta1 "github.com/mercari/merlin/api/v1beta1"
+	"github.com/mercari/merlin/report"
+)
+
+const testManifests = `
+apiVersion: merlin.mercari.com/v1beta1
+kind: Notifier
+metadata:
+  name: slack-test
+spec:
+  slack:
+    severity: critical
+---
+apiVersion: merlin.mercari.com/v1beta1
+kind: ClusterRuleExpression
+metadata:
+  name: replicas
+spec:
+  targetKind:
+    group: apps
+    version: v1
+    kind: Deployment
+  expression: "object.spec.replicas >= 2"
+  notification:
+    notifiers:
+    - slack-test
+---
+apiVersion: merlin.mercari.com/v1beta1
+kind: RuleExpression
+metadata:
+  name: replicas
+  namespace: dev
+spec:
+  targetKind:
+    group: apps
+    version: v1
+    kind: Deployment
+  expression: "object.spec.replicas >= 1"
+  notification:
+    severity: info
+---
+apiVersion: v1
+kind: List
+items:
+- apiVersion: apps/v1
+  kind: Deployment
+  metadata:
+    name: api
+  spec:
+    replicas: 1
+- apiVersion: apps/v1
+  kind: Deployment
+  metadata:
+    name: api
+    namespace: dev
+  spec:
+    replicas: 1
+`
+
+func Test_manifests_decode(t *testing.T) {
+	m := newManifests("default")
+	assert.NoError(t, m.decode(strings.NewReader(testManifests), "manifests.yaml"))
+	var names []string
+	for _, obj := range m.objects {
+		names = append(names, obj.GetKind()+" "+client.ObjectKey{Namespace: obj.GetNamespace(), Name: obj.GetName()}.String())
+	}
+	assert.Equal(t, []string{
+		"Notifier /slack-test",
+		"ClusterRuleExpression /replicas",
+		"RuleExpression dev/replicas",
+		"Deployment default/api",
+		"Deployment dev/api",
+	}, names)
+	assert.Equal(t, &report.Location{File: "manifests.yaml", Line: 2}, m.locations["Notifier /slack-test"])
+	assert.Equal(t, &report.Location{File: "manifests.yaml", Line: 38}, m.locations["Deployment dev/api"])
+
+	m = newManifests("default")
+	assert.NoError(t, m.decode(strings.NewReader(testManifests), ""))
+	assert.Len(t, m.objects, 5)
+	assert.Empty(t, m.locations)
+	assert.Error(t, m.decode(strings.NewReader("apiVersion: v1\nkind: Service\n"), ""))
+}
+
+func Test_scanner_scan(t *testing.T) {
+	scheme := clientgoscheme.Scheme
+	assert.NoError(t, merlinv1beta1.AddToScheme(scheme))
+	m := newManifests("default")
+	assert.NoError(t, m.decode(strings.NewReader(testManifests), "manifests.yaml"))
+	s, err := newScanner(scheme, zapr.NewLogger(zap.L()), m)
+	assert.NoError(t, err)
+	assert.Len(t, s.ruleObjs, 2)
+
+	r, errs := s.scan(context.Background())
+	assert.Empty(t, errs)
+	assert.Len(t, r.Rules, 2)
+	results := r.Results
+	// the cluster rule is replaced by the namespaced rule in namespace dev
+	assert.Equal(t, []report.Result{
+		{
+			Rule: "ClusterRuleExpression/replicas",
+			Alert: alert.Alert{
+				Severity:     alert.SeverityCritical,
+				Message:      "doesn't satisfy the expression `object.spec.replicas >= 2`",
+				ResourceKind: "Deployment",
+				ResourceName: "default/api",
+				Violated:     true,
+			},
+			Location: &report.Location{File: "manifests.yaml", Line: 38},
+		},
+		{
+			Rule: "RuleExpression/replicas",
+			Alert: alert.Alert{
+				Severity:     alert.SeverityInfo,
+				Message:      "satisfies the expression `object.spec.replicas >= 1`",
+				ResourceKind: "Deployment",
+				ResourceName: "dev/api",
+			},
+			Location: &report.Location{File: "manifests.yaml", Line: 38},
+		},
+	}, results)
+	assert.True(t, isFailed(results[0], alert.SeverityCritical))
+	assert.False(t, isFailed(results[0], alert.SeverityFatal))
+	assert.False(t, isFailed(results[1], alert.SeverityInfo))
+
+	var buf bytes.Buffer
+	assert.NoError(t, writeReport(&buf, formatText, r))
+	assert.Equal(t, "ClusterRuleExpression/replicas: [critical] Deployment `default/api` doesn't satisfy the expression `object.spec.replicas >= 2`\n"+
+		"1 violation(s) found in 2 evaluation(s)\n", buf.String())
+	assert.Error(t, writeReport(&buf, "yaml", r))
+}
+
+func Test_scanClient_List(t *testing.T) {
+	scheme := clientgoscheme.Scheme
+	m := newManifests("default")
+	assert.NoError(t, m.decode(strings.NewReader(`
+apiVersion: v1
+kind: Service
+metadata:
+  name: a
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: b
+`), ""))
+	s, err := newScanner(scheme, zapr.NewLogger(zap.L()), m)
+	assert.NoError(t, err)
+
+	list := &corev1.ServiceList{}
+	assert.NoError(t, s.cli.List(context.Background(), list, client.InNamespace("default")))
+	assert.Len(t, list.Items, 2)
+	assert.NoError(t, s.cli.List(context.Background(), list, &client.ListOptions{
+		Namespace:     "default",
+		FieldSelector: fields.Set{".metadata.name": "b"}.AsSelector(),
+	}))
+	assert.Len(t, list.Items, 1)
+	assert.Equal(t, "b", list.Items[0].Name)
+}
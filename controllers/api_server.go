@@ -0,0 +1,282 @@
+package controllers
+
+import (
+	_ "embed"
+	"encoding/json"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/mercari/merlin/alert"
+	"github.com/mercari/merlin/report"
+	"github.com/mercari/merlin/rules"
+)
+
+const (
+	apiPathViolations = "/api/v1/violations"
+	apiPathRules      = "/api/v1/rules"
+	apiPathReport     = "/api/v1/report"
+)
+
+//go:embed dashboard.html
+var dashboardHTML []byte
+
+// violation is the current violation of a resource for the API, from the notifiers' alerts.
+type violation struct {
+	Rule         string         `json:"rule"`
+	Namespace    string         `json:"namespace"`
+	ResourceKind string         `json:"resourceKind"`
+	ResourceName string         `json:"resourceName"`
+	Severity     alert.Severity `json:"severity"`
+	Message      string         `json:"message"`
+	Status       alert.Status   `json:"status"`
+	Suppressed   bool           `json:"suppressed"`
+	Notifiers    []string       `json:"notifiers"`
+}
+
+// ruleSummary is the rule with its readiness and violations count for the API.
+type ruleSummary struct {
+	Name       string         `json:"name"`
+	Kind       string         `json:"kind"`
+	Namespace  string         `json:"namespace"`
+	Ready      bool           `json:"ready"`
+	Violations int            `json:"violations"`
+	Severity   alert.Severity `json:"severity"`
+	Notifiers  []string       `json:"notifiers"`
+}
+
+// apiServer serves the read-only API and dashboard of current violations from the notifiers and rules caches,
+// it's added to the manager and only runs in the leader, which is the one with the caches filled.
+// The server has no authentication, so it binds to localhost unless the address has a host.
+type apiServer struct {
+	addr      string
+	log       logr.Logger
+	notifiers *notifiersCache
+	rules     []*rulesCache
+}
+
+func (s *apiServer) Start(stop <-chan struct{}) error {
+	server := &http.Server{Addr: listenAddr(s.addr), Handler: s.handler()}
+	errCh := make(chan error, 1)
+	go func() {
+		s.log.Info("starting api server", "addr", server.Addr)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			errCh <- err
+		}
+		close(errCh)
+	}()
+	select {
+	case <-stop:
+		s.log.Info("shutting down api server")
+		return server.Close()
+	case err := <-errCh:
+		return err
+	}
+}
+
+func (s *apiServer) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(apiPathViolations, s.handleViolations)
+	mux.HandleFunc(apiPathRules, s.handleRules)
+	mux.HandleFunc(apiPathReport, s.handleReport)
+	mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/" {
+			http.NotFound(w, req)
+			return
+		}
+		w.Header().Set("Content-Type", report.ContentTypeHTML)
+		_, _ = w.Write(dashboardHTML)
+	})
+	return mux
+}
+
+// handleViolations lists the violations, can be filtered by query parameters rule (rule kind or <RuleKind>/<RuleName>),
+// namespace, kind (resource kind) and severity.
+func (s *apiServer) handleViolations(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	query := req.URL.Query()
+	violations := []violation{}
+	for _, v := range s.listViolations() {
+		if (query.Get("rule") == "" || query.Get("rule") == v.Rule || query.Get("rule") == ruleKind(v.Rule)) &&
+			(query.Get("namespace") == "" || query.Get("namespace") == v.Namespace) &&
+			(query.Get("kind") == "" || strings.EqualFold(query.Get("kind"), v.ResourceKind)) &&
+			(query.Get("severity") == "" || query.Get("severity") == string(v.Severity)) {
+			violations = append(violations, v)
+		}
+	}
+	s.writeJSON(w, violations)
+}
+
+// handleRules lists the rules, can be filtered by query parameter namespace, and "-" for cluster rules.
+func (s *apiServer) handleRules(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	namespace := req.URL.Query().Get("namespace")
+	summaries := []ruleSummary{}
+	for _, r := range s.listRules() {
+		meta := r.GetObjectMeta()
+		if namespace != "" && namespace != meta.Namespace && !(namespace == "-" && meta.Namespace == "") {
+			continue
+		}
+		summaries = append(summaries, ruleSummary{
+			Name:       r.GetName(),
+			Kind:       ruleKind(r.GetName()),
+			Namespace:  meta.Namespace,
+			Ready:      r.IsReady(),
+			Violations: len(r.GetViolations()),
+			Severity:   r.GetNotification().Severity,
+			Notifiers:  r.GetNotification().Notifiers,
+		})
+	}
+	s.writeJSON(w, summaries)
+}
+
+// handleReport writes the report of current violations, as SARIF or HTML by the query parameter format.
+func (s *apiServer) handleReport(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	r := report.Report{GeneratedAt: time.Now()}
+	for _, rule := range s.listRules() {
+		meta, err := report.NewRule(rule)
+		if err != nil {
+			s.log.Error(err, "unable to get rule metadata", "rule", rule.GetName())
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		r.Rules = append(r.Rules, meta)
+	}
+	for _, v := range s.listViolations() {
+		r.Results = append(r.Results, report.Result{
+			Rule: v.Rule,
+			Alert: alert.Alert{
+				Suppressed:   v.Suppressed,
+				Severity:     v.Severity,
+				Message:      v.Message,
+				ResourceKind: v.ResourceKind,
+				ResourceName: v.ResourceName,
+				Status:       v.Status,
+				Violated:     true,
+			},
+		})
+	}
+
+	var err error
+	switch format := req.URL.Query().Get("format"); format {
+	case "", "html":
+		w.Header().Set("Content-Type", report.ContentTypeHTML)
+		err = r.WriteHTML(w)
+	case "sarif":
+		w.Header().Set("Content-Type", report.ContentTypeSARIF)
+		err = r.WriteSARIF(w)
+	default:
+		http.Error(w, "unknown format '"+format+"', should be html or sarif", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		s.log.Error(err, "unable to write report")
+	}
+}
+
+func (s *apiServer) writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		s.log.Error(err, "unable to write response")
+	}
+}
+
+// listViolations returns the violated alerts of all notifiers, alerts of the same rule and resource from different
+// notifiers are merged, and it's firing if any of notifiers has fired. Recovering alerts are not violations anymore and are skipped.
+func (s *apiServer) listViolations() []violation {
+	violations := map[string]*violation{}
+	s.notifiers.Lock()
+	for notifierName, notifier := range s.notifiers.notifiers {
+		for name, a := range notifier.Alerts {
+			if !a.Violated || a.Status == alert.StatusRecovering {
+				continue
+			}
+			if v, ok := violations[name]; ok {
+				v.Notifiers = append(v.Notifiers, notifierName)
+				if a.Status == alert.StatusFiring {
+					v.Status = a.Status
+				}
+				continue
+			}
+			violations[name] = &violation{
+				Rule:         strings.TrimSuffix(name, Separator+a.ResourceName),
+				Namespace:    strings.Split(a.ResourceName, Separator)[0],
+				ResourceKind: a.ResourceKind,
+				ResourceName: a.ResourceName,
+				Severity:     a.Severity,
+				Message:      a.Message,
+				Status:       a.Status,
+				Suppressed:   a.Suppressed,
+				Notifiers:    []string{notifierName},
+			}
+		}
+	}
+	s.notifiers.Unlock()
+
+	list := make([]violation, 0, len(violations))
+	for _, v := range violations {
+		sort.Strings(v.Notifiers)
+		list = append(list, *v)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Rule != list[j].Rule {
+			return list[i].Rule < list[j].Rule
+		}
+		return list[i].ResourceName < list[j].ResourceName
+	})
+	return list
+}
+
+// listRules returns the rules of all caches sorted by names, caches can be shared by reconcilers so rules are deduplicated.
+func (s *apiServer) listRules() []rules.Rule {
+	ruleMap := map[string]rules.Rule{}
+	for _, c := range s.rules {
+		c.Lock()
+		for _, namespaced := range c.rules {
+			for _, r := range namespaced {
+				ruleMap[r.GetObjectMeta().Namespace+Separator+r.GetName()] = r
+			}
+		}
+		c.Unlock()
+	}
+	list := make([]rules.Rule, 0, len(ruleMap))
+	for _, r := range ruleMap {
+		list = append(list, r)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].GetName() != list[j].GetName() {
+			return list[i].GetName() < list[j].GetName()
+		}
+		return list[i].GetObjectMeta().Namespace < list[j].GetObjectMeta().Namespace
+	})
+	return list
+}
+
+// listenAddr returns the address with localhost as the host if it has no host, e.g., ":8082" binds to "127.0.0.1:8082",
+// other interfaces have to be given explicitly, e.g., "0.0.0.0:8082".
+func listenAddr(addr string) string {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil || host != "" {
+		return addr
+	}
+	return net.JoinHostPort("127.0.0.1", port)
+}
+
+// ruleKind returns the kind from rule name <RuleKind>/<RuleName>.
+func ruleKind(name string) string {
+	return strings.SplitN(name, Separator, 2)[0]
+}
@@ -0,0 +1,223 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/mercari/merlin/alert"
+	merlinv1beta1 "github.com/mercari/merlin/api/v1beta1"
+	"github.com/mercari/merlin/notifiers"
+	"github.com/mercari/merlin/report"
+	"github.com/mercari/merlin/rules"
+)
+
+func newTestAPIServer(t *testing.T) *apiServer {
+	assert.NoError(t, merlinv1beta1.AddToScheme(scheme.Scheme))
+	ruleObject := &merlinv1beta1.RuleExpression{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "dev", Name: "replicas"},
+		Spec: merlinv1beta1.RuleExpressionSpec{
+			Notification: merlinv1beta1.Notification{Notifiers: []string{"slack"}, Severity: alert.SeverityWarning},
+			TargetKind:   metav1.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+			Expression:   "object.spec.replicas >= 2",
+		},
+	}
+	rule, err := (&rules.ExpressionRule{}).New(context.Background(), fake.NewFakeClientWithScheme(scheme.Scheme, ruleObject), logf.Log, client.ObjectKey{Namespace: "dev", Name: "replicas"})
+	assert.NoError(t, err)
+	rule.SetReady(true)
+	cache := &rulesCache{}
+	cache.Save("dev", "replicas", rule)
+
+	newNotifier := func(alerts map[string]alert.Alert) *notifiers.Notifier {
+		return &notifiers.Notifier{Resource: &merlinv1beta1.Notifier{}, Alerts: alerts}
+	}
+	return &apiServer{
+		log: logf.Log,
+		notifiers: &notifiersCache{notifiers: map[string]*notifiers.Notifier{
+			"slack": newNotifier(map[string]alert.Alert{
+				"RuleExpression/replicas/dev/api": {
+					Severity:     alert.SeverityWarning,
+					Message:      "doesn't satisfy the expression `object.spec.replicas >= 2`",
+					ResourceKind: "Deployment",
+					ResourceName: "dev/api",
+					Status:       alert.StatusFiring,
+					Violated:     true,
+				},
+				"RuleExpression/replicas/dev/web": {
+					Message:      "satisfies the expression `object.spec.replicas >= 2`",
+					ResourceKind: "Deployment",
+					ResourceName: "dev/web",
+					Status:       alert.StatusRecovering,
+				},
+			}),
+			"pagerduty": newNotifier(map[string]alert.Alert{
+				"RuleExpression/replicas/dev/api": {
+					Severity:     alert.SeverityWarning,
+					Message:      "doesn't satisfy the expression `object.spec.replicas >= 2`",
+					ResourceKind: "Deployment",
+					ResourceName: "dev/api",
+					Status:       alert.StatusPending,
+					Violated:     true,
+				},
+				"ClusterRuleNamespaceRequiredLabel/istio/kube-system": {
+					Severity:     alert.SeverityCritical,
+					Message:      "doesnt have required label `istio-injection`",
+					ResourceKind: "Namespace",
+					ResourceName: "kube-system",
+					Status:       alert.StatusFiring,
+					Violated:     true,
+				},
+			}),
+		}},
+		rules: []*rulesCache{cache, cache},
+	}
+}
+
+func Test_apiServer_violations(t *testing.T) {
+	server := httptest.NewServer(newTestAPIServer(t).handler())
+	defer server.Close()
+
+	apiViolation := violation{
+		Rule:         "RuleExpression/replicas",
+		Namespace:    "dev",
+		ResourceKind: "Deployment",
+		ResourceName: "dev/api",
+		Severity:     alert.SeverityWarning,
+		Message:      "doesn't satisfy the expression `object.spec.replicas >= 2`",
+		Status:       alert.StatusFiring,
+		Notifiers:    []string{"pagerduty", "slack"},
+	}
+	namespaceViolation := violation{
+		Rule:         "ClusterRuleNamespaceRequiredLabel/istio",
+		Namespace:    "kube-system",
+		ResourceKind: "Namespace",
+		ResourceName: "kube-system",
+		Severity:     alert.SeverityCritical,
+		Message:      "doesnt have required label `istio-injection`",
+		Status:       alert.StatusFiring,
+		Notifiers:    []string{"pagerduty"},
+	}
+	cases := []struct {
+		desc   string
+		query  string
+		expect []violation
+	}{
+		{desc: "all violations", expect: []violation{namespaceViolation, apiViolation}},
+		{desc: "filter by rule kind", query: "?rule=RuleExpression", expect: []violation{apiViolation}},
+		{desc: "filter by rule name", query: "?rule=ClusterRuleNamespaceRequiredLabel/istio", expect: []violation{namespaceViolation}},
+		{desc: "filter by namespace", query: "?namespace=dev", expect: []violation{apiViolation}},
+		{desc: "filter by kind", query: "?kind=deployment&severity=warning", expect: []violation{apiViolation}},
+		{desc: "filter without result", query: "?namespace=dev&severity=critical", expect: []violation{}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.desc, func(tt *testing.T) {
+			resp, err := http.Get(server.URL + apiPathViolations + tc.query)
+			assert.NoError(tt, err)
+			defer resp.Body.Close()
+			assert.Equal(tt, http.StatusOK, resp.StatusCode)
+			var violations []violation
+			assert.NoError(tt, json.NewDecoder(resp.Body).Decode(&violations))
+			assert.Equal(tt, tc.expect, violations)
+		})
+	}
+
+	resp, err := http.Post(server.URL+apiPathViolations, "application/json", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
+
+func Test_apiServer_rules(t *testing.T) {
+	server := httptest.NewServer(newTestAPIServer(t).handler())
+	defer server.Close()
+
+	for _, query := range []string{"", "?namespace=dev"} {
+		resp, err := http.Get(server.URL + apiPathRules + query)
+		assert.NoError(t, err)
+		var summaries []ruleSummary
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&summaries))
+		resp.Body.Close()
+		assert.Equal(t, []ruleSummary{{
+			Name:      "RuleExpression/replicas",
+			Kind:      "RuleExpression",
+			Namespace: "dev",
+			Ready:     true,
+			Severity:  alert.SeverityWarning,
+			Notifiers: []string{"slack"},
+		}}, summaries)
+	}
+
+	resp, err := http.Get(server.URL + apiPathRules + "?namespace=-")
+	assert.NoError(t, err)
+	var summaries []ruleSummary
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&summaries))
+	resp.Body.Close()
+	assert.Empty(t, summaries)
+}
+
+func Test_apiServer_report(t *testing.T) {
+	server := httptest.NewServer(newTestAPIServer(t).handler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + apiPathReport + "?format=sarif")
+	assert.NoError(t, err)
+	assert.Equal(t, report.ContentTypeSARIF, resp.Header.Get("Content-Type"))
+	var sarif struct {
+		Runs []struct {
+			Results []struct {
+				RuleID string `json:"ruleId"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&sarif))
+	resp.Body.Close()
+	assert.Len(t, sarif.Runs[0].Results, 2)
+
+	resp, err = http.Get(server.URL + apiPathReport)
+	assert.NoError(t, err)
+	assert.Equal(t, report.ContentTypeHTML, resp.Header.Get("Content-Type"))
+	resp.Body.Close()
+
+	resp, err = http.Get(server.URL + apiPathReport + "?format=pdf")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	resp.Body.Close()
+
+	resp, err = http.Get(server.URL + "/")
+	assert.NoError(t, err)
+	body := new(bytes.Buffer)
+	_, _ = body.ReadFrom(resp.Body)
+	resp.Body.Close()
+	assert.Contains(t, body.String(), "<title>Merlin</title>")
+
+	resp, err = http.Get(server.URL + "/unknown")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	resp.Body.Close()
+}
+
+func Test_listenAddr(t *testing.T) {
+	cases := []struct {
+		addr   string
+		expect string
+	}{
+		{addr: ":8082", expect: "127.0.0.1:8082"},
+		{addr: "0.0.0.0:8082", expect: "0.0.0.0:8082"},
+		{addr: "[::1]:8082", expect: "[::1]:8082"},
+		{addr: "merlin:8082", expect: "merlin:8082"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.addr, func(tt *testing.T) {
+			assert.Equal(tt, tc.expect, listenAddr(tc.addr))
+		})
+	}
+}
@@ -0,0 +1,9 @@
+package controllers
+
+// +kubebuilder:rbac:groups=merlin.mercari.com,resources=clusterrulecertificateexpiries,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch
+
+// CertificateExpiryRuleReconciler reconciles rules for ClusterRuleCertificateExpiry
+type CertificateExpiryRuleReconciler struct {
+	RuleReconciler
+}
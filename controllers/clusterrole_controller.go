@@ -0,0 +1,8 @@
+package controllers
+
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterroles,verbs=get;list;watch
+
+// ClusterRoleReconciler reconciles clusterrole and rules for clusterrole objects
+type ClusterRoleReconciler struct {
+	ResourceReconciler
+}
@@ -0,0 +1,8 @@
+package controllers
+
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterrolebindings,verbs=get;list;watch
+
+// ClusterRoleBindingReconciler reconciles clusterrolebinding and rules for clusterrolebinding objects
+type ClusterRoleBindingReconciler struct {
+	ResourceReconciler
+}
@@ -0,0 +1,9 @@
+package controllers
+
+// +kubebuilder:rbac:groups=batch,resources=cronjobs,verbs=get;list;watch
+// +kubebuilder:rbac:groups=batch,resources=cronjobs/status,verbs=get
+
+// CronJobReconciler reconciles cronjob and rules for cronjob objects
+type CronJobReconciler struct {
+	ResourceReconciler
+}
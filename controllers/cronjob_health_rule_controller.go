@@ -0,0 +1,9 @@
+package controllers
+
+// +kubebuilder:rbac:groups=merlin.mercari.com,resources=clusterrulecronjobhealths,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=batch,resources=cronjobs;jobs,verbs=get;list;watch
+
+// CronJobHealthRuleReconciler reconciles rules for ClusterRuleCronJobHealth
+type CronJobHealthRuleReconciler struct {
+	RuleReconciler
+}
@@ -0,0 +1,9 @@
+package controllers
+
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch
+// +kubebuilder:rbac:groups=apps,resources=deployments/status,verbs=get
+
+// DeploymentReconciler reconciles deployment and rules for deployment objects
+type DeploymentReconciler struct {
+	ResourceReconciler
+}
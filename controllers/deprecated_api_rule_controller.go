@@ -0,0 +1,14 @@
+package controllers
+
+// +kubebuilder:rbac:groups=merlin.mercari.com,resources=clusterruledeprecatedapis,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=apps,resources=deployments;statefulsets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=batch,resources=cronjobs,verbs=get;list;watch
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses;networkpolicies,verbs=get;list;watch
+// +kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch
+// +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles;clusterroles;rolebindings;clusterrolebindings,verbs=get;list;watch
+
+// DeprecatedAPIRuleReconciler reconciles rules for ClusterRuleDeprecatedAPI
+type DeprecatedAPIRuleReconciler struct {
+	RuleReconciler
+}
@@ -0,0 +1,152 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	merlinv1beta1 "github.com/mercari/merlin/api/v1beta1"
+	"github.com/mercari/merlin/rules"
+)
+
+const enforcementWebhookPath = "/enforce-merlin-mercari-com"
+
+// +kubebuilder:webhook:path=/enforce-merlin-mercari-com,mutating=false,failurePolicy=ignore,sideEffects=None,groups=*,resources=*,verbs=create;update,versions=*,name=enforce.merlin.mercari.com
+
+// admissionReview is the AdmissionReview with warnings in the response, warnings are supported by kubernetes 1.19 and
+// later for both v1 and v1beta1 reviews, but not in the admission types of the k8s.io/api version we use.
+type admissionReview struct {
+	metav1.TypeMeta `json:",inline"`
+	Request         *admissionv1beta1.AdmissionRequest `json:"request,omitempty"`
+	Response        *admissionResponse                 `json:"response,omitempty"`
+}
+
+type admissionResponse struct {
+	admissionv1beta1.AdmissionResponse `json:",inline"`
+	// Warnings are shown to users by kubectl, and ignored by kubernetes before 1.19
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// enforcer evaluates the incoming objects with the rules enforced as warn or deny, it returns warnings for the
+// violations of warn rules and rejects the objects violating deny rules. The rules are the ones cached by the rule
+// reconcilers, they're evaluated without changing their violations, so dry-run requests are evaluated the same way.
+type enforcer struct {
+	scheme  *runtime.Scheme
+	log     logr.Logger
+	decoder *admission.Decoder
+	// rules are the caches of the rules that can be enforced, i.e., the rules implementing rules.EnforceableRule
+	rules []*rulesCache
+}
+
+func (e *enforcer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	review := admissionReview{}
+	body, err := ioutil.ReadAll(r.Body)
+	if err == nil {
+		err = json.Unmarshal(body, &review)
+	}
+	if err == nil && review.Request == nil {
+		err = fmt.Errorf("admission review has no request")
+	}
+	if err != nil {
+		e.log.Error(err, "unable to decode the admission review")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	resp := e.enforce(r.Context(), admission.Request{AdmissionRequest: *review.Request})
+	resp.UID = review.Request.UID
+	review.Request, review.Response = nil, &resp
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(review); err != nil {
+		e.log.Error(err, "unable to write the admission review")
+	}
+}
+
+func (e *enforcer) enforce(ctx context.Context, req admission.Request) admissionResponse {
+	resp := admissionResponse{AdmissionResponse: admissionv1beta1.AdmissionResponse{Allowed: true}}
+	if (req.Operation != admissionv1beta1.Create && req.Operation != admissionv1beta1.Update) || req.SubResource != "" {
+		return resp
+	}
+	l := e.log.WithValues("kind", req.Kind.Kind, "namespace", req.Namespace, "name", req.Name, "dryRun", req.DryRun != nil && *req.DryRun)
+
+	obj, err := e.decode(req)
+	if err != nil {
+		l.Error(err, "unable to decode the object")
+		return resp
+	}
+
+	gvk := schema.GroupVersionKind{Group: req.Kind.Group, Version: req.Kind.Version, Kind: req.Kind.Kind}
+	var denials []string
+	for _, rule := range e.listRules(req.Namespace) {
+		enforceableRule := rule.(rules.EnforceableRule)
+		enforcement := enforceableRule.GetEnforcement()
+		if (enforcement != merlinv1beta1.EnforcementWarn && enforcement != merlinv1beta1.EnforcementDeny) ||
+			!enforceableRule.IsEnforcedKind(gvk) {
+			continue
+		}
+		a, err := enforceableRule.EvaluateAdmission(ctx, obj.DeepCopyObject())
+		if err != nil {
+			l.Error(err, "unable to evaluate rule", "rule", rule.GetName())
+			continue
+		}
+		if !a.Violated {
+			continue
+		}
+		message := fmt.Sprintf("%s: %s", rule.GetName(), a.Message)
+		l.Info("object violates enforced rule", "rule", rule.GetName(), "enforcement", enforcement, "message", a.Message)
+		if enforcement == merlinv1beta1.EnforcementDeny {
+			denials = append(denials, message)
+		} else {
+			resp.Warnings = append(resp.Warnings, message)
+		}
+	}
+	if len(denials) > 0 {
+		resp.AdmissionResponse = admission.Denied("denied by merlin rules: " + strings.Join(denials, "; ")).AdmissionResponse
+	}
+	return resp
+}
+
+// decode decodes the object as the typed object if the kind is known, otherwise as unstructured,
+// the namespace of the request is set since objects can be created without namespace in metadata.
+func (e *enforcer) decode(req admission.Request) (runtime.Object, error) {
+	obj, err := e.scheme.New(schema.GroupVersionKind{Group: req.Kind.Group, Version: req.Kind.Version, Kind: req.Kind.Kind})
+	if err != nil {
+		obj = &unstructured.Unstructured{}
+	}
+	if err := e.decoder.DecodeRaw(req.Object, obj); err != nil {
+		return nil, err
+	}
+	if o, ok := obj.(metav1.Object); ok && o.GetNamespace() == "" {
+		o.SetNamespace(req.Namespace)
+	}
+	return obj, nil
+}
+
+// listRules lists the enforceable rules to apply to objects in the namespace sorted by names, namespaced rules replace
+// the cluster rules they override as in ResourceReconciler.
+func (e *enforcer) listRules(namespace string) []rules.Rule {
+	var list []rules.Rule
+	for _, c := range e.rules {
+		for _, rule := range c.ListApplicable(namespace) {
+			if _, ok := rule.(rules.EnforceableRule); ok {
+				list = append(list, rule)
+			}
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].GetName() < list[j].GetName()
+	})
+	return list
+}
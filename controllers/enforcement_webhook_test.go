@@ -0,0 +1,182 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	merlinv1beta1 "github.com/mercari/merlin/api/v1beta1"
+	"github.com/mercari/merlin/rules"
+)
+
+func Test_enforcer(t *testing.T) {
+	assert.NoError(t, merlinv1beta1.AddToScheme(scheme.Scheme))
+	decoder, err := admission.NewDecoder(scheme.Scheme)
+	assert.NoError(t, err)
+	deploymentKind := metav1.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}
+	cli := fake.NewFakeClientWithScheme(scheme.Scheme,
+		&merlinv1beta1.ClusterRuleRequiredMetadata{
+			ObjectMeta: metav1.ObjectMeta{Name: "team"},
+			Spec: merlinv1beta1.ClusterRuleRequiredMetadataSpec{
+				Kinds:       []metav1.GroupVersionKind{deploymentKind},
+				Labels:      []merlinv1beta1.RequiredLabel{{Key: "team", Match: "regexp", Value: "^[a-z]+$"}},
+				Enforcement: merlinv1beta1.EnforcementDeny,
+			},
+		},
+		&merlinv1beta1.ClusterRuleRequiredMetadata{
+			ObjectMeta: metav1.ObjectMeta{Name: "owner"},
+			Spec: merlinv1beta1.ClusterRuleRequiredMetadataSpec{
+				Kinds:  []metav1.GroupVersionKind{deploymentKind},
+				Labels: []merlinv1beta1.RequiredLabel{{Key: "owner"}},
+			},
+		},
+		&merlinv1beta1.ClusterRuleNamespaceRequiredLabel{
+			ObjectMeta: metav1.ObjectMeta{Name: "team"},
+			Spec: merlinv1beta1.ClusterRuleNamespaceRequiredLabelSpec{
+				Label:       merlinv1beta1.RequiredLabel{Key: "team"},
+				Enforcement: merlinv1beta1.EnforcementDeny,
+			},
+		},
+		&merlinv1beta1.ClusterRuleExpression{
+			ObjectMeta: metav1.ObjectMeta{Name: "replicas"},
+			Spec: merlinv1beta1.ClusterRuleExpressionSpec{
+				TargetKind:  deploymentKind,
+				Expression:  "object.spec.replicas >= 3",
+				Enforcement: merlinv1beta1.EnforcementDeny,
+			},
+		},
+		&merlinv1beta1.RuleExpression{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "dev", Name: "replicas"},
+			Spec: merlinv1beta1.RuleExpressionSpec{
+				TargetKind:  deploymentKind,
+				Expression:  "object.spec.replicas >= 2",
+				Enforcement: merlinv1beta1.EnforcementWarn,
+			},
+		},
+	)
+	newRulesCache := func(factory rules.RuleFactory, keys ...client.ObjectKey) *rulesCache {
+		cache := &rulesCache{}
+		for _, key := range keys {
+			rule, err := factory.New(context.Background(), cli, logf.Log, key)
+			assert.NoError(t, err)
+			cache.Save(key.Namespace, key.Name, rule)
+		}
+		return cache
+	}
+	e := &enforcer{
+		scheme:  scheme.Scheme,
+		log:     logf.Log,
+		decoder: decoder,
+		rules: []*rulesCache{
+			newRulesCache(&rules.RequiredMetadataRule{}, client.ObjectKey{Name: "team"}, client.ObjectKey{Name: "owner"}),
+			newRulesCache(&rules.NamespaceRequiredLabelRule{}, client.ObjectKey{Name: "team"}),
+			newRulesCache(&rules.ExpressionRule{}, client.ObjectKey{Name: "replicas"}, client.ObjectKey{Namespace: "dev", Name: "replicas"}),
+			newRulesCache(&rules.PodHealthRule{}),
+		},
+	}
+	server := httptest.NewServer(e)
+	defer server.Close()
+
+	newDeployment := func(labels map[string]string, replicas int32) runtime.Object {
+		return &appsv1.Deployment{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Labels: labels},
+			Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		}
+	}
+	dryRun := true
+	cases := []struct {
+		desc      string
+		namespace string
+		obj       runtime.Object
+		operation admissionv1beta1.Operation
+		dryRun    *bool
+		allowed   bool
+		reason    string
+		warnings  []string
+	}{
+		{
+			desc:      "compliant object",
+			namespace: "default",
+			obj:       newDeployment(map[string]string{"team": "merlin"}, 3),
+			operation: admissionv1beta1.Create,
+			allowed:   true,
+		},
+		{
+			desc:      "denied by cluster rules",
+			namespace: "default",
+			obj:       newDeployment(map[string]string{"team": "Merlin"}, 1),
+			operation: admissionv1beta1.Update,
+			dryRun:    &dryRun,
+			reason: "denied by merlin rules: ClusterRuleExpression/replicas: doesn't satisfy the expression `object.spec.replicas >= 3`; " +
+				"ClusterRuleRequiredMetadata/team: has incorrect label value `Merlin` (regex match `^[a-z]+$`) for label `team`",
+		},
+		{
+			desc:      "warned by namespaced rule replacing cluster rule",
+			namespace: "dev",
+			obj:       newDeployment(map[string]string{"team": "merlin"}, 1),
+			operation: admissionv1beta1.Create,
+			allowed:   true,
+			warnings:  []string{"RuleExpression/replicas: doesn't satisfy the expression `object.spec.replicas >= 2`"},
+		},
+		{
+			desc:      "delete is not evaluated",
+			namespace: "default",
+			obj:       newDeployment(nil, 1),
+			operation: admissionv1beta1.Delete,
+			allowed:   true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.desc, func(tt *testing.T) {
+			raw, err := json.Marshal(tc.obj)
+			assert.NoError(tt, err)
+			body, err := json.Marshal(admissionReview{Request: &admissionv1beta1.AdmissionRequest{
+				UID:       "uid",
+				Kind:      metav1.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+				Namespace: tc.namespace,
+				Name:      "api",
+				Operation: tc.operation,
+				DryRun:    tc.dryRun,
+				Object:    runtime.RawExtension{Raw: raw},
+			}})
+			assert.NoError(tt, err)
+			resp, err := http.Post(server.URL, "application/json", bytes.NewReader(body))
+			assert.NoError(tt, err)
+			defer resp.Body.Close()
+
+			review := admissionReview{}
+			assert.NoError(tt, json.NewDecoder(resp.Body).Decode(&review))
+			assert.Equal(tt, "uid", string(review.Response.UID))
+			assert.Equal(tt, tc.allowed, review.Response.Allowed)
+			assert.Equal(tt, tc.warnings, review.Response.Warnings)
+			if tc.reason != "" {
+				assert.Equal(tt, tc.reason, string(review.Response.Result.Reason))
+			}
+		})
+	}
+
+	// evaluations at admission don't change the violations of the rules
+	for _, rule := range e.listRules("dev") {
+		assert.Empty(t, rule.GetViolations(), rule.GetName())
+	}
+
+	resp, err := http.Post(server.URL, "application/json", bytes.NewReader([]byte("{}")))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	resp.Body.Close()
+}
@@ -0,0 +1,11 @@
+package controllers
+
+// +kubebuilder:rbac:groups=merlin.mercari.com,resources=clusterruleexpressions,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=merlin.mercari.com,resources=ruleexpressions,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=*,resources=*,verbs=get;list;watch
+
+// ExpressionRuleReconciler reconciles rules for ClusterRuleExpression and RuleExpression, the target kinds of the rules
+// are watched when the rules are created, so it needs to read all resources.
+type ExpressionRuleReconciler struct {
+	RuleReconciler
+}
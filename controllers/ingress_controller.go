@@ -0,0 +1,9 @@
+package controllers
+
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses/status,verbs=get
+
+// IngressReconciler reconciles ingress and rules for ingress objects
+type IngressReconciler struct {
+	ResourceReconciler
+}
@@ -0,0 +1,9 @@
+package controllers
+
+// +kubebuilder:rbac:groups=merlin.mercari.com,resources=clusterruleingressinvalidbackends,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=core,resources=services;secrets,verbs=get;list;watch
+
+// IngressInvalidBackendRuleReconciler reconciles rules for ClusterRuleIngressInvalidBackend
+type IngressInvalidBackendRuleReconciler struct {
+	RuleReconciler
+}
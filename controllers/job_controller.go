@@ -0,0 +1,9 @@
+package controllers
+
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch
+// +kubebuilder:rbac:groups=batch,resources=jobs/status,verbs=get
+
+// JobReconciler reconciles job and rules for job objects
+type JobReconciler struct {
+	ResourceReconciler
+}
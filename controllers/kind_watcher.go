@@ -0,0 +1,57 @@
+package controllers
+
+import (
+	"fmt"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// kindWatcher watches resources of the kinds from dynamic rules, which are only known after the rules are created.
+// Resources are watched as unstructured, and evaluated by a ResourceReconciler with the rules.
+type kindWatcher struct {
+	sync.Mutex
+	mgr       ctrl.Manager
+	notifiers *notifiersCache
+	// rules is the list of rules cached to apply for the watched resources
+	rules []*rulesCache
+	// watched is the kinds being watched, a kind is watched once even it has multiple rules
+	watched map[schema.GroupVersionKind]bool
+}
+
+// Watch starts a controller for the kind if it's not watched yet
+func (w *kindWatcher) Watch(gvk schema.GroupVersionKind) error {
+	w.Lock()
+	defer w.Unlock()
+	if w.watched[gvk] {
+		return nil
+	}
+
+	resource := &unstructured.Unstructured{}
+	resource.SetGroupVersionKind(gvk)
+	name := fmt.Sprintf("%s.%s.%s", gvk.Kind, gvk.Version, gvk.Group)
+	r := &ResourceReconciler{
+		Client:    w.mgr.GetClient(),
+		log:       ctrl.Log.WithName(name),
+		scheme:    w.mgr.GetScheme(),
+		notifiers: w.notifiers,
+		resource:  resource,
+		rules:     w.rules,
+	}
+	r.log.Info("start watching kind")
+	if err := ctrl.
+		NewControllerManagedBy(w.mgr).
+		For(resource).
+		WithEventFilter(&EventFilter{Log: r.log.WithName("SetupWithManager")}).
+		Named(name).
+		Complete(r); err != nil {
+		return err
+	}
+	if w.watched == nil {
+		w.watched = map[schema.GroupVersionKind]bool{}
+	}
+	w.watched[gvk] = true
+	return nil
+}
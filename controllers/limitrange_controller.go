@@ -0,0 +1,8 @@
+package controllers
+
+// +kubebuilder:rbac:groups=core,resources=limitranges,verbs=get;list;watch
+
+// LimitRangeReconciler reconciles limitrange and rules for limitrange objects
+type LimitRangeReconciler struct {
+	ResourceReconciler
+}
@@ -91,29 +91,23 @@ var _ = Describe("NamespaceControllerTests", func() {
 
 			By("Alert should be added to notifiers status")
 			Eventually(func() map[string]alert.Alert {
-				n := &merlinv1beta1.Notifier{}
-				Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: "", Name: notifier.Name}, n)).Should(Succeed())
-				return n.Status.Alerts
+				return getPersistedAlerts(ctx, notifier.Name)
 			}, time.Second*5, time.Millisecond*200).Should(HaveKey(alertKey))
-			Expect(notifierReconciler.cache.notifiers[notifier.Name].Resource.Status.Alerts).Should(HaveKey(alertKey))
+			Expect(notifierReconciler.cache.notifiers[notifier.Name].Alerts).Should(HaveKey(alertKey))
 
 			By("Ignored Namespace should not have alert")
 			ignoredAlertKey := strings.Join([]string{ruleStructName, rule.Name, "", kubeSystemNamespace}, Separator)
-			n := &merlinv1beta1.Notifier{}
-			Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: "", Name: notifier.Name}, n)).Should(Succeed())
-			Expect(n.Status.Alerts).ShouldNot(HaveKey(ignoredAlertKey))
-			Expect(notifierReconciler.cache.notifiers[notifier.Name].Resource.Status.Alerts).ShouldNot(HaveKey(ignoredAlertKey))
+			Expect(getPersistedAlerts(ctx, notifier.Name)).ShouldNot(HaveKey(ignoredAlertKey))
+			Expect(notifierReconciler.cache.notifiers[notifier.Name].Alerts).ShouldNot(HaveKey(ignoredAlertKey))
 		})
 
 		It("TestRemoveRuleShouldRemoveViolation", func() {
 			Expect(k8sClient.Delete(ctx, rule)).Should(Succeed())
 			// alert should be removed from notifiers status
 			Eventually(func() map[string]alert.Alert {
-				n := &merlinv1beta1.Notifier{}
-				Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: "", Name: notifier.Name}, n)).Should(Succeed())
-				return n.Status.Alerts
+				return getPersistedAlerts(ctx, notifier.Name)
 			}, time.Second*5, time.Millisecond*200).ShouldNot(HaveKey(alertKey))
-			Expect(notifierReconciler.cache.notifiers[notifier.Name].Resource.Status.Alerts).ShouldNot(HaveKey(alertKey))
+			Expect(notifierReconciler.cache.notifiers[notifier.Name].Alerts).ShouldNot(HaveKey(alertKey))
 		})
 
 		It("TestRecreateRuleShouldGetViolationsForExistingNamespace", func() {
@@ -124,11 +118,9 @@ var _ = Describe("NamespaceControllerTests", func() {
 
 			By("Alert should be added to notifiers status")
 			Eventually(func() map[string]alert.Alert {
-				n := &merlinv1beta1.Notifier{}
-				Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: "", Name: notifier.Name}, n)).Should(Succeed())
-				return n.Status.Alerts
+				return getPersistedAlerts(ctx, notifier.Name)
 			}, time.Second*5, time.Millisecond*200).Should(HaveKey(alertKey))
-			Expect(notifierReconciler.cache.notifiers[notifier.Name].Resource.Status.Alerts).Should(HaveKey(alertKey))
+			Expect(notifierReconciler.cache.notifiers[notifier.Name].Alerts).Should(HaveKey(alertKey))
 		})
 	})
 })
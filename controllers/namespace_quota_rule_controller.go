@@ -0,0 +1,9 @@
+package controllers
+
+// +kubebuilder:rbac:groups=merlin.mercari.com,resources=clusterrulenamespacequotas,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=core,resources=namespaces;resourcequotas;limitranges,verbs=get;list;watch
+
+// NamespaceQuotaRuleReconciler reconciles rules for ClusterRuleNamespaceQuota
+type NamespaceQuotaRuleReconciler struct {
+	RuleReconciler
+}
@@ -0,0 +1,8 @@
+package controllers
+
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;list;watch
+
+// NetworkPolicyReconciler reconciles networkpolicy and rules for networkpolicy objects
+type NetworkPolicyReconciler struct {
+	ResourceReconciler
+}
@@ -0,0 +1,10 @@
+package controllers
+
+// +kubebuilder:rbac:groups=merlin.mercari.com,resources=clusterrulenetworkpolicycoverages,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=namespaces;pods,verbs=get;list;watch
+
+// NetworkPolicyCoverageRuleReconciler reconciles rules for ClusterRuleNetworkPolicyCoverage
+type NetworkPolicyCoverageRuleReconciler struct {
+	RuleReconciler
+}
@@ -3,11 +3,13 @@ package controllers
 import (
 	"context"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
 	apierrs "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -24,6 +26,8 @@ type notifiersCache struct {
 	sync.Mutex
 	notifiers map[string]*notifiers.Notifier
 	isReady   bool
+	// cli reads the namespaces of resources for the notifiers and channels to route their alerts to
+	cli client.Reader
 }
 
 func (n *notifiersCache) ClearResourceAlerts(resourceName, msg string) {
@@ -35,28 +39,88 @@ func (n *notifiersCache) ClearResourceAlerts(resourceName, msg string) {
 	return
 }
 
-func (n *notifiersCache) ClearRuleAlerts(ruleNotifiersNames []string, ruleName string, msg string) {
+// ClearRuleAlerts recovers the rule's alerts of all notifiers, since alerts can be routed to notifiers other than the rule's.
+func (n *notifiersCache) ClearRuleAlerts(ruleName string, msg string) {
 	n.Lock()
-	for _, ruleNotifierName := range ruleNotifiersNames {
-		if notifier, ok := n.notifiers[ruleNotifierName]; ok {
-			notifier.ClearRuleAlerts(ruleName, msg)
-		}
+	for _, notifier := range n.notifiers {
+		notifier.ClearRuleAlerts(ruleName, msg)
 	}
 	n.Unlock()
 	return
 }
 
+// SetAlert sets the alert to the notifiers of the rule and the notifiers added by the annotations of the resource's
+// namespace. Other notifiers having the alert get it as recovered, so alerts are recovered when the routes are changed.
 func (n *notifiersCache) SetAlert(rule rules.Rule, a alert.Alert) {
+	// namespaces are read from the watch cache of the client before locking, it may wait for the cache to sync
+	annotations := n.getNamespaceAnnotations(a)
 	n.Lock()
-	for _, ruleNotifierName := range rule.GetNotification().Notifiers {
-		if notifier, ok := n.notifiers[ruleNotifierName]; ok {
-			notifier.SetAlert(rule.GetName(), a)
+	channels := n.route(rule.GetNotification().Notifiers, annotations)
+	recovered := a
+	recovered.Violated = false
+	for name, notifier := range n.notifiers {
+		if channel, ok := channels[name]; ok {
+			routed := a
+			routed.Channel = channel
+			notifier.SetAlert(rule.GetName(), routed)
+		} else if notifier.HasAlert(rule.GetName(), a.ResourceName) {
+			notifier.SetAlert(rule.GetName(), recovered)
 		}
 	}
 	n.Unlock()
 	return
 }
 
+// getNamespaceAnnotations returns the annotations of the alert's resource namespace, empty if it doesn't exist.
+func (n *notifiersCache) getNamespaceAnnotations(a alert.Alert) map[string]string {
+	namespaceName := getAlertNamespace(a)
+	if n.cli == nil || namespaceName == "" {
+		return nil
+	}
+	namespace := &corev1.Namespace{}
+	if err := n.cli.Get(context.Background(), client.ObjectKey{Name: namespaceName}, namespace); err != nil {
+		// namespace may be deleted already, e.g., recovering alerts of deleted resources
+		return nil
+	}
+	return namespace.Annotations
+}
+
+// route returns the channels of the notifiers for the alert, keyed by the notifiers of the rule and the existing notifiers
+// in the namespace annotation. Channels are empty for the notifiers' own channels, unless they're set in the channel
+// annotation as a plain channel for all the notifiers, or as "<notifier>=<channel>" for the notifier.
+func (n *notifiersCache) route(ruleNotifiers []string, annotations map[string]string) map[string]string {
+	channels := map[string]string{}
+	for _, name := range ruleNotifiers {
+		channels[name] = ""
+	}
+	for _, name := range strings.Split(annotations[merlinv1beta1.AnnotationNotifier], ",") {
+		name = strings.TrimSpace(name)
+		if _, ok := n.notifiers[name]; ok {
+			channels[name] = ""
+		}
+	}
+	notifierChannels := map[string]string{}
+	for _, entry := range strings.Split(annotations[merlinv1beta1.AnnotationSlackChannel], ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if i := strings.Index(entry, "="); i >= 0 {
+			notifierChannels[strings.TrimSpace(entry[:i])] = strings.TrimSpace(entry[i+1:])
+			continue
+		}
+		for name := range channels {
+			channels[name] = entry
+		}
+	}
+	for name, channel := range notifierChannels {
+		if _, ok := channels[name]; ok {
+			channels[name] = channel
+		}
+	}
+	return channels
+}
+
 // NotifierReconciler reconciles a Notifier object
 type NotifierReconciler struct {
 	client.Client
@@ -69,6 +133,8 @@ type NotifierReconciler struct {
 	httpClient *http.Client
 	// alertMetrics is the prometheus metrics for alerts, will be 1 if the alert is firing, 0 if not.
 	alertMetrics *prometheus.GaugeVec
+	// violations persists the alerts of notifiers as Violation resources
+	violations *violationStore
 }
 
 // +kubebuilder:rbac:groups=merlin.mercari.com,resources=notifiers,verbs=get;list;watch;create;update;patch;delete
@@ -81,13 +147,25 @@ func (r *NotifierReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	notifierObject := merlinv1beta1.Notifier{}
 	if err := r.Client.Get(ctx, req.NamespacedName, &notifierObject); err != nil {
 		if apierrs.IsNotFound(err) {
-			if _, ok := r.cache.notifiers[req.Name]; ok {
+			r.cache.Lock()
+			notifier, ok := r.cache.notifiers[req.Name]
+			var pending map[string]alert.Alert
+			if ok {
 				msg := "Clear alerts from notifier since this notifier is being deleted"
 				l.Info(msg)
-				r.cache.notifiers[req.Name].ClearAllAlerts(msg)
-				r.cache.notifiers[req.Name].Notify()
+				notifier.ClearAllAlerts(msg)
+				pending = notifier.PendingAlerts()
 				delete(r.cache.notifiers, req.Name)
 			}
+			r.cache.Unlock()
+			if ok {
+				notifier.Send(pending)
+				// alerts failed to recover are removed from violations too since the notifier is gone
+				if err := r.violations.sync(ctx, req.Name, map[string]alert.Alert{}); err != nil {
+					l.Error(err, "unable to remove alerts from violations")
+					return ctrl.Result{RequeueAfter: requeueIntervalForError()}, err
+				}
+			}
 			return ctrl.Result{}, nil
 		}
 		l.Error(err, "failed to get notifier")
@@ -95,25 +173,57 @@ func (r *NotifierReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	}
 
 	// check if notifier is cached, if not it's manager restarted or new notifier is created,
-	// just add to cache and waits for next iteration to send notifications.
-	if _, ok := r.cache.notifiers[req.Name]; !ok {
+	// load the alerts from violations and waits for next iteration to send notifications.
+	r.cache.Lock()
+	notifier, ok := r.cache.notifiers[req.Name]
+	r.cache.Unlock()
+	if !ok {
 		l.Info("Manager restarted or new notifier is created", "status", notifierObject.Status)
-		if notifierObject.Status.Alerts == nil {
-			notifierObject.Status = merlinv1beta1.NotifierStatus{Alerts: map[string]alert.Alert{}}
+		alerts, err := r.violations.load(ctx, req.Name)
+		if err != nil {
+			l.Error(err, "unable to load alerts from violations")
+			return ctrl.Result{RequeueAfter: requeueIntervalForError()}, err
 		}
+		// alerts in the status are from previous versions, they're persisted as violations by the next sync
+		for name, a := range notifierObject.Status.Alerts {
+			if _, ok := alerts[name]; !ok {
+				alerts[name] = a
+			}
+		}
+		r.cache.Lock()
 		r.cache.notifiers[req.Name] = &notifiers.Notifier{
 			Resource:     &notifierObject,
+			Alerts:       alerts,
 			Client:       r.httpClient,
 			AlertMetrics: r.alertMetrics,
 		}
 		r.cache.isReady = true
+		r.cache.Unlock()
 		return ctrl.Result{RequeueAfter: time.Second * time.Duration(notifierObject.Spec.NotifyInterval)}, nil
 	}
 
-	l.V(1).Info("Notifier Status", "alerts", r.cache.notifiers[req.Name].Resource.Status)
-	r.cache.notifiers[req.Name].Notify()
-	notifierObject.Status = r.cache.notifiers[req.Name].Resource.Status
+	// alerts are sent without the lock, so resource reconcilers and the api server aren't blocked by external systems,
+	// and alerts changed meanwhile are kept by merging the results.
+	r.cache.Lock()
+	l.V(1).Info("Notifier Status", "alerts", notifier.Alerts)
+	pending := notifier.PendingAlerts()
+	r.cache.Unlock()
+	results := notifier.Send(pending)
+	r.cache.Lock()
+	notifier.MergeSent(pending, results)
+	alerts := make(map[string]alert.Alert, len(notifier.Alerts))
+	for name, a := range notifier.Alerts {
+		alerts[name] = a
+	}
+	notifierObject.Status.CheckedAt = notifier.Resource.Status.CheckedAt
+	r.cache.Unlock()
 
+	if err := r.violations.sync(ctx, req.Name, alerts); err != nil {
+		l.Error(err, "unable to persist alerts to violations")
+		return ctrl.Result{RequeueAfter: requeueIntervalForError()}, err
+	}
+	notifierObject.Status.Violations = len(alerts)
+	notifierObject.Status.Alerts = nil
 	if err := r.Status().Update(ctx, &notifierObject); err != nil {
 		l.Error(err, "unable to update status")
 		return ctrl.Result{RequeueAfter: requeueIntervalForError()}, err
@@ -121,10 +231,11 @@ func (r *NotifierReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	return ctrl.Result{RequeueAfter: time.Second * time.Duration(notifierObject.Spec.NotifyInterval)}, nil
 }
 
-func (r *NotifierReconciler) SetupWithManager(mgr ctrl.Manager, alertMetrics *prometheus.GaugeVec) error {
+func (r *NotifierReconciler) SetupWithManager(mgr ctrl.Manager, alertMetrics *prometheus.GaugeVec, violationNamespace string) error {
 	l := r.log.WithName("SetupWithManager")
-	r.cache = &notifiersCache{notifiers: map[string]*notifiers.Notifier{}}
+	r.cache = &notifiersCache{notifiers: map[string]*notifiers.Notifier{}, cli: r.Client}
 	r.alertMetrics = alertMetrics
+	r.violations = &violationStore{Client: r.Client, namespace: violationNamespace}
 	l.Info("initialize manager")
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&merlinv1beta1.Notifier{}).
@@ -12,7 +12,6 @@ import (
 	. "github.com/onsi/gomega"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/types"
 
 	// +kubebuilder:scaffold:imports
 
@@ -83,7 +82,7 @@ var _ = Describe("NotifierControllerTests", func() {
 		notifier := notifierReconciler.cache.notifiers[testNotifier.Name]
 		notifier.SetAlert(ruleName, testAlert)
 		By("Notifier cache should have the status")
-		a, ok := notifier.Resource.Status.Alerts[alertKey]
+		a, ok := notifier.Alerts[alertKey]
 		Expect(ok).To(Equal(true))
 		Expect(a).To(Equal(alert.Alert{
 			Severity:     testAlert.Severity,
@@ -94,11 +93,9 @@ var _ = Describe("NotifierControllerTests", func() {
 			Violated:     true,
 		}))
 
-		By("Notifier status should be updated to k8s")
+		By("Violation should be updated to k8s")
 		Eventually(func() alert.Alert {
-			n := &merlinv1beta1.Notifier{}
-			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: testNotifier.Name}, n)).Should(Succeed())
-			return n.Status.Alerts[alertKey]
+			return getPersistedAlerts(ctx, testNotifier.Name)[alertKey]
 		}, time.Second*3, time.Millisecond*200).Should(Equal(alert.Alert{
 			Severity:     testAlert.Severity,
 			ResourceKind: testAlert.ResourceKind,
@@ -109,7 +106,7 @@ var _ = Describe("NotifierControllerTests", func() {
 		}))
 
 		By("Notifier cache should update the status")
-		a, ok = notifier.Resource.Status.Alerts[alertKey]
+		a, ok = notifier.Alerts[alertKey]
 		Expect(ok).To(Equal(true))
 		Expect(a).To(Equal(alert.Alert{
 			Severity:     testAlert.Severity,
@@ -135,19 +132,17 @@ var _ = Describe("NotifierControllerTests", func() {
 			Violated:     false,
 		}
 		By("Notifier cache should have new status")
-		a, ok := notifier.Resource.Status.Alerts[alertKey]
+		a, ok := notifier.Alerts[alertKey]
 		Expect(ok).To(Equal(true))
 		Expect(a).To(Equal(expectAlert))
 
-		By("Notifier status should be updated to k8s")
+		By("Violation should be updated to k8s")
 		Eventually(func() map[string]alert.Alert {
-			n := &merlinv1beta1.Notifier{}
-			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: testNotifier.Name}, n)).Should(Succeed())
-			return n.Status.Alerts
+			return getPersistedAlerts(ctx, testNotifier.Name)
 		}, time.Second*3, time.Millisecond*200).ShouldNot(HaveKey(alertKey))
 
 		By("Notifier cache should remove the alert")
-		Expect(notifier.Resource.Status.Alerts).ShouldNot(HaveKey(alertKey))
+		Expect(notifier.Alerts).ShouldNot(HaveKey(alertKey))
 	})
 
 	It("TestRemoveNotifier", func() {
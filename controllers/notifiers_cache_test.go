@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/mercari/merlin/alert"
+	merlinv1beta1 "github.com/mercari/merlin/api/v1beta1"
+	"github.com/mercari/merlin/notifiers"
+	"github.com/mercari/merlin/rules"
+)
+
+func Test_notifiersCache_SetAlert(t *testing.T) {
+	assert.NoError(t, merlinv1beta1.AddToScheme(scheme.Scheme))
+	cli := fake.NewFakeClientWithScheme(scheme.Scheme,
+		&merlinv1beta1.ClusterRuleNamespaceRequiredLabel{
+			ObjectMeta: metav1.ObjectMeta{Name: "team"},
+			Spec: merlinv1beta1.ClusterRuleNamespaceRequiredLabelSpec{
+				Notification: merlinv1beta1.Notification{Notifiers: []string{"default"}},
+				Label:        merlinv1beta1.RequiredLabel{Key: "team"},
+			},
+		},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "payment", Annotations: map[string]string{
+			merlinv1beta1.AnnotationNotifier:     "unknown, team",
+			merlinv1beta1.AnnotationSlackChannel: "payment-alerts, default=payment-ops, ops=ignored",
+		}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "search", Annotations: map[string]string{
+			merlinv1beta1.AnnotationNotifier: "unknown",
+		}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "billing", Annotations: map[string]string{
+			merlinv1beta1.AnnotationSlackChannel: "billing-alerts",
+		}}},
+	)
+	rule, err := (&rules.NamespaceRequiredLabelRule{}).New(context.Background(), cli, logf.Log, client.ObjectKey{Name: "team"})
+	assert.NoError(t, err)
+	cache := &notifiersCache{cli: cli, notifiers: map[string]*notifiers.Notifier{
+		"default": {Resource: &merlinv1beta1.Notifier{}, Alerts: map[string]alert.Alert{}},
+		"team":    {Resource: &merlinv1beta1.Notifier{}, Alerts: map[string]alert.Alert{}},
+		"ops":     {Resource: &merlinv1beta1.Notifier{}, Alerts: map[string]alert.Alert{}},
+	}}
+
+	// the notifiers of the namespace annotation are added to the rule's, with the channels of their own
+	cache.SetAlert(rule, alert.Alert{ResourceKind: "Deployment", ResourceName: "payment/api", Message: "no team", Violated: true})
+	a := cache.notifiers["team"].Alerts["ClusterRuleNamespaceRequiredLabel/team/payment/api"]
+	assert.Equal(t, alert.StatusPending, a.Status)
+	assert.Equal(t, "payment-alerts", a.Channel)
+	assert.Equal(t, "payment-ops", cache.notifiers["default"].Alerts["ClusterRuleNamespaceRequiredLabel/team/payment/api"].Channel)
+	assert.Empty(t, cache.notifiers["ops"].Alerts)
+
+	// namespaces are routed by their own annotations
+	cache.SetAlert(rule, alert.Alert{ResourceKind: "Namespace", ResourceName: "/payment", Message: "no team", Violated: true})
+	assert.Contains(t, cache.notifiers["team"].Alerts, "ClusterRuleNamespaceRequiredLabel/team//payment")
+
+	// a plain channel applies to the rule's notifiers without the notifier annotation
+	cache.SetAlert(rule, alert.Alert{ResourceKind: "Deployment", ResourceName: "billing/api", Message: "no team", Violated: true})
+	assert.Equal(t, "billing-alerts", cache.notifiers["default"].Alerts["ClusterRuleNamespaceRequiredLabel/team/billing/api"].Channel)
+
+	// only the rule's notifiers for unknown notifiers and namespaces without annotations
+	cache.SetAlert(rule, alert.Alert{ResourceKind: "Deployment", ResourceName: "search/api", Message: "no team", Violated: true})
+	cache.SetAlert(rule, alert.Alert{ResourceKind: "Deployment", ResourceName: "deleted/api", Message: "no team", Violated: true})
+	assert.Len(t, cache.notifiers["default"].Alerts, 5)
+	assert.Len(t, cache.notifiers["team"].Alerts, 2)
+	assert.Equal(t, "", cache.notifiers["default"].Alerts["ClusterRuleNamespaceRequiredLabel/team/search/api"].Channel)
+
+	// alerts of previous routes are recovered in the channel they were sent to, only by the notifiers having them
+	a.Status = alert.StatusFiring
+	cache.notifiers["team"].Alerts["ClusterRuleNamespaceRequiredLabel/team/payment/api"] = a
+	namespace := &corev1.Namespace{}
+	assert.NoError(t, cli.Get(context.Background(), client.ObjectKey{Name: "payment"}, namespace))
+	namespace.Annotations = nil
+	assert.NoError(t, cli.Update(context.Background(), namespace))
+	cache.SetAlert(rule, alert.Alert{ResourceKind: "Deployment", ResourceName: "payment/api", Message: "no team", Violated: true})
+	a = cache.notifiers["team"].Alerts["ClusterRuleNamespaceRequiredLabel/team/payment/api"]
+	assert.Equal(t, alert.StatusRecovering, a.Status)
+	assert.Equal(t, "payment-alerts", a.Channel)
+	assert.Equal(t, "", cache.notifiers["default"].Alerts["ClusterRuleNamespaceRequiredLabel/team/payment/api"].Channel)
+	assert.Empty(t, cache.notifiers["ops"].Alerts)
+
+	// deleted rules recover the sent alerts in all notifiers, and delete the pending ones
+	namespaceAlert := cache.notifiers["team"].Alerts["ClusterRuleNamespaceRequiredLabel/team//payment"]
+	namespaceAlert.Status = alert.StatusFiring
+	cache.notifiers["team"].Alerts["ClusterRuleNamespaceRequiredLabel/team//payment"] = namespaceAlert
+	cache.ClearRuleAlerts(rule.GetName(), "rule deleted")
+	assert.Equal(t, alert.StatusRecovering, cache.notifiers["team"].Alerts["ClusterRuleNamespaceRequiredLabel/team//payment"].Status)
+	assert.Empty(t, cache.notifiers["default"].Alerts)
+}
@@ -0,0 +1,10 @@
+package controllers
+
+// +kubebuilder:rbac:groups=merlin.mercari.com,resources=clusterrulepdboverlaps,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch
+
+// PDBOverlapRuleReconciler reconciles rules for ClusterRulePDBOverlap
+type PDBOverlapRuleReconciler struct {
+	RuleReconciler
+}
@@ -0,0 +1,9 @@
+package controllers
+
+// +kubebuilder:rbac:groups=core,resources=persistentvolumes,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=persistentvolumes/status,verbs=get
+
+// PersistentVolumeReconciler reconciles persistentvolume and rules for persistentvolume objects
+type PersistentVolumeReconciler struct {
+	ResourceReconciler
+}
@@ -0,0 +1,9 @@
+package controllers
+
+// +kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=persistentvolumeclaims/status,verbs=get
+
+// PersistentVolumeClaimReconciler reconciles persistentvolumeclaim and rules for persistentvolumeclaim objects
+type PersistentVolumeClaimReconciler struct {
+	ResourceReconciler
+}
@@ -0,0 +1,11 @@
+package controllers
+
+// +kubebuilder:rbac:groups=merlin.mercari.com,resources=clusterrulepodpendings,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups=apps,resources=replicasets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch
+
+// PodPendingRuleReconciler reconciles rules for ClusterRulePodPending
+type PodPendingRuleReconciler struct {
+	RuleReconciler
+}
@@ -88,23 +88,19 @@ var _ = Describe("PDBControllerTests", func() {
 		It("TestCreateInvalidPDBShouldGetViolations", func() {
 			Expect(k8sClient.Create(ctx, pdb)).Should(Succeed())
 			Eventually(func() map[string]alert.Alert {
-				n := &merlinv1beta1.Notifier{}
-				Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: "", Name: notifier.Name}, n)).Should(Succeed())
-				return n.Status.Alerts
+				return getPersistedAlerts(ctx, notifier.Name)
 			}, time.Second*5, time.Millisecond*200).Should(HaveKey(alertKey))
 			// alert should be added to notifiers status
-			Expect(notifierReconciler.cache.notifiers[notifier.Name].Resource.Status.Alerts).Should(HaveKey(alertKey))
+			Expect(notifierReconciler.cache.notifiers[notifier.Name].Alerts).Should(HaveKey(alertKey))
 		})
 
 		It("TestRemoveRuleShouldRemoveViolation", func() {
 			Expect(k8sClient.Delete(ctx, rule)).Should(Succeed())
 			// alert should be removed from notifiers status
 			Eventually(func() map[string]alert.Alert {
-				n := &merlinv1beta1.Notifier{}
-				Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: "", Name: notifier.Name}, n)).Should(Succeed())
-				return n.Status.Alerts
+				return getPersistedAlerts(ctx, notifier.Name)
 			}, time.Second*5, time.Millisecond*200).ShouldNot(HaveKey(alertKey))
-			Expect(notifierReconciler.cache.notifiers[notifier.Name].Resource.Status.Alerts).ShouldNot(HaveKey(alertKey))
+			Expect(notifierReconciler.cache.notifiers[notifier.Name].Alerts).ShouldNot(HaveKey(alertKey))
 
 		})
 
@@ -115,11 +111,9 @@ var _ = Describe("PDBControllerTests", func() {
 			Expect(k8sClient.Create(ctx, rule)).Should(Succeed(), "Failed to recreate rule")
 			// alert should be added to notifiers status
 			Eventually(func() map[string]alert.Alert {
-				n := &merlinv1beta1.Notifier{}
-				Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: "", Name: notifier.Name}, n)).Should(Succeed())
-				return n.Status.Alerts
+				return getPersistedAlerts(ctx, notifier.Name)
 			}, time.Second*5, time.Millisecond*200).Should(HaveKey(alertKey))
-			Expect(notifierReconciler.cache.notifiers[notifier.Name].Resource.Status.Alerts).Should(HaveKey(alertKey))
+			Expect(notifierReconciler.cache.notifiers[notifier.Name].Alerts).Should(HaveKey(alertKey))
 		})
 	})
 
@@ -186,23 +180,19 @@ var _ = Describe("PDBControllerTests", func() {
 		It("TestCreateViolatedPDBShouldGetViolations", func() {
 			Expect(k8sClient.Create(ctx, pdb)).Should(Succeed())
 			Eventually(func() map[string]alert.Alert {
-				n := &merlinv1beta1.Notifier{}
-				Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: "", Name: notifier.Name}, n)).Should(Succeed())
-				return n.Status.Alerts
+				return getPersistedAlerts(ctx, notifier.Name)
 			}, time.Second*5, time.Millisecond*200).Should(HaveKey(alertKey))
 			// alert should be added to notifiers status
-			Expect(notifierReconciler.cache.notifiers[notifier.Name].Resource.Status.Alerts).Should(HaveKey(alertKey))
+			Expect(notifierReconciler.cache.notifiers[notifier.Name].Alerts).Should(HaveKey(alertKey))
 		})
 
 		It("TestRemoveRuleShouldRemoveViolation", func() {
 			Expect(k8sClient.Delete(ctx, rule)).Should(Succeed())
 			// alert should be removed from notifiers status
 			Eventually(func() map[string]alert.Alert {
-				n := &merlinv1beta1.Notifier{}
-				Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: "", Name: notifier.Name}, n)).Should(Succeed())
-				return n.Status.Alerts
+				return getPersistedAlerts(ctx, notifier.Name)
 			}, time.Second*5, time.Millisecond*200).ShouldNot(HaveKey(alertKey))
-			Expect(notifierReconciler.cache.notifiers[notifier.Name].Resource.Status.Alerts).ShouldNot(HaveKey(alertKey))
+			Expect(notifierReconciler.cache.notifiers[notifier.Name].Alerts).ShouldNot(HaveKey(alertKey))
 
 		})
 
@@ -213,11 +203,9 @@ var _ = Describe("PDBControllerTests", func() {
 			Expect(k8sClient.Create(ctx, rule)).Should(Succeed(), "Failed to recreate rule")
 			// alert should be added to notifiers status
 			Eventually(func() map[string]alert.Alert {
-				n := &merlinv1beta1.Notifier{}
-				Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: "", Name: notifier.Name}, n)).Should(Succeed())
-				return n.Status.Alerts
+				return getPersistedAlerts(ctx, notifier.Name)
 			}, time.Second*5, time.Millisecond*200).Should(HaveKey(alertKey))
-			Expect(notifierReconciler.cache.notifiers[notifier.Name].Resource.Status.Alerts).Should(HaveKey(alertKey))
+			Expect(notifierReconciler.cache.notifiers[notifier.Name].Alerts).Should(HaveKey(alertKey))
 		})
 
 		It("TestCreateEnoughPodsForRuleShouldNotGetViolation", func() {
@@ -242,12 +230,10 @@ var _ = Describe("PDBControllerTests", func() {
 			pdb.Spec.Selector.MatchLabels = labels
 			Expect(k8sClient.Update(ctx, pdb)).Should(Succeed())
 			Eventually(func() map[string]alert.Alert {
-				n := &merlinv1beta1.Notifier{}
-				Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: "", Name: notifier.Name}, n)).Should(Succeed())
-				return n.Status.Alerts
+				return getPersistedAlerts(ctx, notifier.Name)
 			}, time.Second*5, time.Millisecond*200).ShouldNot(HaveKey(alertKey))
 			// alert should be added to notifiers status
-			Expect(notifierReconciler.cache.notifiers[notifier.Name].Resource.Status.Alerts).ShouldNot(HaveKey(alertKey))
+			Expect(notifierReconciler.cache.notifiers[notifier.Name].Alerts).ShouldNot(HaveKey(alertKey))
 		})
 	})
 
@@ -326,23 +312,19 @@ var _ = Describe("PDBControllerTests", func() {
 		It("TestCreateViolatedPDBShouldGetViolations", func() {
 			Expect(k8sClient.Create(ctx, pdb)).Should(Succeed())
 			Eventually(func() map[string]alert.Alert {
-				n := &merlinv1beta1.Notifier{}
-				Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: "", Name: notifier.Name}, n)).Should(Succeed())
-				return n.Status.Alerts
+				return getPersistedAlerts(ctx, notifier.Name)
 			}, time.Second*5, time.Millisecond*200).Should(HaveKey(alertKey))
 			// alert should be added to notifiers status
-			Expect(notifierReconciler.cache.notifiers[notifier.Name].Resource.Status.Alerts).Should(HaveKey(alertKey))
+			Expect(notifierReconciler.cache.notifiers[notifier.Name].Alerts).Should(HaveKey(alertKey))
 		})
 
 		It("TestRemoveRuleShouldRemoveViolation", func() {
 			Expect(k8sClient.Delete(ctx, rule)).Should(Succeed())
 			// alert should be removed from notifiers status
 			Eventually(func() map[string]alert.Alert {
-				n := &merlinv1beta1.Notifier{}
-				Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: "", Name: notifier.Name}, n)).Should(Succeed())
-				return n.Status.Alerts
+				return getPersistedAlerts(ctx, notifier.Name)
 			}, time.Second*5, time.Millisecond*200).ShouldNot(HaveKey(alertKey))
-			Expect(notifierReconciler.cache.notifiers[notifier.Name].Resource.Status.Alerts).ShouldNot(HaveKey(alertKey))
+			Expect(notifierReconciler.cache.notifiers[notifier.Name].Alerts).ShouldNot(HaveKey(alertKey))
 
 		})
 
@@ -353,11 +335,9 @@ var _ = Describe("PDBControllerTests", func() {
 			Expect(k8sClient.Create(ctx, rule)).Should(Succeed(), "Failed to recreate rule")
 			// alert should be added to notifiers status
 			Eventually(func() map[string]alert.Alert {
-				n := &merlinv1beta1.Notifier{}
-				Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: "", Name: notifier.Name}, n)).Should(Succeed())
-				return n.Status.Alerts
+				return getPersistedAlerts(ctx, notifier.Name)
 			}, time.Second*5, time.Millisecond*200).Should(HaveKey(alertKey))
-			Expect(notifierReconciler.cache.notifiers[notifier.Name].Resource.Status.Alerts).Should(HaveKey(alertKey))
+			Expect(notifierReconciler.cache.notifiers[notifier.Name].Alerts).Should(HaveKey(alertKey))
 		})
 
 		It("TestCreateEnoughPodsForRuleShouldNotGetViolation", func() {
@@ -382,12 +362,10 @@ var _ = Describe("PDBControllerTests", func() {
 			pdb.Spec.Selector.MatchLabels = labels
 			Expect(k8sClient.Update(ctx, pdb)).Should(Succeed())
 			Eventually(func() map[string]alert.Alert {
-				n := &merlinv1beta1.Notifier{}
-				Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: "", Name: notifier.Name}, n)).Should(Succeed())
-				return n.Status.Alerts
+				return getPersistedAlerts(ctx, notifier.Name)
 			}, time.Second*5, time.Millisecond*200).ShouldNot(HaveKey(alertKey))
 			// alert should be added to notifiers status
-			Expect(notifierReconciler.cache.notifiers[notifier.Name].Resource.Status.Alerts).ShouldNot(HaveKey(alertKey))
+			Expect(notifierReconciler.cache.notifiers[notifier.Name].Alerts).ShouldNot(HaveKey(alertKey))
 		})
 	})
 
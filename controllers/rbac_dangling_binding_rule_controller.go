@@ -0,0 +1,10 @@
+package controllers
+
+// +kubebuilder:rbac:groups=merlin.mercari.com,resources=clusterrulerbacdanglingbindings,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles;clusterroles;rolebindings;clusterrolebindings,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=serviceaccounts,verbs=get;list;watch
+
+// RBACDanglingBindingRuleReconciler reconciles rules for ClusterRuleRBACDanglingBinding
+type RBACDanglingBindingRuleReconciler struct {
+	RuleReconciler
+}
@@ -0,0 +1,10 @@
+package controllers
+
+// +kubebuilder:rbac:groups=merlin.mercari.com,resources=clusterruleregoes,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=*,resources=*,verbs=get;list;watch
+
+// RegoRuleReconciler reconciles rules for ClusterRuleRego, the target kinds of the rules are watched when the rules
+// are created, so it needs to read all resources.
+type RegoRuleReconciler struct {
+	RuleReconciler
+}
@@ -0,0 +1,10 @@
+package controllers
+
+// +kubebuilder:rbac:groups=merlin.mercari.com,resources=clusterrulerequiredmetadata,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=*,resources=*,verbs=get;list;watch
+
+// RequiredMetadataRuleReconciler reconciles rules for ClusterRuleRequiredMetadata, the rule can check resources of any kinds
+// so it needs to read all resources.
+type RequiredMetadataRuleReconciler struct {
+	RuleReconciler
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/go-logr/logr"
+	"github.com/mercari/merlin/alert"
 	"github.com/mercari/merlin/rules"
 	apierrs "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -47,19 +48,18 @@ func (r *ResourceReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	}
 
 	var rulesToApply []rules.Rule
+	// ruleCaches are the caches of the rules to apply, to mark the rules with changed violations
+	ruleCaches := map[rules.Rule]*rulesCache{}
 	for _, ruleCache := range r.rules {
-		if namespaceRules, ok := ruleCache.LoadNamespaced(req.Namespace); ok {
-			for _, namespaceRule := range namespaceRules {
-				rulesToApply = append(rulesToApply, namespaceRule)
-			}
-		} else if clusterRules, ok := ruleCache.LoadNamespaced(""); ok {
-			for _, clusterRule := range clusterRules {
-				rulesToApply = append(rulesToApply, clusterRule)
-			}
+		for _, rule := range ruleCache.ListApplicable(req.Namespace) {
+			rulesToApply = append(rulesToApply, rule)
+			ruleCaches[rule] = ruleCache
 		}
 	}
 
 	allRulesAreReady := true
+	// requeueAfter is the shortest delay from rules that need to evaluate the object again later
+	var requeueAfter time.Duration
 	for _, rule := range rulesToApply {
 		if !rule.IsReady() {
 			// skip the rule if it's not ready, maybe being created or updated
@@ -68,19 +68,50 @@ func (r *ResourceReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 			continue
 		}
 		l.V(1).Info("evaluating rule", "rule", rule.GetName())
-		a, err := rule.Evaluate(ctx, object)
+		states := rule.GetViolationStates()
+		alerts, err := evaluate(ctx, rule, object)
 		if err != nil {
 			return ctrl.Result{RequeueAfter: requeueIntervalForError()}, err
 		}
-		r.notifiers.SetAlert(rule, a)
+		for _, a := range alerts {
+			r.notifiers.SetAlert(rule, a)
+		}
+		// grace periods are persisted too, e.g., rollouts in progress, so they continue after restarts, changed rules
+		// are persisted in batches by violationsPersister instead of patching the rule for every resource.
+		if !hasSameStates(states, rule.GetViolationStates()) {
+			ruleCaches[rule].MarkDirty(rule)
+		}
+		delay, err := rule.GetDelaySeconds(object)
+		if err != nil {
+			return ctrl.Result{RequeueAfter: requeueIntervalForError()}, err
+		}
+		if delay > 0 && (requeueAfter == 0 || delay < requeueAfter) {
+			requeueAfter = delay
+		}
 	}
 	if !allRulesAreReady {
 		l.V(1).Info("some rules were not evaluated, requeue request")
 		return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
 	}
+	if requeueAfter > 0 {
+		l.V(1).Info("rules need to evaluate again later, requeue request", "after", requeueAfter)
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
+	}
 	return ctrl.Result{}, nil
 }
 
+// evaluate evaluates the object with the rule, and the resources affected by the object for rules with multiple alerts.
+func evaluate(ctx context.Context, rule rules.Rule, object runtime.Object) ([]alert.Alert, error) {
+	if multiAlertRule, ok := rule.(rules.MultiAlertRule); ok {
+		return multiAlertRule.EvaluateRelated(ctx, object)
+	}
+	a, err := rule.Evaluate(ctx, object)
+	if err != nil {
+		return nil, err
+	}
+	return []alert.Alert{a}, nil
+}
+
 func (r *ResourceReconciler) SetupWithManager(mgr ctrl.Manager, indexingFunc func(rawObj runtime.Object) []string) error {
 	ctx := context.Background()
 	l := r.log.WithName("SetupWithManager")
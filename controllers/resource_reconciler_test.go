@@ -0,0 +1,149 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/mercari/merlin/alert"
+	merlinv1beta1 "github.com/mercari/merlin/api/v1beta1"
+	"github.com/mercari/merlin/notifiers"
+	"github.com/mercari/merlin/rules"
+)
+
+func Test_ResourceReconciler_requeueAfterDelay(t *testing.T) {
+	ctx := context.Background()
+	assert.NoError(t, merlinv1beta1.AddToScheme(scheme.Scheme))
+	newRule := func(name string, pendingSeconds int64) *merlinv1beta1.ClusterRulePodPending {
+		return &merlinv1beta1.ClusterRulePodPending{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: merlinv1beta1.ClusterRulePodPendingSpec{
+				Notification:   merlinv1beta1.Notification{Notifiers: []string{"default"}},
+				PendingSeconds: pendingSeconds,
+			},
+		}
+	}
+	cli := fake.NewFakeClientWithScheme(scheme.Scheme,
+		newRule("short", 300),
+		newRule("long", 600),
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pending", CreationTimestamp: metav1.Now()},
+			Status:     corev1.PodStatus{Phase: corev1.PodPending},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "running", CreationTimestamp: metav1.Now()},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+	)
+	shortRules := &rulesCache{}
+	longRules := &rulesCache{}
+	for name, cache := range map[string]*rulesCache{"short": shortRules, "long": longRules} {
+		rule, err := (&rules.PodPendingRule{}).New(ctx, cli, logf.Log, client.ObjectKey{Name: name})
+		assert.NoError(t, err)
+		rule.SetReady(true)
+		cache.Save("", name, rule)
+	}
+	r := &ResourceReconciler{
+		Client: cli,
+		log:    logf.Log,
+		notifiers: &notifiersCache{cli: cli, isReady: true, notifiers: map[string]*notifiers.Notifier{
+			"default": {Resource: &merlinv1beta1.Notifier{}, Alerts: map[string]alert.Alert{}},
+		}},
+		rules:    []*rulesCache{longRules, shortRules},
+		resource: &corev1.Pod{},
+	}
+
+	// pending pods are evaluated again when they reach the shortest pending duration of the rules
+	result, err := r.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "pending"}})
+	assert.NoError(t, err)
+	assert.True(t, result.RequeueAfter > 4*time.Minute && result.RequeueAfter <= 5*time.Minute, result.RequeueAfter)
+
+	// objects without delay from any rules are not requeued
+	result, err = r.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "running"}})
+	assert.NoError(t, err)
+	assert.Equal(t, ctrl.Result{}, result)
+
+	// the initial delays of unused secrets and configmaps also requeue the objects created recently
+	assert.NoError(t, cli.Create(ctx, &merlinv1beta1.ClusterRuleSecretUnused{
+		ObjectMeta: metav1.ObjectMeta{Name: "secret"},
+		Spec: merlinv1beta1.ClusterRuleSecretUnusedSpec{
+			Notification:        merlinv1beta1.Notification{Notifiers: []string{"default"}},
+			InitialDelaySeconds: 60,
+		},
+	}))
+	assert.NoError(t, cli.Create(ctx, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "new", CreationTimestamp: metav1.Now()},
+		Type:       corev1.SecretTypeOpaque,
+	}))
+	secretRule, err := (&rules.SecretUnusedRule{}).New(ctx, cli, logf.Log, client.ObjectKey{Name: "secret"})
+	assert.NoError(t, err)
+	secretRule.SetReady(true)
+	secretRules := &rulesCache{}
+	secretRules.Save("", "secret", secretRule)
+	r.rules = []*rulesCache{secretRules}
+	r.resource = &corev1.Secret{}
+	result, err = r.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "new"}})
+	assert.NoError(t, err)
+	assert.True(t, result.RequeueAfter > 0 && result.RequeueAfter <= time.Minute, result.RequeueAfter)
+}
+
+func Test_ResourceReconciler_persistPending(t *testing.T) {
+	ctx := context.Background()
+	assert.NoError(t, merlinv1beta1.AddToScheme(scheme.Scheme))
+	replicas := int32(3)
+	cli := fake.NewFakeClientWithScheme(scheme.Scheme,
+		&merlinv1beta1.ClusterRuleRolloutProgress{
+			ObjectMeta: metav1.ObjectMeta{Name: "rollout"},
+			Spec:       merlinv1beta1.ClusterRuleRolloutProgressSpec{Notification: merlinv1beta1.Notification{Notifiers: []string{"default"}}},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app", Generation: 2},
+			Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+			Status:     appsv1.DeploymentStatus{ObservedGeneration: 1},
+		},
+	)
+	rule, err := (&rules.RolloutProgressRule{}).New(ctx, cli, logf.Log, client.ObjectKey{Name: "rollout"})
+	assert.NoError(t, err)
+	rule.SetReady(true)
+	cache := &rulesCache{}
+	cache.Save("", "rollout", rule)
+	r := &ResourceReconciler{
+		Client: cli,
+		log:    logf.Log,
+		notifiers: &notifiersCache{cli: cli, isReady: true, notifiers: map[string]*notifiers.Notifier{
+			"default": {Resource: &merlinv1beta1.Notifier{}, Alerts: map[string]alert.Alert{}},
+		}},
+		rules:    []*rulesCache{cache},
+		resource: &appsv1.Deployment{},
+	}
+
+	// rollouts in progress are persisted before they violate the rule, so their grace periods continue after restarts
+	_, err = r.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "app"}})
+	assert.NoError(t, err)
+	updated := &merlinv1beta1.ClusterRuleRolloutProgress{}
+	assert.NoError(t, cli.Get(ctx, client.ObjectKey{Name: "rollout"}, updated))
+	assert.Empty(t, updated.Status.ViolationStates, "violations are persisted by violationsPersister")
+
+	// the rule is marked dirty once and persisted by violationsPersister
+	_, err = r.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "app"}})
+	assert.NoError(t, err)
+	p := &violationsPersister{Client: cli, log: logf.Log, rules: []*rulesCache{cache}}
+	p.persist(ctx)
+	assert.Empty(t, cache.TakeDirty())
+	assert.NoError(t, cli.Get(ctx, client.ObjectKey{Name: "rollout"}, updated))
+	assert.Equal(t, 0, updated.Status.ViolationCount)
+	assert.Len(t, updated.Status.ViolationStates, 1)
+	assert.Equal(t, "Deployment/default/app", updated.Status.ViolationStates[0].Key)
+	assert.True(t, updated.Status.ViolationStates[0].Pending)
+}
@@ -0,0 +1,8 @@
+package controllers
+
+// +kubebuilder:rbac:groups=core,resources=resourcequotas,verbs=get;list;watch
+
+// ResourceQuotaReconciler reconciles resourcequota and rules for resourcequota objects
+type ResourceQuotaReconciler struct {
+	ResourceReconciler
+}
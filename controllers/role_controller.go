@@ -0,0 +1,8 @@
+package controllers
+
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles,verbs=get;list;watch
+
+// RoleReconciler reconciles role and rules for role objects
+type RoleReconciler struct {
+	ResourceReconciler
+}
@@ -0,0 +1,8 @@
+package controllers
+
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=rolebindings,verbs=get;list;watch
+
+// RoleBindingReconciler reconciles rolebinding and rules for rolebinding objects
+type RoleBindingReconciler struct {
+	ResourceReconciler
+}
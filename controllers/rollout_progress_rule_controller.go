@@ -0,0 +1,9 @@
+package controllers
+
+// +kubebuilder:rbac:groups=merlin.mercari.com,resources=clusterrulerolloutprogresses,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=apps,resources=deployments;statefulsets,verbs=get;list;watch
+
+// RolloutProgressRuleReconciler reconciles rules for ClusterRuleRolloutProgress
+type RolloutProgressRuleReconciler struct {
+	RuleReconciler
+}
@@ -2,21 +2,43 @@ package controllers
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/prometheus/client_golang/prometheus"
 	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
+	"github.com/mercari/merlin/alert"
+	merlinv1beta1 "github.com/mercari/merlin/api/v1beta1"
 	"github.com/mercari/merlin/rules"
 )
 
-const FinalizerName = "rule.finalizers.merlin.mercari.com"
+const (
+	FinalizerName = "rule.finalizers.merlin.mercari.com"
+	// maxStatusViolations is the max number of violating resources listed in the rule status
+	maxStatusViolations = 10
+	// maxStatusViolationStates is the max number of violation states persisted in the rule status, to keep the rule
+	// object within the size limit of objects, violating resources are listed before the ones in grace periods.
+	maxStatusViolationStates = 1000
+
+	ruleConditionReasonEvaluated        = "Evaluated"
+	ruleConditionReasonEvaluationFailed = "EvaluationFailed"
+	ruleConditionReasonWatchFailed      = "WatchFailed"
+	ruleConditionReasonInvalidSpec      = "InvalidSpec"
+)
+
+// +kubebuilder:rbac:groups=merlin.mercari.com,resources=clusterrulecertificateexpiries/status;clusterruleconfigmapunuseds/status;clusterrulecronjobhealths/status;clusterruledeprecatedapis/status;clusterruleexpressions/status;clusterrulehpainvalidscaletargetrefs/status;clusterrulehpareplicapercentages/status;clusterruleingressinvalidbackends/status;clusterrulenamespacequotas/status;clusterrulenamespacerequiredlabels/status;clusterrulenetworkpolicycoverages/status;clusterrulepdbinvalidselectors/status;clusterrulepdbminalloweddisruptions/status;clusterrulepdboverlaps/status;clusterrulepodhealths/status;clusterrulepodpendings/status;clusterrulepvcunuseds/status;clusterrulerbacdanglingbindings/status;clusterruleregoes/status;clusterrulerequiredmetadata/status;clusterrulerolloutprogresses/status;clusterrulesecretunuseds/status;clusterruleserviceaccountunuseds/status;clusterruleserviceinvalidselectors/status;clusterruleserviceoverlaps/status;clusterruleworkloadavailabilities/status;ruleexpressions/status;rulehpareplicapercentages/status;rulepdbminalloweddisruptions/status,verbs=get;update;patch
 
 type RuleReconciler struct {
 	client.Client
@@ -31,6 +53,8 @@ type RuleReconciler struct {
 	ruleFactory rules.RuleFactory
 	// violationMetrics
 	violationMetrics *prometheus.GaugeVec
+	// watcher watches the kinds of dynamic rules, only needed for rules implement rules.DynamicRule
+	watcher *kindWatcher
 }
 
 func (r *RuleReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
@@ -43,7 +67,8 @@ func (r *RuleReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 
 	l.Info("Reconciling for rule changed/created")
 	rule, err := r.ruleFactory.New(ctx, r.Client, l, req.NamespacedName)
-	if err != nil {
+	var invalidSpecErr *rules.InvalidSpecError
+	if err != nil && !errors.As(err, &invalidSpecErr) {
 		if apierrs.IsNotFound(err) {
 			l.Info("rule is deleted")
 			return ctrl.Result{}, nil
@@ -52,7 +77,9 @@ func (r *RuleReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 		return ctrl.Result{}, err
 	}
 	rule.SetReady(false)
-	r.rules.Save(req.Namespace, req.Name, rule)
+	if invalidSpecErr == nil {
+		r.rules.Save(req.Namespace, req.Name, rule)
+	}
 	l.V(1).Info("rules to reconcile", "rules", r.rules)
 
 	ruleObject := rule.GetObject()
@@ -68,7 +95,7 @@ func (r *RuleReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	} else if containsString(rule.GetObjectMeta().Finalizers, FinalizerName) {
 		msg := "recover alert since rule is being deleted"
 		l.Info(msg)
-		r.notifiers.ClearRuleAlerts(rule.GetNotification().Notifiers, rule.GetName(), msg)
+		r.notifiers.ClearRuleAlerts(rule.GetName(), msg)
 		r.rules.Delete(req.Namespace, req.Name)
 		rule.RemoveFinalizer(FinalizerName)
 		if err := r.Update(ctx, ruleObject); err != nil {
@@ -78,20 +105,131 @@ func (r *RuleReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 		return ctrl.Result{}, nil
 	}
 
+	// rules with invalid specs are not evaluated until their specs are updated, so they're removed with their alerts,
+	// periodic rules are still rechecked since their specs can depend on other resources, e.g., modules in ConfigMaps.
+	if invalidSpecErr != nil {
+		l.Error(err, "Invalid rule spec")
+		r.rules.Delete(req.Namespace, req.Name)
+		r.notifiers.ClearRuleAlerts(rule.GetName(), "recover alert since rule spec is invalid")
+		r.updateStatus(ctx, l, rule, ruleConditionReasonInvalidSpec, err)
+		if periodicRule, ok := rule.(rules.PeriodicRule); ok && periodicRule.GetRecheckInterval() > 0 {
+			return ctrl.Result{RequeueAfter: periodicRule.GetRecheckInterval()}, nil
+		}
+		return ctrl.Result{}, nil
+	}
+
+	if dynamicRule, ok := rule.(rules.DynamicRule); ok && r.watcher != nil {
+		for _, kind := range dynamicRule.GetTargetKinds() {
+			if err := r.watcher.Watch(kind); err != nil {
+				l.Error(err, "Failed to watch kind", "kind", kind)
+				r.updateStatus(ctx, l, rule, ruleConditionReasonWatchFailed, err)
+				return ctrl.Result{RequeueAfter: requeueIntervalForError()}, err
+			}
+		}
+	}
+
+	// violations persisted in the status are restored, so the first seen times are kept after restarts or rule updates,
+	// and the ones not evaluated again are pruned, e.g., resources deleted while the controller is down.
+	if ruleObject, ok := ruleObject.(merlinv1beta1.RuleObject); ok {
+		rule.RestoreViolations(*ruleObject.GetRuleStatus())
+	}
+	evaluatedAt := time.Now()
 	alerts, err := rule.EvaluateAll(ctx)
 	if err != nil {
 		l.Error(err, "Error running evaluate")
+		r.updateStatus(ctx, l, rule, ruleConditionReasonEvaluationFailed, err)
 		return ctrl.Result{RequeueAfter: requeueIntervalForError()}, err
 	}
+	rule.PruneViolations(evaluatedAt)
 
 	for _, a := range alerts {
+		if req.Namespace == "" && r.isOverridden(rule, a) {
+			// resources in namespaces with rules overriding the cluster rule are evaluated by the namespaced rules
+			a.Violated = false
+		}
 		l.V(1).Info("Setting alerts to notifiers", "alert", a)
 		r.notifiers.SetAlert(rule, a)
 	}
 	rule.SetReady(true)
+	r.updateStatus(ctx, l, rule, ruleConditionReasonEvaluated, nil)
+	if periodicRule, ok := rule.(rules.PeriodicRule); ok && periodicRule.GetRecheckInterval() > 0 {
+		l.V(1).Info("requeue periodic rule", "interval", periodicRule.GetRecheckInterval())
+		return ctrl.Result{RequeueAfter: periodicRule.GetRecheckInterval()}, nil
+	}
 	return ctrl.Result{}, nil
 }
 
+// isOverridden returns if the cluster rule is replaced by namespaced rules in the namespace of the alert's resource.
+func (r *RuleReconciler) isOverridden(clusterRule rules.Rule, a alert.Alert) bool {
+	namespace := strings.SplitN(a.ResourceName, Separator, 2)[0]
+	return namespace != "" && rules.IsOverridden(clusterRule, r.rules.List(namespace))
+}
+
+// updateStatus updates the conditions and violations of the rule after evaluating all applicable resources, failures
+// are only logged since the status is informative, and the status is updated again when the rule is evaluated next time.
+// The status is patched without the resource version since violationsPersister also patches the violations.
+func (r *RuleReconciler) updateStatus(ctx context.Context, l logr.Logger, rule rules.Rule, reason string, evaluateErr error) {
+	base := rule.GetObject().DeepCopyObject()
+	ruleObject, ok := base.DeepCopyObject().(merlinv1beta1.RuleObject)
+	if !ok {
+		return
+	}
+	status := ruleObject.GetRuleStatus()
+	status.ObservedGeneration = ruleObject.GetGeneration()
+	setStatusViolations(status, rule)
+	if evaluateErr != nil {
+		status.SetCondition(merlinv1beta1.RuleConditionReady, metav1.ConditionFalse, reason, evaluateErr.Error())
+		status.SetCondition(merlinv1beta1.RuleConditionDegraded, metav1.ConditionTrue, reason, evaluateErr.Error())
+	} else {
+		now := metav1.Now()
+		status.LastEvaluatedAt = &now
+		status.SetCondition(merlinv1beta1.RuleConditionReady, metav1.ConditionTrue, reason, "all applicable resources are evaluated")
+		status.SetCondition(merlinv1beta1.RuleConditionDegraded, metav1.ConditionFalse, reason, "")
+	}
+	if err := r.Status().Patch(ctx, ruleObject, client.MergeFrom(base)); err != nil {
+		l.Error(err, "Failed to update status")
+	}
+}
+
+// setStatusViolations sets the violation count, the first violating resources and the violation states to the status.
+func setStatusViolations(status *merlinv1beta1.RuleStatus, rule rules.Rule) {
+	var violations []string
+	for key := range rule.GetViolations() {
+		// keys of cluster scoped resources have empty namespaces, e.g., "/default" for namespace default
+		violations = append(violations, strings.TrimPrefix(key, Separator))
+	}
+	sort.Strings(violations)
+	status.ViolationCount = len(violations)
+	if len(violations) > maxStatusViolations {
+		violations = violations[:maxStatusViolations]
+	}
+	status.Violations = violations
+	status.ViolationStates = rule.GetViolationStates()
+	if len(status.ViolationStates) > maxStatusViolationStates {
+		status.ViolationStates = status.ViolationStates[:maxStatusViolationStates]
+	}
+}
+
+// patchViolations persists the violations of the rule after they're changed by evaluating single resources, only the
+// violation fields are patched so the conditions and the evaluated time updated by RuleReconciler are kept.
+func patchViolations(ctx context.Context, cli client.Client, rule rules.Rule) error {
+	ruleObject, ok := rule.GetObject().DeepCopyObject().(merlinv1beta1.RuleObject)
+	if !ok {
+		return nil
+	}
+	status := merlinv1beta1.RuleStatus{}
+	setStatusViolations(&status, rule)
+	patch, err := json.Marshal(map[string]interface{}{"status": map[string]interface{}{
+		"violationCount":  status.ViolationCount,
+		"violations":      status.Violations,
+		"violationStates": status.ViolationStates,
+	}})
+	if err != nil {
+		return err
+	}
+	return cli.Status().Patch(ctx, ruleObject, client.RawPatch(types.MergePatchType, patch))
+}
+
 func (r *RuleReconciler) SetupWithManager(mgr ctrl.Manager, violationMetrics *prometheus.GaugeVec, clusterRule, namespaceRule runtime.Object, indexingFunc func(rawObj runtime.Object) []string) error {
 	ctx := context.Background()
 	l := r.log.WithName("SetupWithManager")
@@ -124,6 +262,8 @@ func (r *RuleReconciler) SetupWithManager(mgr ctrl.Manager, violationMetrics *pr
 type rulesCache struct {
 	sync.Mutex
 	rules map[string]map[string]rules.Rule
+	// dirty are the rules with violations changed by evaluating single resources and not persisted yet.
+	dirty map[types.NamespacedName]bool
 }
 
 func (c *rulesCache) Load(namespace, name string) rules.Rule {
@@ -133,11 +273,31 @@ func (c *rulesCache) Load(namespace, name string) rules.Rule {
 	return r
 }
 
-func (c *rulesCache) LoadNamespaced(namespace string) (map[string]rules.Rule, bool) {
+// List returns the rules in the namespace, "" for cluster rules.
+func (c *rulesCache) List(namespace string) []rules.Rule {
 	c.Lock()
-	rs, ok := c.rules[namespace]
+	list := make([]rules.Rule, 0, len(c.rules[namespace]))
+	for _, rule := range c.rules[namespace] {
+		list = append(list, rule)
+	}
 	c.Unlock()
-	return rs, ok
+	return list
+}
+
+// ListApplicable returns the rules to apply to the resources in the namespace, "" for cluster scoped resources, cluster
+// rules are replaced by the namespaced rules overriding them.
+func (c *rulesCache) ListApplicable(namespace string) []rules.Rule {
+	var namespaced []rules.Rule
+	if namespace != "" {
+		namespaced = c.List(namespace)
+	}
+	applicable := namespaced
+	for _, clusterRule := range c.List("") {
+		if !rules.IsOverridden(clusterRule, namespaced) {
+			applicable = append(applicable, clusterRule)
+		}
+	}
+	return applicable
 }
 
 func (c *rulesCache) Save(namespace, name string, rule rules.Rule) {
@@ -158,5 +318,31 @@ func (c *rulesCache) Delete(namespace, name string) {
 		c.rules = map[string]map[string]rules.Rule{}
 	}
 	delete(c.rules[namespace], name)
+	delete(c.dirty, types.NamespacedName{Namespace: namespace, Name: name})
+	c.Unlock()
+}
+
+// MarkDirty marks the violations of the rule as changed, so they're persisted by violationsPersister later.
+func (c *rulesCache) MarkDirty(rule rules.Rule) {
+	meta := rule.GetObjectMeta()
+	c.Lock()
+	if c.dirty == nil {
+		c.dirty = map[types.NamespacedName]bool{}
+	}
+	c.dirty[types.NamespacedName{Namespace: meta.Namespace, Name: meta.Name}] = true
+	c.Unlock()
+}
+
+// TakeDirty returns the rules marked dirty and clears the marks.
+func (c *rulesCache) TakeDirty() []rules.Rule {
+	c.Lock()
+	var list []rules.Rule
+	for key := range c.dirty {
+		if rule, ok := c.rules[key.Namespace][key.Name]; ok {
+			list = append(list, rule)
+		}
+	}
+	c.dirty = nil
 	c.Unlock()
+	return list
 }
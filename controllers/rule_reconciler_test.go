@@ -0,0 +1,236 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	merlinv1beta1 "github.com/mercari/merlin/api/v1beta1"
+	"github.com/mercari/merlin/rules"
+)
+
+func Test_RuleReconciler_status(t *testing.T) {
+	assert.NoError(t, merlinv1beta1.AddToScheme(scheme.Scheme))
+	ctx := context.Background()
+	rule := &merlinv1beta1.ClusterRuleNamespaceRequiredLabel{
+		ObjectMeta: metav1.ObjectMeta{Name: "team", Generation: 2, Finalizers: []string{FinalizerName}},
+		Spec:       merlinv1beta1.ClusterRuleNamespaceRequiredLabelSpec{Label: merlinv1beta1.RequiredLabel{Key: "team"}},
+	}
+	cli := fake.NewFakeClientWithScheme(scheme.Scheme, rule,
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "dev", Labels: map[string]string{"team": ""}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kube-system"}},
+	)
+	r := &RuleReconciler{
+		Client:      cli,
+		log:         logf.Log,
+		scheme:      scheme.Scheme,
+		notifiers:   &notifiersCache{isReady: true},
+		rules:       &rulesCache{},
+		ruleFactory: &rules.NamespaceRequiredLabelRule{},
+	}
+	_, err := r.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Name: rule.Name}})
+	assert.NoError(t, err)
+
+	updated := &merlinv1beta1.ClusterRuleNamespaceRequiredLabel{}
+	assert.NoError(t, cli.Get(ctx, types.NamespacedName{Name: rule.Name}, updated))
+	status := updated.Status
+	assert.Equal(t, int64(2), status.ObservedGeneration)
+	assert.NotNil(t, status.LastEvaluatedAt)
+	assert.Equal(t, 2, status.ViolationCount)
+	assert.Equal(t, []string{"default", "kube-system"}, status.Violations)
+	assert.Len(t, status.Conditions, 2)
+	assert.Equal(t, metav1.ConditionTrue, status.GetCondition(merlinv1beta1.RuleConditionReady).Status)
+	assert.Equal(t, ruleConditionReasonEvaluated, status.GetCondition(merlinv1beta1.RuleConditionReady).Reason)
+	assert.Equal(t, metav1.ConditionFalse, status.GetCondition(merlinv1beta1.RuleConditionDegraded).Status)
+
+	// the transition time is kept if the status of condition doesn't change, and the violations are limited
+	readyAt := status.GetCondition(merlinv1beta1.RuleConditionReady).LastTransitionTime
+	for i := 0; i < maxStatusViolations; i++ {
+		assert.NoError(t, cli.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns-" + string(rune('a'+i))}}))
+	}
+	_, err = r.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Name: rule.Name}})
+	assert.NoError(t, err)
+	assert.NoError(t, cli.Get(ctx, types.NamespacedName{Name: rule.Name}, updated))
+	assert.Equal(t, maxStatusViolations+2, updated.Status.ViolationCount)
+	assert.Len(t, updated.Status.Violations, maxStatusViolations)
+	assert.Equal(t, "default", updated.Status.Violations[0])
+	assert.Equal(t, readyAt, updated.Status.GetCondition(merlinv1beta1.RuleConditionReady).LastTransitionTime)
+}
+
+func Test_RuleReconciler_restoreViolations(t *testing.T) {
+	assert.NoError(t, merlinv1beta1.AddToScheme(scheme.Scheme))
+	ctx := context.Background()
+	firstSeen := metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second))
+	rule := &merlinv1beta1.ClusterRuleNamespaceRequiredLabel{
+		ObjectMeta: metav1.ObjectMeta{Name: "team", Finalizers: []string{FinalizerName}},
+		Spec:       merlinv1beta1.ClusterRuleNamespaceRequiredLabelSpec{Label: merlinv1beta1.RequiredLabel{Key: "team"}},
+		Status: merlinv1beta1.RuleStatus{
+			LastEvaluatedAt: &firstSeen,
+			ViolationCount:  2,
+			Violations:      []string{"default", "deleted"},
+			ViolationStates: []merlinv1beta1.ViolationState{
+				{Key: "/default", FirstSeen: firstSeen, LastSeen: firstSeen},
+				{Key: "/deleted", FirstSeen: firstSeen, LastSeen: firstSeen},
+			},
+		},
+	}
+	cli := fake.NewFakeClientWithScheme(scheme.Scheme, rule,
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "dev"}},
+	)
+	cache := &rulesCache{}
+	r := &RuleReconciler{
+		Client:      cli,
+		log:         logf.Log,
+		scheme:      scheme.Scheme,
+		notifiers:   &notifiersCache{isReady: true},
+		rules:       cache,
+		ruleFactory: &rules.NamespaceRequiredLabelRule{},
+	}
+	_, err := r.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Name: rule.Name}})
+	assert.NoError(t, err)
+
+	updated := &merlinv1beta1.ClusterRuleNamespaceRequiredLabel{}
+	assert.NoError(t, cli.Get(ctx, types.NamespacedName{Name: rule.Name}, updated))
+	assert.Equal(t, []string{"default", "dev"}, updated.Status.Violations)
+	states := updated.Status.ViolationStates
+	assert.Len(t, states, 2)
+	assert.Equal(t, "/default", states[0].Key)
+	assert.Equal(t, firstSeen, states[0].FirstSeen)
+	assert.True(t, states[0].LastSeen.After(firstSeen.Time))
+	assert.Equal(t, "/dev", states[1].Key)
+	assert.True(t, states[1].FirstSeen.After(firstSeen.Time))
+
+	// violations changed by evaluating single resources are persisted by violationsPersister
+	dev := &corev1.Namespace{}
+	assert.NoError(t, cli.Get(ctx, types.NamespacedName{Name: "dev"}, dev))
+	dev.Labels = map[string]string{"team": ""}
+	assert.NoError(t, cli.Update(ctx, dev))
+	rr := &ResourceReconciler{
+		Client:    cli,
+		log:       logf.Log,
+		scheme:    scheme.Scheme,
+		notifiers: &notifiersCache{isReady: true},
+		rules:     []*rulesCache{cache},
+		resource:  &corev1.Namespace{},
+	}
+	_, err = rr.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Name: "dev"}})
+	assert.NoError(t, err)
+	(&violationsPersister{Client: cli, log: logf.Log, rules: []*rulesCache{cache}}).persist(ctx)
+	assert.NoError(t, cli.Get(ctx, types.NamespacedName{Name: rule.Name}, updated))
+	assert.Equal(t, 1, updated.Status.ViolationCount)
+	assert.Equal(t, []string{"default"}, updated.Status.Violations)
+	assert.Len(t, updated.Status.ViolationStates, 1)
+	assert.Equal(t, metav1.ConditionTrue, updated.Status.GetCondition(merlinv1beta1.RuleConditionReady).Status)
+}
+
+func Test_setStatusViolations(t *testing.T) {
+	seen := metav1.NewTime(time.Now().Truncate(time.Second))
+	states := []merlinv1beta1.ViolationState{{Key: "Deployment/test/pending", FirstSeen: seen, LastSeen: seen, Pending: true}}
+	for i := 0; i < maxStatusViolationStates; i++ {
+		states = append(states, merlinv1beta1.ViolationState{Key: fmt.Sprintf("test/app-%04d", i), FirstSeen: seen, LastSeen: seen})
+	}
+	rule := &rules.RolloutProgressRule{}
+	rule.RestoreViolations(merlinv1beta1.RuleStatus{ViolationStates: states})
+
+	// violation states are limited, and the violating resources are kept before the ones in grace periods
+	status := &merlinv1beta1.RuleStatus{}
+	setStatusViolations(status, rule)
+	assert.Equal(t, maxStatusViolationStates, status.ViolationCount)
+	assert.Len(t, status.ViolationStates, maxStatusViolationStates)
+	assert.Equal(t, "test/app-0000", status.ViolationStates[0].Key)
+	assert.False(t, status.ViolationStates[maxStatusViolationStates-1].Pending)
+}
+
+func Test_RuleReconciler_invalidSpec(t *testing.T) {
+	assert.NoError(t, merlinv1beta1.AddToScheme(scheme.Scheme))
+	ctx := context.Background()
+	rule := &merlinv1beta1.ClusterRuleExpression{
+		ObjectMeta: metav1.ObjectMeta{Name: "replicas", Finalizers: []string{FinalizerName}},
+		Spec: merlinv1beta1.ClusterRuleExpressionSpec{
+			TargetKind: metav1.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+			Expression: "object.spec.replicas >=",
+		},
+	}
+	cli := fake.NewFakeClientWithScheme(scheme.Scheme, rule)
+	cache := &rulesCache{}
+	r := &RuleReconciler{
+		Client:      cli,
+		log:         logf.Log,
+		scheme:      scheme.Scheme,
+		notifiers:   &notifiersCache{isReady: true},
+		rules:       cache,
+		ruleFactory: &rules.ExpressionRule{},
+	}
+
+	// rules failing to compile are reported in the status without retrying, and not applied to resources
+	result, err := r.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Name: rule.Name}})
+	assert.NoError(t, err)
+	assert.Equal(t, ctrl.Result{}, result)
+	assert.Nil(t, cache.Load("", rule.Name))
+	updated := &merlinv1beta1.ClusterRuleExpression{}
+	assert.NoError(t, cli.Get(ctx, types.NamespacedName{Name: rule.Name}, updated))
+	ready := updated.Status.GetCondition(merlinv1beta1.RuleConditionReady)
+	assert.Equal(t, metav1.ConditionFalse, ready.Status)
+	assert.Equal(t, ruleConditionReasonInvalidSpec, ready.Reason)
+	assert.Contains(t, ready.Message, "invalid rule spec")
+
+	// fixed rules are evaluated again
+	updated.Spec.Expression = "object.spec.replicas >= 2"
+	assert.NoError(t, cli.Update(ctx, updated))
+	_, err = r.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Name: rule.Name}})
+	assert.NoError(t, err)
+	assert.NotNil(t, cache.Load("", rule.Name))
+	assert.NoError(t, cli.Get(ctx, types.NamespacedName{Name: rule.Name}, updated))
+	assert.Equal(t, metav1.ConditionTrue, updated.Status.GetCondition(merlinv1beta1.RuleConditionReady).Status)
+}
+
+func Test_rulesCache_ListApplicable(t *testing.T) {
+	assert.NoError(t, merlinv1beta1.AddToScheme(scheme.Scheme))
+	deploymentKind := metav1.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}
+	cli := fake.NewFakeClientWithScheme(scheme.Scheme,
+		&merlinv1beta1.ClusterRuleExpression{
+			ObjectMeta: metav1.ObjectMeta{Name: "replicas"},
+			Spec:       merlinv1beta1.ClusterRuleExpressionSpec{TargetKind: deploymentKind, Expression: "object.spec.replicas >= 3"},
+		},
+		&merlinv1beta1.ClusterRuleExpression{
+			ObjectMeta: metav1.ObjectMeta{Name: "team"},
+			Spec:       merlinv1beta1.ClusterRuleExpressionSpec{TargetKind: deploymentKind, Expression: "has(object.metadata.labels.team)"},
+		},
+		&merlinv1beta1.RuleExpression{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "dev", Name: "replicas"},
+			Spec:       merlinv1beta1.RuleExpressionSpec{TargetKind: deploymentKind, Expression: "object.spec.replicas >= 1"},
+		},
+	)
+	cache := &rulesCache{}
+	for _, key := range []types.NamespacedName{{Name: "replicas"}, {Name: "team"}, {Namespace: "dev", Name: "replicas"}} {
+		rule, err := (&rules.ExpressionRule{}).New(context.Background(), cli, logf.Log, key)
+		assert.NoError(t, err)
+		cache.Save(key.Namespace, key.Name, rule)
+	}
+	ruleNames := func(list []rules.Rule) []string {
+		var names []string
+		for _, rule := range list {
+			names = append(names, rule.GetName())
+		}
+		sort.Strings(names)
+		return names
+	}
+
+	// the namespaced rule only replaces the cluster rule it overrides
+	assert.Equal(t, []string{"ClusterRuleExpression/team", "RuleExpression/replicas"}, ruleNames(cache.ListApplicable("dev")))
+	assert.Equal(t, []string{"ClusterRuleExpression/replicas", "ClusterRuleExpression/team"}, ruleNames(cache.ListApplicable("prod")))
+	assert.Equal(t, []string{"ClusterRuleExpression/replicas", "ClusterRuleExpression/team"}, ruleNames(cache.ListApplicable("")))
+}
@@ -82,12 +82,10 @@ var _ = Describe("SecretUnusedRuleControllerTests", func() {
 		It("TestCreateViolatedObjectShouldGetViolations", func() {
 			Expect(k8sClient.Create(ctx, secret)).Should(Succeed())
 			Eventually(func() map[string]alert.Alert {
-				n := &merlinv1beta1.Notifier{}
-				Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: "", Name: notifier.Name}, n)).Should(Succeed())
-				return n.Status.Alerts
+				return getPersistedAlerts(ctx, notifier.Name)
 			}, time.Second*5, time.Millisecond*200).Should(HaveKey(alertKey))
 			// alert should be added to notifiers status
-			Expect(notifierReconciler.cache.notifiers[notifier.Name].Resource.Status.Alerts).Should(HaveKey(alertKey))
+			Expect(notifierReconciler.cache.notifiers[notifier.Name].Alerts).Should(HaveKey(alertKey))
 		})
 
 		It("TestCreatePodWithSecretShouldNotGetViolation", func() {
@@ -109,12 +107,10 @@ var _ = Describe("SecretUnusedRuleControllerTests", func() {
 				}}
 			Expect(k8sClient.Create(ctx, pod)).Should(Succeed())
 			Eventually(func() map[string]alert.Alert {
-				n := &merlinv1beta1.Notifier{}
-				Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: "", Name: notifier.Name}, n)).Should(Succeed())
-				return n.Status.Alerts
+				return getPersistedAlerts(ctx, notifier.Name)
 			}, time.Second*5, time.Millisecond*200).ShouldNot(HaveKey(alertKey))
 			// alert should be added to notifiers status
-			Expect(notifierReconciler.cache.notifiers[notifier.Name].Resource.Status.Alerts).ShouldNot(HaveKey(alertKey))
+			Expect(notifierReconciler.cache.notifiers[notifier.Name].Alerts).ShouldNot(HaveKey(alertKey))
 		})
 	})
 
@@ -87,23 +87,19 @@ var _ = Describe("ServiceControllerTests", func() {
 		It("TestCreateInvalidServiceShouldGetViolations", func() {
 			Expect(k8sClient.Create(ctx, svc)).Should(Succeed())
 			Eventually(func() map[string]alert.Alert {
-				n := &merlinv1beta1.Notifier{}
-				Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: "", Name: notifier.Name}, n)).Should(Succeed())
-				return n.Status.Alerts
+				return getPersistedAlerts(ctx, notifier.Name)
 			}, time.Second*5, time.Millisecond*200).Should(HaveKey(alertKey))
 			// alert should be added to notifiers status
-			Expect(notifierReconciler.cache.notifiers[notifier.Name].Resource.Status.Alerts).Should(HaveKey(alertKey))
+			Expect(notifierReconciler.cache.notifiers[notifier.Name].Alerts).Should(HaveKey(alertKey))
 		})
 
 		It("TestRemoveRuleShouldRemoveViolation", func() {
 			Expect(k8sClient.Delete(ctx, rule)).Should(Succeed())
 			// alert should be removed from notifiers status
 			Eventually(func() map[string]alert.Alert {
-				n := &merlinv1beta1.Notifier{}
-				Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: "", Name: notifier.Name}, n)).Should(Succeed())
-				return n.Status.Alerts
+				return getPersistedAlerts(ctx, notifier.Name)
 			}, time.Second*5, time.Millisecond*200).ShouldNot(HaveKey(alertKey))
-			Expect(notifierReconciler.cache.notifiers[notifier.Name].Resource.Status.Alerts).ShouldNot(HaveKey(alertKey))
+			Expect(notifierReconciler.cache.notifiers[notifier.Name].Alerts).ShouldNot(HaveKey(alertKey))
 
 		})
 
@@ -114,22 +110,18 @@ var _ = Describe("ServiceControllerTests", func() {
 			Expect(k8sClient.Create(ctx, rule)).Should(Succeed(), "Failed to recreate rule")
 			// alert should be added to notifiers status
 			Eventually(func() map[string]alert.Alert {
-				n := &merlinv1beta1.Notifier{}
-				Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: "", Name: notifier.Name}, n)).Should(Succeed())
-				return n.Status.Alerts
+				return getPersistedAlerts(ctx, notifier.Name)
 			}, time.Second*5, time.Millisecond*200).Should(HaveKey(alertKey))
-			Expect(notifierReconciler.cache.notifiers[notifier.Name].Resource.Status.Alerts).Should(HaveKey(alertKey))
+			Expect(notifierReconciler.cache.notifiers[notifier.Name].Alerts).Should(HaveKey(alertKey))
 		})
 
 		It("TestDeleteServiceShouldRemoveAlert", func() {
 			Expect(k8sClient.Delete(ctx, svc)).Should(Succeed())
 			Eventually(func() map[string]alert.Alert {
-				n := &merlinv1beta1.Notifier{}
-				Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: "", Name: notifier.Name}, n)).Should(Succeed())
-				return n.Status.Alerts
+				return getPersistedAlerts(ctx, notifier.Name)
 			}, time.Second*5, time.Millisecond*200).ShouldNot(HaveKey(alertKey))
 			// alert should be added to notifiers status
-			Expect(notifierReconciler.cache.notifiers[notifier.Name].Resource.Status.Alerts).ShouldNot(HaveKey(alertKey))
+			Expect(notifierReconciler.cache.notifiers[notifier.Name].Alerts).ShouldNot(HaveKey(alertKey))
 		})
 	})
 
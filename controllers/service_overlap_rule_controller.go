@@ -0,0 +1,9 @@
+package controllers
+
+// +kubebuilder:rbac:groups=merlin.mercari.com,resources=clusterruleserviceoverlaps,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=core,resources=pods;services,verbs=get;list;watch
+
+// ServiceOverlapRuleReconciler reconciles rules for ClusterRuleServiceOverlap
+type ServiceOverlapRuleReconciler struct {
+	RuleReconciler
+}
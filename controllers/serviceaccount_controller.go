@@ -0,0 +1,8 @@
+package controllers
+
+// +kubebuilder:rbac:groups=core,resources=serviceaccounts,verbs=get;list;watch
+
+// ServiceAccountReconciler reconciles serviceaccount and rules for serviceaccount objects
+type ServiceAccountReconciler struct {
+	ResourceReconciler
+}
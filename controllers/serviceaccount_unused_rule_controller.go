@@ -0,0 +1,11 @@
+package controllers
+
+// +kubebuilder:rbac:groups=merlin.mercari.com,resources=clusterruleserviceaccountunuseds,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=core,resources=serviceaccounts;pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups=apps,resources=deployments;statefulsets;daemonsets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=batch,resources=jobs;cronjobs,verbs=get;list;watch
+
+// ServiceAccountUnusedRuleReconciler reconciles rules for ClusterRuleServiceAccountUnused
+type ServiceAccountUnusedRuleReconciler struct {
+	RuleReconciler
+}
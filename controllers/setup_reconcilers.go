@@ -6,13 +6,21 @@ import (
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	appsv1 "k8s.io/api/apps/v1"
 	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
 	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	merlinv1beta1 "github.com/mercari/merlin/api/v1beta1"
 	"github.com/mercari/merlin/rules"
@@ -20,7 +28,12 @@ import (
 
 var notifierReconciler *NotifierReconciler
 
-func SetupReconcilers(mgr manager.Manager) error {
+// ruleCaches are all rules caches set up by SetupReconcilers, for the api server to list rules.
+var ruleCaches []*rulesCache
+
+// SetupReconcilers sets up the reconcilers of notifiers, rules and resources, violationNamespace is the namespace for
+// violations of cluster scoped resources.
+func SetupReconcilers(mgr manager.Manager, violationNamespace string) error {
 
 	alertMetrics := prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
@@ -37,7 +50,7 @@ func SetupReconcilers(mgr manager.Manager) error {
 		scheme:     mgr.GetScheme(),
 		httpClient: &http.Client{Timeout: 10 * time.Second},
 	}
-	if err := notifierReconciler.SetupWithManager(mgr, alertMetrics); err != nil {
+	if err := notifierReconciler.SetupWithManager(mgr, alertMetrics, violationNamespace); err != nil {
 		return err
 	}
 
@@ -49,6 +62,60 @@ func SetupReconcilers(mgr manager.Manager) error {
 	serviceInvalidSelectorRules := &rulesCache{}
 	pdbInvalidSelectorRules := &rulesCache{}
 	pdbMinAllowedDisruptionRules := &rulesCache{}
+	ingressInvalidBackendRules := &rulesCache{}
+	certificateExpiryRules := &rulesCache{}
+	workloadAvailabilityRules := &rulesCache{}
+	pdbOverlapRules := &rulesCache{}
+	serviceOverlapRules := &rulesCache{}
+	podHealthRules := &rulesCache{}
+	podPendingRules := &rulesCache{}
+	rolloutProgressRules := &rulesCache{}
+	cronJobHealthRules := &rulesCache{}
+	pvcUnusedRules := &rulesCache{}
+	serviceAccountUnusedRules := &rulesCache{}
+	rbacDanglingBindingRules := &rulesCache{}
+	networkPolicyCoverageRules := &rulesCache{}
+	namespaceQuotaRules := &rulesCache{}
+	deprecatedAPIRules := &rulesCache{}
+	requiredMetadataRules := &rulesCache{}
+	expressionRules := &rulesCache{}
+	regoRules := &rulesCache{}
+	ruleCaches = []*rulesCache{
+		secretUnusedRule,
+		configMapUnusedRule,
+		hpaInvalidScaleTargetRefRule,
+		hpaReplicaPercentageRules,
+		namespaceRequiredLabelRules,
+		serviceInvalidSelectorRules,
+		pdbInvalidSelectorRules,
+		pdbMinAllowedDisruptionRules,
+		ingressInvalidBackendRules,
+		certificateExpiryRules,
+		workloadAvailabilityRules,
+		pdbOverlapRules,
+		serviceOverlapRules,
+		podHealthRules,
+		podPendingRules,
+		rolloutProgressRules,
+		cronJobHealthRules,
+		pvcUnusedRules,
+		serviceAccountUnusedRules,
+		rbacDanglingBindingRules,
+		networkPolicyCoverageRules,
+		namespaceQuotaRules,
+		deprecatedAPIRules,
+		requiredMetadataRules,
+		expressionRules,
+		regoRules,
+	}
+	if err := mgr.Add(&violationsPersister{
+		Client:   mgr.GetClient(),
+		log:      ctrl.Log.WithName("ViolationsPersister"),
+		rules:    ruleCaches,
+		interval: violationsPersistInterval,
+	}); err != nil {
+		return err
+	}
 
 	//// resource Reconcilers ////
 
@@ -59,7 +126,7 @@ func SetupReconcilers(mgr manager.Manager) error {
 			scheme:    mgr.GetScheme(),
 			notifiers: notifierReconciler.cache,
 			resource:  &corev1.Pod{},
-			rules:     []*rulesCache{secretUnusedRule, configMapUnusedRule},
+			rules:     []*rulesCache{secretUnusedRule, configMapUnusedRule, pdbOverlapRules, serviceOverlapRules, podHealthRules, podPendingRules, pvcUnusedRules, serviceAccountUnusedRules, networkPolicyCoverageRules},
 		},
 	}).SetupWithManager(mgr, func(rawObj runtime.Object) []string {
 		obj := rawObj.(*corev1.Pod)
@@ -78,6 +145,8 @@ func SetupReconcilers(mgr manager.Manager) error {
 			rules: []*rulesCache{
 				hpaReplicaPercentageRules,
 				hpaInvalidScaleTargetRefRule,
+				workloadAvailabilityRules,
+				deprecatedAPIRules,
 			},
 		},
 	}).SetupWithManager(mgr,
@@ -95,7 +164,7 @@ func SetupReconcilers(mgr manager.Manager) error {
 			scheme:    mgr.GetScheme(),
 			notifiers: notifierReconciler.cache,
 			resource:  &corev1.Namespace{},
-			rules:     []*rulesCache{namespaceRequiredLabelRules},
+			rules:     []*rulesCache{namespaceRequiredLabelRules, networkPolicyCoverageRules, namespaceQuotaRules, requiredMetadataRules},
 		},
 	}).SetupWithManager(mgr,
 		func(rawObj runtime.Object) []string {
@@ -115,6 +184,9 @@ func SetupReconcilers(mgr manager.Manager) error {
 			rules: []*rulesCache{
 				pdbMinAllowedDisruptionRules,
 				pdbInvalidSelectorRules,
+				workloadAvailabilityRules,
+				pdbOverlapRules,
+				deprecatedAPIRules,
 			},
 		},
 	}).SetupWithManager(mgr,
@@ -132,7 +204,7 @@ func SetupReconcilers(mgr manager.Manager) error {
 			scheme:    mgr.GetScheme(),
 			notifiers: notifierReconciler.cache,
 			resource:  &corev1.Service{},
-			rules:     []*rulesCache{serviceInvalidSelectorRules},
+			rules:     []*rulesCache{serviceInvalidSelectorRules, ingressInvalidBackendRules, serviceOverlapRules, requiredMetadataRules},
 		},
 	}).SetupWithManager(mgr,
 		func(rawObj runtime.Object) []string {
@@ -149,7 +221,7 @@ func SetupReconcilers(mgr manager.Manager) error {
 			scheme:    mgr.GetScheme(),
 			notifiers: notifierReconciler.cache,
 			resource:  &corev1.Secret{},
-			rules:     []*rulesCache{secretUnusedRule},
+			rules:     []*rulesCache{secretUnusedRule, ingressInvalidBackendRules, certificateExpiryRules},
 		},
 	}).SetupWithManager(mgr,
 		func(rawObj runtime.Object) []string {
@@ -166,7 +238,7 @@ func SetupReconcilers(mgr manager.Manager) error {
 			scheme:    mgr.GetScheme(),
 			notifiers: notifierReconciler.cache,
 			resource:  &corev1.ConfigMap{},
-			rules:     []*rulesCache{configMapUnusedRule},
+			rules:     []*rulesCache{configMapUnusedRule, requiredMetadataRules},
 		},
 	}).SetupWithManager(mgr,
 		func(rawObj runtime.Object) []string {
@@ -176,6 +248,261 @@ func SetupReconcilers(mgr manager.Manager) error {
 		return err
 	}
 
+	if err := (&IngressReconciler{
+		ResourceReconciler{
+			Client:    mgr.GetClient(),
+			log:       ctrl.Log.WithName("Ingress"),
+			scheme:    mgr.GetScheme(),
+			notifiers: notifierReconciler.cache,
+			resource:  &networkingv1beta1.Ingress{},
+			rules:     []*rulesCache{ingressInvalidBackendRules, deprecatedAPIRules, requiredMetadataRules},
+		},
+	}).SetupWithManager(mgr,
+		func(rawObj runtime.Object) []string {
+			obj := rawObj.(*networkingv1beta1.Ingress)
+			return []string{obj.Name}
+		}); err != nil {
+		return err
+	}
+
+	if err := (&DeploymentReconciler{
+		ResourceReconciler{
+			Client:    mgr.GetClient(),
+			log:       ctrl.Log.WithName("Deployment"),
+			scheme:    mgr.GetScheme(),
+			notifiers: notifierReconciler.cache,
+			resource:  &appsv1.Deployment{},
+			rules:     []*rulesCache{workloadAvailabilityRules, rolloutProgressRules, deprecatedAPIRules, requiredMetadataRules},
+		},
+	}).SetupWithManager(mgr,
+		func(rawObj runtime.Object) []string {
+			obj := rawObj.(*appsv1.Deployment)
+			return []string{obj.Name}
+		}); err != nil {
+		return err
+	}
+
+	if err := (&StatefulSetReconciler{
+		ResourceReconciler{
+			Client:    mgr.GetClient(),
+			log:       ctrl.Log.WithName("StatefulSet"),
+			scheme:    mgr.GetScheme(),
+			notifiers: notifierReconciler.cache,
+			resource:  &appsv1.StatefulSet{},
+			rules:     []*rulesCache{rolloutProgressRules, deprecatedAPIRules, requiredMetadataRules},
+		},
+	}).SetupWithManager(mgr,
+		func(rawObj runtime.Object) []string {
+			obj := rawObj.(*appsv1.StatefulSet)
+			return []string{obj.Name}
+		}); err != nil {
+		return err
+	}
+
+	if err := (&CronJobReconciler{
+		ResourceReconciler{
+			Client:    mgr.GetClient(),
+			log:       ctrl.Log.WithName("CronJob"),
+			scheme:    mgr.GetScheme(),
+			notifiers: notifierReconciler.cache,
+			resource:  &batchv1beta1.CronJob{},
+			rules:     []*rulesCache{cronJobHealthRules, deprecatedAPIRules, requiredMetadataRules},
+		},
+	}).SetupWithManager(mgr,
+		func(rawObj runtime.Object) []string {
+			obj := rawObj.(*batchv1beta1.CronJob)
+			return []string{obj.Name}
+		}); err != nil {
+		return err
+	}
+
+	if err := (&JobReconciler{
+		ResourceReconciler{
+			Client:    mgr.GetClient(),
+			log:       ctrl.Log.WithName("Job"),
+			scheme:    mgr.GetScheme(),
+			notifiers: notifierReconciler.cache,
+			resource:  &batchv1.Job{},
+			rules:     []*rulesCache{cronJobHealthRules},
+		},
+	}).SetupWithManager(mgr,
+		func(rawObj runtime.Object) []string {
+			obj := rawObj.(*batchv1.Job)
+			return []string{obj.Name}
+		}); err != nil {
+		return err
+	}
+
+	if err := (&PersistentVolumeClaimReconciler{
+		ResourceReconciler{
+			Client:    mgr.GetClient(),
+			log:       ctrl.Log.WithName("PersistentVolumeClaim"),
+			scheme:    mgr.GetScheme(),
+			notifiers: notifierReconciler.cache,
+			resource:  &corev1.PersistentVolumeClaim{},
+			rules:     []*rulesCache{pvcUnusedRules},
+		},
+	}).SetupWithManager(mgr,
+		func(rawObj runtime.Object) []string {
+			obj := rawObj.(*corev1.PersistentVolumeClaim)
+			return []string{obj.Name}
+		}); err != nil {
+		return err
+	}
+
+	if err := (&PersistentVolumeReconciler{
+		ResourceReconciler{
+			Client:    mgr.GetClient(),
+			log:       ctrl.Log.WithName("PersistentVolume"),
+			scheme:    mgr.GetScheme(),
+			notifiers: notifierReconciler.cache,
+			resource:  &corev1.PersistentVolume{},
+			rules:     []*rulesCache{pvcUnusedRules},
+		},
+	}).SetupWithManager(mgr,
+		func(rawObj runtime.Object) []string {
+			obj := rawObj.(*corev1.PersistentVolume)
+			return []string{obj.Name}
+		}); err != nil {
+		return err
+	}
+
+	if err := (&ServiceAccountReconciler{
+		ResourceReconciler{
+			Client:    mgr.GetClient(),
+			log:       ctrl.Log.WithName("ServiceAccount"),
+			scheme:    mgr.GetScheme(),
+			notifiers: notifierReconciler.cache,
+			resource:  &corev1.ServiceAccount{},
+			rules:     []*rulesCache{serviceAccountUnusedRules, rbacDanglingBindingRules},
+		},
+	}).SetupWithManager(mgr,
+		func(rawObj runtime.Object) []string {
+			obj := rawObj.(*corev1.ServiceAccount)
+			return []string{obj.Name}
+		}); err != nil {
+		return err
+	}
+
+	if err := (&RoleReconciler{
+		ResourceReconciler{
+			Client:    mgr.GetClient(),
+			log:       ctrl.Log.WithName("Role"),
+			scheme:    mgr.GetScheme(),
+			notifiers: notifierReconciler.cache,
+			resource:  &rbacv1.Role{},
+			rules:     []*rulesCache{rbacDanglingBindingRules, deprecatedAPIRules},
+		},
+	}).SetupWithManager(mgr,
+		func(rawObj runtime.Object) []string {
+			obj := rawObj.(*rbacv1.Role)
+			return []string{obj.Name}
+		}); err != nil {
+		return err
+	}
+
+	if err := (&ClusterRoleReconciler{
+		ResourceReconciler{
+			Client:    mgr.GetClient(),
+			log:       ctrl.Log.WithName("ClusterRole"),
+			scheme:    mgr.GetScheme(),
+			notifiers: notifierReconciler.cache,
+			resource:  &rbacv1.ClusterRole{},
+			rules:     []*rulesCache{rbacDanglingBindingRules, deprecatedAPIRules},
+		},
+	}).SetupWithManager(mgr,
+		func(rawObj runtime.Object) []string {
+			obj := rawObj.(*rbacv1.ClusterRole)
+			return []string{obj.Name}
+		}); err != nil {
+		return err
+	}
+
+	if err := (&RoleBindingReconciler{
+		ResourceReconciler{
+			Client:    mgr.GetClient(),
+			log:       ctrl.Log.WithName("RoleBinding"),
+			scheme:    mgr.GetScheme(),
+			notifiers: notifierReconciler.cache,
+			resource:  &rbacv1.RoleBinding{},
+			rules:     []*rulesCache{rbacDanglingBindingRules, deprecatedAPIRules},
+		},
+	}).SetupWithManager(mgr,
+		func(rawObj runtime.Object) []string {
+			obj := rawObj.(*rbacv1.RoleBinding)
+			return []string{obj.Name}
+		}); err != nil {
+		return err
+	}
+
+	if err := (&ClusterRoleBindingReconciler{
+		ResourceReconciler{
+			Client:    mgr.GetClient(),
+			log:       ctrl.Log.WithName("ClusterRoleBinding"),
+			scheme:    mgr.GetScheme(),
+			notifiers: notifierReconciler.cache,
+			resource:  &rbacv1.ClusterRoleBinding{},
+			rules:     []*rulesCache{rbacDanglingBindingRules, deprecatedAPIRules},
+		},
+	}).SetupWithManager(mgr,
+		func(rawObj runtime.Object) []string {
+			obj := rawObj.(*rbacv1.ClusterRoleBinding)
+			return []string{obj.Name}
+		}); err != nil {
+		return err
+	}
+
+	if err := (&NetworkPolicyReconciler{
+		ResourceReconciler{
+			Client:    mgr.GetClient(),
+			log:       ctrl.Log.WithName("NetworkPolicy"),
+			scheme:    mgr.GetScheme(),
+			notifiers: notifierReconciler.cache,
+			resource:  &networkingv1.NetworkPolicy{},
+			rules:     []*rulesCache{networkPolicyCoverageRules, deprecatedAPIRules},
+		},
+	}).SetupWithManager(mgr,
+		func(rawObj runtime.Object) []string {
+			obj := rawObj.(*networkingv1.NetworkPolicy)
+			return []string{obj.Name}
+		}); err != nil {
+		return err
+	}
+
+	if err := (&ResourceQuotaReconciler{
+		ResourceReconciler{
+			Client:    mgr.GetClient(),
+			log:       ctrl.Log.WithName("ResourceQuota"),
+			scheme:    mgr.GetScheme(),
+			notifiers: notifierReconciler.cache,
+			resource:  &corev1.ResourceQuota{},
+			rules:     []*rulesCache{namespaceQuotaRules},
+		},
+	}).SetupWithManager(mgr,
+		func(rawObj runtime.Object) []string {
+			obj := rawObj.(*corev1.ResourceQuota)
+			return []string{obj.Name}
+		}); err != nil {
+		return err
+	}
+
+	if err := (&LimitRangeReconciler{
+		ResourceReconciler{
+			Client:    mgr.GetClient(),
+			log:       ctrl.Log.WithName("LimitRange"),
+			scheme:    mgr.GetScheme(),
+			notifiers: notifierReconciler.cache,
+			resource:  &corev1.LimitRange{},
+			rules:     []*rulesCache{namespaceQuotaRules},
+		},
+	}).SetupWithManager(mgr,
+		func(rawObj runtime.Object) []string {
+			obj := rawObj.(*corev1.LimitRange)
+			return []string{obj.Name}
+		}); err != nil {
+		return err
+	}
+
 	//// Rule Reconcilers ////
 
 	if err := (&SecretUnusedRuleReconciler{
@@ -346,5 +673,416 @@ func SetupReconcilers(mgr manager.Manager) error {
 		return err
 	}
 
+	if err := (&IngressInvalidBackendRuleReconciler{
+		RuleReconciler{
+			Client:      mgr.GetClient(),
+			log:         ctrl.Log.WithName("IngressInvalidBackendRule"),
+			scheme:      mgr.GetScheme(),
+			notifiers:   notifierReconciler.cache,
+			rules:       ingressInvalidBackendRules,
+			ruleFactory: &rules.IngressInvalidBackendRule{},
+		},
+	}).SetupWithManager(mgr,
+		alertMetrics,
+		&merlinv1beta1.ClusterRuleIngressInvalidBackend{},
+		nil,
+		func(rawObj runtime.Object) []string {
+			rule := rawObj.(*merlinv1beta1.ClusterRuleIngressInvalidBackend)
+			return []string{rule.Name}
+		}); err != nil {
+		return err
+	}
+
+	if err := (&CertificateExpiryRuleReconciler{
+		RuleReconciler{
+			Client:      mgr.GetClient(),
+			log:         ctrl.Log.WithName("CertificateExpiryRule"),
+			scheme:      mgr.GetScheme(),
+			notifiers:   notifierReconciler.cache,
+			rules:       certificateExpiryRules,
+			ruleFactory: &rules.CertificateExpiryRule{},
+		},
+	}).SetupWithManager(mgr,
+		alertMetrics,
+		&merlinv1beta1.ClusterRuleCertificateExpiry{},
+		nil,
+		func(rawObj runtime.Object) []string {
+			rule := rawObj.(*merlinv1beta1.ClusterRuleCertificateExpiry)
+			return []string{rule.Name}
+		}); err != nil {
+		return err
+	}
+
+	if err := (&WorkloadAvailabilityRuleReconciler{
+		RuleReconciler{
+			Client:      mgr.GetClient(),
+			log:         ctrl.Log.WithName("WorkloadAvailabilityRule"),
+			scheme:      mgr.GetScheme(),
+			notifiers:   notifierReconciler.cache,
+			rules:       workloadAvailabilityRules,
+			ruleFactory: &rules.WorkloadAvailabilityRule{},
+		},
+	}).SetupWithManager(mgr,
+		alertMetrics,
+		&merlinv1beta1.ClusterRuleWorkloadAvailability{},
+		nil,
+		func(rawObj runtime.Object) []string {
+			rule := rawObj.(*merlinv1beta1.ClusterRuleWorkloadAvailability)
+			return []string{rule.Name}
+		}); err != nil {
+		return err
+	}
+
+	if err := (&PDBOverlapRuleReconciler{
+		RuleReconciler{
+			Client:      mgr.GetClient(),
+			log:         ctrl.Log.WithName("PDBOverlapRule"),
+			scheme:      mgr.GetScheme(),
+			notifiers:   notifierReconciler.cache,
+			rules:       pdbOverlapRules,
+			ruleFactory: &rules.PDBOverlapRule{},
+		},
+	}).SetupWithManager(mgr,
+		alertMetrics,
+		&merlinv1beta1.ClusterRulePDBOverlap{},
+		nil,
+		func(rawObj runtime.Object) []string {
+			rule := rawObj.(*merlinv1beta1.ClusterRulePDBOverlap)
+			return []string{rule.Name}
+		}); err != nil {
+		return err
+	}
+
+	if err := (&ServiceOverlapRuleReconciler{
+		RuleReconciler{
+			Client:      mgr.GetClient(),
+			log:         ctrl.Log.WithName("ServiceOverlapRule"),
+			scheme:      mgr.GetScheme(),
+			notifiers:   notifierReconciler.cache,
+			rules:       serviceOverlapRules,
+			ruleFactory: &rules.ServiceOverlapRule{},
+		},
+	}).SetupWithManager(mgr,
+		alertMetrics,
+		&merlinv1beta1.ClusterRuleServiceOverlap{},
+		nil,
+		func(rawObj runtime.Object) []string {
+			rule := rawObj.(*merlinv1beta1.ClusterRuleServiceOverlap)
+			return []string{rule.Name}
+		}); err != nil {
+		return err
+	}
+
+	if err := (&PodHealthRuleReconciler{
+		RuleReconciler{
+			Client:      mgr.GetClient(),
+			log:         ctrl.Log.WithName("PodHealthRule"),
+			scheme:      mgr.GetScheme(),
+			notifiers:   notifierReconciler.cache,
+			rules:       podHealthRules,
+			ruleFactory: &rules.PodHealthRule{},
+		},
+	}).SetupWithManager(mgr,
+		alertMetrics,
+		&merlinv1beta1.ClusterRulePodHealth{},
+		nil,
+		func(rawObj runtime.Object) []string {
+			rule := rawObj.(*merlinv1beta1.ClusterRulePodHealth)
+			return []string{rule.Name}
+		}); err != nil {
+		return err
+	}
+
+	if err := (&PodPendingRuleReconciler{
+		RuleReconciler{
+			Client:      mgr.GetClient(),
+			log:         ctrl.Log.WithName("PodPendingRule"),
+			scheme:      mgr.GetScheme(),
+			notifiers:   notifierReconciler.cache,
+			rules:       podPendingRules,
+			ruleFactory: &rules.PodPendingRule{},
+		},
+	}).SetupWithManager(mgr,
+		alertMetrics,
+		&merlinv1beta1.ClusterRulePodPending{},
+		nil,
+		func(rawObj runtime.Object) []string {
+			rule := rawObj.(*merlinv1beta1.ClusterRulePodPending)
+			return []string{rule.Name}
+		}); err != nil {
+		return err
+	}
+
+	if err := (&RolloutProgressRuleReconciler{
+		RuleReconciler{
+			Client:      mgr.GetClient(),
+			log:         ctrl.Log.WithName("RolloutProgressRule"),
+			scheme:      mgr.GetScheme(),
+			notifiers:   notifierReconciler.cache,
+			rules:       rolloutProgressRules,
+			ruleFactory: &rules.RolloutProgressRule{},
+		},
+	}).SetupWithManager(mgr,
+		alertMetrics,
+		&merlinv1beta1.ClusterRuleRolloutProgress{},
+		nil,
+		func(rawObj runtime.Object) []string {
+			rule := rawObj.(*merlinv1beta1.ClusterRuleRolloutProgress)
+			return []string{rule.Name}
+		}); err != nil {
+		return err
+	}
+
+	if err := (&CronJobHealthRuleReconciler{
+		RuleReconciler{
+			Client:      mgr.GetClient(),
+			log:         ctrl.Log.WithName("CronJobHealthRule"),
+			scheme:      mgr.GetScheme(),
+			notifiers:   notifierReconciler.cache,
+			rules:       cronJobHealthRules,
+			ruleFactory: &rules.CronJobHealthRule{},
+		},
+	}).SetupWithManager(mgr,
+		alertMetrics,
+		&merlinv1beta1.ClusterRuleCronJobHealth{},
+		nil,
+		func(rawObj runtime.Object) []string {
+			rule := rawObj.(*merlinv1beta1.ClusterRuleCronJobHealth)
+			return []string{rule.Name}
+		}); err != nil {
+		return err
+	}
+
+	if err := (&PVCUnusedRuleReconciler{
+		RuleReconciler{
+			Client:      mgr.GetClient(),
+			log:         ctrl.Log.WithName("PVCUnusedRule"),
+			scheme:      mgr.GetScheme(),
+			notifiers:   notifierReconciler.cache,
+			rules:       pvcUnusedRules,
+			ruleFactory: &rules.PVCUnusedRule{},
+		},
+	}).SetupWithManager(mgr,
+		alertMetrics,
+		&merlinv1beta1.ClusterRulePVCUnused{},
+		nil,
+		func(rawObj runtime.Object) []string {
+			rule := rawObj.(*merlinv1beta1.ClusterRulePVCUnused)
+			return []string{rule.Name}
+		}); err != nil {
+		return err
+	}
+
+	if err := (&ServiceAccountUnusedRuleReconciler{
+		RuleReconciler{
+			Client:      mgr.GetClient(),
+			log:         ctrl.Log.WithName("ServiceAccountUnusedRule"),
+			scheme:      mgr.GetScheme(),
+			notifiers:   notifierReconciler.cache,
+			rules:       serviceAccountUnusedRules,
+			ruleFactory: &rules.ServiceAccountUnusedRule{},
+		},
+	}).SetupWithManager(mgr,
+		alertMetrics,
+		&merlinv1beta1.ClusterRuleServiceAccountUnused{},
+		nil,
+		func(rawObj runtime.Object) []string {
+			rule := rawObj.(*merlinv1beta1.ClusterRuleServiceAccountUnused)
+			return []string{rule.Name}
+		}); err != nil {
+		return err
+	}
+
+	if err := (&RBACDanglingBindingRuleReconciler{
+		RuleReconciler{
+			Client:      mgr.GetClient(),
+			log:         ctrl.Log.WithName("RBACDanglingBindingRule"),
+			scheme:      mgr.GetScheme(),
+			notifiers:   notifierReconciler.cache,
+			rules:       rbacDanglingBindingRules,
+			ruleFactory: &rules.RBACDanglingBindingRule{},
+		},
+	}).SetupWithManager(mgr,
+		alertMetrics,
+		&merlinv1beta1.ClusterRuleRBACDanglingBinding{},
+		nil,
+		func(rawObj runtime.Object) []string {
+			rule := rawObj.(*merlinv1beta1.ClusterRuleRBACDanglingBinding)
+			return []string{rule.Name}
+		}); err != nil {
+		return err
+	}
+
+	if err := (&NetworkPolicyCoverageRuleReconciler{
+		RuleReconciler{
+			Client:      mgr.GetClient(),
+			log:         ctrl.Log.WithName("NetworkPolicyCoverageRule"),
+			scheme:      mgr.GetScheme(),
+			notifiers:   notifierReconciler.cache,
+			rules:       networkPolicyCoverageRules,
+			ruleFactory: &rules.NetworkPolicyCoverageRule{},
+		},
+	}).SetupWithManager(mgr,
+		alertMetrics,
+		&merlinv1beta1.ClusterRuleNetworkPolicyCoverage{},
+		nil,
+		func(rawObj runtime.Object) []string {
+			rule := rawObj.(*merlinv1beta1.ClusterRuleNetworkPolicyCoverage)
+			return []string{rule.Name}
+		}); err != nil {
+		return err
+	}
+
+	if err := (&NamespaceQuotaRuleReconciler{
+		RuleReconciler{
+			Client:      mgr.GetClient(),
+			log:         ctrl.Log.WithName("NamespaceQuotaRule"),
+			scheme:      mgr.GetScheme(),
+			notifiers:   notifierReconciler.cache,
+			rules:       namespaceQuotaRules,
+			ruleFactory: &rules.NamespaceQuotaRule{},
+		},
+	}).SetupWithManager(mgr,
+		alertMetrics,
+		&merlinv1beta1.ClusterRuleNamespaceQuota{},
+		nil,
+		func(rawObj runtime.Object) []string {
+			rule := rawObj.(*merlinv1beta1.ClusterRuleNamespaceQuota)
+			return []string{rule.Name}
+		}); err != nil {
+		return err
+	}
+
+	if err := (&DeprecatedAPIRuleReconciler{
+		RuleReconciler{
+			Client:      mgr.GetClient(),
+			log:         ctrl.Log.WithName("DeprecatedAPIRule"),
+			scheme:      mgr.GetScheme(),
+			notifiers:   notifierReconciler.cache,
+			rules:       deprecatedAPIRules,
+			ruleFactory: &rules.DeprecatedAPIRule{},
+		},
+	}).SetupWithManager(mgr,
+		alertMetrics,
+		&merlinv1beta1.ClusterRuleDeprecatedAPI{},
+		nil,
+		func(rawObj runtime.Object) []string {
+			rule := rawObj.(*merlinv1beta1.ClusterRuleDeprecatedAPI)
+			return []string{rule.Name}
+		}); err != nil {
+		return err
+	}
+
+	if err := (&RequiredMetadataRuleReconciler{
+		RuleReconciler{
+			Client:      mgr.GetClient(),
+			log:         ctrl.Log.WithName("RequiredMetadataRule"),
+			scheme:      mgr.GetScheme(),
+			notifiers:   notifierReconciler.cache,
+			rules:       requiredMetadataRules,
+			ruleFactory: &rules.RequiredMetadataRule{},
+		},
+	}).SetupWithManager(mgr,
+		alertMetrics,
+		&merlinv1beta1.ClusterRuleRequiredMetadata{},
+		nil,
+		func(rawObj runtime.Object) []string {
+			rule := rawObj.(*merlinv1beta1.ClusterRuleRequiredMetadata)
+			return []string{rule.Name}
+		}); err != nil {
+		return err
+	}
+
+	// dynamic rules share the watcher, so each kind is watched once and evaluated with all dynamic rules
+	watcher := &kindWatcher{
+		mgr:       mgr,
+		notifiers: notifierReconciler.cache,
+		rules:     []*rulesCache{expressionRules, regoRules},
+	}
+
+	if err := (&ExpressionRuleReconciler{
+		RuleReconciler{
+			Client:      mgr.GetClient(),
+			log:         ctrl.Log.WithName("ExpressionRule"),
+			scheme:      mgr.GetScheme(),
+			notifiers:   notifierReconciler.cache,
+			rules:       expressionRules,
+			ruleFactory: &rules.ExpressionRule{},
+			watcher:     watcher,
+		},
+	}).SetupWithManager(mgr,
+		alertMetrics,
+		&merlinv1beta1.ClusterRuleExpression{},
+		&merlinv1beta1.RuleExpression{},
+		func(rawObj runtime.Object) []string {
+			if clusterRule, ok := rawObj.(*merlinv1beta1.ClusterRuleExpression); ok {
+				return []string{clusterRule.Name}
+			} else if namespaceRule, ok := rawObj.(*merlinv1beta1.RuleExpression); ok {
+				return []string{namespaceRule.Name}
+			}
+			return []string{}
+		}); err != nil {
+		return err
+	}
+
+	if err := (&RegoRuleReconciler{
+		RuleReconciler{
+			Client:      mgr.GetClient(),
+			log:         ctrl.Log.WithName("RegoRule"),
+			scheme:      mgr.GetScheme(),
+			notifiers:   notifierReconciler.cache,
+			rules:       regoRules,
+			ruleFactory: &rules.RegoRule{},
+			watcher:     watcher,
+		},
+	}).SetupWithManager(mgr,
+		alertMetrics,
+		&merlinv1beta1.ClusterRuleRego{},
+		nil,
+		func(rawObj runtime.Object) []string {
+			rule := rawObj.(*merlinv1beta1.ClusterRuleRego)
+			return []string{rule.Name}
+		}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SetupAPIServer adds the read-only api server and dashboard of violations to the manager, the reconcilers need to be
+// set up first since it reads from their caches.
+func SetupAPIServer(mgr manager.Manager, addr string) error {
+	if notifierReconciler == nil {
+		return fmt.Errorf("reconcilers are not set up")
+	}
+	return mgr.Add(&apiServer{
+		addr:      addr,
+		log:       ctrl.Log.WithName("APIServer"),
+		notifiers: notifierReconciler.cache,
+		rules:     ruleCaches,
+	})
+}
+
+// SetupWebhooks registers the validating webhook of rules and notifiers, and the enforcement webhook of rules
+// to the webhook server of the manager, the reconcilers need to be set up first since rules are enforced from their caches.
+func SetupWebhooks(mgr manager.Manager) error {
+	if notifierReconciler == nil {
+		return fmt.Errorf("reconcilers are not set up")
+	}
+	decoder, err := admission.NewDecoder(mgr.GetScheme())
+	if err != nil {
+		return err
+	}
+	server := mgr.GetWebhookServer()
+	server.Register(validatingWebhookPath, &webhook.Admission{Handler: &validator{
+		cli:    mgr.GetClient(),
+		scheme: mgr.GetScheme(),
+	}})
+	server.Register(enforcementWebhookPath, &enforcer{
+		scheme:  mgr.GetScheme(),
+		log:     ctrl.Log.WithName("Enforcer"),
+		decoder: decoder,
+		rules:   ruleCaches,
+	})
 	return nil
 }
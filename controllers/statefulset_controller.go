@@ -0,0 +1,9 @@
+package controllers
+
+// +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=apps,resources=statefulsets/status,verbs=get
+
+// StatefulSetReconciler reconciles statefulset and rules for statefulset objects
+type StatefulSetReconciler struct {
+	ResourceReconciler
+}
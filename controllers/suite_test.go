@@ -16,9 +16,11 @@ limitations under the License.
 package controllers
 
 import (
+	"context"
 	"path/filepath"
 	"testing"
 
+	"github.com/mercari/merlin/alert"
 	merlinv1beta1 "github.com/mercari/merlin/api/v1beta1"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -73,7 +75,7 @@ var _ = BeforeSuite(func(done Done) {
 	})
 
 	Expect(err).NotTo(HaveOccurred())
-	Expect(SetupReconcilers(mgr)).Should(Succeed())
+	Expect(SetupReconcilers(mgr, "default")).Should(Succeed())
 
 	go func() {
 		Expect(mgr.Start(stopCh)).Should(Succeed(), "failed to start manager")
@@ -91,3 +93,10 @@ var _ = AfterSuite(func() {
 	err := testEnv.Stop()
 	Expect(err).ToNot(HaveOccurred())
 })
+
+// getPersistedAlerts returns the alerts of the notifier persisted as violations.
+func getPersistedAlerts(ctx context.Context, notifier string) map[string]alert.Alert {
+	alerts, err := (&violationStore{Client: k8sClient}).load(ctx, notifier)
+	Expect(err).NotTo(HaveOccurred())
+	return alerts
+}
@@ -5,6 +5,8 @@ import (
 	"math/rand"
 	"reflect"
 	"time"
+
+	merlinv1beta1 "github.com/mercari/merlin/api/v1beta1"
 )
 
 const (
@@ -37,3 +39,17 @@ func requeueIntervalForError() time.Duration {
 	rand.Seed(time.Now().UnixNano())
 	return time.Duration(rand.Intn(requeueMaxInternalSeconds-requeueMinInternalSeconds+1)+requeueMinInternalSeconds) * time.Second
 }
+
+// hasSameStates checks if the violation states are for the same violating resources and grace periods, the last seen
+// times are not compared since they're updated by every evaluation.
+func hasSameStates(a, b []merlinv1beta1.ViolationState) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Key != b[i].Key || a[i].Pending != b[i].Pending || !a[i].FirstSeen.Equal(&b[i].FirstSeen) {
+			return false
+		}
+	}
+	return true
+}
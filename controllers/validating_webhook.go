@@ -0,0 +1,217 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/mercari/merlin/alert"
+	merlinv1beta1 "github.com/mercari/merlin/api/v1beta1"
+	"github.com/mercari/merlin/rules"
+)
+
+const validatingWebhookPath = "/validate-merlin-mercari-com-v1beta1"
+
+// +kubebuilder:webhook:path=/validate-merlin-mercari-com-v1beta1,mutating=false,failurePolicy=fail,sideEffects=None,groups=merlin.mercari.com,resources=notifiers;clusterrulecertificateexpiries;clusterruleconfigmapunuseds;clusterrulecronjobhealths;clusterruledeprecatedapis;clusterruleexpressions;clusterrulehpainvalidscaletargetrefs;clusterrulehpareplicapercentages;clusterruleingressinvalidbackends;clusterrulenamespacequotas;clusterrulenamespacerequiredlabels;clusterrulenetworkpolicycoverages;clusterrulepdbinvalidselectors;clusterrulepdbminalloweddisruptions;clusterrulepdboverlaps;clusterrulepodhealths;clusterrulepodpendings;clusterrulepvcunuseds;clusterrulerbacdanglingbindings;clusterruleregoes;clusterrulerequiredmetadata;clusterrulerolloutprogresses;clusterrulesecretunuseds;clusterruleserviceaccountunuseds;clusterruleserviceinvalidselectors;clusterruleserviceoverlaps;clusterruleworkloadavailabilities;ruleexpressions;rulehpareplicapercentages;rulepdbminalloweddisruptions;rulepodresources,verbs=create;update,versions=v1beta1,name=validate.merlin.mercari.com
+
+// severities are the acceptable severities, empty means using the notifier's severity
+var severities = []string{
+	string(alert.SeverityDefault),
+	string(alert.SeverityFatal),
+	string(alert.SeverityCritical),
+	string(alert.SeverityWarning),
+	string(alert.SeverityInfo),
+}
+
+// validator validates rules and notifiers on create and update, since invalid specs are otherwise accepted and only fail at runtime.
+type validator struct {
+	cli     client.Client
+	scheme  *runtime.Scheme
+	decoder *admission.Decoder
+}
+
+func (v *validator) InjectDecoder(d *admission.Decoder) error {
+	v.decoder = d
+	return nil
+}
+
+func (v *validator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	obj, err := v.scheme.New(schema.GroupVersionKind{Group: req.Kind.Group, Version: req.Kind.Version, Kind: req.Kind.Kind})
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if err := v.decoder.Decode(req, obj); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	// updates without spec changes are allowed, e.g., finalizers, so rules can always be deleted even if their notifiers are gone.
+	if len(req.OldObject.Raw) > 0 {
+		oldObj, newObj := &unstructured.Unstructured{}, &unstructured.Unstructured{}
+		if err := v.decoder.DecodeRaw(req.OldObject, oldObj); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		if err := v.decoder.DecodeRaw(req.Object, newObj); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		if equality.Semantic.DeepEqual(oldObj.Object["spec"], newObj.Object["spec"]) {
+			return admission.Allowed("")
+		}
+	}
+
+	var errs field.ErrorList
+	if notifier, ok := obj.(*merlinv1beta1.Notifier); ok {
+		errs = validateNotifier(notifier)
+	} else {
+		if errs, err = v.validateRule(ctx, obj); err != nil {
+			return admission.Errored(http.StatusInternalServerError, err)
+		}
+	}
+	if len(errs) > 0 {
+		return admission.Denied(errs.ToAggregate().Error())
+	}
+	return admission.Allowed("")
+}
+
+// validateRule validates the notification of the rule and the rule specific fields.
+func (v *validator) validateRule(ctx context.Context, obj runtime.Object) (field.ErrorList, error) {
+	specPath := field.NewPath("spec")
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	notification := merlinv1beta1.Notification{}
+	if n, ok, _ := unstructured.NestedMap(content, "spec", "notification"); ok {
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(n, &notification); err != nil {
+			return nil, err
+		}
+	}
+	errs, err := v.validateNotification(ctx, notification, specPath.Child("notification"))
+	if err != nil {
+		return nil, err
+	}
+
+	switch r := obj.(type) {
+	case *merlinv1beta1.ClusterRuleHPAReplicaPercentage:
+		errs = append(errs, validatePercent(r.Spec.Percent, specPath.Child("percent"))...)
+	case *merlinv1beta1.RuleHPAReplicaPercentage:
+		errs = append(errs, validatePercent(r.Spec.Percent, specPath.Child("percent"))...)
+	case *merlinv1beta1.ClusterRuleNamespaceQuota:
+		errs = append(errs, validatePercent(r.Spec.Percent, specPath.Child("percent"))...)
+	case *merlinv1beta1.ClusterRuleNamespaceRequiredLabel:
+		errs = append(errs, validateRequiredLabel(r.Spec.Label, specPath.Child("label"))...)
+	case *merlinv1beta1.ClusterRuleRequiredMetadata:
+		for i, l := range r.Spec.Labels {
+			errs = append(errs, validateRequiredLabel(l, specPath.Child("labels").Index(i))...)
+		}
+		for i, a := range r.Spec.Annotations {
+			errs = append(errs, validateRequiredLabel(a, specPath.Child("annotations").Index(i))...)
+		}
+	case *merlinv1beta1.ClusterRuleCertificateExpiry:
+		for i, t := range r.Spec.Thresholds {
+			errs = append(errs, validateSeverity(t.Severity, specPath.Child("thresholds").Index(i).Child("severity"))...)
+		}
+	case *merlinv1beta1.ClusterRuleExpression:
+		errs = append(errs, validateExpression(r.Spec.Expression, r.Spec.Message, specPath)...)
+	case *merlinv1beta1.RuleExpression:
+		errs = append(errs, validateExpression(r.Spec.Expression, r.Spec.Message, specPath)...)
+	case *merlinv1beta1.ClusterRuleRego:
+		errs = append(errs, validateRego(ctx, r.Spec, specPath)...)
+	}
+	return errs, nil
+}
+
+// validateNotification validates the severity, the message template and that the notifiers exist.
+func (v *validator) validateNotification(ctx context.Context, n merlinv1beta1.Notification, path *field.Path) (field.ErrorList, error) {
+	errs := validateSeverity(n.Severity, path.Child("severity"))
+	if n.CustomMessageTemplate != "" {
+		a := alert.Alert{
+			MessageTemplate: n.CustomMessageTemplate,
+			Severity:        alert.SeverityWarning,
+			ResourceKind:    "Kind",
+			ResourceName:    "namespace/name",
+			Message:         "message",
+		}
+		if _, err := a.ParseMessage(); err != nil {
+			errs = append(errs, field.Invalid(path.Child("customMessageTemplate"), n.CustomMessageTemplate, err.Error()))
+		}
+	}
+	for i, name := range n.Notifiers {
+		if err := v.cli.Get(ctx, client.ObjectKey{Name: name}, &merlinv1beta1.Notifier{}); err != nil {
+			if apierrs.IsNotFound(err) {
+				errs = append(errs, field.NotFound(path.Child("notifiers").Index(i), name))
+				continue
+			}
+			return nil, err
+		}
+	}
+	return errs, nil
+}
+
+func validateNotifier(n *merlinv1beta1.Notifier) (errs field.ErrorList) {
+	specPath := field.NewPath("spec")
+	if n.Spec.NotifyInterval <= 0 {
+		errs = append(errs, field.Invalid(specPath.Child("notifyInterval"), n.Spec.NotifyInterval, "should be greater than 0"))
+	}
+	return append(errs, validateSeverity(n.Spec.Slack.Severity, specPath.Child("slack", "severity"))...)
+}
+
+func validateSeverity(s alert.Severity, path *field.Path) field.ErrorList {
+	if !containsString(severities, string(s)) {
+		return field.ErrorList{field.NotSupported(path, s, severities[1:])}
+	}
+	return nil
+}
+
+func validatePercent(p int32, path *field.Path) field.ErrorList {
+	if p < 0 || p > 100 {
+		return field.ErrorList{field.Invalid(path, p, "should be between 0 and 100")}
+	}
+	return nil
+}
+
+func validateRequiredLabel(l merlinv1beta1.RequiredLabel, path *field.Path) field.ErrorList {
+	if len(l.OneOf) > 0 {
+		return nil
+	}
+	switch l.Match {
+	case "", "exact":
+	case "regexp":
+		if _, err := regexp.Compile(l.Value); err != nil {
+			return field.ErrorList{field.Invalid(path.Child("value"), l.Value, err.Error())}
+		}
+	default:
+		return field.ErrorList{field.NotSupported(path.Child("match"), l.Match, []string{"exact", "regexp"})}
+	}
+	return nil
+}
+
+func validateExpression(expression, message string, path *field.Path) field.ErrorList {
+	if err := rules.ValidateExpression(expression, message); err != nil {
+		return field.ErrorList{field.Invalid(path.Child("expression"), expression, err.Error())}
+	}
+	return nil
+}
+
+// validateRego validates the module is set either inline or from ConfigMap, modules in ConfigMaps are only validated
+// when the rule is loaded since they can be changed without the rule.
+func validateRego(ctx context.Context, spec merlinv1beta1.ClusterRuleRegoSpec, path *field.Path) field.ErrorList {
+	if (spec.Module == "") == (spec.ModuleConfigMap == nil) {
+		return field.ErrorList{field.Invalid(path.Child("module"), spec.Module, "either module or moduleConfigMap is required")}
+	}
+	if spec.ModuleConfigMap != nil {
+		return nil
+	}
+	if err := rules.ValidateRego(ctx, spec.Module, spec.Query, spec.Parameters); err != nil {
+		return field.ErrorList{field.Invalid(path.Child("module"), spec.Module, err.Error())}
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/mercari/merlin/alert"
+	"github.com/mercari/merlin/alert/slack"
+	merlinv1beta1 "github.com/mercari/merlin/api/v1beta1"
+)
+
+func newAdmissionRequest(t *testing.T, kind string, obj, oldObj runtime.Object) admission.Request {
+	req := admission.Request{AdmissionRequest: admissionv1beta1.AdmissionRequest{
+		Kind:      metav1.GroupVersionKind{Group: merlinv1beta1.GROUP, Version: merlinv1beta1.VERSION, Kind: kind},
+		Operation: admissionv1beta1.Create,
+	}}
+	var err error
+	req.Object.Raw, err = json.Marshal(obj)
+	assert.NoError(t, err)
+	if oldObj != nil {
+		req.Operation = admissionv1beta1.Update
+		req.OldObject.Raw, err = json.Marshal(oldObj)
+		assert.NoError(t, err)
+	}
+	return req
+}
+
+func Test_validator_Handle(t *testing.T) {
+	assert.NoError(t, merlinv1beta1.AddToScheme(scheme.Scheme))
+	decoder, err := admission.NewDecoder(scheme.Scheme)
+	assert.NoError(t, err)
+	v := &validator{
+		cli:    fake.NewFakeClientWithScheme(scheme.Scheme, &merlinv1beta1.Notifier{ObjectMeta: metav1.ObjectMeta{Name: "slack"}}),
+		scheme: scheme.Scheme,
+	}
+	assert.NoError(t, v.InjectDecoder(decoder))
+
+	cases := []struct {
+		desc    string
+		kind    string
+		obj     runtime.Object
+		oldObj  runtime.Object
+		allowed bool
+		reason  string
+	}{
+		{
+			desc: "valid rule",
+			kind: "ClusterRuleHPAReplicaPercentage",
+			obj: &merlinv1beta1.ClusterRuleHPAReplicaPercentage{Spec: merlinv1beta1.ClusterRuleHPAReplicaPercentageSpec{
+				Notification: merlinv1beta1.Notification{Notifiers: []string{"slack"}, Severity: alert.SeverityWarning, CustomMessageTemplate: "{{.ResourceName}}: {{.Message}}"},
+				Percent:      80,
+			}},
+			allowed: true,
+		},
+		{
+			desc: "invalid notification and percent",
+			kind: "RuleHPAReplicaPercentage",
+			obj: &merlinv1beta1.RuleHPAReplicaPercentage{Spec: merlinv1beta1.RuleHPAReplicaPercentageSpec{
+				Notification: merlinv1beta1.Notification{Notifiers: []string{"slack", "pagerduty"}, Severity: "high", CustomMessageTemplate: "{{.Unknown}}"},
+				Percent:      120,
+			}},
+			reason: "[spec.notification.severity: Unsupported value: \"high\": supported values: \"fatal\", \"critical\", \"warning\", \"info\", " +
+				"spec.notification.customMessageTemplate: Invalid value: \"{{.Unknown}}\": template: msg:1:2: executing \"msg\" at <.Unknown>: can't evaluate field Unknown in type alert.MessageTemplateVariables, " +
+				"spec.notification.notifiers[1]: Not found: \"pagerduty\", " +
+				"spec.percent: Invalid value: 120: should be between 0 and 100]",
+		},
+		{
+			desc: "invalid regexp",
+			kind: "ClusterRuleRequiredMetadata",
+			obj: &merlinv1beta1.ClusterRuleRequiredMetadata{Spec: merlinv1beta1.ClusterRuleRequiredMetadataSpec{
+				Labels:      []merlinv1beta1.RequiredLabel{{Key: "app", Match: "regexp", Value: "^[a-z"}},
+				Annotations: []merlinv1beta1.RequiredLabel{{Key: "owner", Match: "glob", Value: "*"}, {Key: "team", Match: "regexp", OneOf: []string{"a"}, Value: "("}},
+			}},
+			reason: "[spec.labels[0].value: Invalid value: \"^[a-z\": error parsing regexp: missing closing ]: `[a-z`, " +
+				"spec.annotations[0].match: Unsupported value: \"glob\": supported values: \"exact\", \"regexp\"]",
+		},
+		{
+			desc: "invalid threshold severity",
+			kind: "ClusterRuleCertificateExpiry",
+			obj: &merlinv1beta1.ClusterRuleCertificateExpiry{Spec: merlinv1beta1.ClusterRuleCertificateExpirySpec{
+				Thresholds: []merlinv1beta1.CertificateExpiryThreshold{{Days: 7, Severity: alert.SeverityCritical}, {Days: 30, Severity: "warn"}},
+			}},
+			reason: "spec.thresholds[1].severity: Unsupported value: \"warn\": supported values: \"fatal\", \"critical\", \"warning\", \"info\"",
+		},
+		{
+			desc: "invalid expression",
+			kind: "RuleExpression",
+			obj: &merlinv1beta1.RuleExpression{Spec: merlinv1beta1.RuleExpressionSpec{
+				Expression: "object.spec.replicas >= 2",
+				Message:    "replicas {{ object.spec.replicas + }}",
+			}},
+		},
+		{
+			desc: "invalid rego module",
+			kind: "ClusterRuleRego",
+			obj: &merlinv1beta1.ClusterRuleRego{Spec: merlinv1beta1.ClusterRuleRegoSpec{
+				Module: "package replicas\nviolation[msg] {",
+				Query:  "data.replicas.violation",
+			}},
+		},
+		{
+			desc: "rego module both inline and from configmap",
+			kind: "ClusterRuleRego",
+			obj: &merlinv1beta1.ClusterRuleRego{Spec: merlinv1beta1.ClusterRuleRegoSpec{
+				Module:          "package replicas",
+				ModuleConfigMap: &merlinv1beta1.RegoModuleConfigMap{Namespace: "merlin", Name: "policies", Key: "replicas.rego"},
+				Query:           "data.replicas.violation",
+			}},
+			reason: "spec.module: Invalid value: \"package replicas\": either module or moduleConfigMap is required",
+		},
+		{
+			desc: "valid rego module",
+			kind: "ClusterRuleRego",
+			obj: &merlinv1beta1.ClusterRuleRego{Spec: merlinv1beta1.ClusterRuleRegoSpec{
+				Module: "package replicas\nviolation[msg] { input.review.object.spec.replicas < 2; msg := \"too few replicas\" }",
+				Query:  "data.replicas.violation",
+			}},
+			allowed: true,
+		},
+		{
+			desc:    "update without spec changes",
+			kind:    "RuleExpression",
+			obj:     &merlinv1beta1.RuleExpression{ObjectMeta: metav1.ObjectMeta{Finalizers: []string{"merlin"}}, Spec: merlinv1beta1.RuleExpressionSpec{Expression: "object."}},
+			oldObj:  &merlinv1beta1.RuleExpression{Spec: merlinv1beta1.RuleExpressionSpec{Expression: "object."}},
+			allowed: true,
+		},
+		{
+			desc:    "valid notifier",
+			kind:    "Notifier",
+			obj:     &merlinv1beta1.Notifier{Spec: merlinv1beta1.NotifierSpec{NotifyInterval: 60, Slack: slack.Spec{Severity: alert.SeverityInfo}}},
+			allowed: true,
+		},
+		{
+			desc:   "invalid notifier",
+			kind:   "Notifier",
+			obj:    &merlinv1beta1.Notifier{Spec: merlinv1beta1.NotifierSpec{Slack: slack.Spec{Severity: "debug"}}},
+			oldObj: &merlinv1beta1.Notifier{Spec: merlinv1beta1.NotifierSpec{NotifyInterval: 60}},
+			reason: "[spec.notifyInterval: Invalid value: 0: should be greater than 0, spec.slack.severity: Unsupported value: \"debug\": supported values: \"fatal\", \"critical\", \"warning\", \"info\"]",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.desc, func(tt *testing.T) {
+			resp := v.Handle(context.Background(), newAdmissionRequest(tt, tc.kind, tc.obj, tc.oldObj))
+			assert.Equal(tt, tc.allowed, resp.Allowed)
+			if tc.reason != "" {
+				assert.Equal(tt, tc.reason, string(resp.Result.Reason))
+			}
+		})
+	}
+}
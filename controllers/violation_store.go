@@ -0,0 +1,271 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/mercari/merlin/alert"
+	merlinv1beta1 "github.com/mercari/merlin/api/v1beta1"
+)
+
+// violationRuleKindLabel is the label of violations for the kind of the violated rule
+const violationRuleKindLabel = "merlin.mercari.com/rule-kind"
+
+// +kubebuilder:rbac:groups=merlin.mercari.com,resources=violations,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=merlin.mercari.com,resources=violations/status,verbs=get;update;patch
+
+// violationStore persists the alerts of notifiers as Violation resources, one violation for each rule and resource
+// with the delivery states of all notifiers, so only the violations with changed alerts are written.
+type violationStore struct {
+	client.Client
+	// namespace is the namespace for violations of cluster scoped resources, except namespaces which have their
+	// violations in the namespaces themselves.
+	namespace string
+	// persisted is the alerts last persisted for each notifier, it's only accessed by NotifierReconciler.
+	persisted map[string]map[string]alert.Alert
+	// loaded is the alerts of the violations grouped by notifiers, they're listed once when the first notifier is loaded,
+	// and each notifier takes its alerts when it's loaded.
+	loaded map[string]map[string]alert.Alert
+}
+
+// load returns the alerts of the notifier from the violations, they're the alerts persisted by the last sync. Violations
+// are only listed for the first notifier, notifiers created later have no violations since the violations of deleted
+// notifiers are removed.
+func (s *violationStore) load(ctx context.Context, notifier string) (map[string]alert.Alert, error) {
+	if s.loaded == nil {
+		list := &merlinv1beta1.ViolationList{}
+		if err := s.List(ctx, list); err != nil {
+			return nil, err
+		}
+		s.loaded = map[string]map[string]alert.Alert{}
+		for _, v := range list.Items {
+			for _, state := range v.Status.Notifiers {
+				if s.loaded[state.Name] == nil {
+					s.loaded[state.Name] = map[string]alert.Alert{}
+				}
+				s.loaded[state.Name][v.Spec.Rule+Separator+v.Spec.ResourceName] = alert.Alert{
+					Suppressed:   v.Spec.Suppressed,
+					Severity:     v.Spec.Severity,
+					Message:      v.Spec.Message,
+					ResourceKind: v.Spec.ResourceKind,
+					ResourceName: v.Spec.ResourceName,
+					Status:       state.Status,
+					Channel:      state.Channel,
+					Error:        state.Error,
+					Violated:     state.Status != alert.StatusRecovering,
+				}
+			}
+		}
+	}
+	alerts := map[string]alert.Alert{}
+	persisted := map[string]alert.Alert{}
+	for name, a := range s.loaded[notifier] {
+		alerts[name], persisted[name] = a, a
+	}
+	delete(s.loaded, notifier)
+	if s.persisted == nil {
+		s.persisted = map[string]map[string]alert.Alert{}
+	}
+	s.persisted[notifier] = persisted
+	return alerts, nil
+}
+
+// sync persists the alerts of the notifier changed since the last sync, violations without alerts of any notifiers are
+// deleted. Alerts failed to persist are retried in the next sync.
+func (s *violationStore) sync(ctx context.Context, notifier string, alerts map[string]alert.Alert) error {
+	if s.persisted == nil {
+		s.persisted = map[string]map[string]alert.Alert{}
+	}
+	persisted, ok := s.persisted[notifier]
+	if !ok {
+		persisted = map[string]alert.Alert{}
+		s.persisted[notifier] = persisted
+	}
+	var errs []error
+	for name, a := range alerts {
+		if p, ok := persisted[name]; ok && p == a {
+			continue
+		}
+		if err := s.setAlert(ctx, notifier, name, a); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		persisted[name] = a
+	}
+	for name, a := range persisted {
+		if _, ok := alerts[name]; ok {
+			continue
+		}
+		if err := s.removeAlert(ctx, notifier, name, a); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		delete(persisted, name)
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// setAlert sets the alert of the notifier to the violation, violations are shared by notifiers so updates conflicting
+// with the syncs of other notifiers are retried with the latest violations.
+func (s *violationStore) setAlert(ctx context.Context, notifier, name string, a alert.Alert) error {
+	key := client.ObjectKey{Namespace: s.getNamespace(a), Name: getViolationName(name)}
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		v := &merlinv1beta1.Violation{}
+		if err := s.Get(ctx, key, v); err != nil {
+			if !apierrs.IsNotFound(err) {
+				return err
+			}
+			if !a.Violated {
+				// recovering alerts don't need new violations
+				return nil
+			}
+			// violations can't be created in namespaces being deleted, and they'd be deleted with the namespaces anyway
+			if terminating, err := s.isNamespaceTerminating(ctx, key.Namespace); err != nil || terminating {
+				return err
+			}
+			v = &merlinv1beta1.Violation{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: key.Namespace,
+					Name:      key.Name,
+					Labels:    map[string]string{violationRuleKindLabel: ruleKind(name)},
+				},
+			}
+			setViolationAlert(v, notifier, name, a)
+			status := *v.Status.DeepCopy()
+			status.FirstSeen = metav1.Now()
+			if err := s.Create(ctx, v); err != nil {
+				if !apierrs.IsAlreadyExists(err) {
+					return err
+				}
+				// created by the sync of another notifier since it's got, so it's updated instead
+				if err := s.Get(ctx, key, v); err != nil {
+					return err
+				}
+				return s.updateViolation(ctx, v, notifier, name, a)
+			}
+			// the status is ignored by create since it's a subresource
+			v.Status = status
+			return s.Status().Update(ctx, v)
+		}
+		return s.updateViolation(ctx, v, notifier, name, a)
+	})
+}
+
+// updateViolation sets the alert to the violation, the spec and the status are only updated if they're changed, and
+// the status is updated separately since it's a subresource.
+func (s *violationStore) updateViolation(ctx context.Context, v *merlinv1beta1.Violation, notifier, name string, a alert.Alert) error {
+	spec := v.Spec
+	original := v.Status.DeepCopy()
+	setViolationAlert(v, notifier, name, a)
+	status := *v.Status.DeepCopy()
+	if v.Status.FirstSeen.IsZero() {
+		status.FirstSeen = metav1.Now()
+	}
+	if v.Spec != spec {
+		if err := s.Update(ctx, v); err != nil {
+			return err
+		}
+	}
+	if equality.Semantic.DeepEqual(*original, status) {
+		return nil
+	}
+	v.Status = status
+	return s.Status().Update(ctx, v)
+}
+
+// isNamespaceTerminating returns true if the namespace is being deleted or already deleted
+func (s *violationStore) isNamespaceTerminating(ctx context.Context, name string) (bool, error) {
+	namespace := &corev1.Namespace{}
+	if err := s.Get(ctx, client.ObjectKey{Name: name}, namespace); err != nil {
+		if apierrs.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	}
+	return namespace.DeletionTimestamp != nil || namespace.Status.Phase == corev1.NamespaceTerminating, nil
+}
+
+func (s *violationStore) removeAlert(ctx context.Context, notifier, name string, a alert.Alert) error {
+	key := client.ObjectKey{Namespace: s.getNamespace(a), Name: getViolationName(name)}
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		v := &merlinv1beta1.Violation{}
+		if err := s.Get(ctx, key, v); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+		var states []merlinv1beta1.ViolationNotifierStatus
+		for _, state := range v.Status.Notifiers {
+			if state.Name != notifier {
+				states = append(states, state)
+			}
+		}
+		if len(states) == len(v.Status.Notifiers) {
+			return nil
+		}
+		if len(states) == 0 {
+			return client.IgnoreNotFound(s.Delete(ctx, v))
+		}
+		v.Status.Notifiers = states
+		return s.Status().Update(ctx, v)
+	})
+}
+
+// getNamespace returns the namespace of the violation, which is the namespace of the resource, the namespace itself for
+// namespaces, or the store's namespace for other cluster scoped resources.
+func (s *violationStore) getNamespace(a alert.Alert) string {
+	if namespace := getAlertNamespace(a); namespace != "" {
+		return namespace
+	}
+	return s.namespace
+}
+
+// getAlertNamespace returns the namespace of the alert's resource, or the namespace itself for namespaces, it's empty
+// for other cluster scoped resources.
+func getAlertNamespace(a alert.Alert) string {
+	names := strings.SplitN(a.ResourceName, Separator, 2)
+	if len(names) == 2 && names[0] != "" {
+		return names[0]
+	}
+	if a.ResourceKind == "Namespace" {
+		return names[len(names)-1]
+	}
+	return ""
+}
+
+// setViolationAlert sets the alert to the violation's spec and the delivery state of the notifier.
+func setViolationAlert(v *merlinv1beta1.Violation, notifier, name string, a alert.Alert) {
+	v.Spec = merlinv1beta1.ViolationSpec{
+		Rule:         strings.TrimSuffix(name, Separator+a.ResourceName),
+		ResourceKind: a.ResourceKind,
+		ResourceName: a.ResourceName,
+		Severity:     a.Severity,
+		Message:      a.Message,
+		Suppressed:   a.Suppressed,
+	}
+	state := v.Status.GetNotifier(notifier)
+	if state == nil {
+		v.Status.Notifiers = append(v.Status.Notifiers, merlinv1beta1.ViolationNotifierStatus{Name: notifier})
+		state = &v.Status.Notifiers[len(v.Status.Notifiers)-1]
+	}
+	if state.Status != a.Status || state.LastTransitionTime.IsZero() {
+		state.LastTransitionTime = metav1.Now()
+	}
+	state.Status, state.Channel, state.Error = a.Status, a.Channel, a.Error
+}
+
+// getViolationName returns the name of violation for the alert name <RuleKind>/<RuleName>/<ResourceNamespace>/<ResourceName>,
+// as the lowercase rule kind and the hash of the alert name, since names of rules and resources can be too long together.
+func getViolationName(alertName string) string {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(alertName))
+	return fmt.Sprintf("%s-%x", strings.ToLower(ruleKind(alertName)), h.Sum64())
+}
@@ -0,0 +1,268 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/mercari/merlin/alert"
+	merlinv1beta1 "github.com/mercari/merlin/api/v1beta1"
+	"github.com/mercari/merlin/notifiers"
+)
+
+func Test_violationStore(t *testing.T) {
+	assert.NoError(t, merlinv1beta1.AddToScheme(scheme.Scheme))
+	ctx := context.Background()
+	store := &violationStore{
+		Client: fake.NewFakeClientWithScheme(scheme.Scheme,
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "dev"}},
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "merlin"}},
+		),
+		namespace: "merlin",
+	}
+	deploymentAlert := alert.Alert{
+		Severity:     alert.SeverityWarning,
+		Message:      "doesn't satisfy the expression `object.spec.replicas >= 2`",
+		ResourceKind: "Deployment",
+		ResourceName: "dev/api",
+		Status:       alert.StatusPending,
+		Violated:     true,
+	}
+	namespaceAlert := alert.Alert{
+		Severity:     alert.SeverityCritical,
+		Message:      "doenst have required label `team`",
+		ResourceKind: "Namespace",
+		ResourceName: "/dev",
+		Status:       alert.StatusFiring,
+		Violated:     true,
+	}
+	clusterRoleAlert := alert.Alert{
+		Message:      "doenst have required label `team`",
+		ResourceKind: "ClusterRole",
+		ResourceName: "/admin",
+		Status:       alert.StatusRecovering,
+	}
+	deploymentAlertName := "RuleExpression/replicas/dev/api"
+	namespaceAlertName := "ClusterRuleNamespaceRequiredLabel/team//dev"
+	slackAlerts := map[string]alert.Alert{
+		deploymentAlertName:                       deploymentAlert,
+		namespaceAlertName:                        namespaceAlert,
+		"ClusterRuleRequiredMetadata/team//admin": clusterRoleAlert,
+	}
+	assert.NoError(t, store.sync(ctx, "slack", slackAlerts))
+	assert.NoError(t, store.sync(ctx, "pagerduty", map[string]alert.Alert{deploymentAlertName: deploymentAlert}))
+
+	list := &merlinv1beta1.ViolationList{}
+	assert.NoError(t, store.List(ctx, list))
+	// recovering alerts don't create violations
+	assert.Len(t, list.Items, 2)
+
+	v := &merlinv1beta1.Violation{}
+	assert.NoError(t, store.Get(ctx, client.ObjectKey{Namespace: "dev", Name: getViolationName(deploymentAlertName)}, v))
+	assert.Equal(t, merlinv1beta1.ViolationSpec{
+		Rule:         "RuleExpression/replicas",
+		ResourceKind: "Deployment",
+		ResourceName: "dev/api",
+		Severity:     alert.SeverityWarning,
+		Message:      deploymentAlert.Message,
+	}, v.Spec)
+	assert.Equal(t, "RuleExpression", v.Labels[violationRuleKindLabel])
+	assert.False(t, v.Status.FirstSeen.IsZero())
+	assert.Len(t, v.Status.Notifiers, 2)
+	assert.Equal(t, alert.StatusPending, v.Status.GetNotifier("pagerduty").Status)
+	resourceVersion := v.ResourceVersion
+
+	namespaceViolation := &merlinv1beta1.Violation{}
+	assert.NoError(t, store.Get(ctx, client.ObjectKey{Namespace: "dev", Name: getViolationName(namespaceAlertName)}, namespaceViolation))
+	assert.Equal(t, "ClusterRuleNamespaceRequiredLabel/team", namespaceViolation.Spec.Rule)
+	assert.Equal(t, "merlin", store.getNamespace(clusterRoleAlert))
+
+	// only changed alerts are written, and alerts are restored by load
+	namespaceAlert.Status = alert.StatusRecovering
+	namespaceAlert.Violated = false
+	slackAlerts[namespaceAlertName] = namespaceAlert
+	assert.NoError(t, store.sync(ctx, "slack", slackAlerts))
+	assert.NoError(t, store.Get(ctx, client.ObjectKey{Namespace: "dev", Name: getViolationName(deploymentAlertName)}, v))
+	assert.Equal(t, resourceVersion, v.ResourceVersion)
+	// violations are listed once for all notifiers
+	counting := &listCountingClient{Client: store.Client}
+	loadingStore := &violationStore{Client: counting}
+	alerts, err := loadingStore.load(ctx, "slack")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]alert.Alert{deploymentAlertName: deploymentAlert, namespaceAlertName: namespaceAlert}, alerts)
+	alerts, err = loadingStore.load(ctx, "pagerduty")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]alert.Alert{deploymentAlertName: deploymentAlert}, alerts)
+	assert.Equal(t, 1, counting.lists)
+
+	// violations are deleted when no notifiers have alerts for them
+	delete(slackAlerts, namespaceAlertName)
+	delete(slackAlerts, deploymentAlertName)
+	assert.NoError(t, store.sync(ctx, "slack", slackAlerts))
+	assert.NoError(t, store.List(ctx, list))
+	assert.Len(t, list.Items, 1)
+	assert.Equal(t, "pagerduty", list.Items[0].Status.Notifiers[0].Name)
+	assert.Len(t, list.Items[0].Status.Notifiers, 1)
+}
+
+// listCountingClient counts the list requests
+type listCountingClient struct {
+	client.Client
+	lists int
+}
+
+func (c *listCountingClient) List(ctx context.Context, list runtime.Object, opts ...client.ListOption) error {
+	c.lists++
+	return c.Client.List(ctx, list, opts...)
+}
+
+// racingClient creates the violation by another notifier right before the violation is created
+type racingClient struct {
+	client.Client
+	violation *merlinv1beta1.Violation
+}
+
+func (c *racingClient) Create(ctx context.Context, obj runtime.Object, opts ...client.CreateOption) error {
+	if c.violation != nil {
+		if err := c.Client.Create(ctx, c.violation); err != nil {
+			return err
+		}
+		c.violation = nil
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+// conflictingClient fails the first status updates with conflicts, as if other notifiers updated the violations, and
+// counts the status updates.
+type conflictingClient struct {
+	client.Client
+	conflicts     int
+	statusUpdates int
+}
+
+func (c *conflictingClient) Status() client.StatusWriter {
+	return &conflictingStatusWriter{StatusWriter: c.Client.Status(), client: c}
+}
+
+type conflictingStatusWriter struct {
+	client.StatusWriter
+	client *conflictingClient
+}
+
+func (w *conflictingStatusWriter) Update(ctx context.Context, obj runtime.Object, opts ...client.UpdateOption) error {
+	w.client.statusUpdates++
+	if w.client.conflicts > 0 {
+		w.client.conflicts--
+		return apierrs.NewConflict(merlinv1beta1.GroupVersion.WithResource("violations").GroupResource(), "", nil)
+	}
+	return w.StatusWriter.Update(ctx, obj, opts...)
+}
+
+func Test_violationStore_setAlert(t *testing.T) {
+	assert.NoError(t, merlinv1beta1.AddToScheme(scheme.Scheme))
+	ctx := context.Background()
+	now := metav1.Now()
+	cli := fake.NewFakeClientWithScheme(scheme.Scheme,
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "dev"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "deleting", DeletionTimestamp: &now}, Status: corev1.NamespaceStatus{Phase: corev1.NamespaceTerminating}},
+	)
+	newAlert := func(resourceName string) alert.Alert {
+		return alert.Alert{
+			Message:      "doesn't satisfy the expression `object.spec.replicas >= 2`",
+			ResourceKind: "Deployment",
+			ResourceName: resourceName,
+			Status:       alert.StatusPending,
+			Violated:     true,
+		}
+	}
+
+	// violations aren't created in namespaces being deleted or already deleted
+	store := &violationStore{Client: cli, namespace: "merlin"}
+	assert.NoError(t, store.setAlert(ctx, "slack", "RuleExpression/replicas/deleting/api", newAlert("deleting/api")))
+	assert.NoError(t, store.setAlert(ctx, "slack", "RuleExpression/replicas/deleted/api", newAlert("deleted/api")))
+	list := &merlinv1beta1.ViolationList{}
+	assert.NoError(t, cli.List(ctx, list))
+	assert.Empty(t, list.Items)
+
+	// violations created by other notifiers at the same time are updated
+	name := "RuleExpression/replicas/dev/api"
+	existing := &merlinv1beta1.Violation{ObjectMeta: metav1.ObjectMeta{Namespace: "dev", Name: getViolationName(name)}}
+	setViolationAlert(existing, "pagerduty", name, newAlert("dev/api"))
+	store = &violationStore{Client: &racingClient{Client: cli, violation: existing}, namespace: "merlin"}
+	assert.NoError(t, store.setAlert(ctx, "slack", name, newAlert("dev/api")))
+	v := &merlinv1beta1.Violation{}
+	assert.NoError(t, cli.Get(ctx, client.ObjectKey{Namespace: "dev", Name: getViolationName(name)}, v))
+	assert.Len(t, v.Status.Notifiers, 2)
+	assert.NotNil(t, v.Status.GetNotifier("slack"))
+	assert.NotNil(t, v.Status.GetNotifier("pagerduty"))
+	assert.False(t, v.Status.FirstSeen.IsZero())
+
+	// updates conflicting with other notifiers are retried
+	conflicting := &conflictingClient{Client: cli, conflicts: 1}
+	store = &violationStore{Client: conflicting, namespace: "merlin"}
+	firing := newAlert("dev/api")
+	firing.Status = alert.StatusFiring
+	assert.NoError(t, store.setAlert(ctx, "slack", name, firing))
+	assert.Equal(t, 2, conflicting.statusUpdates)
+	assert.NoError(t, cli.Get(ctx, client.ObjectKey{Namespace: "dev", Name: getViolationName(name)}, v))
+	assert.Equal(t, alert.StatusFiring, v.Status.GetNotifier("slack").Status)
+
+	// the status isn't updated when the notifier's state is unchanged
+	conflicting.statusUpdates = 0
+	assert.NoError(t, store.setAlert(ctx, "slack", name, firing))
+	assert.Equal(t, 0, conflicting.statusUpdates)
+}
+
+func Test_NotifierReconciler_migrateAlerts(t *testing.T) {
+	assert.NoError(t, merlinv1beta1.AddToScheme(scheme.Scheme))
+	ctx := context.Background()
+	legacyAlert := alert.Alert{
+		Severity:     alert.SeverityWarning,
+		Message:      "has no pdb",
+		ResourceKind: "Deployment",
+		ResourceName: "default/api",
+		Status:       alert.StatusFiring,
+		Violated:     true,
+	}
+	cli := fake.NewFakeClientWithScheme(scheme.Scheme, &merlinv1beta1.Notifier{
+		ObjectMeta: metav1.ObjectMeta{Name: "slack"},
+		Spec:       merlinv1beta1.NotifierSpec{NotifyInterval: 60},
+		Status:     merlinv1beta1.NotifierStatus{Alerts: map[string]alert.Alert{"ClusterRuleWorkloadAvailability/pdb/default/api": legacyAlert}},
+	}, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}})
+	r := &NotifierReconciler{
+		Client:     cli,
+		log:        logf.Log,
+		scheme:     scheme.Scheme,
+		cache:      &notifiersCache{notifiers: map[string]*notifiers.Notifier{}},
+		violations: &violationStore{Client: cli, namespace: "merlin"},
+		alertMetrics: prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "merlin_violation"},
+			[]string{"rule", "rule_name", "resource_name", "resource_namespace", "resource_kind"}),
+	}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "slack"}}
+	_, err := r.Reconcile(req)
+	assert.NoError(t, err)
+	assert.True(t, r.cache.isReady)
+	assert.Equal(t, legacyAlert, r.cache.notifiers["slack"].Alerts["ClusterRuleWorkloadAvailability/pdb/default/api"])
+
+	_, err = r.Reconcile(req)
+	assert.NoError(t, err)
+	n := &merlinv1beta1.Notifier{}
+	assert.NoError(t, cli.Get(ctx, req.NamespacedName, n))
+	assert.Empty(t, n.Status.Alerts)
+	assert.Equal(t, 1, n.Status.Violations)
+	alerts, err := (&violationStore{Client: cli}).load(ctx, "slack")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]alert.Alert{"ClusterRuleWorkloadAvailability/pdb/default/api": legacyAlert}, alerts)
+}
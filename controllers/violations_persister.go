@@ -0,0 +1,50 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// violationsPersistInterval is the interval to persist the violations changed by evaluating single resources.
+const violationsPersistInterval = 30 * time.Second
+
+// violationsPersister persists the violations of rules marked dirty by ResourceReconciler at an interval, so resources
+// changing often don't patch the status of their rules on every reconcile.
+// It's added to the manager and only runs in the leader, which is the one evaluating the rules.
+type violationsPersister struct {
+	client.Client
+	log      logr.Logger
+	rules    []*rulesCache
+	interval time.Duration
+}
+
+func (p *violationsPersister) Start(stop <-chan struct{}) error {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			p.persist(context.Background())
+		case <-stop:
+			// violations changed since the last interval are persisted before shutting down
+			p.persist(context.Background())
+			return nil
+		}
+	}
+}
+
+// persist patches the violations of the dirty rules, the rules failed to patch are marked dirty again to retry later.
+func (p *violationsPersister) persist(ctx context.Context) {
+	for _, ruleCache := range p.rules {
+		for _, rule := range ruleCache.TakeDirty() {
+			if err := patchViolations(ctx, p.Client, rule); err != nil && !apierrs.IsNotFound(err) {
+				p.log.Error(err, "Failed to persist violations", "rule", rule.GetName())
+				ruleCache.MarkDirty(rule)
+			}
+		}
+	}
+}
@@ -0,0 +1,11 @@
+package controllers
+
+// +kubebuilder:rbac:groups=merlin.mercari.com,resources=clusterruleworkloadavailabilities,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch
+
+// WorkloadAvailabilityRuleReconciler reconciles rules for ClusterRuleWorkloadAvailability
+type WorkloadAvailabilityRuleReconciler struct {
+	RuleReconciler
+}
@@ -47,11 +47,19 @@ func main() {
 	path, _ := os.Executable()
 	fmt.Printf("Program starting at %s \n", path)
 	var metricsAddr string
+	var apiAddr string
+	var violationNamespace string
 	var enableLeaderElection bool
 	flag.StringVar(&metricsAddr, "metrics-addr", ":8080", "The address the metric endpoint binds to.")
+	flag.StringVar(&apiAddr, "api-addr", "", "The address the read-only violations API and dashboard bind to, localhost if the host is empty, disabled if empty.")
+	flag.StringVar(&violationNamespace, "violation-namespace", os.Getenv("POD_NAMESPACE"),
+		"The namespace for violations of cluster scoped resources, defaults to the namespace of the manager or \"default\".")
 	flag.BoolVar(&enableLeaderElection, "enable-leader-election", false,
 		"Enable leader election for controller manager. Enabling this will ensure there is only one active controller manager.")
 	flag.Parse()
+	if violationNamespace == "" {
+		violationNamespace = "default"
+	}
 
 	ctrl.SetLogger(kubezap.New(func(o *kubezap.Options) {
 		o.Development = os.Getenv("DEBUG") != ""
@@ -81,10 +89,25 @@ func main() {
 		os.Exit(1)
 	}
 
-	if err := controllers.SetupReconcilers(mgr); err != nil {
+	if err := controllers.SetupReconcilers(mgr, violationNamespace); err != nil {
 		setupLog.Error(err, "unable to setup reconcilers")
 	}
 
+	// webhooks need the serving certificates, which are only mounted when the webhook is enabled in the kustomization
+	if os.Getenv("ENABLE_WEBHOOKS") == "true" {
+		if err := controllers.SetupWebhooks(mgr); err != nil {
+			setupLog.Error(err, "unable to setup webhooks")
+			os.Exit(1)
+		}
+	}
+
+	if apiAddr != "" {
+		if err := controllers.SetupAPIServer(mgr, apiAddr); err != nil {
+			setupLog.Error(err, "unable to setup api server")
+			os.Exit(1)
+		}
+	}
+
 	setupLog.Info("starting manager")
 	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
 		setupLog.Error(err, "problem running manager")
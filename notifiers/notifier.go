@@ -15,39 +15,92 @@ import (
 const Separator = merlinv1beta1.Separator
 
 type Notifier struct {
-	Resource     *merlinv1beta1.Notifier
+	Resource *merlinv1beta1.Notifier
+	// Alerts are the alerts of the notifier with <Rule>/<RuleName>/<ResourceNamespace>/<ResourceName> as keys,
+	// they're persisted as Violation resources by the notifier controller.
+	Alerts       map[string]alert.Alert
 	Client       *http.Client
 	AlertMetrics *prometheus.GaugeVec
 }
 
+// Notify sends the alerts and updates their status, see PendingAlerts, Send and MergeSent for sending them without
+// holding the lock of the alerts.
 func (n *Notifier) Notify() {
-	for name, a := range n.Resource.Status.Alerts {
-		if a.Suppressed {
-			continue
+	pending := n.PendingAlerts()
+	n.MergeSent(pending, n.Send(pending))
+}
+
+// PendingAlerts returns copies of the alerts to send, i.e., alerts not suppressed nor firing already.
+func (n *Notifier) PendingAlerts() map[string]alert.Alert {
+	pending := map[string]alert.Alert{}
+	for name, a := range n.Alerts {
+		if !a.Suppressed && a.Status != alert.StatusFiring { // wont send again if already firing
+			pending[name] = a
 		}
-		if a.Status != alert.StatusFiring { // wont send again if already firing
-			if n.Resource.Spec.Slack.Channel != "" {
-				slackClient := slack.NewClient(n.Client, n.Resource.Spec.Slack.Severity, n.Resource.Spec.Slack.WebhookURL, n.Resource.Spec.Slack.Channel)
-				if err := slackClient.SendAlert(a); err != nil {
-					a.Error = err.Error()
-				} else {
-					a.Error = ""
-					if a.Status == alert.StatusPending || a.Status == "" {
-						a.Status = alert.StatusFiring
-					}
-				}
+	}
+	return pending
+}
 
-			} else {
-				// TODO: add pagerduty, note if they'll co-exists then we'll need other Status/Error fields for PagerDuty
-			}
+// Send sends the alerts to external systems without changing the notifier's alerts, and returns the results of the
+// alerts sent, nil for alerts sent successfully.
+func (n *Notifier) Send(alerts map[string]alert.Alert) map[string]error {
+	results := map[string]error{}
+	for name, a := range alerts {
+		channel := n.Resource.Spec.Slack.Channel
+		if a.Channel != "" {
+			channel = a.Channel
 		}
+		if channel != "" {
+			slackClient := slack.NewClient(n.Client, n.Resource.Spec.Slack.Severity, n.Resource.Spec.Slack.WebhookURL, channel)
+			results[name] = slackClient.SendAlert(a)
+		} else {
+			// TODO: add pagerduty, note if they'll co-exists then we'll need other Status/Error fields for PagerDuty
+		}
+	}
+	return results
+}
 
-		if a.Status == alert.StatusRecovering {
-			delete(n.Resource.Status.Alerts, name)
+// MergeSent updates the alerts by the results of sending the alerts returned by PendingAlerts, alerts changed while
+// sending are kept to be sent again.
+func (n *Notifier) MergeSent(sent map[string]alert.Alert, results map[string]error) {
+	for name, a := range sent {
+		err, ok := results[name]
+		current, exists := n.Alerts[name]
+		switch {
+		case a.Status == alert.StatusRecovering:
+			if exists && current.Status == alert.StatusFiring {
+				// violated again while the recovery was sent
+				current.Status = alert.StatusPending
+				n.Alerts[name] = current
+			} else if exists && current.Status == alert.StatusRecovering {
+				delete(n.Alerts, name)
+			}
+		case !ok:
+		case err != nil:
+			if exists && current == a {
+				current.Error = err.Error()
+				n.Alerts[name] = current
+			}
+		case !exists:
+			// recovered while the alert was sent, the recovery is sent next time
+			a.Status, a.Error, a.Violated = alert.StatusRecovering, "", false
+			n.Alerts[name] = a
+		case (current.Status == alert.StatusPending || current.Status == "") &&
+			current.Severity == a.Severity && current.Channel == a.Channel:
+			current.Status, current.Error = alert.StatusFiring, ""
+			n.Alerts[name] = current
+		}
+		if current, exists := n.Alerts[name]; exists {
+			n.setPromLabel(name, current)
 		} else {
-			n.Resource.Status.Alerts[name] = a
+			a.Violated = false
+			n.setPromLabel(name, a)
+		}
+	}
+	for name, a := range n.Alerts {
+		if _, ok := sent[name]; !ok && !a.Suppressed {
+			n.setPromLabel(name, a)
 		}
-		n.setPromLabel(name, a)
 	}
 	n.Resource.Status.CheckedAt = time.Now().Format(time.RFC3339)
 }
@@ -61,58 +114,77 @@ func (n *Notifier) SetAlert(rule string, newAlert alert.Alert) {
 	}
 
 	if newAlert.Violated {
-		if a, ok := n.Resource.Status.Alerts[name]; !ok {
+		a, ok := n.Alerts[name]
+		if ok && a.Status != alert.StatusPending && a.Status != "" {
+			// sent alerts stay in their channel until they recover, so recoveries go to the channel the alert fired in
+			newAlert.Channel = a.Channel
+		}
+		if !ok || a.Severity != newAlert.Severity {
+			// alerts with changed severity are sent again, e.g., escalated from warning to critical
 			newAlert.Status = alert.StatusPending
-		} else if a.Status == alert.StatusRecovering || a.Status == alert.StatusFiring {
+		} else if a.Status == alert.StatusRecovering {
 			newAlert.Status = alert.StatusFiring
+		} else {
+			// pending alerts stay pending until they're sent
+			newAlert.Status = a.Status
 		}
-		n.Resource.Status.Alerts[name] = newAlert
+		n.Alerts[name] = newAlert
 	} else {
-		if a, ok := n.Resource.Status.Alerts[name]; ok {
+		if a, ok := n.Alerts[name]; ok {
 			if a.Status == alert.StatusPending {
-				delete(n.Resource.Status.Alerts, name)
+				delete(n.Alerts, name)
 			} else {
-				newAlert.Status = alert.StatusRecovering
-				n.Resource.Status.Alerts[name] = newAlert
+				// recover in the channel the alert was sent to, the route may be changed
+				newAlert.Status, newAlert.Channel = alert.StatusRecovering, a.Channel
+				n.Alerts[name] = newAlert
 			}
 		}
 	}
 }
 
+// HasAlert returns if the notifier has the rule's alert for the resource.
+func (n *Notifier) HasAlert(rule, resourceName string) bool {
+	_, ok := n.Alerts[getAlertName(rule, resourceName)]
+	return ok
+}
+
 func (n *Notifier) ClearAllAlerts(message string) {
-	for k := range n.Resource.Status.Alerts {
-		newAlert := n.Resource.Status.Alerts[k]
-		newAlert.Status = alert.StatusRecovering
-		newAlert.Message = message + " " + n.Resource.Status.Alerts[k].Message
-		n.Resource.Status.Alerts[k] = newAlert
+	for name := range n.Alerts {
+		n.clearAlert(name, message)
 	}
 	return
 }
 
 func (n *Notifier) ClearRuleAlerts(rule, message string) {
-	for name, a := range n.Resource.Status.Alerts {
+	for name, a := range n.Alerts {
 		if rule == getRuleName(name, a.ResourceName) {
-			newAlert := n.Resource.Status.Alerts[name]
-			newAlert.Status = alert.StatusRecovering
-			newAlert.Message = message + " " + n.Resource.Status.Alerts[name].Message
-			n.Resource.Status.Alerts[name] = newAlert
+			n.clearAlert(name, message)
 		}
 	}
 	return
 }
 
 func (n *Notifier) ClearResourceAlerts(resource, message string) {
-	for name := range n.Resource.Status.Alerts {
+	for name := range n.Alerts {
 		if resource == getResourceName(name) {
-			newAlert := n.Resource.Status.Alerts[name]
-			newAlert.Status = alert.StatusRecovering
-			newAlert.Message = message + " " + n.Resource.Status.Alerts[name].Message
-			n.Resource.Status.Alerts[name] = newAlert
+			n.clearAlert(name, message)
 		}
 	}
 	return
 }
 
+// clearAlert recovers the alert, pending alerts are deleted since they have not been sent yet.
+func (n *Notifier) clearAlert(name, message string) {
+	newAlert := n.Alerts[name]
+	if newAlert.Status == alert.StatusPending {
+		delete(n.Alerts, name)
+		return
+	}
+	newAlert.Status = alert.StatusRecovering
+	newAlert.Message = message + " " + newAlert.Message
+	n.Alerts[name] = newAlert
+}
+
 func (n *Notifier) setPromLabel(alertName string, a alert.Alert) {
 	names := strings.Split(alertName, Separator)
 	if len(names) == 4 {
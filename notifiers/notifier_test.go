@@ -2,6 +2,7 @@ package notifiers
 
 import (
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -31,7 +32,6 @@ func Test_Notifier(t *testing.T) {
 				Channel:    "test-channel",
 			},
 		},
-		Status: merlinv1beta1.NotifierStatus{Alerts: map[string]alert.Alert{}},
 	}
 	promMetrics := prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
@@ -42,6 +42,7 @@ func Test_Notifier(t *testing.T) {
 	)
 	notifier := Notifier{
 		Resource:     notifierResource,
+		Alerts:       map[string]alert.Alert{},
 		Client:       &http.Client{Timeout: 10 * time.Second},
 		AlertMetrics: promMetrics,
 	}
@@ -58,7 +59,7 @@ func Test_Notifier(t *testing.T) {
 	notifier.SetAlert("Rule/A", testAlertRuleAResourceA1)
 	notifier.Notify()
 	testAlertRuleAResourceA1.Status = alert.StatusFiring
-	assert.Equal(t, testAlertRuleAResourceA1, notifier.Resource.Status.Alerts["Rule/A/test-resource/A1"])
+	assert.Equal(t, testAlertRuleAResourceA1, notifier.Alerts["Rule/A/test-resource/A1"])
 
 	// test adding more alerts
 	testAlertRuleAResourceA2 := alert.Alert{
@@ -92,51 +93,207 @@ func Test_Notifier(t *testing.T) {
 	testAlertRuleAResourceA2.Status = alert.StatusPending
 	testAlertRuleBResourceB.Status = alert.StatusPending
 	testAlertRuleBResourceC.Status = alert.StatusPending
-	assert.Equal(t, testAlertRuleAResourceA2, notifier.Resource.Status.Alerts["Rule/A/test-resource/A2"])
+	assert.Equal(t, testAlertRuleAResourceA2, notifier.Alerts["Rule/A/test-resource/A2"])
 
-	// test clear rule alerts should recover alerts for the rule
+	assert.True(t, notifier.HasAlert("Rule/A", "test-resource/A2"))
+	assert.False(t, notifier.HasAlert("Rule/B", "test-resource/A2"))
+
+	// test clear rule alerts should recover alerts for the rule, pending alerts are deleted since they were not sent
 	msg := "clear alerts for RuleA"
 	notifier.ClearRuleAlerts("Rule/A", msg)
 	testAlertRuleAResourceA1.Status = alert.StatusRecovering
-	testAlertRuleAResourceA2.Status = alert.StatusRecovering
 	testAlertRuleAResourceA1.Message = msg + " " + testAlertRuleAResourceA1.Message
-	testAlertRuleAResourceA2.Message = msg + " " + testAlertRuleAResourceA2.Message
-	assert.Equal(t, testAlertRuleAResourceA1, notifier.Resource.Status.Alerts["Rule/A/test-resource/A1"])
-	assert.Equal(t, testAlertRuleAResourceA2, notifier.Resource.Status.Alerts["Rule/A/test-resource/A2"])
-	assert.Equal(t, testAlertRuleBResourceB, notifier.Resource.Status.Alerts["Rule/B/test-resource/B"])
-	assert.Equal(t, testAlertRuleBResourceC, notifier.Resource.Status.Alerts["Rule/B/test-resource/C"])
+	assert.Equal(t, testAlertRuleAResourceA1, notifier.Alerts["Rule/A/test-resource/A1"])
+	assert.False(t, notifier.HasAlert("Rule/A", "test-resource/A2"))
+	assert.Equal(t, testAlertRuleBResourceB, notifier.Alerts["Rule/B/test-resource/B"])
+	assert.Equal(t, testAlertRuleBResourceC, notifier.Alerts["Rule/B/test-resource/C"])
 
 	// notify should send recovering alert and remove them, but will not remove other rules' alert
 	notifier.Notify()
-	assert.Empty(t, notifier.Resource.Status.Alerts["Rule/A/test-resource/A1"])
-	assert.Empty(t, notifier.Resource.Status.Alerts["Rule/A/test-resource/A2"])
+	assert.Empty(t, notifier.Alerts["Rule/A/test-resource/A1"])
+	assert.Empty(t, notifier.Alerts["Rule/A/test-resource/A2"])
 	testAlertRuleBResourceB.Status = alert.StatusFiring
 	testAlertRuleBResourceC.Status = alert.StatusFiring
-	assert.Equal(t, testAlertRuleBResourceB, notifier.Resource.Status.Alerts["Rule/B/test-resource/B"])
-	assert.Equal(t, testAlertRuleBResourceC, notifier.Resource.Status.Alerts["Rule/B/test-resource/C"])
+	assert.Equal(t, testAlertRuleBResourceB, notifier.Alerts["Rule/B/test-resource/B"])
+	assert.Equal(t, testAlertRuleBResourceC, notifier.Alerts["Rule/B/test-resource/C"])
 
 	// clear resource alerts should recover alerts for the resource
 	msg = "clear resource alerts"
 	notifier.ClearResourceAlerts("test-resource/B", msg)
 	testAlertRuleBResourceB.Status = alert.StatusRecovering
 	testAlertRuleBResourceB.Message = msg + " " + testAlertRuleBResourceB.Message
-	assert.Equal(t, testAlertRuleBResourceB, notifier.Resource.Status.Alerts["Rule/B/test-resource/B"])
+	assert.Equal(t, testAlertRuleBResourceB, notifier.Alerts["Rule/B/test-resource/B"])
 
 	// notify should send recovering alert and remove them, but will not remove other resources' alert
 	notifier.Notify()
-	assert.Empty(t, notifier.Resource.Status.Alerts["Rule/B/test-resource/B"])
-	assert.Equal(t, testAlertRuleBResourceC, notifier.Resource.Status.Alerts["Rule/B/test-resource/C"])
+	assert.Empty(t, notifier.Alerts["Rule/B/test-resource/B"])
+	assert.Equal(t, testAlertRuleBResourceC, notifier.Alerts["Rule/B/test-resource/C"])
 
 	// clear all alerts should recover all alerts
 	msg = "clear all alerts"
 	notifier.ClearAllAlerts(msg)
 	testAlertRuleBResourceC.Status = alert.StatusRecovering
 	testAlertRuleBResourceC.Message = msg + " " + testAlertRuleBResourceC.Message
-	assert.Equal(t, testAlertRuleBResourceC, notifier.Resource.Status.Alerts["Rule/B/test-resource/C"])
+	assert.Equal(t, testAlertRuleBResourceC, notifier.Alerts["Rule/B/test-resource/C"])
 
 	// notify should remove the last recovered alert.
 	notifier.Notify()
-	assert.Empty(t, notifier.Resource.Status.Alerts["Rule/C/test-resource/C"])
+	assert.Empty(t, notifier.Alerts["Rule/C/test-resource/C"])
+}
+
+func Test_Notifier_severityChange(t *testing.T) {
+	var requests []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		requests = append(requests, string(body))
+		w.WriteHeader(200)
+		w.Write([]byte(`ok`))
+	}))
+	defer ts.Close()
+	notifier := Notifier{
+		Resource: &merlinv1beta1.Notifier{
+			Spec: merlinv1beta1.NotifierSpec{Slack: slack.Spec{WebhookURL: ts.URL, Channel: "test-channel"}},
+		},
+		Alerts: map[string]alert.Alert{},
+		Client: &http.Client{Timeout: 10 * time.Second},
+		AlertMetrics: prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "merlin_violation"},
+			[]string{"rule", "rule_name", "resource_name", "resource_namespace", "resource_kind"}),
+	}
+	a := alert.Alert{
+		Severity:     alert.SeverityWarning,
+		Message:      "certificate expires in 20 days",
+		ResourceKind: "Secret",
+		ResourceName: "default/tls",
+		Violated:     true,
+	}
+	notifier.SetAlert("Rule/A", a)
+	notifier.Notify()
+	assert.Equal(t, alert.StatusFiring, notifier.Alerts["Rule/A/default/tls"].Status)
+
+	// same severity is not sent again
+	notifier.SetAlert("Rule/A", a)
+	notifier.Notify()
+	assert.Len(t, requests, 1)
+
+	// escalated severity is sent as a new alert
+	a.Severity, a.Message = alert.SeverityCritical, "certificate expires in 5 days"
+	notifier.SetAlert("Rule/A", a)
+	assert.Equal(t, alert.StatusPending, notifier.Alerts["Rule/A/default/tls"].Status)
+	notifier.Notify()
+	assert.Equal(t, alert.StatusFiring, notifier.Alerts["Rule/A/default/tls"].Status)
+	assert.Len(t, requests, 2)
+	assert.Contains(t, requests[0], "certificate expires in 20 days")
+	assert.Contains(t, requests[1], "certificate expires in 5 days")
+}
+
+func Test_Notifier_SetAlert_channel(t *testing.T) {
+	name := "Rule/A/default/tls"
+	a := alert.Alert{Severity: alert.SeverityWarning, ResourceName: "default/tls", Channel: "old", Violated: true}
+	cases := []struct {
+		desc     string
+		status   alert.Status
+		severity alert.Severity
+		expected alert.Alert
+	}{
+		{
+			desc:     "pending alert is sent to the new channel",
+			status:   alert.StatusPending,
+			severity: alert.SeverityWarning,
+			expected: alert.Alert{Severity: alert.SeverityWarning, ResourceName: "default/tls", Channel: "new", Status: alert.StatusPending, Violated: true},
+		},
+		{
+			desc:     "firing alert stays in the channel it was sent to",
+			status:   alert.StatusFiring,
+			severity: alert.SeverityWarning,
+			expected: alert.Alert{Severity: alert.SeverityWarning, ResourceName: "default/tls", Channel: "old", Status: alert.StatusFiring, Violated: true},
+		},
+		{
+			desc:     "escalated alert is sent again to the channel it was sent to",
+			status:   alert.StatusFiring,
+			severity: alert.SeverityCritical,
+			expected: alert.Alert{Severity: alert.SeverityCritical, ResourceName: "default/tls", Channel: "old", Status: alert.StatusPending, Violated: true},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			current := a
+			current.Status = tc.status
+			notifier := Notifier{Resource: &merlinv1beta1.Notifier{}, Alerts: map[string]alert.Alert{name: current}}
+			rerouted := a
+			rerouted.Channel, rerouted.Severity = "new", tc.severity
+			notifier.SetAlert("Rule/A", rerouted)
+			assert.Equal(t, tc.expected, notifier.Alerts[name])
+		})
+	}
+}
+
+func Test_Notifier_MergeSent(t *testing.T) {
+	name := "Rule/A/default/tls"
+	firing := alert.Alert{Severity: alert.SeverityWarning, ResourceName: "default/tls", Status: alert.StatusFiring, Violated: true}
+	pending := firing
+	pending.Status = alert.StatusPending
+	escalated := pending
+	escalated.Severity = alert.SeverityCritical
+	recovering := firing
+	recovering.Status, recovering.Violated = alert.StatusRecovering, false
+
+	cases := []struct {
+		desc     string
+		sent     alert.Alert
+		err      error
+		current  map[string]alert.Alert
+		expected map[string]alert.Alert
+	}{
+		{
+			desc:     "sent alert becomes firing",
+			sent:     pending,
+			current:  map[string]alert.Alert{name: pending},
+			expected: map[string]alert.Alert{name: firing},
+		},
+		{
+			desc:     "failed alert keeps the error to send it again",
+			sent:     pending,
+			err:      fmt.Errorf("timeout"),
+			current:  map[string]alert.Alert{name: pending},
+			expected: map[string]alert.Alert{name: func() alert.Alert { a := pending; a.Error = "timeout"; return a }()},
+		},
+		{
+			desc:     "alert escalated while sending is sent again",
+			sent:     pending,
+			current:  map[string]alert.Alert{name: escalated},
+			expected: map[string]alert.Alert{name: escalated},
+		},
+		{
+			desc:     "alert recovered while sending sends the recovery",
+			sent:     pending,
+			current:  map[string]alert.Alert{},
+			expected: map[string]alert.Alert{name: recovering},
+		},
+		{
+			desc:     "recovered alert is removed",
+			sent:     recovering,
+			current:  map[string]alert.Alert{name: recovering},
+			expected: map[string]alert.Alert{},
+		},
+		{
+			desc:     "alert violated again while sending the recovery is sent again",
+			sent:     recovering,
+			current:  map[string]alert.Alert{name: firing},
+			expected: map[string]alert.Alert{name: pending},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			notifier := Notifier{
+				Resource: &merlinv1beta1.Notifier{},
+				Alerts:   tc.current,
+				AlertMetrics: prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "merlin_violation"},
+					[]string{"rule", "rule_name", "resource_name", "resource_namespace", "resource_kind"}),
+			}
+			notifier.MergeSent(map[string]alert.Alert{name: tc.sent}, map[string]error{name: tc.err})
+			assert.Equal(t, tc.expected, notifier.Alerts)
+		})
+	}
 }
 
 func Test_getAlertName(t *testing.T) {
@@ -0,0 +1,94 @@
+package report
+
+import (
+	"html/template"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/mercari/merlin/alert"
+)
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"color": func(s alert.Severity) string { return s.Color() },
+	"time":  func(t time.Time) string { return t.Format(time.RFC3339) },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Merlin report</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 1em; }
+th, td { border: 1px solid #ddd; padding: 4px 8px; text-align: left; vertical-align: top; }
+th { background: #f4f4f4; }
+.severity { border-left: 6px solid; }
+.suppressed { color: #999; }
+</style>
+</head>
+<body>
+<h1>Merlin report</h1>
+<p>{{ .Total }} violation(s) in {{ len .Namespaces }} namespace(s) from {{ .RuleCount }} rule(s){{ if not .GeneratedAt.IsZero }}, generated at {{ time .GeneratedAt }}{{ end }}.</p>
+{{- range .Namespaces }}
+<h2>{{ .Name }}</h2>
+{{- range .Rules }}
+<h3>{{ .Name }}</h3>
+<table>
+<tr><th>Severity</th><th>Kind</th><th>Resource</th><th>Message</th><th>Location</th></tr>
+{{- range .Results }}
+<tr{{ if .Suppressed }} class="suppressed"{{ end }}>
+<td class="severity" style="border-left-color: {{ color .Severity }}">{{ if .Severity }}{{ .Severity }}{{ else }}-{{ end }}{{ if .Suppressed }} (suppressed){{ end }}</td>
+<td>{{ .ResourceKind }}</td>
+<td>{{ .ResourceName }}</td>
+<td>{{ .Message }}</td>
+<td>{{ with .Location }}{{ .File }}{{ if .Line }}:{{ .Line }}{{ end }}{{ end }}</td>
+</tr>
+{{- end }}
+</table>
+{{- end }}
+{{- end }}
+</body>
+</html>
+`))
+
+type htmlData struct {
+	GeneratedAt time.Time
+	Total       int
+	RuleCount   int
+	Namespaces  []htmlNamespace
+}
+
+type htmlNamespace struct {
+	Name  string
+	Rules []htmlRule
+}
+
+type htmlRule struct {
+	Name    string
+	Results []Result
+}
+
+// WriteHTML writes the violations as a standalone HTML page, grouped by the resources' namespaces and then rules,
+// cluster scoped resources are in the "(cluster)" group.
+func (r Report) WriteHTML(w io.Writer) error {
+	data := htmlData{GeneratedAt: r.GeneratedAt, RuleCount: len(r.Rules)}
+	namespaces := map[string]map[string][]Result{}
+	for _, v := range r.violations() {
+		namespace := namespaceOf(v.ResourceName)
+		if _, ok := namespaces[namespace]; !ok {
+			namespaces[namespace] = map[string][]Result{}
+		}
+		namespaces[namespace][v.Rule] = append(namespaces[namespace][v.Rule], v)
+		data.Total++
+	}
+	for name, rules := range namespaces {
+		namespace := htmlNamespace{Name: name}
+		for rule, results := range rules {
+			namespace.Rules = append(namespace.Rules, htmlRule{Name: rule, Results: results})
+		}
+		sort.Slice(namespace.Rules, func(i, j int) bool { return namespace.Rules[i].Name < namespace.Rules[j].Name })
+		data.Namespaces = append(data.Namespaces, namespace)
+	}
+	sort.Slice(data.Namespaces, func(i, j int) bool { return data.Namespaces[i].Name < data.Namespaces[j].Name })
+	return htmlTemplate.Execute(w, data)
+}
@@ -0,0 +1,106 @@
+// Package report generates reports of rule violations, as SARIF for code scanning tools and as standalone HTML pages.
+package report
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/mercari/merlin/alert"
+	"github.com/mercari/merlin/rules"
+)
+
+const (
+	ContentTypeSARIF = "application/sarif+json"
+	ContentTypeHTML  = "text/html; charset=utf-8"
+
+	// clusterScope is the namespace name in reports for cluster scoped resources.
+	clusterScope = "(cluster)"
+)
+
+// Report is the set of rules and their results.
+type Report struct {
+	// GeneratedAt is the time the results are evaluated, not shown if it's zero.
+	GeneratedAt time.Time
+	// Rules are the rules that the results are from.
+	Rules []Rule
+	// Results are the rules' alerts, only violated ones are in the reports.
+	Results []Result
+}
+
+// Rule is the metadata of a rule, from the rule custom resource.
+type Rule struct {
+	// Name is the rule name, as <RuleKind>/<RuleName>, same as rules.Rule.GetName()
+	Name string
+	// Kind is the kind of the rule, e.g., ClusterRuleHPAReplicaPercentage
+	Kind string
+	// Namespace is the namespace of the rule, empty for cluster rules.
+	Namespace string
+	// Severity is the severity from the rule's notification.
+	Severity alert.Severity
+	// Notifiers are the notifiers from the rule's notification.
+	Notifiers []string
+	// Spec is the rule's spec.
+	Spec map[string]interface{}
+}
+
+// Result is the alert from the rule.
+type Result struct {
+	// Rule is the rule name, as <RuleKind>/<RuleName>
+	Rule string
+	alert.Alert
+	// Location is where the resource is defined, nil if it's unknown, e.g., resources from the cluster.
+	Location *Location
+}
+
+// Location is the source manifest location of the resource.
+type Location struct {
+	// File is the manifest file path, as the path given when loading the manifests.
+	File string `json:"file"`
+	// Line is the line number of the start of the resource's document, starts from 1.
+	Line int `json:"line,omitempty"`
+}
+
+// NewRule creates the rule metadata from the rule.
+func NewRule(r rules.Rule) (Rule, error) {
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(r.GetObject())
+	if err != nil {
+		return Rule{}, err
+	}
+	spec, _ := obj["spec"].(map[string]interface{})
+	notification := r.GetNotification()
+	return Rule{
+		Name:      r.GetName(),
+		Kind:      strings.SplitN(r.GetName(), rules.Separator, 2)[0],
+		Namespace: r.GetObjectMeta().Namespace,
+		Severity:  notification.Severity,
+		Notifiers: notification.Notifiers,
+		Spec:      spec,
+	}, nil
+}
+
+// violations returns the violated results, sorted by rule and resource.
+func (r Report) violations() (violations []Result) {
+	for _, result := range r.Results {
+		if result.Violated {
+			violations = append(violations, result)
+		}
+	}
+	sort.SliceStable(violations, func(i, j int) bool {
+		if violations[i].Rule != violations[j].Rule {
+			return violations[i].Rule < violations[j].Rule
+		}
+		return violations[i].ResourceName < violations[j].ResourceName
+	})
+	return
+}
+
+// namespaceOf returns the namespace of the resource name, which is same as types.NamespacedName.String().
+func namespaceOf(resourceName string) string {
+	if i := strings.Index(resourceName, rules.Separator); i > 0 {
+		return resourceName[:i]
+	}
+	return clusterScope
+}
@@ -0,0 +1,190 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/go-logr/zapr"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/mercari/merlin/alert"
+	merlinv1beta1 "github.com/mercari/merlin/api/v1beta1"
+	"github.com/mercari/merlin/rules"
+)
+
+var testReport = Report{
+	Rules: []Rule{
+		{
+			Name:      "ClusterRuleHPAReplicaPercentage/hpa",
+			Kind:      "ClusterRuleHPAReplicaPercentage",
+			Severity:  alert.SeverityCritical,
+			Notifiers: []string{"slack"},
+			Spec:      map[string]interface{}{"percent": int64(90)},
+		},
+		{
+			Name:      "RuleExpression/replicas",
+			Kind:      "RuleExpression",
+			Namespace: "dev",
+		},
+	},
+	Results: []Result{
+		{
+			Rule: "RuleExpression/replicas",
+			Alert: alert.Alert{
+				Message:      "doesn't satisfy the expression `object.spec.replicas >= 2`",
+				ResourceKind: "Deployment",
+				ResourceName: "dev/api",
+				Violated:     true,
+				Suppressed:   true,
+			},
+		},
+		{
+			Rule: "ClusterRuleHPAReplicaPercentage/hpa",
+			Alert: alert.Alert{
+				Severity:     alert.SeverityCritical,
+				Message:      "HPA percentage is >= 90%",
+				ResourceKind: "HorizontalPodAutoscaler",
+				ResourceName: "default/api",
+				Violated:     true,
+			},
+			Location: &Location{File: "manifests/hpa.yaml", Line: 3},
+		},
+		{
+			Rule: "ClusterRuleHPAReplicaPercentage/hpa",
+			Alert: alert.Alert{
+				Message:      "HPA percentage is within threshold (< 90%)",
+				ResourceKind: "HorizontalPodAutoscaler",
+				ResourceName: "default/web",
+			},
+		},
+	},
+}
+
+func Test_NewRule(t *testing.T) {
+	assert.NoError(t, merlinv1beta1.AddToScheme(scheme.Scheme))
+	cli := fake.NewFakeClientWithScheme(scheme.Scheme, &merlinv1beta1.RuleExpression{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "dev", Name: "replicas"},
+		Spec: merlinv1beta1.RuleExpressionSpec{
+			Notification: merlinv1beta1.Notification{Notifiers: []string{"slack"}, Severity: alert.SeverityInfo},
+			TargetKind:   metav1.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+			Expression:   "object.spec.replicas >= 2",
+		},
+	})
+	r, err := (&rules.ExpressionRule{}).New(context.Background(), cli, zapr.NewLogger(zap.L()), client.ObjectKey{Namespace: "dev", Name: "replicas"})
+	assert.NoError(t, err)
+	rule, err := NewRule(r)
+	assert.NoError(t, err)
+	assert.Equal(t, Rule{
+		Name:      "RuleExpression/replicas",
+		Kind:      "RuleExpression",
+		Namespace: "dev",
+		Severity:  alert.SeverityInfo,
+		Notifiers: []string{"slack"},
+		Spec: map[string]interface{}{
+			"notification": map[string]interface{}{"notifiers": []interface{}{"slack"}, "severity": "info"},
+			"selector":     map[string]interface{}{},
+			"targetKind":   map[string]interface{}{"group": "apps", "version": "v1", "kind": "Deployment"},
+			"expression":   "object.spec.replicas >= 2",
+		},
+	}, rule)
+}
+
+func Test_Report_WriteSARIF(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, testReport.WriteSARIF(&buf))
+	log := sarifLog{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &log))
+	assert.Equal(t, "2.1.0", log.Version)
+	assert.Len(t, log.Runs, 1)
+	run := log.Runs[0]
+	assert.Equal(t, "merlin", run.Tool.Driver.Name)
+	assert.Equal(t, []sarifReportingDescriptor{
+		{
+			ID:                   "ClusterRuleHPAReplicaPercentage/hpa",
+			Name:                 "ClusterRuleHPAReplicaPercentage",
+			ShortDescription:     sarifMessage{Text: "Merlin rule ClusterRuleHPAReplicaPercentage/hpa"},
+			DefaultConfiguration: &sarifConfiguration{Level: "error"},
+			Properties: map[string]interface{}{
+				"severity":  "critical",
+				"notifiers": []interface{}{"slack"},
+				"spec":      map[string]interface{}{"percent": float64(90)},
+			},
+		},
+		{
+			ID:               "RuleExpression/replicas",
+			Name:             "RuleExpression",
+			ShortDescription: sarifMessage{Text: "Merlin rule RuleExpression/replicas"},
+			Properties:       map[string]interface{}{"namespace": "dev"},
+		},
+	}, run.Tool.Driver.Rules)
+
+	ruleIndex0, ruleIndex1 := 0, 1
+	assert.Equal(t, []sarifResult{
+		{
+			RuleID:    "ClusterRuleHPAReplicaPercentage/hpa",
+			RuleIndex: &ruleIndex0,
+			Level:     "error",
+			Message:   sarifMessage{Text: "HorizontalPodAutoscaler `default/api` HPA percentage is >= 90%"},
+			Locations: []sarifLocation{{
+				PhysicalLocation: &sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: "manifests/hpa.yaml"},
+					Region:           &sarifRegion{StartLine: 3},
+				},
+				LogicalLocations: []sarifLogicalLocation{{
+					Name:               "default/api",
+					FullyQualifiedName: "HorizontalPodAutoscaler/default/api",
+					Kind:               "resource",
+				}},
+			}},
+		},
+		{
+			RuleID:    "RuleExpression/replicas",
+			RuleIndex: &ruleIndex1,
+			Level:     "warning",
+			Message:   sarifMessage{Text: "Deployment `dev/api` doesn't satisfy the expression `object.spec.replicas >= 2`"},
+			Locations: []sarifLocation{{
+				LogicalLocations: []sarifLogicalLocation{{
+					Name:               "dev/api",
+					FullyQualifiedName: "Deployment/dev/api",
+					Kind:               "resource",
+				}},
+			}},
+			Suppressions: []sarifSuppression{{Kind: "external", Justification: "suppressed by the rule's notification"}},
+		},
+	}, run.Results)
+
+	buf.Reset()
+	assert.NoError(t, Report{}.WriteSARIF(&buf))
+	assert.Contains(t, buf.String(), `"results": []`)
+}
+
+func Test_Report_WriteHTML(t *testing.T) {
+	var buf bytes.Buffer
+	r := testReport
+	r.GeneratedAt = time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	r.Results = append(r.Results, Result{
+		Rule: "ClusterRuleHPAReplicaPercentage/hpa",
+		Alert: alert.Alert{
+			Message:      "<script>alert(1)</script>",
+			ResourceKind: "Namespace",
+			ResourceName: "/kube-system",
+			Violated:     true,
+		},
+	})
+	assert.NoError(t, r.WriteHTML(&buf))
+	html := buf.String()
+	assert.Contains(t, html, "3 violation(s) in 3 namespace(s) from 2 rule(s), generated at 2021-01-02T03:04:05Z.")
+	assert.Contains(t, html, "<td>manifests/hpa.yaml:3</td>")
+	assert.Contains(t, html, "&lt;script&gt;alert(1)&lt;/script&gt;")
+	assert.NotContains(t, html, "default/web")
+	// namespaces are in order, and cluster scoped resources are in the cluster group
+	assert.Regexp(t, `(?s)<h2>\(cluster\)</h2>.*/kube-system.*<h2>default</h2>.*default/api.*<h2>dev</h2>.*suppressed.*dev/api`, html)
+}
@@ -0,0 +1,181 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/mercari/merlin/alert"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	toolName     = "merlin"
+	toolURI      = "https://github.com/mercari/merlin"
+)
+
+// the following are the subset of SARIF 2.1.0 objects used by the reports,
+// see https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html for the details.
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string                     `json:"name"`
+	InformationURI string                     `json:"informationUri"`
+	Rules          []sarifReportingDescriptor `json:"rules"`
+}
+
+type sarifReportingDescriptor struct {
+	ID                   string                 `json:"id"`
+	Name                 string                 `json:"name"`
+	ShortDescription     sarifMessage           `json:"shortDescription"`
+	DefaultConfiguration *sarifConfiguration    `json:"defaultConfiguration,omitempty"`
+	Properties           map[string]interface{} `json:"properties,omitempty"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID       string             `json:"ruleId"`
+	RuleIndex    *int               `json:"ruleIndex,omitempty"`
+	Level        string             `json:"level"`
+	Message      sarifMessage       `json:"message"`
+	Locations    []sarifLocation    `json:"locations"`
+	Suppressions []sarifSuppression `json:"suppressions,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+type sarifLogicalLocation struct {
+	Name               string `json:"name"`
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+type sarifSuppression struct {
+	Kind          string `json:"kind"`
+	Justification string `json:"justification"`
+}
+
+// sarifLevel maps the severity to SARIF level, alerts without severity are warnings.
+func sarifLevel(s alert.Severity) string {
+	switch s {
+	case alert.SeverityFatal, alert.SeverityCritical:
+		return "error"
+	case alert.SeverityInfo:
+		return "note"
+	default:
+		return "warning"
+	}
+}
+
+// WriteSARIF writes the violations as a SARIF 2.1.0 log with one run, rules are the reporting descriptors of the tool
+// and resources are the logical locations of results, with the physical locations from manifests if they are known.
+func (r Report) WriteSARIF(w io.Writer) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           toolName,
+			InformationURI: toolURI,
+			Rules:          []sarifReportingDescriptor{},
+		}},
+		Results: []sarifResult{},
+	}
+	ruleIndexes := map[string]int{}
+	for i, rule := range r.Rules {
+		descriptor := sarifReportingDescriptor{
+			ID:               rule.Name,
+			Name:             rule.Kind,
+			ShortDescription: sarifMessage{Text: fmt.Sprintf("Merlin rule %s", rule.Name)},
+			Properties:       map[string]interface{}{},
+		}
+		if rule.Severity != alert.SeverityDefault {
+			descriptor.DefaultConfiguration = &sarifConfiguration{Level: sarifLevel(rule.Severity)}
+			descriptor.Properties["severity"] = rule.Severity
+		}
+		if rule.Namespace != "" {
+			descriptor.Properties["namespace"] = rule.Namespace
+		}
+		if len(rule.Notifiers) > 0 {
+			descriptor.Properties["notifiers"] = rule.Notifiers
+		}
+		if len(rule.Spec) > 0 {
+			descriptor.Properties["spec"] = rule.Spec
+		}
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, descriptor)
+		ruleIndexes[rule.Name] = i
+	}
+
+	for _, v := range r.violations() {
+		result := sarifResult{
+			RuleID:  v.Rule,
+			Level:   sarifLevel(v.Severity),
+			Message: sarifMessage{Text: fmt.Sprintf("%s `%s` %s", v.ResourceKind, v.ResourceName, v.Message)},
+		}
+		if i, ok := ruleIndexes[v.Rule]; ok {
+			index := i
+			result.RuleIndex = &index
+		}
+		location := sarifLocation{
+			LogicalLocations: []sarifLogicalLocation{{
+				Name:               v.ResourceName,
+				FullyQualifiedName: v.ResourceKind + "/" + v.ResourceName,
+				Kind:               "resource",
+			}},
+		}
+		if v.Location != nil && v.Location.File != "" {
+			location.PhysicalLocation = &sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(v.Location.File)},
+			}
+			if v.Location.Line > 0 {
+				location.PhysicalLocation.Region = &sarifRegion{StartLine: v.Location.Line}
+			}
+		}
+		result.Locations = []sarifLocation{location}
+		if v.Suppressed {
+			result.Suppressions = []sarifSuppression{{Kind: "external", Justification: "suppressed by the rule's notification"}}
+		}
+		run.Results = append(run.Results, result)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(sarifLog{Version: sarifVersion, Schema: sarifSchema, Runs: []sarifRun{run}})
+}
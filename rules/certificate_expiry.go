@@ -0,0 +1,220 @@
+package rules
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/mercari/merlin/alert"
+	merlinv1beta1 "github.com/mercari/merlin/api/v1beta1"
+)
+
+const defaultCertificateRecheckIntervalSeconds = 3600
+
+var defaultCertificateExpiryThresholds = []merlinv1beta1.CertificateExpiryThreshold{
+	{Days: 30, Severity: alert.SeverityWarning},
+	{Days: 7, Severity: alert.SeverityCritical},
+}
+
+type CertificateExpiryRule struct {
+	rule
+	resource *merlinv1beta1.ClusterRuleCertificateExpiry
+}
+
+func (c *CertificateExpiryRule) New(ctx context.Context, cli client.Client, logger logr.Logger, key client.ObjectKey) (Rule, error) {
+	c.cli = cli
+	c.log = logger
+	c.status = &Status{}
+	c.resource = &merlinv1beta1.ClusterRuleCertificateExpiry{}
+	if err := c.cli.Get(ctx, key, c.resource); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *CertificateExpiryRule) GetObject() runtime.Object {
+	return c.resource
+}
+
+func (c CertificateExpiryRule) GetName() string {
+	return strings.Join([]string{getStructName(c.resource), c.resource.Name}, Separator)
+}
+
+func (c CertificateExpiryRule) GetObjectMeta() metav1.ObjectMeta {
+	return c.resource.ObjectMeta
+}
+
+func (c CertificateExpiryRule) GetNotification() merlinv1beta1.Notification {
+	return c.resource.Spec.Notification
+}
+
+func (c *CertificateExpiryRule) SetFinalizer(finalizer string) {
+	c.resource.ObjectMeta.Finalizers = append(c.resource.ObjectMeta.Finalizers, finalizer)
+}
+
+func (c *CertificateExpiryRule) RemoveFinalizer(finalizer string) {
+	c.resource.ObjectMeta.Finalizers = removeString(c.resource.ObjectMeta.Finalizers, finalizer)
+}
+
+func (c *CertificateExpiryRule) GetRecheckInterval() time.Duration {
+	if c.resource.Spec.RecheckIntervalSeconds > 0 {
+		return time.Duration(c.resource.Spec.RecheckIntervalSeconds) * time.Second
+	}
+	return defaultCertificateRecheckIntervalSeconds * time.Second
+}
+
+func (c *CertificateExpiryRule) EvaluateAll(ctx context.Context) (alerts []alert.Alert, err error) {
+	secrets := &corev1.SecretList{}
+	if err = c.cli.List(ctx, secrets); err != nil {
+		return
+	}
+
+	if len(secrets.Items) == 0 {
+		c.log.Info("no resource found")
+		return
+	}
+	for _, secret := range secrets.Items {
+		if len(c.getCertificateKeys(&secret)) == 0 {
+			continue
+		}
+		c.log.V(1).Info("evaluating secret", "secret", secret.Name)
+		var a alert.Alert
+		a, err = c.Evaluate(ctx, &secret)
+		if err != nil {
+			return
+		}
+		alerts = append(alerts, a)
+	}
+	return
+}
+
+func (c *CertificateExpiryRule) Evaluate(ctx context.Context, object interface{}) (a alert.Alert, err error) {
+	secret, ok := object.(*corev1.Secret)
+	if !ok {
+		err = fmt.Errorf("object being evaluated is not type %T", secret)
+		return
+	}
+	key := client.ObjectKey{Namespace: secret.Namespace, Name: secret.Name}
+	a = alert.Alert{
+		Suppressed:      c.resource.Spec.Notification.Suppressed,
+		Severity:        c.resource.Spec.Notification.Severity,
+		MessageTemplate: c.resource.Spec.Notification.CustomMessageTemplate,
+		Message:         "secret has no certificate",
+		ResourceKind:    getStructName(secret),
+		ResourceName:    key.String(),
+		Violated:        false,
+	}
+	if isStringInSlice(c.resource.Spec.IgnoreNamespaces, secret.Namespace) {
+		a.Message = "namespace is ignored by the rule"
+		return
+	}
+
+	var cert *x509.Certificate
+	var certKey string
+	for _, k := range c.getCertificateKeys(secret) {
+		for _, parsed := range parseCertificates(secret.Data[k]) {
+			if cert == nil || parsed.NotAfter.Before(cert.NotAfter) {
+				cert = parsed
+				certKey = k
+			}
+		}
+	}
+	if cert == nil {
+		c.status.setViolation(key, a.Violated)
+		return
+	}
+
+	thresholds := c.getThresholds()
+	left := time.Until(cert.NotAfter)
+	description := describeCertificate(cert, certKey)
+	// the message only changes with the reached threshold instead of the days left, so alerts aren't updated every day
+	a.Message = fmt.Sprintf("%s expires at %s", description, cert.NotAfter.UTC().Format(time.RFC3339))
+	for _, t := range thresholds {
+		if left <= time.Duration(t.Days)*24*time.Hour {
+			a.Violated = true
+			a.Message = fmt.Sprintf("%s expires within %d days at %s", description, t.Days, cert.NotAfter.UTC().Format(time.RFC3339))
+			if t.Severity != alert.SeverityDefault {
+				a.Severity = t.Severity
+			}
+			break
+		}
+	}
+	if left <= 0 {
+		a.Message = fmt.Sprintf("%s expired at %s", description, cert.NotAfter.UTC().Format(time.RFC3339))
+	}
+	c.status.setViolation(key, a.Violated)
+	return
+}
+
+func (c *CertificateExpiryRule) GetDelaySeconds(object interface{}) (time.Duration, error) {
+	return 0, nil
+}
+
+// getCertificateKeys returns the secret data keys that have certificates to check
+func (c *CertificateExpiryRule) getCertificateKeys(secret *corev1.Secret) (keys []string) {
+	if secret.Type == corev1.SecretTypeTLS {
+		keys = append(keys, corev1.TLSCertKey)
+	}
+	for _, k := range c.resource.Spec.Keys {
+		if _, ok := secret.Data[k]; ok && !isStringInSlice(keys, k) {
+			keys = append(keys, k)
+		}
+	}
+	return
+}
+
+// getThresholds returns the thresholds sorted from the smallest days, so the first reached threshold is the most severe one
+func (c *CertificateExpiryRule) getThresholds() []merlinv1beta1.CertificateExpiryThreshold {
+	thresholds := make([]merlinv1beta1.CertificateExpiryThreshold, 0, len(c.resource.Spec.Thresholds))
+	thresholds = append(thresholds, c.resource.Spec.Thresholds...)
+	if len(thresholds) == 0 {
+		thresholds = append(thresholds, defaultCertificateExpiryThresholds...)
+	}
+	sort.Slice(thresholds, func(i, j int) bool { return thresholds[i].Days < thresholds[j].Days })
+	return thresholds
+}
+
+// parseCertificates parses all PEM encoded certificates in data, blocks that cannot be parsed are skipped
+func parseCertificates(data []byte) (certs []*x509.Certificate) {
+	for len(data) > 0 {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		if cert, err := x509.ParseCertificate(block.Bytes); err == nil {
+			certs = append(certs, cert)
+		}
+	}
+	return
+}
+
+func describeCertificate(cert *x509.Certificate, key string) string {
+	var sans []string
+	sans = append(sans, cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	sans = append(sans, cert.EmailAddresses...)
+	for _, uri := range cert.URIs {
+		sans = append(sans, uri.String())
+	}
+	description := fmt.Sprintf("certificate `%s` in `%s`", cert.Subject.String(), key)
+	if len(sans) > 0 {
+		description = fmt.Sprintf("certificate `%s` (SANs: %s) in `%s`", cert.Subject.String(), strings.Join(sans, ", "), key)
+	}
+	return description
+}
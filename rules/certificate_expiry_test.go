@@ -0,0 +1,269 @@
+package rules
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/go-logr/zapr"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/mercari/merlin/alert"
+	merlinv1beta1 "github.com/mercari/merlin/api/v1beta1"
+	"github.com/mercari/merlin/mocks"
+)
+
+func newTestCertificate(t *testing.T, cn string, dnsNames []string, notAfter time.Time) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: cn},
+		DNSNames:     dnsNames,
+		NotBefore:    notAfter.Add(-365 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func Test_CertificateExpiryRule_Basic(t *testing.T) {
+	notification := merlinv1beta1.Notification{
+		Notifiers:  []string{"testNotifier"},
+		Suppressed: true,
+	}
+
+	merlinv1beta1Rule := &merlinv1beta1.ClusterRuleCertificateExpiry{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-r"},
+		Spec: merlinv1beta1.ClusterRuleCertificateExpirySpec{
+			Notification: notification,
+		},
+	}
+
+	r := &CertificateExpiryRule{resource: merlinv1beta1Rule}
+	assert.Equal(t, merlinv1beta1Rule.ObjectMeta, r.GetObjectMeta())
+	assert.Equal(t, merlinv1beta1Rule, r.GetObject())
+	assert.Equal(t, notification, r.GetNotification())
+	assert.Equal(t, "ClusterRuleCertificateExpiry/test-r", r.GetName())
+	assert.Equal(t, time.Hour, r.GetRecheckInterval())
+	r.resource.Spec.RecheckIntervalSeconds = 60
+	assert.Equal(t, time.Minute, r.GetRecheckInterval())
+
+	finalizer := "test.finalizer"
+	r.SetFinalizer(finalizer)
+	assert.Equal(t, finalizer, r.resource.Finalizers[0])
+	r.RemoveFinalizer(finalizer)
+	assert.Empty(t, r.resource.Finalizers)
+	delay, err := r.GetDelaySeconds(&corev1.Secret{})
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), delay)
+}
+
+func Test_CertificateExpiryRule_Evaluate(t *testing.T) {
+	ctx := context.Background()
+	log := zapr.NewLogger(zap.L())
+	now := time.Now().Truncate(time.Second)
+	r := &CertificateExpiryRule{
+		rule: rule{log: log, status: &Status{}},
+		resource: &merlinv1beta1.ClusterRuleCertificateExpiry{
+			Spec: merlinv1beta1.ClusterRuleCertificateExpirySpec{
+				IgnoreNamespaces: []string{"ignoredNS"},
+				Notification:     merlinv1beta1.Notification{Notifiers: []string{"testNotifier"}, Severity: alert.SeverityInfo},
+				Keys:             []string{"ca.crt"},
+			},
+		},
+	}
+	tlsSecret := func(namespace string, data map[string][]byte) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "cert"},
+			Type:       corev1.SecretTypeTLS,
+			Data:       data,
+		}
+	}
+	in60Days := now.Add(60*24*time.Hour + time.Hour)
+	in20Days := now.Add(20*24*time.Hour + time.Hour)
+	in3Days := now.Add(3*24*time.Hour + time.Hour)
+	expired := now.Add(-time.Hour)
+
+	cases := []struct {
+		desc      string
+		resource  interface{}
+		expect    alert.Alert
+		expectErr bool
+	}{
+		{
+			desc:      "non secret should have error",
+			resource:  &corev1.Pod{},
+			expectErr: true,
+		},
+		{
+			desc:     "ignored namespace should not get violated alert",
+			resource: tlsSecret("ignoredNS", map[string][]byte{corev1.TLSCertKey: newTestCertificate(t, "a", nil, expired)}),
+			expect: alert.Alert{
+				Severity:     alert.SeverityInfo,
+				Message:      "namespace is ignored by the rule",
+				ResourceKind: "Secret",
+				ResourceName: "ignoredNS/cert",
+			},
+		},
+		{
+			desc:     "secret without certificate should not get violated alert",
+			resource: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "cert"}, Type: corev1.SecretTypeOpaque},
+			expect: alert.Alert{
+				Severity:     alert.SeverityInfo,
+				Message:      "secret has no certificate",
+				ResourceKind: "Secret",
+				ResourceName: "test/cert",
+			},
+		},
+		{
+			desc:     "certificate far from expiry should not get violated alert",
+			resource: tlsSecret("test", map[string][]byte{corev1.TLSCertKey: newTestCertificate(t, "example.com", []string{"example.com"}, in60Days)}),
+			expect: alert.Alert{
+				Severity:     alert.SeverityInfo,
+				Message:      fmt.Sprintf("certificate `CN=example.com` (SANs: example.com) in `tls.crt` expires at %s", in60Days.UTC().Format(time.RFC3339)),
+				ResourceKind: "Secret",
+				ResourceName: "test/cert",
+			},
+		},
+		{
+			desc:     "certificate within 30 days should get warning alert",
+			resource: tlsSecret("test", map[string][]byte{corev1.TLSCertKey: newTestCertificate(t, "example.com", []string{"example.com", "www.example.com"}, in20Days)}),
+			expect: alert.Alert{
+				Severity:     alert.SeverityWarning,
+				Message:      fmt.Sprintf("certificate `CN=example.com` (SANs: example.com, www.example.com) in `tls.crt` expires within 30 days at %s", in20Days.UTC().Format(time.RFC3339)),
+				ResourceKind: "Secret",
+				ResourceName: "test/cert",
+				Violated:     true,
+			},
+		},
+		{
+			desc: "soonest expiring certificate in configured keys should get critical alert",
+			resource: tlsSecret("test", map[string][]byte{
+				corev1.TLSCertKey: newTestCertificate(t, "example.com", nil, in60Days),
+				"ca.crt":          newTestCertificate(t, "ca", nil, in3Days),
+			}),
+			expect: alert.Alert{
+				Severity:     alert.SeverityCritical,
+				Message:      fmt.Sprintf("certificate `CN=ca` in `ca.crt` expires within 7 days at %s", in3Days.UTC().Format(time.RFC3339)),
+				ResourceKind: "Secret",
+				ResourceName: "test/cert",
+				Violated:     true,
+			},
+		},
+		{
+			desc:     "expired certificate should get critical alert",
+			resource: tlsSecret("test", map[string][]byte{corev1.TLSCertKey: newTestCertificate(t, "example.com", nil, expired)}),
+			expect: alert.Alert{
+				Severity:     alert.SeverityCritical,
+				Message:      fmt.Sprintf("certificate `CN=example.com` in `tls.crt` expired at %s", expired.UTC().Format(time.RFC3339)),
+				ResourceKind: "Secret",
+				ResourceName: "test/cert",
+				Violated:     true,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(tt *testing.T) {
+			a, err := r.Evaluate(ctx, tc.resource)
+			if tc.expectErr {
+				assert.Error(tt, err)
+			} else {
+				assert.NoError(tt, err)
+				assert.Equal(tt, tc.expect, a)
+			}
+		})
+	}
+}
+
+func Test_CertificateExpiryRule_EvaluateAll(t *testing.T) {
+	ctx := context.Background()
+	log := zapr.NewLogger(zap.L())
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mocks.NewMockClient(mockCtrl)
+	r := &CertificateExpiryRule{
+		rule: rule{cli: mockClient, log: log, status: &Status{}},
+		resource: &merlinv1beta1.ClusterRuleCertificateExpiry{
+			Spec: merlinv1beta1.ClusterRuleCertificateExpirySpec{
+				Notification: merlinv1beta1.Notification{Notifiers: []string{"testNotifier"}},
+				Thresholds:   []merlinv1beta1.CertificateExpiryThreshold{{Days: 90, Severity: alert.SeverityInfo}},
+			},
+		},
+	}
+	notAfter := time.Now().Truncate(time.Second).Add(50*24*time.Hour + time.Hour)
+
+	cases := []struct {
+		desc      string
+		mockCalls []*gomock.Call
+		expect    []alert.Alert
+		expectErr bool
+	}{
+		{
+			desc: "no resources should returns nil alerts",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().
+					List(ctx, &corev1.SecretList{}).
+					Return(nil),
+			},
+		},
+		{
+			desc: "only secrets with certificates should be evaluated",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().
+					List(ctx, &corev1.SecretList{}).
+					SetArg(1, corev1.SecretList{
+						Items: []corev1.Secret{
+							{
+								ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "opaque"},
+								Type:       corev1.SecretTypeOpaque,
+							},
+							{
+								ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "cert"},
+								Type:       corev1.SecretTypeTLS,
+								Data:       map[string][]byte{corev1.TLSCertKey: newTestCertificate(t, "example.com", nil, notAfter)},
+							},
+						},
+					}).
+					Return(nil),
+			},
+			expect: []alert.Alert{
+				{
+					Severity:     alert.SeverityInfo,
+					Message:      fmt.Sprintf("certificate `CN=example.com` in `tls.crt` expires within 90 days at %s", notAfter.UTC().Format(time.RFC3339)),
+					ResourceKind: "Secret",
+					ResourceName: "test/cert",
+					Violated:     true,
+				},
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.desc, func(tt *testing.T) {
+			for _, call := range tc.mockCalls {
+				call.Times(1)
+			}
+			alerts, err := r.EvaluateAll(ctx)
+			if tc.expectErr {
+				assert.Error(tt, err)
+			} else {
+				assert.NoError(tt, err)
+				assert.Equal(tt, tc.expect, alerts)
+			}
+		})
+	}
+}
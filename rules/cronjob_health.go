@@ -0,0 +1,260 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/robfig/cron/v3"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/mercari/merlin/alert"
+	merlinv1beta1 "github.com/mercari/merlin/api/v1beta1"
+)
+
+const (
+	defaultCronJobHealthMissedSchedules        = 3
+	defaultCronJobHealthSuspendedSeconds       = 86400
+	defaultCronJobHealthRecheckIntervalSeconds = 300
+)
+
+// failedJobReasons are the reasons of the Failed condition set by the job controller when the job gives up
+var failedJobReasons = []string{"BackoffLimitExceeded", "DeadlineExceeded"}
+
+// CronJobHealthRule keeps the time when CronJobs are first seen suspended as the pending states of the status, keyed by
+// namespace/name, since the CronJob doesn't tell when it was suspended.
+type CronJobHealthRule struct {
+	rule
+	resource *merlinv1beta1.ClusterRuleCronJobHealth
+}
+
+func (c *CronJobHealthRule) New(ctx context.Context, cli client.Client, logger logr.Logger, key client.ObjectKey) (Rule, error) {
+	c.cli = cli
+	c.log = logger
+	// keep the suspended CronJobs when the rule is re-created
+	c.status = c.status.keepPending()
+	c.resource = &merlinv1beta1.ClusterRuleCronJobHealth{}
+	if err := c.cli.Get(ctx, key, c.resource); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *CronJobHealthRule) GetObject() runtime.Object {
+	return c.resource
+}
+
+func (c *CronJobHealthRule) GetName() string {
+	return strings.Join([]string{getStructName(c.resource), c.resource.Name}, Separator)
+}
+
+func (c *CronJobHealthRule) GetObjectMeta() metav1.ObjectMeta {
+	return c.resource.ObjectMeta
+}
+
+func (c *CronJobHealthRule) GetNotification() merlinv1beta1.Notification {
+	return c.resource.Spec.Notification
+}
+
+func (c *CronJobHealthRule) SetFinalizer(finalizer string) {
+	c.resource.ObjectMeta.Finalizers = append(c.resource.ObjectMeta.Finalizers, finalizer)
+}
+
+func (c *CronJobHealthRule) RemoveFinalizer(finalizer string) {
+	c.resource.ObjectMeta.Finalizers = removeString(c.resource.ObjectMeta.Finalizers, finalizer)
+}
+
+// GetRecheckInterval returns the interval to re-evaluate all CronJobs, since missed schedules depend on the time
+// and don't come with any change of the CronJob.
+func (c *CronJobHealthRule) GetRecheckInterval() time.Duration {
+	if c.resource.Spec.RecheckIntervalSeconds > 0 {
+		return time.Duration(c.resource.Spec.RecheckIntervalSeconds) * time.Second
+	}
+	return defaultCronJobHealthRecheckIntervalSeconds * time.Second
+}
+
+func (c *CronJobHealthRule) EvaluateAll(ctx context.Context) (alerts []alert.Alert, err error) {
+	cronJobs := &batchv1beta1.CronJobList{}
+	if err = c.cli.List(ctx, cronJobs); err != nil {
+		return
+	}
+	jobs := &batchv1.JobList{}
+	if err = c.cli.List(ctx, jobs); err != nil {
+		return
+	}
+
+	if len(cronJobs.Items) == 0 && len(jobs.Items) == 0 {
+		c.log.Info("no cronjob or job found")
+		return
+	}
+	now := time.Now()
+	for _, cronJob := range cronJobs.Items {
+		c.log.V(1).Info("evaluating", fmt.Sprintf("%T", cronJob), cronJob.Name)
+		a := c.checkCronJob(&cronJob, jobs.Items, now)
+		alerts = append(alerts, a)
+	}
+	for _, job := range jobs.Items {
+		c.log.V(1).Info("evaluating", fmt.Sprintf("%T", job), job.Name)
+		a := c.checkJob(&job)
+		alerts = append(alerts, a)
+	}
+	return
+}
+
+func (c *CronJobHealthRule) Evaluate(ctx context.Context, object interface{}) (a alert.Alert, err error) {
+	switch obj := object.(type) {
+	case *batchv1beta1.CronJob:
+		jobs := &batchv1.JobList{}
+		if !isStringInSlice(c.resource.Spec.IgnoreNamespaces, obj.Namespace) {
+			if err = c.cli.List(ctx, jobs, &client.ListOptions{Namespace: obj.Namespace}); err != nil {
+				return
+			}
+		}
+		a = c.checkCronJob(obj, jobs.Items, time.Now())
+	case *batchv1.Job:
+		a = c.checkJob(obj)
+	default:
+		err = fmt.Errorf("object being evaluated is not type %T or %T", &batchv1beta1.CronJob{}, &batchv1.Job{})
+	}
+	return
+}
+
+func (c *CronJobHealthRule) GetDelaySeconds(object interface{}) (time.Duration, error) {
+	return 0, nil
+}
+
+func (c *CronJobHealthRule) newAlert(object interface{}, key client.ObjectKey) alert.Alert {
+	return alert.Alert{
+		Suppressed:      c.resource.Spec.Notification.Suppressed,
+		Severity:        c.resource.Spec.Notification.Severity,
+		MessageTemplate: c.resource.Spec.Notification.CustomMessageTemplate,
+		ResourceName:    key.String(),
+		ResourceKind:    getStructName(object),
+		Violated:        false,
+	}
+}
+
+// checkCronJob checks if the CronJob is suspended for too long, or missed too many schedules without a successful run.
+// jobs can be any jobs, only the ones controlled by the CronJob are used to find the last successful run.
+func (c *CronJobHealthRule) checkCronJob(cronJob *batchv1beta1.CronJob, jobs []batchv1.Job, now time.Time) alert.Alert {
+	key := client.ObjectKey{Namespace: cronJob.Namespace, Name: cronJob.Name}
+	a := c.newAlert(cronJob, key)
+	if isStringInSlice(c.resource.Spec.IgnoreNamespaces, key.Namespace) {
+		a.Message = "namespace is ignored by the rule"
+		return a
+	}
+	defer func() { c.status.setViolation(key, a.Violated) }()
+
+	suspended := cronJob.Spec.Suspend != nil && *cronJob.Spec.Suspend
+	elapsed := now.Sub(c.status.setPending(key.String(), suspended, now))
+	if suspended {
+		a.Violated = elapsed >= c.getSuspendedDuration()
+		a.Message = fmt.Sprintf("CronJob is suspended for %s", elapsed.Truncate(time.Second))
+		return a
+	}
+
+	schedule, err := cron.ParseStandard(cronJob.Spec.Schedule)
+	if err != nil {
+		a.Violated = true
+		a.Message = fmt.Sprintf("CronJob has invalid schedule `%s`: %s", cronJob.Spec.Schedule, err)
+		return a
+	}
+	lastSuccess := getCronJobLastSuccessfulTime(cronJob, jobs)
+	since := fmt.Sprintf("last successful run completed at %s", lastSuccess.UTC().Format(time.RFC3339))
+	if lastSuccess.IsZero() {
+		// the successful jobs are not kept, so the last successful run is unknown
+		if cronJob.Spec.SuccessfulJobsHistoryLimit != nil && *cronJob.Spec.SuccessfulJobsHistoryLimit == 0 {
+			a.Message = "CronJob keeps no successful jobs to check its runs"
+			return a
+		}
+		lastSuccess = cronJob.CreationTimestamp.Time
+		since = fmt.Sprintf("no successful run since created at %s", lastSuccess.UTC().Format(time.RFC3339))
+	}
+	missedSchedules := c.getMissedSchedules()
+	// the last missed schedule is given a full period to complete before it's counted
+	if countSchedules(schedule, lastSuccess, now, missedSchedules+1) > missedSchedules {
+		a.Violated = true
+		a.Message = fmt.Sprintf("CronJob has not completed successfully for %d schedules, %s", missedSchedules, since)
+		return a
+	}
+	a.Message = fmt.Sprintf("CronJob has completed successfully within %d schedules, %s", missedSchedules, since)
+	return a
+}
+
+// checkJob checks if the Job failed by exceeding its backoff limit or active deadline
+func (c *CronJobHealthRule) checkJob(job *batchv1.Job) alert.Alert {
+	key := client.ObjectKey{Namespace: job.Namespace, Name: job.Name}
+	a := c.newAlert(job, key)
+	if isStringInSlice(c.resource.Spec.IgnoreNamespaces, key.Namespace) {
+		a.Message = "namespace is ignored by the rule"
+		return a
+	}
+	a.Message = "Job has not failed"
+	for _, cond := range job.Status.Conditions {
+		if cond.Type == batchv1.JobFailed && cond.Status == corev1.ConditionTrue && isStringInSlice(failedJobReasons, cond.Reason) {
+			a.Violated = true
+			a.Message = fmt.Sprintf("Job failed: %s, %s", cond.Reason, cond.Message)
+		}
+	}
+	c.status.setViolation(key, a.Violated)
+	return a
+}
+
+func (c *CronJobHealthRule) getMissedSchedules() int {
+	if c.resource.Spec.MissedSchedules > 0 {
+		return int(c.resource.Spec.MissedSchedules)
+	}
+	return defaultCronJobHealthMissedSchedules
+}
+
+func (c *CronJobHealthRule) getSuspendedDuration() time.Duration {
+	if c.resource.Spec.SuspendedSeconds > 0 {
+		return time.Duration(c.resource.Spec.SuspendedSeconds) * time.Second
+	}
+	return defaultCronJobHealthSuspendedSeconds * time.Second
+}
+
+// getCronJobLastSuccessfulTime returns the latest completion time of the successful jobs controlled by the CronJob,
+// or zero time if there isn't any.
+func getCronJobLastSuccessfulTime(cronJob *batchv1beta1.CronJob, jobs []batchv1.Job) (last time.Time) {
+	for _, job := range jobs {
+		owner := metav1.GetControllerOf(&job)
+		if job.Namespace != cronJob.Namespace || owner == nil || owner.Kind != "CronJob" || owner.Name != cronJob.Name {
+			continue
+		}
+		for _, cond := range job.Status.Conditions {
+			if cond.Type != batchv1.JobComplete || cond.Status != corev1.ConditionTrue {
+				continue
+			}
+			completedAt := cond.LastTransitionTime.Time
+			if job.Status.CompletionTime != nil {
+				completedAt = job.Status.CompletionTime.Time
+			}
+			if completedAt.After(last) {
+				last = completedAt
+			}
+		}
+	}
+	return
+}
+
+// countSchedules counts the scheduled times after since and not after now, up to limit.
+// Schedules are in UTC, same as the CronJob controller running in the usual control plane.
+func countSchedules(schedule cron.Schedule, since, now time.Time, limit int) (count int) {
+	t := since.UTC()
+	for count < limit {
+		t = schedule.Next(t)
+		if t.IsZero() || t.After(now) {
+			return
+		}
+		count++
+	}
+	return
+}
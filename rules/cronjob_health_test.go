@@ -0,0 +1,357 @@
+package rules
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/zapr"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/mercari/merlin/alert"
+	merlinv1beta1 "github.com/mercari/merlin/api/v1beta1"
+	"github.com/mercari/merlin/mocks"
+)
+
+func newTestCronJob(schedule string, createdAt time.Time, suspend bool) *batchv1beta1.CronJob {
+	return &batchv1beta1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "backup", CreationTimestamp: metav1.NewTime(createdAt)},
+		Spec:       batchv1beta1.CronJobSpec{Schedule: schedule, Suspend: &suspend},
+	}
+}
+
+func newTestCronJobJob(name string, completedAt time.Time) batchv1.Job {
+	isController := true
+	completionTime := metav1.NewTime(completedAt)
+	return batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       "test",
+			Name:            name,
+			OwnerReferences: []metav1.OwnerReference{{Kind: "CronJob", Name: "backup", Controller: &isController}},
+		},
+		Status: batchv1.JobStatus{
+			CompletionTime: &completionTime,
+			Conditions:     []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}},
+		},
+	}
+}
+
+func Test_CronJobHealthRule_Basic(t *testing.T) {
+	notification := merlinv1beta1.Notification{
+		Notifiers:  []string{"testNotifier"},
+		Suppressed: true,
+	}
+
+	merlinv1beta1Rule := &merlinv1beta1.ClusterRuleCronJobHealth{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-r"},
+		Spec: merlinv1beta1.ClusterRuleCronJobHealthSpec{
+			Notification: notification,
+		},
+	}
+
+	r := &CronJobHealthRule{resource: merlinv1beta1Rule}
+	assert.Equal(t, merlinv1beta1Rule.ObjectMeta, r.GetObjectMeta())
+	assert.Equal(t, merlinv1beta1Rule, r.GetObject())
+	assert.Equal(t, notification, r.GetNotification())
+	assert.Equal(t, "ClusterRuleCronJobHealth/test-r", r.GetName())
+	assert.Equal(t, 5*time.Minute, r.GetRecheckInterval())
+
+	finalizer := "test.finalizer"
+	r.SetFinalizer(finalizer)
+	assert.Equal(t, finalizer, r.resource.Finalizers[0])
+	r.RemoveFinalizer(finalizer)
+	assert.Empty(t, r.resource.Finalizers)
+}
+
+func Test_CronJobHealthRule_Evaluate(t *testing.T) {
+	ctx := context.Background()
+	log := zapr.NewLogger(zap.L())
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mocks.NewMockClient(mockCtrl)
+	now := time.Now().Truncate(time.Second)
+	createdAt := now.Add(-5 * time.Hour)
+	noHistory := newTestCronJob("0 * * * *", createdAt, false)
+	historyLimit := int32(0)
+	noHistory.Spec.SuccessfulJobsHistoryLimit = &historyLimit
+
+	cases := []struct {
+		desc      string
+		suspended map[string]time.Time
+		mockCalls []*gomock.Call
+		resource  interface{}
+		expect    alert.Alert
+		expectErr bool
+	}{
+		{
+			desc:      "non cronjob or job should have error",
+			resource:  &corev1.Pod{},
+			expectErr: true,
+		},
+		{
+			desc:     "ignored namespace should not get violated alert",
+			resource: &batchv1beta1.CronJob{ObjectMeta: metav1.ObjectMeta{Namespace: "ignoredNS", Name: "backup"}},
+			expect: alert.Alert{
+				Message:      "namespace is ignored by the rule",
+				ResourceKind: "CronJob",
+				ResourceName: "ignoredNS/backup",
+			},
+		},
+		{
+			desc: "cronjob completed recently should not get violated alert",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().
+					List(ctx, &batchv1.JobList{}, &client.ListOptions{Namespace: "test"}).
+					SetArg(1, batchv1.JobList{Items: []batchv1.Job{
+						newTestCronJobJob("backup-1", now.Add(-3*time.Hour)),
+						newTestCronJobJob("backup-2", now.Add(-30*time.Minute)),
+					}}).
+					Return(nil),
+			},
+			resource: newTestCronJob("0 * * * *", createdAt, false),
+			expect: alert.Alert{
+				Message:      "CronJob has completed successfully within 3 schedules, last successful run completed at " + now.Add(-30*time.Minute).UTC().Format(time.RFC3339),
+				ResourceKind: "CronJob",
+				ResourceName: "test/backup",
+			},
+		},
+		{
+			desc: "cronjob not completed for too many schedules should get violated alert",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().
+					List(ctx, &batchv1.JobList{}, &client.ListOptions{Namespace: "test"}).
+					SetArg(1, batchv1.JobList{Items: []batchv1.Job{newTestCronJobJob("backup-1", now.Add(-4*time.Hour))}}).
+					Return(nil),
+			},
+			resource: newTestCronJob("0 * * * *", createdAt, false),
+			expect: alert.Alert{
+				Message:      "CronJob has not completed successfully for 3 schedules, last successful run completed at " + now.Add(-4*time.Hour).UTC().Format(time.RFC3339),
+				ResourceKind: "CronJob",
+				ResourceName: "test/backup",
+				Violated:     true,
+			},
+		},
+		{
+			desc: "cronjob never completed should get violated alert",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().
+					List(ctx, &batchv1.JobList{}, &client.ListOptions{Namespace: "test"}).
+					Return(nil),
+			},
+			resource: newTestCronJob("0 * * * *", createdAt, false),
+			expect: alert.Alert{
+				Message:      "CronJob has not completed successfully for 3 schedules, no successful run since created at " + createdAt.UTC().Format(time.RFC3339),
+				ResourceKind: "CronJob",
+				ResourceName: "test/backup",
+				Violated:     true,
+			},
+		},
+		{
+			desc: "cronjob keeping no successful jobs should not get violated alert",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().
+					List(ctx, &batchv1.JobList{}, &client.ListOptions{Namespace: "test"}).
+					Return(nil),
+			},
+			resource: noHistory,
+			expect: alert.Alert{
+				Message:      "CronJob keeps no successful jobs to check its runs",
+				ResourceKind: "CronJob",
+				ResourceName: "test/backup",
+			},
+		},
+		{
+			desc: "cronjob with invalid schedule should get violated alert",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().
+					List(ctx, &batchv1.JobList{}, &client.ListOptions{Namespace: "test"}).
+					Return(nil),
+			},
+			resource: newTestCronJob("every hour", createdAt, false),
+			expect: alert.Alert{
+				Message:      "CronJob has invalid schedule `every hour`: expected exactly 5 fields, found 2: [every hour]",
+				ResourceKind: "CronJob",
+				ResourceName: "test/backup",
+				Violated:     true,
+			},
+		},
+		{
+			desc:      "cronjob suspended for too long should get violated alert",
+			suspended: map[string]time.Time{"test/backup": time.Now().Add(-48 * time.Hour)},
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().
+					List(ctx, &batchv1.JobList{}, &client.ListOptions{Namespace: "test"}).
+					Return(nil),
+			},
+			resource: newTestCronJob("0 * * * *", createdAt, true),
+			expect: alert.Alert{
+				Message:      "CronJob is suspended for 48h0m0s",
+				ResourceKind: "CronJob",
+				ResourceName: "test/backup",
+				Violated:     true,
+			},
+		},
+		{
+			desc: "job exceeded backoff limit should get violated alert",
+			resource: &batchv1.Job{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "migrate"},
+				Status: batchv1.JobStatus{Conditions: []batchv1.JobCondition{{
+					Type:    batchv1.JobFailed,
+					Status:  corev1.ConditionTrue,
+					Reason:  "BackoffLimitExceeded",
+					Message: "Job has reached the specified backoff limit",
+				}}},
+			},
+			expect: alert.Alert{
+				Message:      "Job failed: BackoffLimitExceeded, Job has reached the specified backoff limit",
+				ResourceKind: "Job",
+				ResourceName: "test/migrate",
+				Violated:     true,
+			},
+		},
+		{
+			desc:     "running job should not get violated alert",
+			resource: &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "migrate"}},
+			expect: alert.Alert{
+				Message:      "Job has not failed",
+				ResourceKind: "Job",
+				ResourceName: "test/migrate",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(tt *testing.T) {
+			for _, call := range tc.mockCalls {
+				call.Times(1)
+			}
+			status := &Status{}
+			for key, since := range tc.suspended {
+				status.setPending(key, true, since)
+			}
+			r := &CronJobHealthRule{
+				rule: rule{cli: mockClient, log: log, status: status},
+				resource: &merlinv1beta1.ClusterRuleCronJobHealth{
+					Spec: merlinv1beta1.ClusterRuleCronJobHealthSpec{IgnoreNamespaces: []string{"ignoredNS"}},
+				},
+			}
+			a, err := r.Evaluate(ctx, tc.resource)
+			if tc.expectErr {
+				assert.Error(tt, err)
+			} else {
+				assert.NoError(tt, err)
+				assert.Equal(tt, tc.expect, a)
+			}
+		})
+	}
+}
+
+func Test_CronJobHealthRule_EvaluateAll(t *testing.T) {
+	ctx := context.Background()
+	log := zapr.NewLogger(zap.L())
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mocks.NewMockClient(mockCtrl)
+	r := &CronJobHealthRule{
+		rule:     rule{cli: mockClient, log: log, status: &Status{}},
+		resource: &merlinv1beta1.ClusterRuleCronJobHealth{},
+	}
+
+	cases := []struct {
+		desc      string
+		mockCalls []*gomock.Call
+		expect    []alert.Alert
+		expectErr bool
+	}{
+		{
+			desc: "no resources should returns nil alerts",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().
+					List(ctx, &batchv1beta1.CronJobList{}).
+					Return(nil),
+				mockClient.EXPECT().
+					List(ctx, &batchv1.JobList{}).
+					Return(nil),
+			},
+		},
+		{
+			desc: "cronjobs and jobs should be evaluated",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().
+					List(ctx, &batchv1beta1.CronJobList{}).
+					SetArg(1, batchv1beta1.CronJobList{Items: []batchv1beta1.CronJob{
+						*newTestCronJob("@daily", time.Now().Add(-time.Hour), true),
+					}}).
+					Return(nil),
+				mockClient.EXPECT().
+					List(ctx, &batchv1.JobList{}).
+					SetArg(1, batchv1.JobList{Items: []batchv1.Job{newTestCronJobJob("backup-1", time.Now())}}).
+					Return(nil),
+			},
+			expect: []alert.Alert{
+				{
+					Message:      "CronJob is suspended for 0s",
+					ResourceKind: "CronJob",
+					ResourceName: "test/backup",
+				},
+				{
+					Message:      "Job has not failed",
+					ResourceKind: "Job",
+					ResourceName: "test/backup-1",
+				},
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.desc, func(tt *testing.T) {
+			for _, call := range tc.mockCalls {
+				call.Times(1)
+			}
+			alerts, err := r.EvaluateAll(ctx)
+			if tc.expectErr {
+				assert.Error(tt, err)
+			} else {
+				assert.NoError(tt, err)
+				assert.Equal(tt, tc.expect, alerts)
+			}
+		})
+	}
+}
+
+func Test_CronJobHealthRule_RestoreViolations(t *testing.T) {
+	ctx := context.Background()
+	log := zapr.NewLogger(zap.L())
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mocks.NewMockClient(mockCtrl)
+	r := &CronJobHealthRule{
+		rule:     rule{cli: mockClient, log: log, status: &Status{}},
+		resource: &merlinv1beta1.ClusterRuleCronJobHealth{},
+	}
+	since := metav1.NewTime(time.Now().Add(-48 * time.Hour))
+
+	// CronJobs suspended before restarting are suspended since the persisted first seen time
+	r.RestoreViolations(merlinv1beta1.RuleStatus{ViolationStates: []merlinv1beta1.ViolationState{
+		{Key: "test/backup", FirstSeen: since, LastSeen: since},
+		{Key: "test/backup", FirstSeen: since, LastSeen: since, Pending: true},
+	}})
+	mockClient.EXPECT().
+		List(ctx, &batchv1.JobList{}, &client.ListOptions{Namespace: "test"}).
+		Return(nil).
+		Times(1)
+	a, err := r.Evaluate(ctx, newTestCronJob("0 * * * *", since.Time, true))
+	assert.NoError(t, err)
+	assert.Equal(t, alert.Alert{
+		Message:      "CronJob is suspended for 48h0m0s",
+		ResourceKind: "CronJob",
+		ResourceName: "test/backup",
+		Violated:     true,
+	}, a)
+}
@@ -0,0 +1,289 @@
+package rules
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	networkingv1 "k8s.io/api/networking/v1"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/mercari/merlin/alert"
+	merlinv1beta1 "github.com/mercari/merlin/api/v1beta1"
+)
+
+const lastAppliedConfigAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// kubeVersion is the major and minor version of Kubernetes
+type kubeVersion struct {
+	major int
+	minor int
+}
+
+func (v kubeVersion) String() string {
+	return fmt.Sprintf("v%d.%d", v.major, v.minor)
+}
+
+func (v kubeVersion) atMost(target kubeVersion) bool {
+	return v.major < target.major || (v.major == target.major && v.minor <= target.minor)
+}
+
+// parseKubeVersion parses versions like "1.22", "v1.22" or "v1.22.3", the patch version is ignored
+func parseKubeVersion(version string) (v kubeVersion, err error) {
+	parts := strings.Split(strings.TrimPrefix(version, "v"), ".")
+	if len(parts) < 2 || len(parts) > 3 {
+		return v, fmt.Errorf("invalid kubernetes version `%s`, expect format like `1.22`", version)
+	}
+	if v.major, err = strconv.Atoi(parts[0]); err != nil {
+		return v, fmt.Errorf("invalid kubernetes version `%s`: %w", version, err)
+	}
+	if v.minor, err = strconv.Atoi(parts[1]); err != nil {
+		return v, fmt.Errorf("invalid kubernetes version `%s`: %w", version, err)
+	}
+	return
+}
+
+// apiDeprecation is a deprecated API version of a kind, replacement is empty if the kind is removed without replacement
+type apiDeprecation struct {
+	apiVersion   string
+	kind         string
+	replacement  string
+	deprecatedIn kubeVersion
+	removedIn    kubeVersion
+}
+
+// apiDeprecations is the table of deprecated APIs, from https://kubernetes.io/docs/reference/using-api/deprecation-guide/
+var apiDeprecations = []apiDeprecation{
+	{"extensions/v1beta1", "Deployment", "apps/v1", kubeVersion{1, 9}, kubeVersion{1, 16}},
+	{"apps/v1beta1", "Deployment", "apps/v1", kubeVersion{1, 9}, kubeVersion{1, 16}},
+	{"apps/v1beta2", "Deployment", "apps/v1", kubeVersion{1, 9}, kubeVersion{1, 16}},
+	{"apps/v1beta1", "StatefulSet", "apps/v1", kubeVersion{1, 9}, kubeVersion{1, 16}},
+	{"apps/v1beta2", "StatefulSet", "apps/v1", kubeVersion{1, 9}, kubeVersion{1, 16}},
+	{"extensions/v1beta1", "DaemonSet", "apps/v1", kubeVersion{1, 9}, kubeVersion{1, 16}},
+	{"apps/v1beta2", "DaemonSet", "apps/v1", kubeVersion{1, 9}, kubeVersion{1, 16}},
+	{"extensions/v1beta1", "ReplicaSet", "apps/v1", kubeVersion{1, 9}, kubeVersion{1, 16}},
+	{"apps/v1beta1", "ReplicaSet", "apps/v1", kubeVersion{1, 9}, kubeVersion{1, 16}},
+	{"apps/v1beta2", "ReplicaSet", "apps/v1", kubeVersion{1, 9}, kubeVersion{1, 16}},
+	{"extensions/v1beta1", "NetworkPolicy", "networking.k8s.io/v1", kubeVersion{1, 9}, kubeVersion{1, 16}},
+	{"extensions/v1beta1", "PodSecurityPolicy", "policy/v1beta1", kubeVersion{1, 10}, kubeVersion{1, 16}},
+	{"extensions/v1beta1", "Ingress", "networking.k8s.io/v1", kubeVersion{1, 14}, kubeVersion{1, 22}},
+	{"networking.k8s.io/v1beta1", "Ingress", "networking.k8s.io/v1", kubeVersion{1, 19}, kubeVersion{1, 22}},
+	{"networking.k8s.io/v1beta1", "IngressClass", "networking.k8s.io/v1", kubeVersion{1, 19}, kubeVersion{1, 22}},
+	{"rbac.authorization.k8s.io/v1beta1", "Role", "rbac.authorization.k8s.io/v1", kubeVersion{1, 17}, kubeVersion{1, 22}},
+	{"rbac.authorization.k8s.io/v1beta1", "ClusterRole", "rbac.authorization.k8s.io/v1", kubeVersion{1, 17}, kubeVersion{1, 22}},
+	{"rbac.authorization.k8s.io/v1beta1", "RoleBinding", "rbac.authorization.k8s.io/v1", kubeVersion{1, 17}, kubeVersion{1, 22}},
+	{"rbac.authorization.k8s.io/v1beta1", "ClusterRoleBinding", "rbac.authorization.k8s.io/v1", kubeVersion{1, 17}, kubeVersion{1, 22}},
+	{"apiextensions.k8s.io/v1beta1", "CustomResourceDefinition", "apiextensions.k8s.io/v1", kubeVersion{1, 16}, kubeVersion{1, 22}},
+	{"admissionregistration.k8s.io/v1beta1", "MutatingWebhookConfiguration", "admissionregistration.k8s.io/v1", kubeVersion{1, 16}, kubeVersion{1, 22}},
+	{"admissionregistration.k8s.io/v1beta1", "ValidatingWebhookConfiguration", "admissionregistration.k8s.io/v1", kubeVersion{1, 16}, kubeVersion{1, 22}},
+	{"scheduling.k8s.io/v1beta1", "PriorityClass", "scheduling.k8s.io/v1", kubeVersion{1, 14}, kubeVersion{1, 22}},
+	{"storage.k8s.io/v1beta1", "StorageClass", "storage.k8s.io/v1", kubeVersion{1, 19}, kubeVersion{1, 22}},
+	{"storage.k8s.io/v1beta1", "CSIDriver", "storage.k8s.io/v1", kubeVersion{1, 19}, kubeVersion{1, 22}},
+	{"storage.k8s.io/v1beta1", "CSINode", "storage.k8s.io/v1", kubeVersion{1, 17}, kubeVersion{1, 22}},
+	{"storage.k8s.io/v1beta1", "VolumeAttachment", "storage.k8s.io/v1", kubeVersion{1, 19}, kubeVersion{1, 22}},
+	{"certificates.k8s.io/v1beta1", "CertificateSigningRequest", "certificates.k8s.io/v1", kubeVersion{1, 19}, kubeVersion{1, 22}},
+	{"coordination.k8s.io/v1beta1", "Lease", "coordination.k8s.io/v1", kubeVersion{1, 14}, kubeVersion{1, 22}},
+	{"apiregistration.k8s.io/v1beta1", "APIService", "apiregistration.k8s.io/v1", kubeVersion{1, 19}, kubeVersion{1, 22}},
+	{"batch/v1beta1", "CronJob", "batch/v1", kubeVersion{1, 21}, kubeVersion{1, 25}},
+	{"policy/v1beta1", "PodDisruptionBudget", "policy/v1", kubeVersion{1, 21}, kubeVersion{1, 25}},
+	{"policy/v1beta1", "PodSecurityPolicy", "", kubeVersion{1, 21}, kubeVersion{1, 25}},
+	{"discovery.k8s.io/v1beta1", "EndpointSlice", "discovery.k8s.io/v1", kubeVersion{1, 21}, kubeVersion{1, 25}},
+	{"events.k8s.io/v1beta1", "Event", "events.k8s.io/v1", kubeVersion{1, 19}, kubeVersion{1, 25}},
+	{"autoscaling/v2beta1", "HorizontalPodAutoscaler", "autoscaling/v2", kubeVersion{1, 22}, kubeVersion{1, 25}},
+	{"node.k8s.io/v1beta1", "RuntimeClass", "node.k8s.io/v1", kubeVersion{1, 20}, kubeVersion{1, 25}},
+	{"autoscaling/v2beta2", "HorizontalPodAutoscaler", "autoscaling/v2", kubeVersion{1, 23}, kubeVersion{1, 26}},
+	{"flowcontrol.apiserver.k8s.io/v1beta1", "FlowSchema", "flowcontrol.apiserver.k8s.io/v1beta3", kubeVersion{1, 23}, kubeVersion{1, 26}},
+	{"flowcontrol.apiserver.k8s.io/v1beta1", "PriorityLevelConfiguration", "flowcontrol.apiserver.k8s.io/v1beta3", kubeVersion{1, 23}, kubeVersion{1, 26}},
+	{"storage.k8s.io/v1beta1", "CSIStorageCapacity", "storage.k8s.io/v1", kubeVersion{1, 24}, kubeVersion{1, 27}},
+	{"flowcontrol.apiserver.k8s.io/v1beta2", "FlowSchema", "flowcontrol.apiserver.k8s.io/v1", kubeVersion{1, 26}, kubeVersion{1, 29}},
+	{"flowcontrol.apiserver.k8s.io/v1beta2", "PriorityLevelConfiguration", "flowcontrol.apiserver.k8s.io/v1", kubeVersion{1, 26}, kubeVersion{1, 29}},
+	{"flowcontrol.apiserver.k8s.io/v1beta3", "FlowSchema", "flowcontrol.apiserver.k8s.io/v1", kubeVersion{1, 29}, kubeVersion{1, 32}},
+	{"flowcontrol.apiserver.k8s.io/v1beta3", "PriorityLevelConfiguration", "flowcontrol.apiserver.k8s.io/v1", kubeVersion{1, 29}, kubeVersion{1, 32}},
+}
+
+// findAPIDeprecation returns the deprecation of the api version and kind, nil if it's not deprecated
+func findAPIDeprecation(apiVersion, kind string) *apiDeprecation {
+	for i, d := range apiDeprecations {
+		if d.apiVersion == apiVersion && d.kind == kind {
+			return &apiDeprecations[i]
+		}
+	}
+	return nil
+}
+
+// deprecatedAPIListTypes are the lists of objects evaluated by EvaluateAll, same as the resources watched for the rule
+var deprecatedAPIListTypes = []func() runtime.Object{
+	func() runtime.Object { return &appsv1.DeploymentList{} },
+	func() runtime.Object { return &appsv1.StatefulSetList{} },
+	func() runtime.Object { return &batchv1beta1.CronJobList{} },
+	func() runtime.Object { return &networkingv1beta1.IngressList{} },
+	func() runtime.Object { return &networkingv1.NetworkPolicyList{} },
+	func() runtime.Object { return &autoscalingv1.HorizontalPodAutoscalerList{} },
+	func() runtime.Object { return &policyv1beta1.PodDisruptionBudgetList{} },
+	func() runtime.Object { return &rbacv1.RoleList{} },
+	func() runtime.Object { return &rbacv1.ClusterRoleList{} },
+	func() runtime.Object { return &rbacv1.RoleBindingList{} },
+	func() runtime.Object { return &rbacv1.ClusterRoleBindingList{} },
+}
+
+type DeprecatedAPIRule struct {
+	rule
+	resource *merlinv1beta1.ClusterRuleDeprecatedAPI
+}
+
+func (d *DeprecatedAPIRule) New(ctx context.Context, cli client.Client, logger logr.Logger, key client.ObjectKey) (Rule, error) {
+	d.cli = cli
+	d.log = logger
+	d.status = &Status{}
+	d.resource = &merlinv1beta1.ClusterRuleDeprecatedAPI{}
+	if err := d.cli.Get(ctx, key, d.resource); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (d *DeprecatedAPIRule) GetObject() runtime.Object {
+	return d.resource
+}
+
+func (d DeprecatedAPIRule) GetName() string {
+	return strings.Join([]string{getStructName(d.resource), d.resource.Name}, Separator)
+}
+
+func (d DeprecatedAPIRule) GetObjectMeta() metav1.ObjectMeta {
+	return d.resource.ObjectMeta
+}
+
+func (d DeprecatedAPIRule) GetNotification() merlinv1beta1.Notification {
+	return d.resource.Spec.Notification
+}
+
+func (d *DeprecatedAPIRule) SetFinalizer(finalizer string) {
+	d.resource.ObjectMeta.Finalizers = append(d.resource.ObjectMeta.Finalizers, finalizer)
+}
+
+func (d *DeprecatedAPIRule) RemoveFinalizer(finalizer string) {
+	d.resource.ObjectMeta.Finalizers = removeString(d.resource.ObjectMeta.Finalizers, finalizer)
+}
+
+func (d *DeprecatedAPIRule) EvaluateAll(ctx context.Context) (alerts []alert.Alert, err error) {
+	for _, newList := range deprecatedAPIListTypes {
+		list := newList()
+		if err = d.cli.List(ctx, list); err != nil {
+			return
+		}
+		var objects []runtime.Object
+		if objects, err = meta.ExtractList(list); err != nil {
+			return
+		}
+		for _, object := range objects {
+			var a alert.Alert
+			if a, err = d.Evaluate(ctx, object); err != nil {
+				return
+			}
+			alerts = append(alerts, a)
+		}
+	}
+	return
+}
+
+// Evaluate checks the api versions used to apply the object, from its last-applied-configuration annotation and managedFields.
+func (d *DeprecatedAPIRule) Evaluate(ctx context.Context, object interface{}) (a alert.Alert, err error) {
+	obj, ok := object.(metav1.Object)
+	if !ok {
+		err = fmt.Errorf("object being evaluated is not a kubernetes object: %T", object)
+		return
+	}
+	var target kubeVersion
+	if target, err = parseKubeVersion(d.resource.Spec.TargetVersion); err != nil {
+		return
+	}
+	d.log.V(1).Info("evaluating", fmt.Sprintf("%T", object), obj.GetName())
+
+	kind := getStructName(object)
+	key := client.ObjectKey{Namespace: obj.GetNamespace(), Name: obj.GetName()}
+	a = d.newAlert(object, key.String())
+	if obj.GetNamespace() != "" && isStringInSlice(d.resource.Spec.IgnoreNamespaces, obj.GetNamespace()) {
+		a.Message = "namespace is ignored by the rule"
+		return
+	}
+
+	var apiVersions []string
+	sources := map[string][]string{}
+	addSource := func(apiVersion, source string) {
+		if _, ok := sources[apiVersion]; !ok {
+			apiVersions = append(apiVersions, apiVersion)
+		}
+		sources[apiVersion] = append(sources[apiVersion], source)
+	}
+	if lastApplied, ok := obj.GetAnnotations()[lastAppliedConfigAnnotation]; ok {
+		typeMeta := metav1.TypeMeta{}
+		if err := json.Unmarshal([]byte(lastApplied), &typeMeta); err != nil {
+			d.log.Error(err, "failed to parse annotation", "annotation", lastAppliedConfigAnnotation, "object", a.ResourceName)
+		} else if typeMeta.APIVersion != "" {
+			addSource(typeMeta.APIVersion, "last-applied-configuration")
+		}
+	}
+	for _, field := range obj.GetManagedFields() {
+		if field.APIVersion != "" {
+			addSource(field.APIVersion, fmt.Sprintf("managedFields of '%s'", field.Manager))
+		}
+	}
+
+	var messages []string
+	for _, apiVersion := range apiVersions {
+		deprecation := findAPIDeprecation(apiVersion, kind)
+		if deprecation == nil || !deprecation.deprecatedIn.atMost(target) {
+			continue
+		}
+		message := fmt.Sprintf("%s (%s)", apiVersion, strings.Join(uniqueStrings(sources[apiVersion]), ", "))
+		if deprecation.removedIn.atMost(target) {
+			message += fmt.Sprintf(" is removed in %s", deprecation.removedIn)
+		} else {
+			message += fmt.Sprintf(" is deprecated in %s and will be removed in %s", deprecation.deprecatedIn, deprecation.removedIn)
+		}
+		if deprecation.replacement != "" {
+			message += fmt.Sprintf(", use %s instead", deprecation.replacement)
+		} else {
+			message += ", without replacement"
+		}
+		messages = append(messages, message)
+	}
+
+	if len(messages) > 0 {
+		a.Violated = true
+		a.Message = fmt.Sprintf("%s uses deprecated apiVersion %s", kind, strings.Join(messages, "; "))
+	} else {
+		a.Message = fmt.Sprintf("%s doesn't use apiVersion deprecated in %s", kind, target)
+	}
+	d.status.setViolation(key, a.Violated)
+	return
+}
+
+func (d *DeprecatedAPIRule) GetDelaySeconds(object interface{}) (time.Duration, error) {
+	return 0, nil
+}
+
+func (d *DeprecatedAPIRule) newAlert(object interface{}, resourceName string) alert.Alert {
+	return alert.Alert{
+		Suppressed:      d.resource.Spec.Notification.Suppressed,
+		Severity:        d.resource.Spec.Notification.Severity,
+		MessageTemplate: d.resource.Spec.Notification.CustomMessageTemplate,
+		ResourceName:    resourceName,
+		ResourceKind:    getStructName(object),
+		Violated:        false,
+	}
+}
@@ -0,0 +1,289 @@
+package rules
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/zapr"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	networkingv1 "k8s.io/api/networking/v1"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/mercari/merlin/alert"
+	merlinv1beta1 "github.com/mercari/merlin/api/v1beta1"
+	"github.com/mercari/merlin/mocks"
+)
+
+func Test_DeprecatedAPIRule_Basic(t *testing.T) {
+	notification := merlinv1beta1.Notification{
+		Notifiers:  []string{"testNotifier"},
+		Suppressed: true,
+	}
+
+	merlinv1beta1Rule := &merlinv1beta1.ClusterRuleDeprecatedAPI{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-r"},
+		Spec: merlinv1beta1.ClusterRuleDeprecatedAPISpec{
+			TargetVersion: "1.22",
+			Notification:  notification,
+		},
+	}
+
+	r := &DeprecatedAPIRule{resource: merlinv1beta1Rule}
+	assert.Equal(t, merlinv1beta1Rule.ObjectMeta, r.GetObjectMeta())
+	assert.Equal(t, merlinv1beta1Rule, r.GetObject())
+	assert.Equal(t, notification, r.GetNotification())
+	assert.Equal(t, "ClusterRuleDeprecatedAPI/test-r", r.GetName())
+
+	finalizer := "test.finalizer"
+	r.SetFinalizer(finalizer)
+	assert.Equal(t, finalizer, r.resource.Finalizers[0])
+	r.RemoveFinalizer(finalizer)
+	assert.Empty(t, r.resource.Finalizers)
+}
+
+func Test_parseKubeVersion(t *testing.T) {
+	cases := []struct {
+		version   string
+		expect    kubeVersion
+		expectErr bool
+	}{
+		{version: "1.22", expect: kubeVersion{1, 22}},
+		{version: "v1.16", expect: kubeVersion{1, 16}},
+		{version: "v1.25.3", expect: kubeVersion{1, 25}},
+		{version: "1", expectErr: true},
+		{version: "1.x", expectErr: true},
+		{version: "", expectErr: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.version, func(tt *testing.T) {
+			v, err := parseKubeVersion(tc.version)
+			if tc.expectErr {
+				assert.Error(tt, err)
+			} else {
+				assert.NoError(tt, err)
+				assert.Equal(tt, tc.expect, v)
+			}
+		})
+	}
+}
+
+func Test_DeprecatedAPIRule_Evaluate(t *testing.T) {
+	ctx := context.Background()
+	log := zapr.NewLogger(zap.L())
+	lastApplied := func(apiVersion, kind string) map[string]string {
+		return map[string]string{lastAppliedConfigAnnotation: `{"apiVersion":"` + apiVersion + `","kind":"` + kind + `","metadata":{"name":"test"}}`}
+	}
+
+	cases := []struct {
+		desc          string
+		targetVersion string
+		resource      interface{}
+		expect        alert.Alert
+		expectErr     bool
+	}{
+		{
+			desc:          "non kubernetes object should have error",
+			targetVersion: "1.22",
+			resource:      "test",
+			expectErr:     true,
+		},
+		{
+			desc:          "invalid target version should have error",
+			targetVersion: "latest",
+			resource:      &appsv1.Deployment{},
+			expectErr:     true,
+		},
+		{
+			desc:          "ignored namespace should not get violated alert",
+			targetVersion: "1.22",
+			resource: &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "ignoredNS",
+				Name:        "test",
+				Annotations: lastApplied("extensions/v1beta1", "Deployment"),
+			}},
+			expect: alert.Alert{
+				Message:      "namespace is ignored by the rule",
+				ResourceKind: "Deployment",
+				ResourceName: "ignoredNS/test",
+			},
+		},
+		{
+			desc:          "removed api in last-applied-configuration should get violated alert",
+			targetVersion: "1.22",
+			resource: &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "default",
+				Name:        "test",
+				Annotations: lastApplied("extensions/v1beta1", "Deployment"),
+				ManagedFields: []metav1.ManagedFieldsEntry{
+					{Manager: "kubectl", Operation: metav1.ManagedFieldsOperationUpdate, APIVersion: "extensions/v1beta1"},
+					{Manager: "kube-controller-manager", Operation: metav1.ManagedFieldsOperationUpdate, APIVersion: "apps/v1"},
+				},
+			}},
+			expect: alert.Alert{
+				Message:      "Deployment uses deprecated apiVersion extensions/v1beta1 (last-applied-configuration, managedFields of 'kubectl') is removed in v1.16, use apps/v1 instead",
+				ResourceKind: "Deployment",
+				ResourceName: "default/test",
+				Violated:     true,
+			},
+		},
+		{
+			desc:          "deprecated api in managedFields should get violated alert",
+			targetVersion: "v1.22.1",
+			resource: &batchv1beta1.CronJob{ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      "test",
+				ManagedFields: []metav1.ManagedFieldsEntry{
+					{Manager: "helm", Operation: metav1.ManagedFieldsOperationUpdate, APIVersion: "batch/v1beta1"},
+				},
+			}},
+			expect: alert.Alert{
+				Message:      "CronJob uses deprecated apiVersion batch/v1beta1 (managedFields of 'helm') is deprecated in v1.21 and will be removed in v1.25, use batch/v1 instead",
+				ResourceKind: "CronJob",
+				ResourceName: "default/test",
+				Violated:     true,
+			},
+		},
+		{
+			desc:          "api deprecated after target version should not get violated alert",
+			targetVersion: "1.20",
+			resource: &batchv1beta1.CronJob{ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "default",
+				Name:        "test",
+				Annotations: lastApplied("batch/v1beta1", "CronJob"),
+			}},
+			expect: alert.Alert{
+				Message:      "CronJob doesn't use apiVersion deprecated in v1.20",
+				ResourceKind: "CronJob",
+				ResourceName: "default/test",
+			},
+		},
+		{
+			desc:          "cluster scoped object with current api should not get violated alert",
+			targetVersion: "1.22",
+			resource: &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{
+				Name:        "test",
+				Annotations: lastApplied("rbac.authorization.k8s.io/v1", "ClusterRole"),
+			}},
+			expect: alert.Alert{
+				Message:      "ClusterRole doesn't use apiVersion deprecated in v1.22",
+				ResourceKind: "ClusterRole",
+				ResourceName: "/test",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(tt *testing.T) {
+			r := &DeprecatedAPIRule{
+				rule: rule{log: log, status: &Status{}},
+				resource: &merlinv1beta1.ClusterRuleDeprecatedAPI{
+					Spec: merlinv1beta1.ClusterRuleDeprecatedAPISpec{
+						IgnoreNamespaces: []string{"ignoredNS"},
+						TargetVersion:    tc.targetVersion,
+					},
+				},
+			}
+			a, err := r.Evaluate(ctx, tc.resource)
+			if tc.expectErr {
+				assert.Error(tt, err)
+			} else {
+				assert.NoError(tt, err)
+				assert.Equal(tt, tc.expect, a)
+			}
+		})
+	}
+}
+
+func Test_DeprecatedAPIRule_EvaluateAll(t *testing.T) {
+	ctx := context.Background()
+	log := zapr.NewLogger(zap.L())
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mocks.NewMockClient(mockCtrl)
+	r := &DeprecatedAPIRule{
+		rule: rule{cli: mockClient, log: log, status: &Status{}},
+		resource: &merlinv1beta1.ClusterRuleDeprecatedAPI{
+			Spec: merlinv1beta1.ClusterRuleDeprecatedAPISpec{TargetVersion: "1.25"},
+		},
+	}
+
+	cases := []struct {
+		desc      string
+		mockCalls []*gomock.Call
+		expect    []alert.Alert
+		expectErr bool
+	}{
+		{
+			desc: "list error should have error",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().List(ctx, &appsv1.DeploymentList{}).Return(assert.AnError),
+			},
+			expectErr: true,
+		},
+		{
+			desc: "objects of all kinds should be evaluated",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().List(ctx, &appsv1.DeploymentList{}).Return(nil),
+				mockClient.EXPECT().List(ctx, &appsv1.StatefulSetList{}).Return(nil),
+				mockClient.EXPECT().List(ctx, &batchv1beta1.CronJobList{}).Return(nil),
+				mockClient.EXPECT().List(ctx, &networkingv1beta1.IngressList{}).
+					SetArg(1, networkingv1beta1.IngressList{Items: []networkingv1beta1.Ingress{{
+						ObjectMeta: metav1.ObjectMeta{
+							Namespace: "default",
+							Name:      "test",
+							ManagedFields: []metav1.ManagedFieldsEntry{
+								{Manager: "kubectl", APIVersion: "networking.k8s.io/v1beta1"},
+							},
+						},
+					}}}).
+					Return(nil),
+				mockClient.EXPECT().List(ctx, &networkingv1.NetworkPolicyList{}).Return(nil),
+				mockClient.EXPECT().List(ctx, &autoscalingv1.HorizontalPodAutoscalerList{}).Return(nil),
+				mockClient.EXPECT().List(ctx, &policyv1beta1.PodDisruptionBudgetList{}).
+					SetArg(1, policyv1beta1.PodDisruptionBudgetList{Items: []policyv1beta1.PodDisruptionBudget{{
+						ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test"},
+					}}}).
+					Return(nil),
+				mockClient.EXPECT().List(ctx, &rbacv1.RoleList{}).Return(nil),
+				mockClient.EXPECT().List(ctx, &rbacv1.ClusterRoleList{}).Return(nil),
+				mockClient.EXPECT().List(ctx, &rbacv1.RoleBindingList{}).Return(nil),
+				mockClient.EXPECT().List(ctx, &rbacv1.ClusterRoleBindingList{}).Return(nil),
+			},
+			expect: []alert.Alert{
+				{
+					Message:      "Ingress uses deprecated apiVersion networking.k8s.io/v1beta1 (managedFields of 'kubectl') is removed in v1.22, use networking.k8s.io/v1 instead",
+					ResourceKind: "Ingress",
+					ResourceName: "default/test",
+					Violated:     true,
+				},
+				{
+					Message:      "PodDisruptionBudget doesn't use apiVersion deprecated in v1.25",
+					ResourceKind: "PodDisruptionBudget",
+					ResourceName: "default/test",
+				},
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.desc, func(tt *testing.T) {
+			for _, call := range tc.mockCalls {
+				call.Times(1)
+			}
+			alerts, err := r.EvaluateAll(ctx)
+			if tc.expectErr {
+				assert.Error(tt, err)
+			} else {
+				assert.NoError(tt, err)
+				assert.Equal(tt, tc.expect, alerts)
+			}
+		})
+	}
+}
@@ -0,0 +1,417 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/google/cel-go/cel"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	"github.com/mercari/merlin/alert"
+	merlinv1beta1 "github.com/mercari/merlin/api/v1beta1"
+)
+
+// expressionObjectVariable is the variable name of the evaluated object in expressions
+const expressionObjectVariable = "object"
+
+// messageInterpolationRegexp matches the expressions to interpolate in messages, e.g. `{{ object.spec.replicas }}`
+var messageInterpolationRegexp = regexp.MustCompile(`{{(.*?)}}`)
+
+// expressionEvaluator evaluates the CEL expression and message of a rule against objects as unstructured data
+type expressionEvaluator struct {
+	expression string
+	program    cel.Program
+	message    string
+	// messagePrograms are the programs of the expressions to interpolate in the message, in the order of matches
+	messagePrograms []cel.Program
+}
+
+func newExpressionEvaluator(expression, message string) (*expressionEvaluator, error) {
+	env, err := cel.NewEnv(cel.Variable(expressionObjectVariable, cel.MapType(cel.StringType, cel.DynType)))
+	if err != nil {
+		return nil, err
+	}
+	e := &expressionEvaluator{expression: expression, message: message}
+	if e.program, err = compileExpression(env, expression); err != nil {
+		return nil, fmt.Errorf("invalid expression `%s`: %w", expression, err)
+	}
+	for _, match := range messageInterpolationRegexp.FindAllStringSubmatch(message, -1) {
+		program, err := compileExpression(env, strings.TrimSpace(match[1]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid expression `%s` in message: %w", match[1], err)
+		}
+		e.messagePrograms = append(e.messagePrograms, program)
+	}
+	return e, nil
+}
+
+// ValidateExpression returns error if the expression or the expressions in message can't be compiled.
+func ValidateExpression(expression, message string) error {
+	_, err := newExpressionEvaluator(expression, message)
+	return err
+}
+
+func compileExpression(env *cel.Env, expression string) (cel.Program, error) {
+	ast, issues := env.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, issues.Err()
+	}
+	return env.Program(ast)
+}
+
+// evaluate returns if the object satisfies the expression
+func (e *expressionEvaluator) evaluate(object map[string]interface{}) (bool, error) {
+	out, _, err := e.program.Eval(map[string]interface{}{expressionObjectVariable: object})
+	if err != nil {
+		return false, err
+	}
+	satisfied, ok := out.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("expression returns %T instead of bool", out.Value())
+	}
+	return satisfied, nil
+}
+
+// formatMessage returns the message with the interpolated values, errors of the expressions are used as their values.
+func (e *expressionEvaluator) formatMessage(object map[string]interface{}) string {
+	if e.message == "" {
+		return fmt.Sprintf("doesn't satisfy the expression `%s`", e.expression)
+	}
+	i := 0
+	return messageInterpolationRegexp.ReplaceAllStringFunc(e.message, func(string) string {
+		program := e.messagePrograms[i]
+		i++
+		out, _, err := program.Eval(map[string]interface{}{expressionObjectVariable: object})
+		if err != nil {
+			return fmt.Sprintf("<%s>", err)
+		}
+		return fmt.Sprint(out.Value())
+	})
+}
+
+// evaluateObject evaluates the unstructured object and sets the result to the alert, errors of the expression are violations
+// since the object can't be checked.
+func (e *expressionEvaluator) evaluateObject(a alert.Alert, object map[string]interface{}) alert.Alert {
+	satisfied, err := e.evaluate(object)
+	if err != nil {
+		a.Violated = true
+		a.Message = fmt.Sprintf("failed to evaluate the expression `%s`: %s", e.expression, err)
+	} else if !satisfied {
+		a.Violated = true
+		a.Message = e.formatMessage(object)
+	} else {
+		a.Message = fmt.Sprintf("satisfies the expression `%s`", e.expression)
+	}
+	return a
+}
+
+// toUnstructuredObject returns the object as unstructured data with its group, version and kind,
+// typed objects are converted so the expressions see the same fields as the ones from the API server.
+func toUnstructuredObject(object interface{}) (obj metav1.Object, content map[string]interface{}, gvk schema.GroupVersionKind, err error) {
+	runtimeObj, ok := object.(runtime.Object)
+	if !ok {
+		err = fmt.Errorf("object being evaluated is not a kubernetes object: %T", object)
+		return
+	}
+	if obj, ok = object.(metav1.Object); !ok {
+		err = fmt.Errorf("object being evaluated is not a kubernetes object: %T", object)
+		return
+	}
+	if gvk, err = apiutil.GVKForObject(runtimeObj, scheme.Scheme); err != nil {
+		return
+	}
+	if u, ok := object.(*unstructured.Unstructured); ok {
+		content = u.Object
+		return
+	}
+	if content, err = runtime.DefaultUnstructuredConverter.ToUnstructured(object); err != nil {
+		return
+	}
+	content["apiVersion"], content["kind"] = gvk.GroupVersion().String(), gvk.Kind
+	return
+}
+
+// isObjectSelected checks if the object has the name and labels of the selector
+func isObjectSelected(s merlinv1beta1.Selector, obj metav1.Object) bool {
+	if s.Name != "" && s.Name != obj.GetName() {
+		return false
+	}
+	return labels.SelectorFromSet(s.MatchLabels).Matches(labels.Set(obj.GetLabels()))
+}
+
+// isKindMatched checks if the object is the target kind, versions of the same kind are the same object.
+func isKindMatched(target metav1.GroupVersionKind, gvk schema.GroupVersionKind) bool {
+	return target.Group == gvk.Group && target.Kind == gvk.Kind
+}
+
+// listTargetKind lists the objects of the target kind as unstructured
+func listTargetKind(ctx context.Context, cli client.Client, target metav1.GroupVersionKind, opts *client.ListOptions) (*unstructured.UnstructuredList, error) {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(schema.GroupVersionKind{Group: target.Group, Version: target.Version, Kind: target.Kind + "List"})
+	if err := cli.List(ctx, list, opts); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+type ExpressionRule struct{}
+
+func (e *ExpressionRule) New(ctx context.Context, cli client.Client, logger logr.Logger, key client.ObjectKey) (Rule, error) {
+	var r Rule
+	if key.Namespace == "" {
+		resource := &merlinv1beta1.ClusterRuleExpression{}
+		if err := cli.Get(ctx, key, resource); err != nil {
+			return nil, err
+		}
+		evaluator, err := newExpressionEvaluator(resource.Spec.Expression, resource.Spec.Message)
+		r = &expressionClusterRule{
+			resource:  resource,
+			evaluator: evaluator,
+			rule:      rule{cli: cli, log: logger, status: &Status{}},
+		}
+		if err != nil {
+			return r, &InvalidSpecError{Err: err}
+		}
+	} else {
+		resource := &merlinv1beta1.RuleExpression{}
+		if err := cli.Get(ctx, key, resource); err != nil {
+			return nil, err
+		}
+		evaluator, err := newExpressionEvaluator(resource.Spec.Expression, resource.Spec.Message)
+		r = &expressionNamespaceRule{
+			resource:  resource,
+			evaluator: evaluator,
+			rule:      rule{cli: cli, log: logger, status: &Status{}},
+		}
+		if err != nil {
+			return r, &InvalidSpecError{Err: err}
+		}
+	}
+	return r, nil
+}
+
+type expressionClusterRule struct {
+	rule
+	resource  *merlinv1beta1.ClusterRuleExpression
+	evaluator *expressionEvaluator
+}
+
+func (e *expressionClusterRule) GetObject() runtime.Object {
+	return e.resource
+}
+
+func (e expressionClusterRule) GetName() string {
+	return strings.Join([]string{getStructName(e.resource), e.resource.Name}, Separator)
+}
+
+func (e expressionClusterRule) GetObjectMeta() metav1.ObjectMeta {
+	return e.resource.ObjectMeta
+}
+
+func (e expressionClusterRule) GetNotification() merlinv1beta1.Notification {
+	return e.resource.Spec.Notification
+}
+
+func (e expressionClusterRule) GetEnforcement() merlinv1beta1.Enforcement {
+	return e.resource.Spec.Enforcement
+}
+
+func (e *expressionClusterRule) SetFinalizer(finalizer string) {
+	e.resource.ObjectMeta.Finalizers = append(e.resource.ObjectMeta.Finalizers, finalizer)
+}
+
+func (e *expressionClusterRule) RemoveFinalizer(finalizer string) {
+	e.resource.ObjectMeta.Finalizers = removeString(e.resource.ObjectMeta.Finalizers, finalizer)
+}
+
+func (e *expressionClusterRule) GetTargetKinds() []schema.GroupVersionKind {
+	return []schema.GroupVersionKind{schema.GroupVersionKind(e.resource.Spec.TargetKind)}
+}
+
+func (e *expressionClusterRule) EvaluateAll(ctx context.Context) (alerts []alert.Alert, err error) {
+	list, err := listTargetKind(ctx, e.cli, e.resource.Spec.TargetKind, getListOptions(e.resource.Spec.Selector, ""))
+	if err != nil {
+		return
+	}
+
+	if len(list.Items) == 0 {
+		e.log.Info("no object found", "kind", e.resource.Spec.TargetKind.String())
+		return
+	}
+	for _, obj := range list.Items {
+		var a alert.Alert
+		if a, err = e.Evaluate(ctx, &obj); err != nil {
+			return
+		}
+		alerts = append(alerts, a)
+	}
+	return
+}
+
+func (e *expressionClusterRule) Evaluate(ctx context.Context, object interface{}) (alert.Alert, error) {
+	return e.evaluate(object, e.status)
+}
+
+func (e *expressionClusterRule) EvaluateAdmission(ctx context.Context, object interface{}) (alert.Alert, error) {
+	return e.evaluate(object, nil)
+}
+
+func (e *expressionClusterRule) IsEnforcedKind(gvk schema.GroupVersionKind) bool {
+	return isKindMatched(e.resource.Spec.TargetKind, gvk)
+}
+
+// evaluate evaluates the object and sets its violation to the status, nil status for evaluations at admission
+func (e *expressionClusterRule) evaluate(object interface{}, status *Status) (a alert.Alert, err error) {
+	obj, content, gvk, err := toUnstructuredObject(object)
+	if err != nil {
+		return
+	}
+	e.log.V(1).Info("evaluating", gvk.Kind, obj.GetName())
+	key := client.ObjectKey{Namespace: obj.GetNamespace(), Name: obj.GetName()}
+	a = alert.Alert{
+		Suppressed:      e.resource.Spec.Notification.Suppressed,
+		Severity:        e.resource.Spec.Notification.Severity,
+		MessageTemplate: e.resource.Spec.Notification.CustomMessageTemplate,
+		ResourceName:    key.String(),
+		ResourceKind:    gvk.Kind,
+		Violated:        false,
+	}
+	if !isKindMatched(e.resource.Spec.TargetKind, gvk) || !isObjectSelected(e.resource.Spec.Selector, obj) {
+		a.Message = "object is not selected by the rule"
+		return
+	}
+	if isStringInSlice(e.resource.Spec.IgnoreNamespaces, key.Namespace) {
+		a.Message = "namespace is ignored by the rule"
+		return
+	}
+	a = e.evaluator.evaluateObject(a, content)
+	if status != nil {
+		status.setViolation(key, a.Violated)
+	}
+	return
+}
+
+func (e *expressionClusterRule) GetDelaySeconds(object interface{}) (time.Duration, error) {
+	return 0, nil
+}
+
+type expressionNamespaceRule struct {
+	rule
+	resource  *merlinv1beta1.RuleExpression
+	evaluator *expressionEvaluator
+}
+
+func (e *expressionNamespaceRule) GetObject() runtime.Object {
+	return e.resource
+}
+
+func (e expressionNamespaceRule) GetName() string {
+	return strings.Join([]string{getStructName(e.resource), e.resource.Name}, Separator)
+}
+
+func (e expressionNamespaceRule) GetObjectMeta() metav1.ObjectMeta {
+	return e.resource.ObjectMeta
+}
+
+func (e expressionNamespaceRule) GetNotification() merlinv1beta1.Notification {
+	return e.resource.Spec.Notification
+}
+
+func (e expressionNamespaceRule) GetEnforcement() merlinv1beta1.Enforcement {
+	return e.resource.Spec.Enforcement
+}
+
+func (e *expressionNamespaceRule) SetFinalizer(finalizer string) {
+	e.resource.ObjectMeta.Finalizers = append(e.resource.ObjectMeta.Finalizers, finalizer)
+}
+
+func (e *expressionNamespaceRule) RemoveFinalizer(finalizer string) {
+	e.resource.ObjectMeta.Finalizers = removeString(e.resource.ObjectMeta.Finalizers, finalizer)
+}
+
+func (e *expressionNamespaceRule) GetTargetKinds() []schema.GroupVersionKind {
+	return []schema.GroupVersionKind{schema.GroupVersionKind(e.resource.Spec.TargetKind)}
+}
+
+func (e *expressionNamespaceRule) EvaluateAll(ctx context.Context) (alerts []alert.Alert, err error) {
+	list, err := listTargetKind(ctx, e.cli, e.resource.Spec.TargetKind, getListOptions(e.resource.Spec.Selector, e.resource.Namespace))
+	if err != nil {
+		return
+	}
+
+	if len(list.Items) == 0 {
+		e.log.Info("no object found", "kind", e.resource.Spec.TargetKind.String())
+		return
+	}
+	for _, obj := range list.Items {
+		var a alert.Alert
+		if a, err = e.Evaluate(ctx, &obj); err != nil {
+			return
+		}
+		alerts = append(alerts, a)
+	}
+	return
+}
+
+func (e *expressionNamespaceRule) Evaluate(ctx context.Context, object interface{}) (alert.Alert, error) {
+	return e.evaluate(object, e.status)
+}
+
+func (e *expressionNamespaceRule) EvaluateAdmission(ctx context.Context, object interface{}) (alert.Alert, error) {
+	return e.evaluate(object, nil)
+}
+
+// Overrides returns if the cluster rule has the same name and target kind, so other ClusterRuleExpressions still
+// apply to the namespace.
+func (e *expressionNamespaceRule) Overrides(clusterRule Rule) bool {
+	c, ok := clusterRule.(*expressionClusterRule)
+	return ok && c.resource.Name == e.resource.Name &&
+		c.resource.Spec.TargetKind.Group == e.resource.Spec.TargetKind.Group &&
+		c.resource.Spec.TargetKind.Kind == e.resource.Spec.TargetKind.Kind
+}
+
+func (e *expressionNamespaceRule) IsEnforcedKind(gvk schema.GroupVersionKind) bool {
+	return isKindMatched(e.resource.Spec.TargetKind, gvk)
+}
+
+// evaluate evaluates the object and sets its violation to the status, nil status for evaluations at admission
+func (e *expressionNamespaceRule) evaluate(object interface{}, status *Status) (a alert.Alert, err error) {
+	obj, content, gvk, err := toUnstructuredObject(object)
+	if err != nil {
+		return
+	}
+	e.log.V(1).Info("evaluating", gvk.Kind, obj.GetName())
+	key := client.ObjectKey{Namespace: obj.GetNamespace(), Name: obj.GetName()}
+	a = alert.Alert{
+		Suppressed:      e.resource.Spec.Notification.Suppressed,
+		Severity:        e.resource.Spec.Notification.Severity,
+		MessageTemplate: e.resource.Spec.Notification.CustomMessageTemplate,
+		ResourceName:    key.String(),
+		ResourceKind:    gvk.Kind,
+		Violated:        false,
+	}
+	if !isKindMatched(e.resource.Spec.TargetKind, gvk) || key.Namespace != e.resource.Namespace || !isObjectSelected(e.resource.Spec.Selector, obj) {
+		a.Message = "object is not selected by the rule"
+		return
+	}
+	a = e.evaluator.evaluateObject(a, content)
+	if status != nil {
+		status.setViolation(key, a.Violated)
+	}
+	return
+}
+
+func (e *expressionNamespaceRule) GetDelaySeconds(object interface{}) (time.Duration, error) {
+	return 0, nil
+}
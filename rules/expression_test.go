@@ -0,0 +1,365 @@
+package rules
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/zapr"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/pointer"
+
+	"github.com/mercari/merlin/alert"
+	merlinv1beta1 "github.com/mercari/merlin/api/v1beta1"
+	"github.com/mercari/merlin/mocks"
+)
+
+func Test_ExpressionRule_Basic(t *testing.T) {
+	notification := merlinv1beta1.Notification{
+		Notifiers:  []string{"testNotifier"},
+		Suppressed: true,
+	}
+	targetKind := metav1.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}
+
+	clusterRule := &merlinv1beta1.ClusterRuleExpression{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-r"},
+		Spec: merlinv1beta1.ClusterRuleExpressionSpec{
+			TargetKind:   targetKind,
+			Notification: notification,
+		},
+	}
+	r := &expressionClusterRule{resource: clusterRule}
+	assert.Equal(t, clusterRule.ObjectMeta, r.GetObjectMeta())
+	assert.Equal(t, clusterRule, r.GetObject())
+	assert.Equal(t, notification, r.GetNotification())
+	assert.Equal(t, "ClusterRuleExpression/test-r", r.GetName())
+	assert.Equal(t, []schema.GroupVersionKind{{Group: "apps", Version: "v1", Kind: "Deployment"}}, r.GetTargetKinds())
+
+	finalizer := "test.finalizer"
+	r.SetFinalizer(finalizer)
+	assert.Equal(t, finalizer, r.resource.Finalizers[0])
+	r.RemoveFinalizer(finalizer)
+	assert.Empty(t, r.resource.Finalizers)
+
+	namespaceRule := &merlinv1beta1.RuleExpression{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test-r"},
+		Spec: merlinv1beta1.RuleExpressionSpec{
+			TargetKind:   targetKind,
+			Notification: notification,
+		},
+	}
+	nr := &expressionNamespaceRule{resource: namespaceRule}
+	assert.Equal(t, namespaceRule.ObjectMeta, nr.GetObjectMeta())
+	assert.Equal(t, namespaceRule, nr.GetObject())
+	assert.Equal(t, notification, nr.GetNotification())
+	assert.Equal(t, "RuleExpression/test-r", nr.GetName())
+	assert.Equal(t, []schema.GroupVersionKind{{Group: "apps", Version: "v1", Kind: "Deployment"}}, nr.GetTargetKinds())
+
+	nr.SetFinalizer(finalizer)
+	assert.Equal(t, finalizer, nr.resource.Finalizers[0])
+	nr.RemoveFinalizer(finalizer)
+	assert.Empty(t, nr.resource.Finalizers)
+}
+
+func Test_IsOverridden(t *testing.T) {
+	deploymentKind := metav1.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}
+	newClusterRule := func(name string, kind metav1.GroupVersionKind) Rule {
+		return &expressionClusterRule{resource: &merlinv1beta1.ClusterRuleExpression{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       merlinv1beta1.ClusterRuleExpressionSpec{TargetKind: kind},
+		}}
+	}
+	namespaceRule := &expressionNamespaceRule{resource: &merlinv1beta1.RuleExpression{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "dev", Name: "replicas"},
+		Spec:       merlinv1beta1.RuleExpressionSpec{TargetKind: deploymentKind},
+	}}
+
+	// namespaced rules only replace the cluster rules with the same name and target kind
+	assert.True(t, IsOverridden(newClusterRule("replicas", deploymentKind), []Rule{namespaceRule}))
+	assert.True(t, IsOverridden(newClusterRule("replicas", metav1.GroupVersionKind{Group: "apps", Version: "v1beta1", Kind: "Deployment"}), []Rule{namespaceRule}))
+	assert.False(t, IsOverridden(newClusterRule("team", deploymentKind), []Rule{namespaceRule}))
+	assert.False(t, IsOverridden(newClusterRule("replicas", metav1.GroupVersionKind{Group: "apps", Version: "v1", Kind: "StatefulSet"}), []Rule{namespaceRule}))
+	assert.False(t, IsOverridden(newClusterRule("replicas", deploymentKind), nil))
+	// other namespaced rules replace all cluster rules
+	assert.True(t, IsOverridden(&hpaReplicaPercentageClusterRule{}, []Rule{&hpaReplicaPercentageNamespaceRule{}}))
+}
+
+func Test_newExpressionEvaluator(t *testing.T) {
+	cases := []struct {
+		desc      string
+		expr      string
+		message   string
+		expectErr bool
+	}{
+		{desc: "valid expression and message", expr: "object.spec.replicas >= 2", message: "replicas {{ object.spec.replicas }}"},
+		{desc: "valid expression without message", expr: "has(object.spec.replicas)"},
+		{desc: "invalid expression should have error", expr: "object.spec.replicas >=", expectErr: true},
+		{desc: "unknown variable should have error", expr: "spec.replicas >= 2", expectErr: true},
+		{desc: "invalid expression in message should have error", expr: "true", message: "replicas {{ object.spec. }}", expectErr: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.desc, func(tt *testing.T) {
+			_, err := newExpressionEvaluator(tc.expr, tc.message)
+			if tc.expectErr {
+				assert.Error(tt, err)
+			} else {
+				assert.NoError(tt, err)
+			}
+		})
+	}
+}
+
+func Test_ExpressionRule_Evaluate(t *testing.T) {
+	ctx := context.Background()
+	log := zapr.NewLogger(zap.L())
+	targetKind := metav1.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}
+	evaluator, err := newExpressionEvaluator("object.spec.replicas >= 2", "Deployment {{ object.metadata.name }} has {{ object.spec.replicas }} replicas")
+	assert.NoError(t, err)
+	labelEvaluator, err := newExpressionEvaluator("object.metadata.labels.tier == 'backend'", "")
+	assert.NoError(t, err)
+	newDeployment := func(namespace string, replicas int32) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "test", Labels: map[string]string{"app": "test"}},
+			Spec:       appsv1.DeploymentSpec{Replicas: pointer.Int32Ptr(replicas)},
+		}
+	}
+	unstructuredDeployment := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"namespace": "default", "name": "test", "labels": map[string]interface{}{"app": "test"}},
+		"spec":       map[string]interface{}{"replicas": int64(3)},
+	}}
+
+	clusterCases := []struct {
+		desc      string
+		evaluator *expressionEvaluator
+		selector  merlinv1beta1.Selector
+		resource  interface{}
+		expect    alert.Alert
+		expectErr bool
+	}{
+		{
+			desc:      "non kubernetes object should have error",
+			evaluator: evaluator,
+			resource:  "test",
+			expectErr: true,
+		},
+		{
+			desc:      "object of other kinds should not get violated alert",
+			evaluator: evaluator,
+			resource:  &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test"}},
+			expect: alert.Alert{
+				Message:      "object is not selected by the rule",
+				ResourceKind: "Service",
+				ResourceName: "default/test",
+			},
+		},
+		{
+			desc:      "object not matching selector should not get violated alert",
+			evaluator: evaluator,
+			selector:  merlinv1beta1.Selector{MatchLabels: map[string]string{"app": "other"}},
+			resource:  newDeployment("default", 1),
+			expect: alert.Alert{
+				Message:      "object is not selected by the rule",
+				ResourceKind: "Deployment",
+				ResourceName: "default/test",
+			},
+		},
+		{
+			desc:      "ignored namespace should not get violated alert",
+			evaluator: evaluator,
+			resource:  newDeployment("ignoredNS", 1),
+			expect: alert.Alert{
+				Message:      "namespace is ignored by the rule",
+				ResourceKind: "Deployment",
+				ResourceName: "ignoredNS/test",
+			},
+		},
+		{
+			desc:      "typed object not satisfying expression should get violated alert with interpolated message",
+			evaluator: evaluator,
+			selector:  merlinv1beta1.Selector{Name: "test", MatchLabels: map[string]string{"app": "test"}},
+			resource:  newDeployment("default", 1),
+			expect: alert.Alert{
+				Message:      "Deployment test has 1 replicas",
+				ResourceKind: "Deployment",
+				ResourceName: "default/test",
+				Violated:     true,
+			},
+		},
+		{
+			desc:      "unstructured object satisfying expression should not get violated alert",
+			evaluator: evaluator,
+			resource:  unstructuredDeployment,
+			expect: alert.Alert{
+				Message:      "satisfies the expression `object.spec.replicas >= 2`",
+				ResourceKind: "Deployment",
+				ResourceName: "default/test",
+			},
+		},
+		{
+			desc:      "expression error should get violated alert",
+			evaluator: labelEvaluator,
+			resource:  unstructuredDeployment,
+			expect: alert.Alert{
+				Message:      "failed to evaluate the expression `object.metadata.labels.tier == 'backend'`: no such key: tier",
+				ResourceKind: "Deployment",
+				ResourceName: "default/test",
+				Violated:     true,
+			},
+		},
+	}
+	for _, tc := range clusterCases {
+		t.Run("cluster rule: "+tc.desc, func(tt *testing.T) {
+			r := &expressionClusterRule{
+				rule: rule{log: log, status: &Status{}},
+				resource: &merlinv1beta1.ClusterRuleExpression{
+					Spec: merlinv1beta1.ClusterRuleExpressionSpec{
+						IgnoreNamespaces: []string{"ignoredNS"},
+						Selector:         tc.selector,
+						TargetKind:       targetKind,
+					},
+				},
+				evaluator: tc.evaluator,
+			}
+			a, err := r.Evaluate(ctx, tc.resource)
+			if tc.expectErr {
+				assert.Error(tt, err)
+			} else {
+				assert.NoError(tt, err)
+				assert.Equal(tt, tc.expect, a)
+			}
+		})
+	}
+
+	namespaceCases := []struct {
+		desc     string
+		resource interface{}
+		expect   alert.Alert
+	}{
+		{
+			desc:     "object in other namespaces should not get violated alert",
+			resource: newDeployment("other", 1),
+			expect: alert.Alert{
+				Message:      "object is not selected by the rule",
+				ResourceKind: "Deployment",
+				ResourceName: "other/test",
+			},
+		},
+		{
+			desc:     "object not satisfying expression should get violated alert with default message",
+			resource: newDeployment("default", 3),
+			expect: alert.Alert{
+				Message:      "doesn't satisfy the expression `object.spec.replicas <= 2`",
+				ResourceKind: "Deployment",
+				ResourceName: "default/test",
+				Violated:     true,
+			},
+		},
+	}
+	namespaceEvaluator, err := newExpressionEvaluator("object.spec.replicas <= 2", "")
+	assert.NoError(t, err)
+	for _, tc := range namespaceCases {
+		t.Run("namespace rule: "+tc.desc, func(tt *testing.T) {
+			r := &expressionNamespaceRule{
+				rule: rule{log: log, status: &Status{}},
+				resource: &merlinv1beta1.RuleExpression{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test-r"},
+					Spec:       merlinv1beta1.RuleExpressionSpec{TargetKind: targetKind},
+				},
+				evaluator: namespaceEvaluator,
+			}
+			a, err := r.Evaluate(ctx, tc.resource)
+			assert.NoError(tt, err)
+			assert.Equal(tt, tc.expect, a)
+		})
+	}
+}
+
+func Test_ExpressionRule_EvaluateAll(t *testing.T) {
+	ctx := context.Background()
+	log := zapr.NewLogger(zap.L())
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mocks.NewMockClient(mockCtrl)
+	evaluator, err := newExpressionEvaluator("has(object.metadata.annotations)", "")
+	assert.NoError(t, err)
+	r := &expressionNamespaceRule{
+		rule: rule{cli: mockClient, log: log, status: &Status{}},
+		resource: &merlinv1beta1.RuleExpression{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test-r"},
+			Spec: merlinv1beta1.RuleExpressionSpec{
+				TargetKind: metav1.GroupVersionKind{Version: "v1", Kind: "Service"},
+				Selector:   merlinv1beta1.Selector{MatchLabels: map[string]string{"app": "test"}},
+			},
+		},
+		evaluator: evaluator,
+	}
+	newList := func(items ...unstructured.Unstructured) *unstructured.UnstructuredList {
+		list := &unstructured.UnstructuredList{Items: items}
+		list.SetAPIVersion("v1")
+		list.SetKind("ServiceList")
+		return list
+	}
+	listOpts := getListOptions(r.resource.Spec.Selector, "default")
+	service := unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Service",
+		"metadata":   map[string]interface{}{"namespace": "default", "name": "test", "labels": map[string]interface{}{"app": "test"}},
+	}}
+
+	cases := []struct {
+		desc      string
+		mockCalls []*gomock.Call
+		expect    []alert.Alert
+		expectErr bool
+	}{
+		{
+			desc: "list error should have error",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().List(ctx, newList(), listOpts).Return(assert.AnError),
+			},
+			expectErr: true,
+		},
+		{
+			desc: "no object should returns nil alerts",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().List(ctx, newList(), listOpts).Return(nil),
+			},
+		},
+		{
+			desc: "listed objects should be evaluated",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().List(ctx, newList(), listOpts).SetArg(1, *newList(service)).Return(nil),
+			},
+			expect: []alert.Alert{
+				{
+					Message:      "doesn't satisfy the expression `has(object.metadata.annotations)`",
+					ResourceKind: "Service",
+					ResourceName: "default/test",
+					Violated:     true,
+				},
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.desc, func(tt *testing.T) {
+			for _, call := range tc.mockCalls {
+				call.Times(1)
+			}
+			alerts, err := r.EvaluateAll(ctx)
+			if tc.expectErr {
+				assert.Error(tt, err)
+			} else {
+				assert.NoError(tt, err)
+				assert.Equal(tt, tc.expect, alerts)
+			}
+		})
+	}
+}
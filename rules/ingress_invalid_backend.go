@@ -0,0 +1,286 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/mercari/merlin/alert"
+	merlinv1beta1 "github.com/mercari/merlin/api/v1beta1"
+)
+
+const defaultIngressInvalidBackendRecheckIntervalSeconds = 300
+
+type IngressInvalidBackendRule struct {
+	rule
+	resource *merlinv1beta1.ClusterRuleIngressInvalidBackend
+}
+
+func (s *IngressInvalidBackendRule) New(ctx context.Context, cli client.Client, logger logr.Logger, key client.ObjectKey) (Rule, error) {
+	s.cli = cli
+	s.log = logger
+	s.status = &Status{}
+	s.resource = &merlinv1beta1.ClusterRuleIngressInvalidBackend{}
+	if err := s.cli.Get(ctx, key, s.resource); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *IngressInvalidBackendRule) GetObject() runtime.Object {
+	return s.resource
+}
+
+func (s IngressInvalidBackendRule) GetName() string {
+	return strings.Join([]string{getStructName(s.resource), s.resource.Name}, Separator)
+}
+
+func (s IngressInvalidBackendRule) GetObjectMeta() metav1.ObjectMeta {
+	return s.resource.ObjectMeta
+}
+
+func (s IngressInvalidBackendRule) GetNotification() merlinv1beta1.Notification {
+	return s.resource.Spec.Notification
+}
+
+func (s *IngressInvalidBackendRule) SetFinalizer(finalizer string) {
+	s.resource.ObjectMeta.Finalizers = append(s.resource.ObjectMeta.Finalizers, finalizer)
+}
+
+func (s *IngressInvalidBackendRule) RemoveFinalizer(finalizer string) {
+	s.resource.ObjectMeta.Finalizers = removeString(s.resource.ObjectMeta.Finalizers, finalizer)
+}
+
+func (s *IngressInvalidBackendRule) EvaluateAll(ctx context.Context) (alerts []alert.Alert, err error) {
+	ingressList := &networkingv1beta1.IngressList{}
+	if err = s.cli.List(ctx, ingressList); err != nil {
+		return
+	}
+
+	if len(ingressList.Items) == 0 {
+		s.log.Info("no ingress found")
+		return
+	}
+	for _, ingress := range ingressList.Items {
+		s.log.Info("evaluating", fmt.Sprintf("%T", ingress), ingress.Name)
+		var a alert.Alert
+		a, err = s.evaluateIngress(ctx, &ingress)
+		if err != nil {
+			return
+		}
+		alerts = append(alerts, a)
+	}
+	return
+}
+
+// Evaluate evaluates the ingress, or the ingresses in the same namespace referencing the service or secret being changed,
+// and returns the alert of the first referencing ingress, ResourceReconciler uses EvaluateRelated for the alerts of all of them.
+func (s *IngressInvalidBackendRule) Evaluate(ctx context.Context, object interface{}) (a alert.Alert, err error) {
+	var alerts []alert.Alert
+	if alerts, err = s.EvaluateRelated(ctx, object); err != nil || len(alerts) == 0 {
+		return s.newAlert(), err
+	}
+	return alerts[0], nil
+}
+
+// EvaluateRelated evaluates the ingress, or all ingresses in the same namespace referencing the service or secret being changed.
+func (s *IngressInvalidBackendRule) EvaluateRelated(ctx context.Context, object interface{}) ([]alert.Alert, error) {
+	switch obj := object.(type) {
+	case *networkingv1beta1.Ingress:
+		a, err := s.evaluateIngress(ctx, obj)
+		if err != nil {
+			return nil, err
+		}
+		return []alert.Alert{a}, nil
+	case *corev1.Service:
+		return s.evaluateReferrers(ctx, obj.Namespace, func(ing *networkingv1beta1.Ingress) bool {
+			return ingressReferencesService(ing, obj.Name)
+		})
+	case *corev1.Secret:
+		return s.evaluateReferrers(ctx, obj.Namespace, func(ing *networkingv1beta1.Ingress) bool {
+			return ingressReferencesSecret(ing, obj.Name)
+		})
+	}
+	return nil, fmt.Errorf("object being evaluated is not type %T, %T or %T", &networkingv1beta1.Ingress{}, &corev1.Service{}, &corev1.Secret{})
+}
+
+// GetRecheckInterval returns the interval to re-evaluate all ingresses, since deleting services or secrets
+// makes ingresses invalid without any event of the ingresses.
+func (s *IngressInvalidBackendRule) GetRecheckInterval() time.Duration {
+	if s.resource.Spec.RecheckIntervalSeconds > 0 {
+		return time.Duration(s.resource.Spec.RecheckIntervalSeconds) * time.Second
+	}
+	return defaultIngressInvalidBackendRecheckIntervalSeconds * time.Second
+}
+
+func (s *IngressInvalidBackendRule) newAlert() alert.Alert {
+	return alert.Alert{
+		Suppressed:      s.resource.Spec.Notification.Suppressed,
+		Severity:        s.resource.Spec.Notification.Severity,
+		MessageTemplate: s.resource.Spec.Notification.CustomMessageTemplate,
+		ResourceKind:    getStructName(networkingv1beta1.Ingress{}),
+		Violated:        false,
+	}
+}
+
+// evaluateReferrers re-checks the ingresses that reference the changed service or secret, and returns their alerts.
+func (s *IngressInvalidBackendRule) evaluateReferrers(ctx context.Context, namespace string, isReferrer func(*networkingv1beta1.Ingress) bool) (alerts []alert.Alert, err error) {
+	if s.status.checkedAt == nil || isStringInSlice(s.resource.Spec.IgnoreNamespaces, namespace) {
+		return
+	}
+	ingressList := networkingv1beta1.IngressList{}
+	if err = s.cli.List(ctx, &ingressList, &client.ListOptions{Namespace: namespace}); err != nil && client.IgnoreNotFound(err) != nil {
+		return
+	}
+	err = nil
+	for _, ingress := range ingressList.Items {
+		if !isReferrer(&ingress) {
+			continue
+		}
+		var a alert.Alert
+		if a, err = s.evaluateIngress(ctx, &ingress); err != nil {
+			return
+		}
+		alerts = append(alerts, a)
+	}
+	return
+}
+
+func (s *IngressInvalidBackendRule) evaluateIngress(ctx context.Context, ingress *networkingv1beta1.Ingress) (a alert.Alert, err error) {
+	a, err = s.checkIngress(ctx, ingress)
+	if err != nil {
+		return
+	}
+	if !isStringInSlice(s.resource.Spec.IgnoreNamespaces, ingress.Namespace) {
+		s.status.setViolation(client.ObjectKey{Namespace: ingress.Namespace, Name: ingress.Name}, a.Violated)
+	}
+	return
+}
+
+// checkIngress checks the ingress backends and TLS secrets without updating the rule status.
+func (s *IngressInvalidBackendRule) checkIngress(ctx context.Context, ingress *networkingv1beta1.Ingress) (a alert.Alert, err error) {
+	key := client.ObjectKey{Namespace: ingress.Namespace, Name: ingress.Name}
+	a = alert.Alert{
+		Suppressed:      s.resource.Spec.Notification.Suppressed,
+		Severity:        s.resource.Spec.Notification.Severity,
+		MessageTemplate: s.resource.Spec.Notification.CustomMessageTemplate,
+		Message:         "Ingress has valid backends and TLS secrets",
+		ResourceName:    key.String(),
+		ResourceKind:    getStructName(ingress),
+		Violated:        false,
+	}
+	if isStringInSlice(s.resource.Spec.IgnoreNamespaces, key.Namespace) {
+		a.Message = "namespace is ignored by the rule"
+		return
+	}
+
+	var issues []string
+	services := map[string]*corev1.Service{}
+	for _, backend := range getIngressBackends(ingress) {
+		if backend.ServiceName == "" {
+			continue
+		}
+		svc, ok := services[backend.ServiceName]
+		if !ok {
+			svc = &corev1.Service{}
+			if err = s.cli.Get(ctx, client.ObjectKey{Namespace: ingress.Namespace, Name: backend.ServiceName}, svc); err != nil {
+				if !apierrs.IsNotFound(err) {
+					return
+				}
+				err = nil
+				svc = nil
+			}
+			services[backend.ServiceName] = svc
+		}
+		if svc == nil {
+			issues = append(issues, fmt.Sprintf("service `%s` doesn't exist", backend.ServiceName))
+		} else if !serviceExposesPort(svc, backend.ServicePort) {
+			issues = append(issues, fmt.Sprintf("service `%s` doesn't expose port `%s`", backend.ServiceName, backend.ServicePort.String()))
+		}
+	}
+
+	for _, tls := range ingress.Spec.TLS {
+		if tls.SecretName == "" {
+			continue
+		}
+		secret := &corev1.Secret{}
+		if err = s.cli.Get(ctx, client.ObjectKey{Namespace: ingress.Namespace, Name: tls.SecretName}, secret); err != nil {
+			if !apierrs.IsNotFound(err) {
+				return
+			}
+			err = nil
+			issues = append(issues, fmt.Sprintf("TLS secret `%s` doesn't exist", tls.SecretName))
+			continue
+		}
+		if secret.Type != corev1.SecretTypeTLS {
+			issues = append(issues, fmt.Sprintf("TLS secret `%s` has type `%s` (expect `%s`)", tls.SecretName, secret.Type, corev1.SecretTypeTLS))
+		}
+	}
+
+	if len(issues) > 0 {
+		a.Violated = true
+		a.Message = "Ingress has invalid references: " + strings.Join(uniqueStrings(issues), ", ")
+	}
+	return
+}
+
+func (s *IngressInvalidBackendRule) GetDelaySeconds(object interface{}) (time.Duration, error) {
+	return 0, nil
+}
+
+// getIngressBackends returns the default backend and all rule backends of the ingress
+func getIngressBackends(ingress *networkingv1beta1.Ingress) (backends []networkingv1beta1.IngressBackend) {
+	if ingress.Spec.Backend != nil {
+		backends = append(backends, *ingress.Spec.Backend)
+	}
+	for _, r := range ingress.Spec.Rules {
+		if r.HTTP == nil {
+			continue
+		}
+		for _, p := range r.HTTP.Paths {
+			backends = append(backends, p.Backend)
+		}
+	}
+	return
+}
+
+func ingressReferencesService(ingress *networkingv1beta1.Ingress, name string) bool {
+	for _, backend := range getIngressBackends(ingress) {
+		if backend.ServiceName == name {
+			return true
+		}
+	}
+	return false
+}
+
+func ingressReferencesSecret(ingress *networkingv1beta1.Ingress, name string) bool {
+	for _, tls := range ingress.Spec.TLS {
+		if tls.SecretName == name {
+			return true
+		}
+	}
+	return false
+}
+
+// serviceExposesPort checks if the service has the port, by number or by name
+func serviceExposesPort(svc *corev1.Service, port intstr.IntOrString) bool {
+	for _, p := range svc.Spec.Ports {
+		if port.Type == intstr.Int && p.Port == port.IntVal {
+			return true
+		}
+		if port.Type == intstr.String && p.Name == port.StrVal {
+			return true
+		}
+	}
+	return false
+}
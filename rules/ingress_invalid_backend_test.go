@@ -0,0 +1,352 @@
+package rules
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/zapr"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/mercari/merlin/alert"
+	merlinv1beta1 "github.com/mercari/merlin/api/v1beta1"
+	"github.com/mercari/merlin/mocks"
+)
+
+func Test_IngressInvalidBackendRule_Basic(t *testing.T) {
+	notification := merlinv1beta1.Notification{
+		Notifiers:  []string{"testNotifier"},
+		Suppressed: true,
+	}
+
+	merlinv1beta1Rule := &merlinv1beta1.ClusterRuleIngressInvalidBackend{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-r"},
+		Spec: merlinv1beta1.ClusterRuleIngressInvalidBackendSpec{
+			Notification: notification,
+		},
+	}
+
+	r := &IngressInvalidBackendRule{resource: merlinv1beta1Rule}
+	assert.Equal(t, merlinv1beta1Rule.ObjectMeta, r.GetObjectMeta())
+	assert.Equal(t, merlinv1beta1Rule, r.GetObject())
+	assert.Equal(t, notification, r.GetNotification())
+	assert.Equal(t, "ClusterRuleIngressInvalidBackend/test-r", r.GetName())
+
+	finalizer := "test.finalizer"
+	r.SetFinalizer(finalizer)
+	assert.Equal(t, finalizer, r.resource.Finalizers[0])
+	r.RemoveFinalizer(finalizer)
+	assert.Empty(t, r.resource.Finalizers)
+	delay, err := r.GetDelaySeconds(&networkingv1beta1.Ingress{})
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), delay)
+	assert.Equal(t, 300*time.Second, r.GetRecheckInterval())
+	r.resource.Spec.RecheckIntervalSeconds = 60
+	assert.Equal(t, 60*time.Second, r.GetRecheckInterval())
+}
+
+func Test_IngressInvalidBackendRule_Evaluate(t *testing.T) {
+	ctx := context.Background()
+	log := zapr.NewLogger(zap.L())
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mocks.NewMockClient(mockCtrl)
+	notification := merlinv1beta1.Notification{Notifiers: []string{"testNotifier"}}
+	r := &IngressInvalidBackendRule{
+		rule: rule{cli: mockClient, log: log, status: &Status{}},
+		resource: &merlinv1beta1.ClusterRuleIngressInvalidBackend{
+			Spec: merlinv1beta1.ClusterRuleIngressInvalidBackendSpec{
+				Notification:     notification,
+				IgnoreNamespaces: []string{"ignoredNS"},
+			},
+		},
+	}
+	notFound := func(resource, name string) error {
+		return apierrs.NewNotFound(schema.GroupResource{Resource: resource}, name)
+	}
+	svcKey := client.ObjectKey{Namespace: "test", Name: "svc"}
+	secretKey := client.ObjectKey{Namespace: "test", Name: "tls"}
+	ingress := &networkingv1beta1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "ingress"},
+		Spec: networkingv1beta1.IngressSpec{
+			Backend: &networkingv1beta1.IngressBackend{ServiceName: "svc", ServicePort: intstr.FromInt(80)},
+			Rules: []networkingv1beta1.IngressRule{{
+				Host: "example.com",
+				IngressRuleValue: networkingv1beta1.IngressRuleValue{HTTP: &networkingv1beta1.HTTPIngressRuleValue{
+					Paths: []networkingv1beta1.HTTPIngressPath{
+						{Path: "/", Backend: networkingv1beta1.IngressBackend{ServiceName: "svc", ServicePort: intstr.FromString("http")}},
+					},
+				}},
+			}},
+			TLS: []networkingv1beta1.IngressTLS{{Hosts: []string{"example.com"}, SecretName: "tls"}},
+		},
+	}
+	svc := corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "svc"},
+		Spec: corev1.ServiceSpec{Ports: []corev1.ServicePort{
+			{Name: "http", Port: 80},
+		}},
+	}
+
+	cases := []struct {
+		desc      string
+		mockCalls []*gomock.Call
+		expect    alert.Alert
+		resource  interface{}
+		expectErr bool
+	}{
+		{
+			desc:      "non ingress, service or secret should have error",
+			resource:  "non-ingress",
+			expectErr: true,
+		},
+		{
+			desc: "ignored namespace should not get violated alert",
+			resource: &networkingv1beta1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ignoredNS", Name: "ingress"},
+			},
+			expect: alert.Alert{
+				Message:      "namespace is ignored by the rule",
+				ResourceKind: "Ingress",
+				ResourceName: "ignoredNS/ingress",
+			},
+		},
+		{
+			desc: "missing service and secret should get violated alert",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().Get(ctx, svcKey, &corev1.Service{}).Return(notFound("services", "svc")),
+				mockClient.EXPECT().Get(ctx, secretKey, &corev1.Secret{}).Return(notFound("secrets", "tls")),
+			},
+			resource: ingress,
+			expect: alert.Alert{
+				Message:      "Ingress has invalid references: service `svc` doesn't exist, TLS secret `tls` doesn't exist",
+				ResourceKind: "Ingress",
+				ResourceName: "test/ingress",
+				Violated:     true,
+			},
+		},
+		{
+			desc: "service without the port and secret with wrong type should get violated alert",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().Get(ctx, svcKey, &corev1.Service{}).
+					SetArg(2, corev1.Service{
+						ObjectMeta: svc.ObjectMeta,
+						Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: "grpc", Port: 8080}}},
+					}).
+					Return(nil),
+				mockClient.EXPECT().Get(ctx, secretKey, &corev1.Secret{}).
+					SetArg(2, corev1.Secret{Type: corev1.SecretTypeOpaque}).
+					Return(nil),
+			},
+			resource: ingress,
+			expect: alert.Alert{
+				Message: "Ingress has invalid references: service `svc` doesn't expose port `80`, service `svc` doesn't expose port `http`, " +
+					"TLS secret `tls` has type `Opaque` (expect `kubernetes.io/tls`)",
+				ResourceKind: "Ingress",
+				ResourceName: "test/ingress",
+				Violated:     true,
+			},
+		},
+		{
+			desc: "valid service and secret should not get violated alert",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().Get(ctx, svcKey, &corev1.Service{}).SetArg(2, svc).Return(nil),
+				mockClient.EXPECT().Get(ctx, secretKey, &corev1.Secret{}).
+					SetArg(2, corev1.Secret{Type: corev1.SecretTypeTLS}).
+					Return(nil),
+			},
+			resource: ingress,
+			expect: alert.Alert{
+				Message:      "Ingress has valid backends and TLS secrets",
+				ResourceKind: "Ingress",
+				ResourceName: "test/ingress",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(tt *testing.T) {
+			for _, call := range tc.mockCalls {
+				call.Times(1)
+			}
+			a, err := r.Evaluate(ctx, tc.resource)
+			if tc.expectErr {
+				assert.Error(tt, err)
+			} else {
+				assert.NoError(tt, err)
+				assert.Equal(tt, tc.expect, a)
+			}
+		})
+	}
+}
+
+func Test_IngressInvalidBackendRule_EvaluateRelated(t *testing.T) {
+	ctx := context.Background()
+	log := zapr.NewLogger(zap.L())
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mocks.NewMockClient(mockCtrl)
+	r := &IngressInvalidBackendRule{
+		rule: rule{cli: mockClient, log: log, status: &Status{}},
+		resource: &merlinv1beta1.ClusterRuleIngressInvalidBackend{
+			Spec: merlinv1beta1.ClusterRuleIngressInvalidBackendSpec{
+				Notification: merlinv1beta1.Notification{Notifiers: []string{"testNotifier"}},
+			},
+		},
+	}
+	ingressList := networkingv1beta1.IngressList{Items: []networkingv1beta1.Ingress{
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "other"},
+			Spec: networkingv1beta1.IngressSpec{
+				Backend: &networkingv1beta1.IngressBackend{ServiceName: "other", ServicePort: intstr.FromInt(80)},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "ingress"},
+			Spec: networkingv1beta1.IngressSpec{
+				Backend: &networkingv1beta1.IngressBackend{ServiceName: "svc", ServicePort: intstr.FromInt(80)},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "ingress-internal"},
+			Spec: networkingv1beta1.IngressSpec{
+				Backend: &networkingv1beta1.IngressBackend{ServiceName: "svc", ServicePort: intstr.FromString("http")},
+			},
+		},
+	}}
+	svc := corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "svc"},
+		Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Port: 80}}},
+	}
+
+	// rule has not been evaluated yet, nothing to re-evaluate
+	alerts, err := r.EvaluateRelated(ctx, &svc)
+	assert.NoError(t, err)
+	assert.Empty(t, alerts)
+	a, err := r.Evaluate(ctx, &svc)
+	assert.NoError(t, err)
+	assert.False(t, a.Violated)
+	assert.Empty(t, a.ResourceName)
+
+	// all ingresses referencing the service are evaluated
+	r.status.setViolation(client.ObjectKey{Namespace: "test", Name: "ingress"}, true)
+	mockClient.EXPECT().
+		List(ctx, &networkingv1beta1.IngressList{}, &client.ListOptions{Namespace: "test"}).
+		SetArg(1, ingressList).
+		Return(nil).
+		Times(1)
+	mockClient.EXPECT().
+		Get(ctx, client.ObjectKey{Namespace: "test", Name: "svc"}, &corev1.Service{}).
+		SetArg(2, svc).
+		Return(nil).
+		Times(2)
+	alerts, err = r.EvaluateRelated(ctx, &svc)
+	assert.NoError(t, err)
+	assert.Equal(t, []alert.Alert{
+		{
+			Message:      "Ingress has valid backends and TLS secrets",
+			ResourceKind: "Ingress",
+			ResourceName: "test/ingress",
+		},
+		{
+			Message:      "Ingress has invalid references: service `svc` doesn't expose port `http`",
+			ResourceKind: "Ingress",
+			ResourceName: "test/ingress-internal",
+			Violated:     true,
+		},
+	}, alerts)
+	assert.False(t, r.status.isViolated(client.ObjectKey{Namespace: "test", Name: "ingress"}))
+	assert.True(t, r.status.isViolated(client.ObjectKey{Namespace: "test", Name: "ingress-internal"}))
+
+	// secret not referenced by any ingress should not return alert for ingress
+	mockClient.EXPECT().
+		List(ctx, &networkingv1beta1.IngressList{}, &client.ListOptions{Namespace: "test"}).
+		SetArg(1, ingressList).
+		Return(nil).
+		Times(1)
+	alerts, err = r.EvaluateRelated(ctx, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "tls"}})
+	assert.NoError(t, err)
+	assert.Empty(t, alerts)
+}
+
+func Test_IngressInvalidBackendRule_EvaluateAll(t *testing.T) {
+	ctx := context.Background()
+	log := zapr.NewLogger(zap.L())
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mocks.NewMockClient(mockCtrl)
+	r := &IngressInvalidBackendRule{
+		rule: rule{cli: mockClient, log: log, status: &Status{}},
+		resource: &merlinv1beta1.ClusterRuleIngressInvalidBackend{
+			Spec: merlinv1beta1.ClusterRuleIngressInvalidBackendSpec{
+				Notification: merlinv1beta1.Notification{Notifiers: []string{"testNotifier"}},
+			},
+		},
+	}
+
+	cases := []struct {
+		desc      string
+		mockCalls []*gomock.Call
+		expect    []alert.Alert
+		expectErr bool
+	}{
+		{
+			desc: "no resources should returns nil alerts",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().
+					List(ctx, &networkingv1beta1.IngressList{}).
+					Return(nil),
+			},
+		},
+		{
+			desc: "ingress with missing service should returns violated alert",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().
+					List(ctx, &networkingv1beta1.IngressList{}).
+					SetArg(1, networkingv1beta1.IngressList{
+						Items: []networkingv1beta1.Ingress{{
+							ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "ingress"},
+							Spec: networkingv1beta1.IngressSpec{
+								Backend: &networkingv1beta1.IngressBackend{ServiceName: "svc", ServicePort: intstr.FromInt(80)},
+							},
+						}},
+					}).
+					Return(nil),
+				mockClient.EXPECT().
+					Get(ctx, client.ObjectKey{Namespace: "test", Name: "svc"}, &corev1.Service{}).
+					Return(apierrs.NewNotFound(schema.GroupResource{Resource: "services"}, "svc")),
+			},
+			expect: []alert.Alert{
+				{
+					Message:      "Ingress has invalid references: service `svc` doesn't exist",
+					ResourceKind: "Ingress",
+					ResourceName: "test/ingress",
+					Violated:     true,
+				},
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.desc, func(tt *testing.T) {
+			for _, call := range tc.mockCalls {
+				call.Times(1)
+			}
+			alerts, err := r.EvaluateAll(ctx)
+			if tc.expectErr {
+				assert.Error(tt, err)
+			} else {
+				assert.NoError(tt, err)
+				assert.Equal(tt, tc.expect, alerts)
+			}
+		})
+	}
+}
@@ -0,0 +1,265 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/mercari/merlin/alert"
+	merlinv1beta1 "github.com/mercari/merlin/api/v1beta1"
+)
+
+const defaultNamespaceQuotaRecheckIntervalSeconds = 300
+
+type NamespaceQuotaRule struct {
+	rule
+	resource *merlinv1beta1.ClusterRuleNamespaceQuota
+}
+
+func (n *NamespaceQuotaRule) New(ctx context.Context, cli client.Client, logger logr.Logger, key client.ObjectKey) (Rule, error) {
+	n.cli = cli
+	n.log = logger
+	n.status = &Status{}
+	n.resource = &merlinv1beta1.ClusterRuleNamespaceQuota{}
+	if err := n.cli.Get(ctx, key, n.resource); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+func (n *NamespaceQuotaRule) GetObject() runtime.Object {
+	return n.resource
+}
+
+func (n NamespaceQuotaRule) GetName() string {
+	return strings.Join([]string{getStructName(n.resource), n.resource.Name}, Separator)
+}
+
+func (n NamespaceQuotaRule) GetObjectMeta() metav1.ObjectMeta {
+	return n.resource.ObjectMeta
+}
+
+func (n NamespaceQuotaRule) GetNotification() merlinv1beta1.Notification {
+	return n.resource.Spec.Notification
+}
+
+func (n *NamespaceQuotaRule) SetFinalizer(finalizer string) {
+	n.resource.ObjectMeta.Finalizers = append(n.resource.ObjectMeta.Finalizers, finalizer)
+}
+
+func (n *NamespaceQuotaRule) RemoveFinalizer(finalizer string) {
+	n.resource.ObjectMeta.Finalizers = removeString(n.resource.ObjectMeta.Finalizers, finalizer)
+}
+
+// GetRecheckInterval returns the interval to re-evaluate all namespaces and quotas, since deleting quotas or limit ranges
+// makes namespaces violated without any event of the namespaces.
+func (n *NamespaceQuotaRule) GetRecheckInterval() time.Duration {
+	if n.resource.Spec.RecheckIntervalSeconds > 0 {
+		return time.Duration(n.resource.Spec.RecheckIntervalSeconds) * time.Second
+	}
+	return defaultNamespaceQuotaRecheckIntervalSeconds * time.Second
+}
+
+func (n *NamespaceQuotaRule) EvaluateAll(ctx context.Context) (alerts []alert.Alert, err error) {
+	namespaces := &corev1.NamespaceList{}
+	if err = n.cli.List(ctx, namespaces); err != nil {
+		return
+	}
+	quotas := &corev1.ResourceQuotaList{}
+	if err = n.cli.List(ctx, quotas); err != nil {
+		return
+	}
+	limitRanges := &corev1.LimitRangeList{}
+	if err = n.cli.List(ctx, limitRanges); err != nil {
+		return
+	}
+
+	if len(namespaces.Items) == 0 {
+		n.log.Info("no namespace found")
+		return
+	}
+	namespaceQuotas := map[string]int{}
+	for _, quota := range quotas.Items {
+		namespaceQuotas[quota.Namespace]++
+	}
+	namespaceLimitRanges := map[string]int{}
+	for _, limitRange := range limitRanges.Items {
+		namespaceLimitRanges[limitRange.Namespace]++
+	}
+	selectedNamespaces := map[string]bool{}
+	for _, ns := range namespaces.Items {
+		n.log.V(1).Info("evaluating", fmt.Sprintf("%T", ns), ns.Name)
+		var a alert.Alert
+		if a, err = n.checkNamespace(&ns, namespaceQuotas[ns.Name], namespaceLimitRanges[ns.Name]); err != nil {
+			return
+		}
+		if selectedNamespaces[ns.Name], err = n.isNamespaceSelected(&ns); err != nil {
+			return
+		}
+		alerts = append(alerts, a)
+	}
+	for _, quota := range quotas.Items {
+		n.log.V(1).Info("evaluating", fmt.Sprintf("%T", quota), quota.Name)
+		alerts = append(alerts, n.checkQuota(&quota, selectedNamespaces[quota.Namespace]))
+	}
+	return
+}
+
+// Evaluate evaluates namespaces for the required ResourceQuota and LimitRange, and ResourceQuotas for their usage.
+// Changes of ResourceQuotas and LimitRanges re-check their namespace, and the namespace alert is returned if its violation state changes.
+func (n *NamespaceQuotaRule) Evaluate(ctx context.Context, object interface{}) (a alert.Alert, err error) {
+	switch obj := object.(type) {
+	case *corev1.Namespace:
+		return n.evaluateNamespace(ctx, obj)
+	case *corev1.ResourceQuota:
+		var namespace *corev1.Namespace
+		var changed bool
+		if namespace, a, changed, err = n.evaluateNamespaceOf(ctx, obj.Namespace); err != nil || changed {
+			return
+		}
+		var selected bool
+		if selected, err = n.isNamespaceSelected(namespace); err != nil {
+			return
+		}
+		return n.checkQuota(obj, selected), nil
+	case *corev1.LimitRange:
+		_, a, _, err = n.evaluateNamespaceOf(ctx, obj.Namespace)
+		return
+	}
+	err = fmt.Errorf("object being evaluated is not type %T, %T or %T", &corev1.Namespace{}, &corev1.ResourceQuota{}, &corev1.LimitRange{})
+	return
+}
+
+func (n *NamespaceQuotaRule) GetDelaySeconds(object interface{}) (time.Duration, error) {
+	return 0, nil
+}
+
+func (n *NamespaceQuotaRule) newAlert(object interface{}, resourceName string) alert.Alert {
+	return alert.Alert{
+		Suppressed:      n.resource.Spec.Notification.Suppressed,
+		Severity:        n.resource.Spec.Notification.Severity,
+		MessageTemplate: n.resource.Spec.Notification.CustomMessageTemplate,
+		ResourceName:    resourceName,
+		ResourceKind:    getStructName(object),
+		Violated:        false,
+	}
+}
+
+func (n *NamespaceQuotaRule) evaluateNamespace(ctx context.Context, namespace *corev1.Namespace) (a alert.Alert, err error) {
+	quotas := &corev1.ResourceQuotaList{}
+	if err = n.cli.List(ctx, quotas, &client.ListOptions{Namespace: namespace.Name}); err != nil {
+		return
+	}
+	limitRanges := &corev1.LimitRangeList{}
+	if err = n.cli.List(ctx, limitRanges, &client.ListOptions{Namespace: namespace.Name}); err != nil {
+		return
+	}
+	return n.checkNamespace(namespace, len(quotas.Items), len(limitRanges.Items))
+}
+
+// evaluateNamespaceOf gets and re-checks the namespace of the changed ResourceQuota or LimitRange,
+// and returns if the violation state of the namespace has changed.
+func (n *NamespaceQuotaRule) evaluateNamespaceOf(ctx context.Context, name string) (namespace *corev1.Namespace, a alert.Alert, changed bool, err error) {
+	namespace = &corev1.Namespace{}
+	if err = n.cli.Get(ctx, client.ObjectKey{Name: name}, namespace); err != nil {
+		return
+	}
+	key := client.ObjectKey{Name: name}
+	wasViolated := n.status.isViolated(key)
+	if a, err = n.evaluateNamespace(ctx, namespace); err != nil {
+		return
+	}
+	changed = a.Violated != wasViolated
+	return
+}
+
+// checkNamespace checks if the namespace has the ResourceQuota and LimitRange required by the rule
+func (n *NamespaceQuotaRule) checkNamespace(namespace *corev1.Namespace, quotas, limitRanges int) (a alert.Alert, err error) {
+	key := client.ObjectKey{Name: namespace.Name}
+	a = n.newAlert(namespace, key.String())
+	if isStringInSlice(n.resource.Spec.IgnoreNamespaces, namespace.Name) {
+		a.Message = "namespace is ignored by the rule"
+		return
+	}
+	var selected bool
+	if selected, err = n.isNamespaceSelected(namespace); err != nil {
+		return
+	} else if !selected {
+		a.Message = "namespace is not selected by the rule"
+		return
+	}
+	var missing []string
+	if n.resource.Spec.RequireResourceQuota && quotas == 0 {
+		missing = append(missing, "ResourceQuota")
+	}
+	if n.resource.Spec.RequireLimitRange && limitRanges == 0 {
+		missing = append(missing, "LimitRange")
+	}
+	if len(missing) > 0 {
+		a.Violated = true
+		a.Message = fmt.Sprintf("Namespace has no %s", strings.Join(missing, " and "))
+	} else {
+		a.Message = "Namespace has the required ResourceQuota and LimitRange"
+	}
+	n.status.setViolation(key, a.Violated)
+	return
+}
+
+// checkQuota checks if any used resource of the ResourceQuota reaches the percent of its hard limit
+func (n *NamespaceQuotaRule) checkQuota(quota *corev1.ResourceQuota, namespaceSelected bool) alert.Alert {
+	key := client.ObjectKey{Namespace: quota.Namespace, Name: quota.Name}
+	a := n.newAlert(quota, key.String())
+	if isStringInSlice(n.resource.Spec.IgnoreNamespaces, quota.Namespace) {
+		a.Message = "namespace is ignored by the rule"
+		return a
+	} else if !namespaceSelected {
+		a.Message = "namespace is not selected by the rule"
+		return a
+	}
+	var names []string
+	for name := range quota.Status.Hard {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+	var nearLimits []string
+	for _, name := range names {
+		hard := quota.Status.Hard[corev1.ResourceName(name)]
+		used, ok := quota.Status.Used[corev1.ResourceName(name)]
+		if !ok || hard.IsZero() {
+			continue
+		}
+		ratio := float64(used.MilliValue()) / float64(hard.MilliValue())
+		if ratio >= float64(n.resource.Spec.Percent)/100.0 {
+			nearLimits = append(nearLimits, fmt.Sprintf("%s %s/%s (%d%%)", name, used.String(), hard.String(), int64(ratio*100)))
+		}
+	}
+	if len(nearLimits) > 0 {
+		a.Violated = true
+		a.Message = fmt.Sprintf("ResourceQuota usage is >= %v%%: %s", n.resource.Spec.Percent, strings.Join(nearLimits, ", "))
+	} else {
+		a.Message = fmt.Sprintf("ResourceQuota usage is within threshold (< %v%%)", n.resource.Spec.Percent)
+	}
+	n.status.setViolation(key, a.Violated)
+	return a
+}
+
+// isNamespaceSelected checks if the namespace matches the namespace selector of the rule
+func (n *NamespaceQuotaRule) isNamespaceSelected(namespace *corev1.Namespace) (bool, error) {
+	if n.resource.Spec.NamespaceSelector == nil {
+		return true, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(n.resource.Spec.NamespaceSelector)
+	if err != nil {
+		return false, err
+	}
+	return selector.Matches(labels.Set(namespace.Labels)), nil
+}
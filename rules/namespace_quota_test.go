@@ -0,0 +1,281 @@
+package rules
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/zapr"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/mercari/merlin/alert"
+	merlinv1beta1 "github.com/mercari/merlin/api/v1beta1"
+	"github.com/mercari/merlin/mocks"
+)
+
+func newTestResourceQuota(hard, used corev1.ResourceList) corev1.ResourceQuota {
+	return corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "quota"},
+		Status:     corev1.ResourceQuotaStatus{Hard: hard, Used: used},
+	}
+}
+
+func Test_NamespaceQuotaRule_Basic(t *testing.T) {
+	notification := merlinv1beta1.Notification{
+		Notifiers:  []string{"testNotifier"},
+		Suppressed: true,
+	}
+
+	merlinv1beta1Rule := &merlinv1beta1.ClusterRuleNamespaceQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-r"},
+		Spec: merlinv1beta1.ClusterRuleNamespaceQuotaSpec{
+			Notification: notification,
+		},
+	}
+
+	r := &NamespaceQuotaRule{resource: merlinv1beta1Rule}
+	assert.Equal(t, merlinv1beta1Rule.ObjectMeta, r.GetObjectMeta())
+	assert.Equal(t, merlinv1beta1Rule, r.GetObject())
+	assert.Equal(t, notification, r.GetNotification())
+	assert.Equal(t, "ClusterRuleNamespaceQuota/test-r", r.GetName())
+	assert.Equal(t, 5*time.Minute, r.GetRecheckInterval())
+
+	finalizer := "test.finalizer"
+	r.SetFinalizer(finalizer)
+	assert.Equal(t, finalizer, r.resource.Finalizers[0])
+	r.RemoveFinalizer(finalizer)
+	assert.Empty(t, r.resource.Finalizers)
+}
+
+func Test_NamespaceQuotaRule_Evaluate(t *testing.T) {
+	ctx := context.Background()
+	log := zapr.NewLogger(zap.L())
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mocks.NewMockClient(mockCtrl)
+	tenantNS := corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test", Labels: map[string]string{"tenant": "true"}}}
+	listOpts := &client.ListOptions{Namespace: "test"}
+	quota := newTestResourceQuota(
+		corev1.ResourceList{
+			corev1.ResourceLimitsCPU:    resource.MustParse("10"),
+			corev1.ResourceLimitsMemory: resource.MustParse("10Gi"),
+			corev1.ResourcePods:         resource.MustParse("20"),
+			corev1.ResourceServices:     resource.MustParse("0"),
+		},
+		corev1.ResourceList{
+			corev1.ResourceLimitsCPU:    resource.MustParse("9500m"),
+			corev1.ResourceLimitsMemory: resource.MustParse("4Gi"),
+			corev1.ResourcePods:         resource.MustParse("20"),
+			corev1.ResourceServices:     resource.MustParse("0"),
+		},
+	)
+
+	cases := []struct {
+		desc      string
+		mockCalls []*gomock.Call
+		resource  interface{}
+		expect    alert.Alert
+		expectErr bool
+	}{
+		{
+			desc:      "non namespace, resourcequota or limitrange should have error",
+			resource:  &corev1.Pod{},
+			expectErr: true,
+		},
+		{
+			desc: "ignored namespace should not get violated alert",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().List(ctx, &corev1.ResourceQuotaList{}, &client.ListOptions{Namespace: "ignoredNS"}).Return(nil),
+				mockClient.EXPECT().List(ctx, &corev1.LimitRangeList{}, &client.ListOptions{Namespace: "ignoredNS"}).Return(nil),
+			},
+			resource: &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ignoredNS"}},
+			expect: alert.Alert{
+				Message:      "namespace is ignored by the rule",
+				ResourceKind: "Namespace",
+				ResourceName: "/ignoredNS",
+			},
+		},
+		{
+			desc: "namespace without resourcequota and limitrange should get violated alert",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().List(ctx, &corev1.ResourceQuotaList{}, listOpts).Return(nil),
+				mockClient.EXPECT().List(ctx, &corev1.LimitRangeList{}, listOpts).Return(nil),
+			},
+			resource: &tenantNS,
+			expect: alert.Alert{
+				Message:      "Namespace has no ResourceQuota and LimitRange",
+				ResourceKind: "Namespace",
+				ResourceName: "/test",
+				Violated:     true,
+			},
+		},
+		{
+			desc: "namespace with resourcequota and limitrange should not get violated alert",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().List(ctx, &corev1.ResourceQuotaList{}, listOpts).
+					SetArg(1, corev1.ResourceQuotaList{Items: []corev1.ResourceQuota{quota}}).
+					Return(nil),
+				mockClient.EXPECT().List(ctx, &corev1.LimitRangeList{}, listOpts).
+					SetArg(1, corev1.LimitRangeList{Items: []corev1.LimitRange{{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "limits"}}}}).
+					Return(nil),
+			},
+			resource: &tenantNS,
+			expect: alert.Alert{
+				Message:      "Namespace has the required ResourceQuota and LimitRange",
+				ResourceKind: "Namespace",
+				ResourceName: "/test",
+			},
+		},
+		{
+			desc: "resourcequota near its limits should get violated alert",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().Get(ctx, client.ObjectKey{Name: "test"}, &corev1.Namespace{}).SetArg(2, tenantNS).Return(nil),
+				mockClient.EXPECT().List(ctx, &corev1.ResourceQuotaList{}, listOpts).
+					SetArg(1, corev1.ResourceQuotaList{Items: []corev1.ResourceQuota{quota}}).
+					Return(nil),
+				mockClient.EXPECT().List(ctx, &corev1.LimitRangeList{}, listOpts).
+					SetArg(1, corev1.LimitRangeList{Items: []corev1.LimitRange{{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "limits"}}}}).
+					Return(nil),
+			},
+			resource: &quota,
+			expect: alert.Alert{
+				Message:      "ResourceQuota usage is >= 90%: limits.cpu 9500m/10 (95%), pods 20/20 (100%)",
+				ResourceKind: "ResourceQuota",
+				ResourceName: "test/quota",
+				Violated:     true,
+			},
+		},
+		{
+			desc: "resourcequota with namespace violation changed should get namespace alert",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().Get(ctx, client.ObjectKey{Name: "test"}, &corev1.Namespace{}).SetArg(2, tenantNS).Return(nil),
+				mockClient.EXPECT().List(ctx, &corev1.ResourceQuotaList{}, listOpts).
+					SetArg(1, corev1.ResourceQuotaList{Items: []corev1.ResourceQuota{quota}}).
+					Return(nil),
+				mockClient.EXPECT().List(ctx, &corev1.LimitRangeList{}, listOpts).Return(nil),
+			},
+			resource: &quota,
+			expect: alert.Alert{
+				Message:      "Namespace has no LimitRange",
+				ResourceKind: "Namespace",
+				ResourceName: "/test",
+				Violated:     true,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(tt *testing.T) {
+			for _, call := range tc.mockCalls {
+				call.Times(1)
+			}
+			r := &NamespaceQuotaRule{
+				rule: rule{cli: mockClient, log: log, status: &Status{}},
+				resource: &merlinv1beta1.ClusterRuleNamespaceQuota{
+					Spec: merlinv1beta1.ClusterRuleNamespaceQuotaSpec{
+						IgnoreNamespaces:     []string{"ignoredNS"},
+						NamespaceSelector:    &metav1.LabelSelector{MatchLabels: map[string]string{"tenant": "true"}},
+						RequireResourceQuota: true,
+						RequireLimitRange:    true,
+						Percent:              90,
+					},
+				},
+			}
+			a, err := r.Evaluate(ctx, tc.resource)
+			if tc.expectErr {
+				assert.Error(tt, err)
+			} else {
+				assert.NoError(tt, err)
+				assert.Equal(tt, tc.expect, a)
+			}
+		})
+	}
+}
+
+func Test_NamespaceQuotaRule_EvaluateAll(t *testing.T) {
+	ctx := context.Background()
+	log := zapr.NewLogger(zap.L())
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mocks.NewMockClient(mockCtrl)
+	r := &NamespaceQuotaRule{
+		rule: rule{cli: mockClient, log: log, status: &Status{}},
+		resource: &merlinv1beta1.ClusterRuleNamespaceQuota{
+			Spec: merlinv1beta1.ClusterRuleNamespaceQuotaSpec{RequireResourceQuota: true, Percent: 80},
+		},
+	}
+
+	cases := []struct {
+		desc      string
+		mockCalls []*gomock.Call
+		expect    []alert.Alert
+		expectErr bool
+	}{
+		{
+			desc: "no resources should returns nil alerts",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().List(ctx, &corev1.NamespaceList{}).Return(nil),
+				mockClient.EXPECT().List(ctx, &corev1.ResourceQuotaList{}).Return(nil),
+				mockClient.EXPECT().List(ctx, &corev1.LimitRangeList{}).Return(nil),
+			},
+		},
+		{
+			desc: "namespaces and resourcequotas should be evaluated",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().List(ctx, &corev1.NamespaceList{}).
+					SetArg(1, corev1.NamespaceList{Items: []corev1.Namespace{
+						{ObjectMeta: metav1.ObjectMeta{Name: "test"}},
+						{ObjectMeta: metav1.ObjectMeta{Name: "other"}},
+					}}).
+					Return(nil),
+				mockClient.EXPECT().List(ctx, &corev1.ResourceQuotaList{}).
+					SetArg(1, corev1.ResourceQuotaList{Items: []corev1.ResourceQuota{
+						newTestResourceQuota(
+							corev1.ResourceList{corev1.ResourceRequestsMemory: resource.MustParse("1Gi")},
+							corev1.ResourceList{corev1.ResourceRequestsMemory: resource.MustParse("512Mi")},
+						),
+					}}).
+					Return(nil),
+				mockClient.EXPECT().List(ctx, &corev1.LimitRangeList{}).Return(nil),
+			},
+			expect: []alert.Alert{
+				{
+					Message:      "Namespace has the required ResourceQuota and LimitRange",
+					ResourceKind: "Namespace",
+					ResourceName: "/test",
+				},
+				{
+					Message:      "Namespace has no ResourceQuota",
+					ResourceKind: "Namespace",
+					ResourceName: "/other",
+					Violated:     true,
+				},
+				{
+					Message:      "ResourceQuota usage is within threshold (< 80%)",
+					ResourceKind: "ResourceQuota",
+					ResourceName: "test/quota",
+				},
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.desc, func(tt *testing.T) {
+			for _, call := range tc.mockCalls {
+				call.Times(1)
+			}
+			alerts, err := r.EvaluateAll(ctx)
+			if tc.expectErr {
+				assert.Error(tt, err)
+			} else {
+				assert.NoError(tt, err)
+				assert.Equal(tt, tc.expect, alerts)
+			}
+		})
+	}
+}
@@ -10,6 +10,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/mercari/merlin/alert"
@@ -22,14 +23,15 @@ type NamespaceRequiredLabelRule struct {
 }
 
 func (n *NamespaceRequiredLabelRule) New(ctx context.Context, cli client.Client, logger logr.Logger, key client.ObjectKey) (Rule, error) {
-	n.cli = cli
-	n.log = logger
-	n.status = &Status{}
-	n.resource = &merlinv1beta1.ClusterRuleNamespaceRequiredLabel{}
-	if err := n.cli.Get(ctx, key, n.resource); err != nil {
+	// a new rule for each resource, since the enforcement webhook evaluates the cached rules
+	namespaceRule := &NamespaceRequiredLabelRule{
+		rule:     rule{cli: cli, log: logger, status: &Status{}},
+		resource: &merlinv1beta1.ClusterRuleNamespaceRequiredLabel{},
+	}
+	if err := cli.Get(ctx, key, namespaceRule.resource); err != nil {
 		return nil, err
 	}
-	return n, nil
+	return namespaceRule, nil
 }
 
 func (n *NamespaceRequiredLabelRule) GetObject() runtime.Object {
@@ -48,6 +50,10 @@ func (n NamespaceRequiredLabelRule) GetNotification() merlinv1beta1.Notification
 	return n.resource.Spec.Notification
 }
 
+func (n NamespaceRequiredLabelRule) GetEnforcement() merlinv1beta1.Enforcement {
+	return n.resource.Spec.Enforcement
+}
+
 func (n *NamespaceRequiredLabelRule) SetFinalizer(finalizer string) {
 	n.resource.ObjectMeta.Finalizers = append(n.resource.ObjectMeta.Finalizers, finalizer)
 }
@@ -78,7 +84,20 @@ func (n *NamespaceRequiredLabelRule) EvaluateAll(ctx context.Context) (alerts []
 	return
 }
 
-func (n *NamespaceRequiredLabelRule) Evaluate(ctx context.Context, object interface{}) (a alert.Alert, err error) {
+func (n *NamespaceRequiredLabelRule) Evaluate(ctx context.Context, object interface{}) (alert.Alert, error) {
+	return n.evaluate(object, n.status)
+}
+
+func (n *NamespaceRequiredLabelRule) EvaluateAdmission(ctx context.Context, object interface{}) (alert.Alert, error) {
+	return n.evaluate(object, nil)
+}
+
+func (n *NamespaceRequiredLabelRule) IsEnforcedKind(gvk schema.GroupVersionKind) bool {
+	return gvk.Group == "" && gvk.Kind == "Namespace"
+}
+
+// evaluate evaluates the namespace and sets its violation to the status, nil status for evaluations at admission
+func (n *NamespaceRequiredLabelRule) evaluate(object interface{}, status *Status) (a alert.Alert, err error) {
 	namespace, ok := object.(*corev1.Namespace)
 	if !ok {
 		err = fmt.Errorf("unable to convert object to type %T", namespace)
@@ -106,7 +125,9 @@ func (n *NamespaceRequiredLabelRule) Evaluate(ctx context.Context, object interf
 		a.Message = message
 		a.Violated = true
 	}
-	n.status.setViolation(key, a.Violated)
+	if status != nil {
+		status.setViolation(key, a.Violated)
+	}
 	return
 }
 
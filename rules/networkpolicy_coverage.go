@@ -0,0 +1,314 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/mercari/merlin/alert"
+	merlinv1beta1 "github.com/mercari/merlin/api/v1beta1"
+)
+
+const defaultNetworkPolicyCoverageRecheckIntervalSeconds = 300
+
+type NetworkPolicyCoverageRule struct {
+	rule
+	resource *merlinv1beta1.ClusterRuleNetworkPolicyCoverage
+}
+
+func (n *NetworkPolicyCoverageRule) New(ctx context.Context, cli client.Client, logger logr.Logger, key client.ObjectKey) (Rule, error) {
+	n.cli = cli
+	n.log = logger
+	n.status = &Status{}
+	n.resource = &merlinv1beta1.ClusterRuleNetworkPolicyCoverage{}
+	if err := n.cli.Get(ctx, key, n.resource); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+func (n *NetworkPolicyCoverageRule) GetObject() runtime.Object {
+	return n.resource
+}
+
+func (n NetworkPolicyCoverageRule) GetName() string {
+	return strings.Join([]string{getStructName(n.resource), n.resource.Name}, Separator)
+}
+
+func (n NetworkPolicyCoverageRule) GetObjectMeta() metav1.ObjectMeta {
+	return n.resource.ObjectMeta
+}
+
+func (n NetworkPolicyCoverageRule) GetNotification() merlinv1beta1.Notification {
+	return n.resource.Spec.Notification
+}
+
+func (n *NetworkPolicyCoverageRule) SetFinalizer(finalizer string) {
+	n.resource.ObjectMeta.Finalizers = append(n.resource.ObjectMeta.Finalizers, finalizer)
+}
+
+func (n *NetworkPolicyCoverageRule) RemoveFinalizer(finalizer string) {
+	n.resource.ObjectMeta.Finalizers = removeString(n.resource.ObjectMeta.Finalizers, finalizer)
+}
+
+// GetRecheckInterval returns the interval to re-evaluate all namespaces and policies, since deleting policies or pods
+// changes the coverage without any event of the namespaces or the remaining policies.
+func (n *NetworkPolicyCoverageRule) GetRecheckInterval() time.Duration {
+	if n.resource.Spec.RecheckIntervalSeconds > 0 {
+		return time.Duration(n.resource.Spec.RecheckIntervalSeconds) * time.Second
+	}
+	return defaultNetworkPolicyCoverageRecheckIntervalSeconds * time.Second
+}
+
+func (n *NetworkPolicyCoverageRule) EvaluateAll(ctx context.Context) (alerts []alert.Alert, err error) {
+	namespaces := &corev1.NamespaceList{}
+	if err = n.cli.List(ctx, namespaces); err != nil {
+		return
+	}
+	policies := &networkingv1.NetworkPolicyList{}
+	if err = n.cli.List(ctx, policies); err != nil {
+		return
+	}
+
+	if len(namespaces.Items) == 0 {
+		n.log.Info("no namespace found")
+		return
+	}
+	namespacePolicies := map[string][]networkingv1.NetworkPolicy{}
+	for _, policy := range policies.Items {
+		namespacePolicies[policy.Namespace] = append(namespacePolicies[policy.Namespace], policy)
+	}
+	selectedNamespaces := map[string]bool{}
+	for _, ns := range namespaces.Items {
+		n.log.V(1).Info("evaluating", fmt.Sprintf("%T", ns), ns.Name)
+		var a alert.Alert
+		if a, err = n.checkNamespace(&ns, namespacePolicies[ns.Name]); err != nil {
+			return
+		}
+		if selectedNamespaces[ns.Name], err = n.isNamespaceSelected(&ns); err != nil {
+			return
+		}
+		alerts = append(alerts, a)
+	}
+	for _, policy := range policies.Items {
+		n.log.V(1).Info("evaluating", fmt.Sprintf("%T", policy), policy.Name)
+		var a alert.Alert
+		if a, err = n.checkPolicy(ctx, &policy, selectedNamespaces[policy.Namespace]); err != nil {
+			return
+		}
+		alerts = append(alerts, a)
+	}
+	return
+}
+
+func (n *NetworkPolicyCoverageRule) Evaluate(ctx context.Context, object interface{}) (a alert.Alert, err error) {
+	switch obj := object.(type) {
+	case *corev1.Namespace:
+		policies := &networkingv1.NetworkPolicyList{}
+		if err = n.cli.List(ctx, policies, &client.ListOptions{Namespace: obj.Name}); err != nil {
+			return
+		}
+		return n.checkNamespace(obj, policies.Items)
+	case *networkingv1.NetworkPolicy:
+		var selected bool
+		if selected, err = n.isNamespaceNameSelected(ctx, obj.Namespace); err != nil {
+			return
+		}
+		return n.checkPolicy(ctx, obj, selected)
+	case *corev1.Pod:
+		return n.evaluatePod(ctx, obj)
+	}
+	err = fmt.Errorf("object being evaluated is not type %T, %T or %T", &corev1.Namespace{}, &networkingv1.NetworkPolicy{}, &corev1.Pod{})
+	return
+}
+
+func (n *NetworkPolicyCoverageRule) GetDelaySeconds(object interface{}) (time.Duration, error) {
+	return 0, nil
+}
+
+func (n *NetworkPolicyCoverageRule) newAlert(object interface{}, resourceName string) alert.Alert {
+	return alert.Alert{
+		Suppressed:      n.resource.Spec.Notification.Suppressed,
+		Severity:        n.resource.Spec.Notification.Severity,
+		MessageTemplate: n.resource.Spec.Notification.CustomMessageTemplate,
+		ResourceName:    resourceName,
+		ResourceKind:    getStructName(object),
+		Violated:        false,
+	}
+}
+
+// evaluatePod recovers the first violated NetworkPolicy in the namespace whose podSelector selects the new pod
+func (n *NetworkPolicyCoverageRule) evaluatePod(ctx context.Context, pod *corev1.Pod) (a alert.Alert, err error) {
+	a = n.newAlert(networkingv1.NetworkPolicy{}, "")
+	if n.status.checkedAt == nil || len(n.status.getViolations(pod.Namespace)) == 0 {
+		return
+	}
+	policies := &networkingv1.NetworkPolicyList{}
+	if err = n.cli.List(ctx, policies, &client.ListOptions{Namespace: pod.Namespace}); err != nil {
+		return
+	}
+	for _, policy := range policies.Items {
+		key := client.ObjectKey{Namespace: policy.Namespace, Name: policy.Name}
+		if !n.status.isViolated(key) {
+			continue
+		}
+		var selector labels.Selector
+		if selector, err = metav1.LabelSelectorAsSelector(&policy.Spec.PodSelector); err != nil {
+			return
+		}
+		if selector.Matches(labels.Set(pod.Labels)) {
+			a.ResourceName = key.String()
+			a.Message = "NetworkPolicy has pods for the podSelector"
+			n.status.setViolation(key, a.Violated)
+			return
+		}
+	}
+	return
+}
+
+// checkNamespace checks if the namespace has any NetworkPolicy, and default-deny policies for the policy types of the rule
+func (n *NetworkPolicyCoverageRule) checkNamespace(namespace *corev1.Namespace, policies []networkingv1.NetworkPolicy) (a alert.Alert, err error) {
+	key := client.ObjectKey{Name: namespace.Name}
+	a = n.newAlert(namespace, key.String())
+	if isStringInSlice(n.resource.Spec.IgnoreNamespaces, namespace.Name) {
+		a.Message = "namespace is ignored by the rule"
+		return
+	}
+	var selected bool
+	if selected, err = n.isNamespaceSelected(namespace); err != nil {
+		return
+	} else if !selected {
+		a.Message = "namespace is not selected by the rule"
+		return
+	}
+
+	policyTypes := n.getDefaultDenyPolicyTypes()
+	if len(policies) == 0 {
+		a.Violated = true
+		a.Message = "Namespace has no NetworkPolicy"
+	} else {
+		var notDenied []string
+		for _, policyType := range policyTypes {
+			denied := false
+			for _, policy := range policies {
+				if isDefaultDenyPolicy(&policy, networkingv1.PolicyType(policyType)) {
+					denied = true
+					break
+				}
+			}
+			if !denied {
+				notDenied = append(notDenied, policyType)
+			}
+		}
+		if len(notDenied) > 0 {
+			a.Violated = true
+			a.Message = fmt.Sprintf("Namespace has no default-deny NetworkPolicy for %s", strings.Join(notDenied, ", "))
+		} else {
+			a.Message = fmt.Sprintf("Namespace has default-deny NetworkPolicy for %s", strings.Join(policyTypes, ", "))
+		}
+	}
+	n.status.setViolation(key, a.Violated)
+	return
+}
+
+// checkPolicy checks if the podSelector of the NetworkPolicy matches any pods,
+// empty podSelector selects all pods in the namespace so it's not checked.
+func (n *NetworkPolicyCoverageRule) checkPolicy(ctx context.Context, policy *networkingv1.NetworkPolicy, namespaceSelected bool) (a alert.Alert, err error) {
+	key := client.ObjectKey{Namespace: policy.Namespace, Name: policy.Name}
+	a = n.newAlert(policy, key.String())
+	if isStringInSlice(n.resource.Spec.IgnoreNamespaces, policy.Namespace) {
+		a.Message = "namespace is ignored by the rule"
+		return
+	} else if !namespaceSelected {
+		a.Message = "namespace is not selected by the rule"
+		return
+	}
+	selector, err := metav1.LabelSelectorAsSelector(&policy.Spec.PodSelector)
+	if err != nil {
+		return
+	}
+	if selector.Empty() {
+		a.Message = "NetworkPolicy selects all pods in the namespace"
+	} else {
+		pods := &corev1.PodList{}
+		if err = n.cli.List(ctx, pods, &client.ListOptions{Namespace: policy.Namespace, LabelSelector: selector}); err != nil {
+			return
+		}
+		if len(pods.Items) == 0 {
+			a.Violated = true
+			a.Message = "NetworkPolicy has no matched pods for the podSelector"
+		} else {
+			a.Message = "NetworkPolicy has pods for the podSelector"
+		}
+	}
+	n.status.setViolation(key, a.Violated)
+	return
+}
+
+// isNamespaceSelected checks if the namespace matches the namespace selector of the rule
+func (n *NetworkPolicyCoverageRule) isNamespaceSelected(namespace *corev1.Namespace) (bool, error) {
+	if n.resource.Spec.NamespaceSelector == nil {
+		return true, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(n.resource.Spec.NamespaceSelector)
+	if err != nil {
+		return false, err
+	}
+	return selector.Matches(labels.Set(namespace.Labels)), nil
+}
+
+// isNamespaceNameSelected gets the namespace and checks if it matches the namespace selector of the rule
+func (n *NetworkPolicyCoverageRule) isNamespaceNameSelected(ctx context.Context, name string) (bool, error) {
+	if n.resource.Spec.NamespaceSelector == nil {
+		return true, nil
+	}
+	namespace := &corev1.Namespace{}
+	if err := n.cli.Get(ctx, client.ObjectKey{Name: name}, namespace); err != nil {
+		return false, err
+	}
+	return n.isNamespaceSelected(namespace)
+}
+
+func (n *NetworkPolicyCoverageRule) getDefaultDenyPolicyTypes() []string {
+	if len(n.resource.Spec.DefaultDenyPolicyTypes) > 0 {
+		return n.resource.Spec.DefaultDenyPolicyTypes
+	}
+	return []string{string(networkingv1.PolicyTypeIngress)}
+}
+
+// isDefaultDenyPolicy checks if the NetworkPolicy selects all pods in the namespace and allows no traffic of the policy type.
+// Policies without policyTypes apply to Ingress, and to Egress if they have egress rules.
+func isDefaultDenyPolicy(policy *networkingv1.NetworkPolicy, policyType networkingv1.PolicyType) bool {
+	if len(policy.Spec.PodSelector.MatchLabels) > 0 || len(policy.Spec.PodSelector.MatchExpressions) > 0 {
+		return false
+	}
+	policyTypes := policy.Spec.PolicyTypes
+	if len(policyTypes) == 0 {
+		policyTypes = []networkingv1.PolicyType{networkingv1.PolicyTypeIngress}
+		if len(policy.Spec.Egress) > 0 {
+			policyTypes = append(policyTypes, networkingv1.PolicyTypeEgress)
+		}
+	}
+	applied := false
+	for _, t := range policyTypes {
+		if t == policyType {
+			applied = true
+		}
+	}
+	if !applied {
+		return false
+	}
+	if policyType == networkingv1.PolicyTypeEgress {
+		return len(policy.Spec.Egress) == 0
+	}
+	return len(policy.Spec.Ingress) == 0
+}
@@ -0,0 +1,350 @@
+package rules
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/zapr"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/mercari/merlin/alert"
+	merlinv1beta1 "github.com/mercari/merlin/api/v1beta1"
+	"github.com/mercari/merlin/mocks"
+)
+
+func newTestNetworkPolicy(name string, podLabels map[string]string, policyTypes ...networkingv1.PolicyType) networkingv1.NetworkPolicy {
+	return networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: name},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{MatchLabels: podLabels},
+			PolicyTypes: policyTypes,
+		},
+	}
+}
+
+func Test_NetworkPolicyCoverageRule_Basic(t *testing.T) {
+	notification := merlinv1beta1.Notification{
+		Notifiers:  []string{"testNotifier"},
+		Suppressed: true,
+	}
+
+	merlinv1beta1Rule := &merlinv1beta1.ClusterRuleNetworkPolicyCoverage{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-r"},
+		Spec: merlinv1beta1.ClusterRuleNetworkPolicyCoverageSpec{
+			Notification: notification,
+		},
+	}
+
+	r := &NetworkPolicyCoverageRule{resource: merlinv1beta1Rule}
+	assert.Equal(t, merlinv1beta1Rule.ObjectMeta, r.GetObjectMeta())
+	assert.Equal(t, merlinv1beta1Rule, r.GetObject())
+	assert.Equal(t, notification, r.GetNotification())
+	assert.Equal(t, "ClusterRuleNetworkPolicyCoverage/test-r", r.GetName())
+	assert.Equal(t, 5*time.Minute, r.GetRecheckInterval())
+
+	finalizer := "test.finalizer"
+	r.SetFinalizer(finalizer)
+	assert.Equal(t, finalizer, r.resource.Finalizers[0])
+	r.RemoveFinalizer(finalizer)
+	assert.Empty(t, r.resource.Finalizers)
+}
+
+func Test_isDefaultDenyPolicy(t *testing.T) {
+	denyIngress := newTestNetworkPolicy("deny", nil)
+	assert.True(t, isDefaultDenyPolicy(&denyIngress, networkingv1.PolicyTypeIngress))
+	assert.False(t, isDefaultDenyPolicy(&denyIngress, networkingv1.PolicyTypeEgress))
+
+	denyAll := newTestNetworkPolicy("deny", nil, networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress)
+	assert.True(t, isDefaultDenyPolicy(&denyAll, networkingv1.PolicyTypeIngress))
+	assert.True(t, isDefaultDenyPolicy(&denyAll, networkingv1.PolicyTypeEgress))
+
+	allowIngress := newTestNetworkPolicy("allow", nil)
+	allowIngress.Spec.Ingress = []networkingv1.NetworkPolicyIngressRule{{}}
+	assert.False(t, isDefaultDenyPolicy(&allowIngress, networkingv1.PolicyTypeIngress))
+
+	selected := newTestNetworkPolicy("app", map[string]string{"app": "web"})
+	assert.False(t, isDefaultDenyPolicy(&selected, networkingv1.PolicyTypeIngress))
+}
+
+func Test_NetworkPolicyCoverageRule_Evaluate(t *testing.T) {
+	ctx := context.Background()
+	log := zapr.NewLogger(zap.L())
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mocks.NewMockClient(mockCtrl)
+	tenantNS := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test", Labels: map[string]string{"tenant": "true"}}}
+	webPolicy := newTestNetworkPolicy("web", map[string]string{"app": "web"})
+
+	cases := []struct {
+		desc      string
+		mockCalls []*gomock.Call
+		resource  interface{}
+		expect    alert.Alert
+		expectErr bool
+	}{
+		{
+			desc:      "non namespace, networkpolicy or pod should have error",
+			resource:  &corev1.Service{},
+			expectErr: true,
+		},
+		{
+			desc: "ignored namespace should not get violated alert",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().
+					List(ctx, &networkingv1.NetworkPolicyList{}, &client.ListOptions{Namespace: "ignoredNS"}).
+					Return(nil),
+			},
+			resource: &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ignoredNS"}},
+			expect: alert.Alert{
+				Message:      "namespace is ignored by the rule",
+				ResourceKind: "Namespace",
+				ResourceName: "/ignoredNS",
+			},
+		},
+		{
+			desc: "namespace not selected should not get violated alert",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().
+					List(ctx, &networkingv1.NetworkPolicyList{}, &client.ListOptions{Namespace: "system"}).
+					Return(nil),
+			},
+			resource: &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "system"}},
+			expect: alert.Alert{
+				Message:      "namespace is not selected by the rule",
+				ResourceKind: "Namespace",
+				ResourceName: "/system",
+			},
+		},
+		{
+			desc: "namespace without networkpolicy should get violated alert",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().
+					List(ctx, &networkingv1.NetworkPolicyList{}, &client.ListOptions{Namespace: "test"}).
+					Return(nil),
+			},
+			resource: tenantNS,
+			expect: alert.Alert{
+				Message:      "Namespace has no NetworkPolicy",
+				ResourceKind: "Namespace",
+				ResourceName: "/test",
+				Violated:     true,
+			},
+		},
+		{
+			desc: "namespace without default-deny networkpolicy should get violated alert",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().
+					List(ctx, &networkingv1.NetworkPolicyList{}, &client.ListOptions{Namespace: "test"}).
+					SetArg(1, networkingv1.NetworkPolicyList{Items: []networkingv1.NetworkPolicy{webPolicy}}).
+					Return(nil),
+			},
+			resource: tenantNS,
+			expect: alert.Alert{
+				Message:      "Namespace has no default-deny NetworkPolicy for Ingress",
+				ResourceKind: "Namespace",
+				ResourceName: "/test",
+				Violated:     true,
+			},
+		},
+		{
+			desc: "namespace with default-deny networkpolicy should not get violated alert",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().
+					List(ctx, &networkingv1.NetworkPolicyList{}, &client.ListOptions{Namespace: "test"}).
+					SetArg(1, networkingv1.NetworkPolicyList{Items: []networkingv1.NetworkPolicy{webPolicy, newTestNetworkPolicy("deny", nil)}}).
+					Return(nil),
+			},
+			resource: tenantNS,
+			expect: alert.Alert{
+				Message:      "Namespace has default-deny NetworkPolicy for Ingress",
+				ResourceKind: "Namespace",
+				ResourceName: "/test",
+			},
+		},
+		{
+			desc: "networkpolicy without matched pods should get violated alert",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().
+					Get(ctx, client.ObjectKey{Name: "test"}, &corev1.Namespace{}).
+					SetArg(2, *tenantNS).
+					Return(nil),
+				mockClient.EXPECT().
+					List(ctx, &corev1.PodList{}, &client.ListOptions{Namespace: "test", LabelSelector: labels.SelectorFromSet(labels.Set{"app": "web"})}).
+					Return(nil),
+			},
+			resource: &webPolicy,
+			expect: alert.Alert{
+				Message:      "NetworkPolicy has no matched pods for the podSelector",
+				ResourceKind: "NetworkPolicy",
+				ResourceName: "test/web",
+				Violated:     true,
+			},
+		},
+		{
+			desc: "networkpolicy selecting all pods should not get violated alert",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().
+					Get(ctx, client.ObjectKey{Name: "test"}, &corev1.Namespace{}).
+					SetArg(2, *tenantNS).
+					Return(nil),
+			},
+			resource: &networkingv1.NetworkPolicy{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "deny"}},
+			expect: alert.Alert{
+				Message:      "NetworkPolicy selects all pods in the namespace",
+				ResourceKind: "NetworkPolicy",
+				ResourceName: "test/deny",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(tt *testing.T) {
+			for _, call := range tc.mockCalls {
+				call.Times(1)
+			}
+			r := &NetworkPolicyCoverageRule{
+				rule: rule{cli: mockClient, log: log, status: &Status{}},
+				resource: &merlinv1beta1.ClusterRuleNetworkPolicyCoverage{
+					Spec: merlinv1beta1.ClusterRuleNetworkPolicyCoverageSpec{
+						IgnoreNamespaces:  []string{"ignoredNS"},
+						NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"tenant": "true"}},
+					},
+				},
+			}
+			a, err := r.Evaluate(ctx, tc.resource)
+			if tc.expectErr {
+				assert.Error(tt, err)
+			} else {
+				assert.NoError(tt, err)
+				assert.Equal(tt, tc.expect, a)
+			}
+		})
+	}
+}
+
+func Test_NetworkPolicyCoverageRule_EvaluatePod(t *testing.T) {
+	ctx := context.Background()
+	log := zapr.NewLogger(zap.L())
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mocks.NewMockClient(mockCtrl)
+	r := &NetworkPolicyCoverageRule{
+		rule:     rule{cli: mockClient, log: log, status: &Status{}},
+		resource: &merlinv1beta1.ClusterRuleNetworkPolicyCoverage{},
+	}
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "web-1", Labels: map[string]string{"app": "web"}}}
+
+	a, err := r.Evaluate(ctx, pod)
+	assert.NoError(t, err)
+	assert.Equal(t, alert.Alert{ResourceKind: "NetworkPolicy"}, a)
+
+	r.status.setViolation(client.ObjectKey{Namespace: "test", Name: "web"}, true)
+	mockClient.EXPECT().
+		List(ctx, &networkingv1.NetworkPolicyList{}, &client.ListOptions{Namespace: "test"}).
+		SetArg(1, networkingv1.NetworkPolicyList{Items: []networkingv1.NetworkPolicy{
+			newTestNetworkPolicy("api", map[string]string{"app": "api"}),
+			newTestNetworkPolicy("web", map[string]string{"app": "web"}),
+		}}).
+		Return(nil).
+		Times(1)
+	a, err = r.Evaluate(ctx, pod)
+	assert.NoError(t, err)
+	assert.Equal(t, alert.Alert{
+		Message:      "NetworkPolicy has pods for the podSelector",
+		ResourceKind: "NetworkPolicy",
+		ResourceName: "test/web",
+	}, a)
+	assert.False(t, r.status.isViolated(client.ObjectKey{Namespace: "test", Name: "web"}))
+}
+
+func Test_NetworkPolicyCoverageRule_EvaluateAll(t *testing.T) {
+	ctx := context.Background()
+	log := zapr.NewLogger(zap.L())
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mocks.NewMockClient(mockCtrl)
+	r := &NetworkPolicyCoverageRule{
+		rule: rule{cli: mockClient, log: log, status: &Status{}},
+		resource: &merlinv1beta1.ClusterRuleNetworkPolicyCoverage{
+			Spec: merlinv1beta1.ClusterRuleNetworkPolicyCoverageSpec{DefaultDenyPolicyTypes: []string{"Ingress", "Egress"}},
+		},
+	}
+
+	cases := []struct {
+		desc      string
+		mockCalls []*gomock.Call
+		expect    []alert.Alert
+		expectErr bool
+	}{
+		{
+			desc: "no resources should returns nil alerts",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().
+					List(ctx, &corev1.NamespaceList{}).
+					Return(nil),
+				mockClient.EXPECT().
+					List(ctx, &networkingv1.NetworkPolicyList{}).
+					Return(nil),
+			},
+		},
+		{
+			desc: "namespaces and networkpolicies should be evaluated",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().
+					List(ctx, &corev1.NamespaceList{}).
+					SetArg(1, corev1.NamespaceList{Items: []corev1.Namespace{
+						{ObjectMeta: metav1.ObjectMeta{Name: "test"}},
+						{ObjectMeta: metav1.ObjectMeta{Name: "other"}},
+					}}).
+					Return(nil),
+				mockClient.EXPECT().
+					List(ctx, &networkingv1.NetworkPolicyList{}).
+					SetArg(1, networkingv1.NetworkPolicyList{Items: []networkingv1.NetworkPolicy{
+						newTestNetworkPolicy("deny", nil, networkingv1.PolicyTypeIngress),
+					}}).
+					Return(nil),
+			},
+			expect: []alert.Alert{
+				{
+					Message:      "Namespace has no default-deny NetworkPolicy for Egress",
+					ResourceKind: "Namespace",
+					ResourceName: "/test",
+					Violated:     true,
+				},
+				{
+					Message:      "Namespace has no NetworkPolicy",
+					ResourceKind: "Namespace",
+					ResourceName: "/other",
+					Violated:     true,
+				},
+				{
+					Message:      "NetworkPolicy selects all pods in the namespace",
+					ResourceKind: "NetworkPolicy",
+					ResourceName: "test/deny",
+				},
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.desc, func(tt *testing.T) {
+			for _, call := range tc.mockCalls {
+				call.Times(1)
+			}
+			alerts, err := r.EvaluateAll(ctx)
+			if tc.expectErr {
+				assert.Error(tt, err)
+			} else {
+				assert.NoError(tt, err)
+				assert.Equal(tt, tc.expect, alerts)
+			}
+		})
+	}
+}
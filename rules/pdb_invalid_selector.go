@@ -101,11 +101,8 @@ func (s *PDBInvalidSelectorRule) Evaluate(ctx context.Context, object interface{
 		a.Message = "namespace is ignored by the rule"
 		return
 	}
-	pods := corev1.PodList{}
-	if err = s.cli.List(ctx, &pods, &client.ListOptions{
-		Namespace:     pdb.Namespace,
-		LabelSelector: labels.Set(pdb.Spec.Selector.MatchLabels).AsSelector(),
-	}); err != nil && client.IgnoreNotFound(err) != nil {
+	var pods corev1.PodList
+	if pods, err = listPDBPods(ctx, s.cli, pdb); err != nil {
 		return
 	}
 	if len(pods.Items) <= 0 {
@@ -121,3 +118,35 @@ func (s *PDBInvalidSelectorRule) Evaluate(ctx context.Context, object interface{
 func (s *PDBInvalidSelectorRule) GetDelaySeconds(object interface{}) (time.Duration, error) {
 	return 0, nil
 }
+
+// getPDBSelector returns the selector for the pods covered by the PDB, with both matchLabels and matchExpressions
+func getPDBSelector(pdb *policyv1beta1.PodDisruptionBudget) (labels.Selector, error) {
+	if pdb.Spec.Selector == nil {
+		return labels.Nothing(), nil
+	}
+	return metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+}
+
+// listPDBPods lists the pods in the PDB namespace that are covered by the PDB
+func listPDBPods(ctx context.Context, cli client.Client, pdb *policyv1beta1.PodDisruptionBudget) (pods corev1.PodList, err error) {
+	var selector labels.Selector
+	if selector, err = getPDBSelector(pdb); err != nil {
+		return
+	}
+	if err = cli.List(ctx, &pods, &client.ListOptions{
+		Namespace:     pdb.Namespace,
+		LabelSelector: selector,
+	}); err != nil && client.IgnoreNotFound(err) == nil {
+		err = nil
+	}
+	return
+}
+
+// pdbCoversLabels checks if the pods with the labels are covered by the PDB
+func pdbCoversLabels(pdb *policyv1beta1.PodDisruptionBudget, podLabels map[string]string) (bool, error) {
+	selector, err := getPDBSelector(pdb)
+	if err != nil {
+		return false, err
+	}
+	return selector.Matches(labels.Set(podLabels)), nil
+}
@@ -0,0 +1,215 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/mercari/merlin/alert"
+	merlinv1beta1 "github.com/mercari/merlin/api/v1beta1"
+)
+
+const defaultPDBOverlapRecheckIntervalSeconds = 300
+
+type PDBOverlapRule struct {
+	rule
+	resource *merlinv1beta1.ClusterRulePDBOverlap
+}
+
+func (p *PDBOverlapRule) New(ctx context.Context, cli client.Client, logger logr.Logger, key client.ObjectKey) (Rule, error) {
+	p.cli = cli
+	p.log = logger
+	p.status = &Status{}
+	p.resource = &merlinv1beta1.ClusterRulePDBOverlap{}
+	if err := p.cli.Get(ctx, key, p.resource); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *PDBOverlapRule) GetObject() runtime.Object {
+	return p.resource
+}
+
+func (p PDBOverlapRule) GetName() string {
+	return strings.Join([]string{getStructName(p.resource), p.resource.Name}, Separator)
+}
+
+func (p PDBOverlapRule) GetObjectMeta() metav1.ObjectMeta {
+	return p.resource.ObjectMeta
+}
+
+func (p PDBOverlapRule) GetNotification() merlinv1beta1.Notification {
+	return p.resource.Spec.Notification
+}
+
+func (p *PDBOverlapRule) SetFinalizer(finalizer string) {
+	p.resource.ObjectMeta.Finalizers = append(p.resource.ObjectMeta.Finalizers, finalizer)
+}
+
+func (p *PDBOverlapRule) RemoveFinalizer(finalizer string) {
+	p.resource.ObjectMeta.Finalizers = removeString(p.resource.ObjectMeta.Finalizers, finalizer)
+}
+
+// GetRecheckInterval returns the interval to re-evaluate all PDBs, since deleting PDBs or pods
+// resolves overlaps without any event of the other PDBs.
+func (p *PDBOverlapRule) GetRecheckInterval() time.Duration {
+	if p.resource.Spec.RecheckIntervalSeconds > 0 {
+		return time.Duration(p.resource.Spec.RecheckIntervalSeconds) * time.Second
+	}
+	return defaultPDBOverlapRecheckIntervalSeconds * time.Second
+}
+
+func (p *PDBOverlapRule) EvaluateAll(ctx context.Context) (alerts []alert.Alert, err error) {
+	pdbs := &policyv1beta1.PodDisruptionBudgetList{}
+	if err = p.cli.List(ctx, pdbs); err != nil {
+		return
+	}
+	pods := &corev1.PodList{}
+	if err = p.cli.List(ctx, pods); err != nil {
+		return
+	}
+
+	if len(pdbs.Items) == 0 {
+		p.log.Info("no pdb found")
+		return
+	}
+	namespacePods := map[string][]corev1.Pod{}
+	for _, pod := range pods.Items {
+		namespacePods[pod.Namespace] = append(namespacePods[pod.Namespace], pod)
+	}
+	namespacePDBs := map[string][]policyv1beta1.PodDisruptionBudget{}
+	for _, pdb := range pdbs.Items {
+		namespacePDBs[pdb.Namespace] = append(namespacePDBs[pdb.Namespace], pdb)
+	}
+	for _, pdb := range pdbs.Items {
+		p.log.V(1).Info("evaluating", fmt.Sprintf("%T", pdb), pdb.Name)
+		var a alert.Alert
+		if a, err = p.evaluatePDB(&pdb, namespacePDBs[pdb.Namespace], namespacePods[pdb.Namespace]); err != nil {
+			return
+		}
+		alerts = append(alerts, a)
+	}
+	return
+}
+
+// Evaluate evaluates the PDB, or the first PDB in the namespace of the pod being changed,
+// ResourceReconciler uses EvaluateRelated for the alerts of all PDBs in the namespace.
+func (p *PDBOverlapRule) Evaluate(ctx context.Context, object interface{}) (a alert.Alert, err error) {
+	var alerts []alert.Alert
+	if alerts, err = p.EvaluateRelated(ctx, object); err != nil {
+		return
+	}
+	pdb, isPDB := object.(*policyv1beta1.PodDisruptionBudget)
+	for _, pdbAlert := range alerts {
+		if !isPDB || pdbAlert.ResourceName == (client.ObjectKey{Namespace: pdb.Namespace, Name: pdb.Name}).String() {
+			return pdbAlert, nil
+		}
+	}
+	return p.newAlert(""), nil
+}
+
+// EvaluateRelated evaluates all PDBs in the namespace of the PDB or pod being changed, since changes of a
+// PDB or pod labels can change the overlaps of the other PDBs.
+func (p *PDBOverlapRule) EvaluateRelated(ctx context.Context, object interface{}) (alerts []alert.Alert, err error) {
+	var namespace string
+	switch obj := object.(type) {
+	case *policyv1beta1.PodDisruptionBudget:
+		namespace = obj.Namespace
+	case *corev1.Pod:
+		namespace = obj.Namespace
+	default:
+		err = fmt.Errorf("object being evaluated is not type %T or %T", &corev1.Pod{}, &policyv1beta1.PodDisruptionBudget{})
+		return
+	}
+	if isStringInSlice(p.resource.Spec.IgnoreNamespaces, namespace) {
+		if pdb, ok := object.(*policyv1beta1.PodDisruptionBudget); ok {
+			var a alert.Alert
+			a, err = p.evaluatePDB(pdb, nil, nil)
+			alerts = append(alerts, a)
+		}
+		return
+	}
+	pdbs := policyv1beta1.PodDisruptionBudgetList{}
+	if err = p.cli.List(ctx, &pdbs, &client.ListOptions{Namespace: namespace}); err != nil && client.IgnoreNotFound(err) != nil {
+		return
+	}
+	pods := corev1.PodList{}
+	if err = p.cli.List(ctx, &pods, &client.ListOptions{Namespace: namespace}); err != nil && client.IgnoreNotFound(err) != nil {
+		return
+	}
+	err = nil
+	for _, pdb := range pdbs.Items {
+		var a alert.Alert
+		if a, err = p.evaluatePDB(&pdb, pdbs.Items, pods.Items); err != nil {
+			return
+		}
+		alerts = append(alerts, a)
+	}
+	return
+}
+
+func (p *PDBOverlapRule) GetDelaySeconds(object interface{}) (time.Duration, error) {
+	return 0, nil
+}
+
+func (p *PDBOverlapRule) newAlert(resourceName string) alert.Alert {
+	return alert.Alert{
+		Suppressed:      p.resource.Spec.Notification.Suppressed,
+		Severity:        p.resource.Spec.Notification.Severity,
+		MessageTemplate: p.resource.Spec.Notification.CustomMessageTemplate,
+		Message:         "PDB does not cover the same pods as other PDBs",
+		ResourceName:    resourceName,
+		ResourceKind:    getStructName(policyv1beta1.PodDisruptionBudget{}),
+		Violated:        false,
+	}
+}
+
+// evaluatePDB checks the PDB against the other PDBs in the namespace, each overlapping pair is only reported
+// by the PDB with the smaller name, so a pair of PDBs has a single alert.
+func (p *PDBOverlapRule) evaluatePDB(pdb *policyv1beta1.PodDisruptionBudget, pdbs []policyv1beta1.PodDisruptionBudget, pods []corev1.Pod) (a alert.Alert, err error) {
+	key := client.ObjectKey{Namespace: pdb.Namespace, Name: pdb.Name}
+	a = p.newAlert(key.String())
+	if isStringInSlice(p.resource.Spec.IgnoreNamespaces, key.Namespace) {
+		a.Message = "namespace is ignored by the rule"
+		return
+	}
+	var selector labels.Selector
+	if selector, err = getPDBSelector(pdb); err != nil {
+		return
+	}
+	var overlaps []string
+	for _, other := range pdbs {
+		if other.Name <= pdb.Name {
+			continue
+		}
+		var otherSelector labels.Selector
+		if otherSelector, err = getPDBSelector(&other); err != nil {
+			return
+		}
+		var covered int
+		for _, pod := range pods {
+			if selector.Matches(labels.Set(pod.Labels)) && otherSelector.Matches(labels.Set(pod.Labels)) {
+				covered++
+			}
+		}
+		if covered > 0 {
+			overlaps = append(overlaps, fmt.Sprintf("`%s` (%d pods)", other.Name, covered))
+		}
+	}
+	if len(overlaps) > 0 {
+		a.Violated = true
+		a.Message = "PDB covers the same pods as other PDBs and the pods cannot be evicted: " + strings.Join(overlaps, ", ")
+	}
+	p.status.setViolation(key, a.Violated)
+	return
+}
@@ -0,0 +1,321 @@
+package rules
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/zapr"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/mercari/merlin/alert"
+	merlinv1beta1 "github.com/mercari/merlin/api/v1beta1"
+	"github.com/mercari/merlin/mocks"
+)
+
+func Test_PDBOverlapRule_Basic(t *testing.T) {
+	notification := merlinv1beta1.Notification{
+		Notifiers:  []string{"testNotifier"},
+		Suppressed: true,
+	}
+
+	merlinv1beta1Rule := &merlinv1beta1.ClusterRulePDBOverlap{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-r"},
+		Spec: merlinv1beta1.ClusterRulePDBOverlapSpec{
+			Notification: notification,
+		},
+	}
+
+	r := &PDBOverlapRule{resource: merlinv1beta1Rule}
+	assert.Equal(t, merlinv1beta1Rule.ObjectMeta, r.GetObjectMeta())
+	assert.Equal(t, merlinv1beta1Rule, r.GetObject())
+	assert.Equal(t, notification, r.GetNotification())
+	assert.Equal(t, "ClusterRulePDBOverlap/test-r", r.GetName())
+
+	finalizer := "test.finalizer"
+	r.SetFinalizer(finalizer)
+	assert.Equal(t, finalizer, r.resource.Finalizers[0])
+	r.RemoveFinalizer(finalizer)
+	assert.Empty(t, r.resource.Finalizers)
+	delay, err := r.GetDelaySeconds(&corev1.Pod{})
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), delay)
+	assert.Equal(t, 300*time.Second, r.GetRecheckInterval())
+}
+
+func newTestPDB(name string, selector *metav1.LabelSelector) policyv1beta1.PodDisruptionBudget {
+	return policyv1beta1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: name},
+		Spec:       policyv1beta1.PodDisruptionBudgetSpec{Selector: selector},
+	}
+}
+
+func Test_PDBOverlapRule_Evaluate(t *testing.T) {
+	ctx := context.Background()
+	log := zapr.NewLogger(zap.L())
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mocks.NewMockClient(mockCtrl)
+	pods := corev1.PodList{Items: []corev1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "app-1", Labels: map[string]string{"app": "app", "tier": "web"}}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "app-2", Labels: map[string]string{"app": "app", "tier": "web"}}},
+	}}
+
+	cases := []struct {
+		desc      string
+		mockCalls []*gomock.Call
+		resource  interface{}
+		expect    alert.Alert
+		expectErr bool
+	}{
+		{
+			desc:      "non pod or pdb should have error",
+			resource:  &corev1.Namespace{},
+			expectErr: true,
+		},
+		{
+			desc:     "ignored namespace should not get violated alert",
+			resource: &policyv1beta1.PodDisruptionBudget{ObjectMeta: metav1.ObjectMeta{Namespace: "ignoredNS", Name: "app"}},
+			expect: alert.Alert{
+				Message:      "namespace is ignored by the rule",
+				ResourceKind: "PodDisruptionBudget",
+				ResourceName: "ignoredNS/app",
+			},
+		},
+		{
+			desc: "pdbs covering different pods should not get violated alert",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().
+					List(ctx, &policyv1beta1.PodDisruptionBudgetList{}, &client.ListOptions{Namespace: "test"}).
+					SetArg(1, policyv1beta1.PodDisruptionBudgetList{Items: []policyv1beta1.PodDisruptionBudget{
+						newTestPDB("app", &metav1.LabelSelector{MatchLabels: map[string]string{"app": "app"}}),
+						newTestPDB("other", &metav1.LabelSelector{MatchLabels: map[string]string{"app": "other"}}),
+						newTestPDB("web", &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+							{Key: "tier", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"web"}},
+						}}),
+						newTestPDB("nil", nil),
+					}}).
+					Return(nil),
+				mockClient.EXPECT().
+					List(ctx, &corev1.PodList{}, &client.ListOptions{Namespace: "test"}).
+					SetArg(1, pods).
+					Return(nil),
+			},
+			resource: &policyv1beta1.PodDisruptionBudget{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "app"}},
+			expect: alert.Alert{
+				Message:      "PDB does not cover the same pods as other PDBs",
+				ResourceKind: "PodDisruptionBudget",
+				ResourceName: "test/app",
+			},
+		},
+		{
+			desc: "pdbs covering same pods with match expressions should get violated alert",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().
+					List(ctx, &policyv1beta1.PodDisruptionBudgetList{}, &client.ListOptions{Namespace: "test"}).
+					SetArg(1, policyv1beta1.PodDisruptionBudgetList{Items: []policyv1beta1.PodDisruptionBudget{
+						newTestPDB("app", &metav1.LabelSelector{MatchLabels: map[string]string{"app": "app"}}),
+						newTestPDB("web", &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+							{Key: "tier", Operator: metav1.LabelSelectorOpExists},
+						}}),
+					}}).
+					Return(nil),
+				mockClient.EXPECT().
+					List(ctx, &corev1.PodList{}, &client.ListOptions{Namespace: "test"}).
+					SetArg(1, pods).
+					Return(nil),
+			},
+			resource: &pods.Items[0],
+			expect: alert.Alert{
+				Message:      "PDB covers the same pods as other PDBs and the pods cannot be evicted: `web` (2 pods)",
+				ResourceKind: "PodDisruptionBudget",
+				ResourceName: "test/app",
+				Violated:     true,
+			},
+		},
+		{
+			desc: "pdb with invalid selector should have error",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().
+					List(ctx, &policyv1beta1.PodDisruptionBudgetList{}, &client.ListOptions{Namespace: "test"}).
+					SetArg(1, policyv1beta1.PodDisruptionBudgetList{Items: []policyv1beta1.PodDisruptionBudget{
+						newTestPDB("app", &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+							{Key: "tier", Operator: "Invalid"},
+						}}),
+					}}).
+					Return(nil),
+				mockClient.EXPECT().
+					List(ctx, &corev1.PodList{}, &client.ListOptions{Namespace: "test"}).
+					SetArg(1, pods).
+					Return(nil),
+			},
+			resource:  &pods.Items[0],
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(tt *testing.T) {
+			for _, call := range tc.mockCalls {
+				call.Times(1)
+			}
+			r := &PDBOverlapRule{
+				rule: rule{cli: mockClient, log: log, status: &Status{}},
+				resource: &merlinv1beta1.ClusterRulePDBOverlap{
+					Spec: merlinv1beta1.ClusterRulePDBOverlapSpec{IgnoreNamespaces: []string{"ignoredNS"}},
+				},
+			}
+			a, err := r.Evaluate(ctx, tc.resource)
+			if tc.expectErr {
+				assert.Error(tt, err)
+			} else {
+				assert.NoError(tt, err)
+				assert.Equal(tt, tc.expect, a)
+			}
+		})
+	}
+}
+
+func Test_PDBOverlapRule_EvaluateRelated(t *testing.T) {
+	ctx := context.Background()
+	log := zapr.NewLogger(zap.L())
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mocks.NewMockClient(mockCtrl)
+	pdb := newTestPDB("b", &metav1.LabelSelector{MatchLabels: map[string]string{"app": "app"}})
+	pdbs := policyv1beta1.PodDisruptionBudgetList{Items: []policyv1beta1.PodDisruptionBudget{
+		newTestPDB("a", &metav1.LabelSelector{MatchLabels: map[string]string{"app": "app"}}),
+		pdb,
+	}}
+	r := &PDBOverlapRule{
+		rule:     rule{cli: mockClient, log: log, status: &Status{}},
+		resource: &merlinv1beta1.ClusterRulePDBOverlap{},
+	}
+
+	// the pair of overlapping pdbs is reported once by the pdb with the smaller name
+	mockClient.EXPECT().
+		List(ctx, &policyv1beta1.PodDisruptionBudgetList{}, &client.ListOptions{Namespace: "test"}).
+		SetArg(1, pdbs).
+		Return(nil).
+		Times(1)
+	mockClient.EXPECT().
+		List(ctx, &corev1.PodList{}, &client.ListOptions{Namespace: "test"}).
+		SetArg(1, corev1.PodList{Items: []corev1.Pod{
+			{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "app-1", Labels: map[string]string{"app": "app"}}},
+		}}).
+		Return(nil).
+		Times(1)
+	alerts, err := r.EvaluateRelated(ctx, &pdb)
+	assert.NoError(t, err)
+	assert.Equal(t, []alert.Alert{
+		{
+			Message:      "PDB covers the same pods as other PDBs and the pods cannot be evicted: `b` (1 pods)",
+			ResourceKind: "PodDisruptionBudget",
+			ResourceName: "test/a",
+			Violated:     true,
+		},
+		{
+			Message:      "PDB does not cover the same pods as other PDBs",
+			ResourceKind: "PodDisruptionBudget",
+			ResourceName: "test/b",
+		},
+	}, alerts)
+
+	// deleting the other pdb should recover the remaining pdb
+	mockClient.EXPECT().
+		List(ctx, &policyv1beta1.PodDisruptionBudgetList{}, &client.ListOptions{Namespace: "test"}).
+		SetArg(1, policyv1beta1.PodDisruptionBudgetList{Items: pdbs.Items[:1]}).
+		Return(nil).
+		Times(1)
+	mockClient.EXPECT().
+		List(ctx, &corev1.PodList{}, &client.ListOptions{Namespace: "test"}).
+		Return(nil).
+		Times(1)
+	alerts, err = r.EvaluateRelated(ctx, &pdb)
+	assert.NoError(t, err)
+	assert.Len(t, alerts, 1)
+	assert.Empty(t, r.status.getAllViolations())
+}
+
+func Test_PDBOverlapRule_EvaluateAll(t *testing.T) {
+	ctx := context.Background()
+	log := zapr.NewLogger(zap.L())
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mocks.NewMockClient(mockCtrl)
+	r := &PDBOverlapRule{
+		rule:     rule{cli: mockClient, log: log, status: &Status{}},
+		resource: &merlinv1beta1.ClusterRulePDBOverlap{},
+	}
+
+	cases := []struct {
+		desc      string
+		mockCalls []*gomock.Call
+		expect    []alert.Alert
+		expectErr bool
+	}{
+		{
+			desc: "no resources should returns nil alerts",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().
+					List(ctx, &policyv1beta1.PodDisruptionBudgetList{}).
+					Return(nil),
+				mockClient.EXPECT().
+					List(ctx, &corev1.PodList{}).
+					Return(nil),
+			},
+		},
+		{
+			desc: "pdbs should be evaluated with the pods in their namespaces",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().
+					List(ctx, &policyv1beta1.PodDisruptionBudgetList{}).
+					SetArg(1, policyv1beta1.PodDisruptionBudgetList{Items: []policyv1beta1.PodDisruptionBudget{
+						newTestPDB("a", &metav1.LabelSelector{MatchLabels: map[string]string{"app": "app"}}),
+						newTestPDB("b", &metav1.LabelSelector{MatchLabels: map[string]string{"app": "app"}}),
+					}}).
+					Return(nil),
+				mockClient.EXPECT().
+					List(ctx, &corev1.PodList{}).
+					SetArg(1, corev1.PodList{Items: []corev1.Pod{
+						{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "app-1", Labels: map[string]string{"app": "app"}}},
+						{ObjectMeta: metav1.ObjectMeta{Namespace: "other", Name: "app-1", Labels: map[string]string{"app": "app"}}},
+					}}).
+					Return(nil),
+			},
+			expect: []alert.Alert{
+				{
+					Message:      "PDB covers the same pods as other PDBs and the pods cannot be evicted: `b` (1 pods)",
+					ResourceKind: "PodDisruptionBudget",
+					ResourceName: "test/a",
+					Violated:     true,
+				},
+				{
+					Message:      "PDB does not cover the same pods as other PDBs",
+					ResourceKind: "PodDisruptionBudget",
+					ResourceName: "test/b",
+				},
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.desc, func(tt *testing.T) {
+			for _, call := range tc.mockCalls {
+				call.Times(1)
+			}
+			alerts, err := r.EvaluateAll(ctx)
+			if tc.expectErr {
+				assert.Error(tt, err)
+			} else {
+				assert.NoError(tt, err)
+				assert.Equal(tt, tc.expect, alerts)
+			}
+		})
+	}
+}
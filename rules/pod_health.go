@@ -0,0 +1,459 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/mercari/merlin/alert"
+	merlinv1beta1 "github.com/mercari/merlin/api/v1beta1"
+)
+
+const (
+	defaultPodHealthOOMKilledThreshold     = 2
+	defaultPodHealthWindowSeconds          = 3600
+	defaultPodHealthRecheckIntervalSeconds = 300
+)
+
+// unhealthyWaitingReasons are the container waiting reasons that make the pod unhealthy
+var unhealthyWaitingReasons = []string{"CrashLoopBackOff", "ImagePullBackOff", "ErrImagePull"}
+
+type PodHealthRule struct {
+	rule
+	resource *merlinv1beta1.ClusterRulePodHealth
+	// restarts keeps the observed restarts of containers, keyed by namespace/pod/container,
+	// since container statuses only have the total restart count and the last termination.
+	restarts map[string][]containerRestart
+	// unhealthy keeps the workloads with violation, so they recover when all their pods are gone
+	unhealthy map[podWorkload]bool
+	lock      sync.Mutex
+}
+
+// podHealthReasonOOMKilled is the reason of container restarts by OOMKilled terminations
+const podHealthReasonOOMKilled = "OOMKilled"
+
+// containerRestart is an observed restart count of a container
+type containerRestart struct {
+	at        time.Time
+	count     int32
+	oomKilled bool
+}
+
+// podWorkload is the workload owning pods, found by following the controller ownerReferences
+type podWorkload struct {
+	Kind string
+	Key  client.ObjectKey
+}
+
+func (p *PodHealthRule) New(ctx context.Context, cli client.Client, logger logr.Logger, key client.ObjectKey) (Rule, error) {
+	p.cli = cli
+	p.log = logger
+	p.status = &Status{}
+	p.resource = &merlinv1beta1.ClusterRulePodHealth{}
+	// keep the observed restarts and unhealthy workloads when the rule is re-created for periodic checks
+	p.lock.Lock()
+	if p.restarts == nil {
+		p.restarts = map[string][]containerRestart{}
+	}
+	if p.unhealthy == nil {
+		p.unhealthy = map[podWorkload]bool{}
+	}
+	p.lock.Unlock()
+	if err := p.cli.Get(ctx, key, p.resource); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *PodHealthRule) GetObject() runtime.Object {
+	return p.resource
+}
+
+func (p *PodHealthRule) GetName() string {
+	return strings.Join([]string{getStructName(p.resource), p.resource.Name}, Separator)
+}
+
+func (p *PodHealthRule) GetObjectMeta() metav1.ObjectMeta {
+	return p.resource.ObjectMeta
+}
+
+func (p *PodHealthRule) GetNotification() merlinv1beta1.Notification {
+	return p.resource.Spec.Notification
+}
+
+func (p *PodHealthRule) SetFinalizer(finalizer string) {
+	p.resource.ObjectMeta.Finalizers = append(p.resource.ObjectMeta.Finalizers, finalizer)
+}
+
+func (p *PodHealthRule) RemoveFinalizer(finalizer string) {
+	p.resource.ObjectMeta.Finalizers = removeString(p.resource.ObjectMeta.Finalizers, finalizer)
+}
+
+func (p *PodHealthRule) GetRecheckInterval() time.Duration {
+	if p.resource.Spec.RecheckIntervalSeconds > 0 {
+		return time.Duration(p.resource.Spec.RecheckIntervalSeconds) * time.Second
+	}
+	return defaultPodHealthRecheckIntervalSeconds * time.Second
+}
+
+func (p *PodHealthRule) EvaluateAll(ctx context.Context) (alerts []alert.Alert, err error) {
+	pods := &corev1.PodList{}
+	if err = p.cli.List(ctx, pods); err != nil {
+		return
+	}
+
+	if len(pods.Items) == 0 {
+		p.log.Info("no pod found")
+	}
+	var workloads []podWorkload
+	workloadPods := map[podWorkload][]corev1.Pod{}
+	parents := map[podWorkload]podWorkload{}
+	for _, pod := range pods.Items {
+		if isStringInSlice(p.resource.Spec.IgnoreNamespaces, pod.Namespace) {
+			continue
+		}
+		var workload podWorkload
+		if workload, err = getPodWorkload(ctx, p.cli, &pod, parents); err != nil {
+			return
+		}
+		if _, ok := workloadPods[workload]; !ok {
+			workloads = append(workloads, workload)
+		}
+		workloadPods[workload] = append(workloadPods[workload], pod)
+	}
+	for _, workload := range workloads {
+		p.log.V(1).Info("evaluating", "kind", workload.Kind, "workload", workload.Key)
+		a := p.checkWorkload(workload, workloadPods[workload])
+		p.setViolation(workload, a.Violated)
+		alerts = append(alerts, a)
+	}
+	for _, workload := range p.getUnhealthyWorkloads() {
+		if _, ok := workloadPods[workload]; ok {
+			continue
+		}
+		a := p.newAlert(workload)
+		a.Message = fmt.Sprintf("%s has no pods", workload.Kind)
+		p.setViolation(workload, a.Violated)
+		alerts = append(alerts, a)
+	}
+	p.pruneRestarts(pods.Items)
+	return
+}
+
+// GetViolationStates returns the violation states with the kinds of the unhealthy workloads, followed by the observed
+// restarts of containers as pending states, so they're kept after restarts.
+func (p *PodHealthRule) GetViolationStates() []merlinv1beta1.ViolationState {
+	states := p.rule.GetViolationStates()
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	kinds := map[string]string{}
+	for workload := range p.unhealthy {
+		kinds[workload.Key.String()] = workload.Kind
+	}
+	for i := range states {
+		states[i].Kind = kinds[states[i].Key]
+	}
+	var keys []string
+	for key := range p.restarts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		for _, r := range p.restarts[key] {
+			state := merlinv1beta1.ViolationState{Key: key, FirstSeen: metav1.NewTime(r.at), LastSeen: metav1.NewTime(r.at), Pending: true, Count: r.count}
+			if r.oomKilled {
+				state.Reason = podHealthReasonOOMKilled
+			}
+			states = append(states, state)
+		}
+	}
+	return states
+}
+
+// RestoreViolations restores the violations, and seeds the unhealthy workloads and the observed restarts of containers
+// from the status, the ones already observed are kept.
+func (p *PodHealthRule) RestoreViolations(status merlinv1beta1.RuleStatus) {
+	var violations []merlinv1beta1.ViolationState
+	restarts := map[string][]containerRestart{}
+	for _, s := range status.ViolationStates {
+		if s.Pending {
+			restarts[s.Key] = append(restarts[s.Key], containerRestart{at: s.FirstSeen.Time, count: s.Count, oomKilled: s.Reason == podHealthReasonOOMKilled})
+		} else {
+			violations = append(violations, s)
+		}
+	}
+	status.ViolationStates = violations
+	p.rule.RestoreViolations(status)
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.restarts == nil {
+		p.restarts = map[string][]containerRestart{}
+	}
+	if p.unhealthy == nil {
+		p.unhealthy = map[podWorkload]bool{}
+	}
+	for key, records := range restarts {
+		if _, ok := p.restarts[key]; !ok {
+			sort.Slice(records, func(i, j int) bool { return records[i].at.Before(records[j].at) })
+			p.restarts[key] = records
+		}
+	}
+	for _, s := range violations {
+		if s.Kind == "" {
+			continue
+		}
+		keys := strings.SplitN(s.Key, Separator, 2)
+		if len(keys) != 2 {
+			continue
+		}
+		p.unhealthy[podWorkload{Kind: s.Kind, Key: client.ObjectKey{Namespace: keys[0], Name: keys[1]}}] = true
+	}
+}
+
+// pruneRestarts removes the observed restarts of containers of the pods not in the list, i.e., deleted pods
+func (p *PodHealthRule) pruneRestarts(pods []corev1.Pod) {
+	podKeys := map[string]bool{}
+	for _, pod := range pods {
+		podKeys[strings.Join([]string{pod.Namespace, pod.Name}, Separator)] = true
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	for key := range p.restarts {
+		if !podKeys[key[:strings.LastIndex(key, Separator)]] {
+			delete(p.restarts, key)
+		}
+	}
+}
+
+// Evaluate evaluates the workload owning the pod, with all pods of the workload.
+func (p *PodHealthRule) Evaluate(ctx context.Context, object interface{}) (a alert.Alert, err error) {
+	pod, ok := object.(*corev1.Pod)
+	if !ok {
+		err = fmt.Errorf("object being evaluated is not type %T", pod)
+		return
+	}
+	if isStringInSlice(p.resource.Spec.IgnoreNamespaces, pod.Namespace) {
+		a = p.newAlert(podWorkload{Kind: getStructName(pod), Key: client.ObjectKey{Namespace: pod.Namespace, Name: pod.Name}})
+		a.Message = "namespace is ignored by the rule"
+		return
+	}
+	workload, workloadPods, err := listWorkloadPods(ctx, p.cli, pod)
+	if err != nil {
+		return
+	}
+	a = p.checkWorkload(workload, workloadPods)
+	p.setViolation(workload, a.Violated)
+	return
+}
+
+func (p *PodHealthRule) GetDelaySeconds(object interface{}) (time.Duration, error) {
+	return 0, nil
+}
+
+func (p *PodHealthRule) setViolation(workload podWorkload, violated bool) {
+	p.status.setViolation(workload.Key, violated)
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.unhealthy == nil {
+		p.unhealthy = map[podWorkload]bool{}
+	}
+	if violated {
+		p.unhealthy[workload] = true
+	} else {
+		delete(p.unhealthy, workload)
+	}
+}
+
+func (p *PodHealthRule) getUnhealthyWorkloads() (workloads []podWorkload) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	for workload := range p.unhealthy {
+		workloads = append(workloads, workload)
+	}
+	return
+}
+
+func (p *PodHealthRule) newAlert(workload podWorkload) alert.Alert {
+	return alert.Alert{
+		Suppressed:      p.resource.Spec.Notification.Suppressed,
+		Severity:        p.resource.Spec.Notification.Severity,
+		MessageTemplate: p.resource.Spec.Notification.CustomMessageTemplate,
+		ResourceName:    workload.Key.String(),
+		ResourceKind:    workload.Kind,
+		Violated:        false,
+	}
+}
+
+// checkWorkload checks the pods of the workload and aggregates the issues into one alert
+func (p *PodHealthRule) checkWorkload(workload podWorkload, pods []corev1.Pod) alert.Alert {
+	a := p.newAlert(workload)
+	a.Message = fmt.Sprintf("%s has healthy pods", workload.Kind)
+	now := time.Now()
+	var podIssues []string
+	for _, pod := range pods {
+		issues := p.checkPod(&pod, now)
+		if len(issues) == 0 {
+			continue
+		}
+		if workload.Kind == getStructName(pod) {
+			podIssues = append(podIssues, strings.Join(issues, ", "))
+		} else {
+			podIssues = append(podIssues, fmt.Sprintf("pod `%s`: %s", pod.Name, strings.Join(issues, ", ")))
+		}
+	}
+	if len(podIssues) > 0 {
+		a.Violated = true
+		a.Message = fmt.Sprintf("%s has unhealthy pods: %s", workload.Kind, strings.Join(podIssues, "; "))
+	}
+	return a
+}
+
+// checkPod returns the issues of the pod containers
+func (p *PodHealthRule) checkPod(pod *corev1.Pod, now time.Time) (issues []string) {
+	window := p.getWindow()
+	var statuses []corev1.ContainerStatus
+	statuses = append(statuses, pod.Status.InitContainerStatuses...)
+	statuses = append(statuses, pod.Status.ContainerStatuses...)
+	for _, status := range statuses {
+		if status.State.Waiting != nil && isStringInSlice(unhealthyWaitingReasons, status.State.Waiting.Reason) {
+			issues = append(issues, fmt.Sprintf("container `%s` is in %s", status.Name, status.State.Waiting.Reason))
+		}
+		restarts, oomKills := p.observeRestarts(pod, status, now)
+		if oomKills >= p.getOOMKilledThreshold() {
+			issues = append(issues, fmt.Sprintf("container `%s` was OOMKilled %d times in %s", status.Name, oomKills, window))
+		}
+		if p.resource.Spec.RestartThreshold > 0 && restarts > p.resource.Spec.RestartThreshold {
+			issues = append(issues, fmt.Sprintf("container `%s` restarted %d times in %s", status.Name, restarts, window))
+		}
+	}
+	return
+}
+
+// observeRestarts records the restart count of the container, and returns the number of restarts and OOMKilled terminations within the window.
+func (p *PodHealthRule) observeRestarts(pod *corev1.Pod, status corev1.ContainerStatus, now time.Time) (restarts, oomKills int32) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.restarts == nil {
+		p.restarts = map[string][]containerRestart{}
+	}
+	key := strings.Join([]string{pod.Namespace, pod.Name, status.Name}, Separator)
+	windowStart := now.Add(-p.getWindow())
+
+	records := p.restarts[key]
+	if len(records) > 0 && status.RestartCount < records[len(records)-1].count {
+		// the pod was re-created with the same name
+		records = nil
+	}
+	if len(records) == 0 || status.RestartCount > records[len(records)-1].count {
+		records = append(records, containerRestart{
+			at:        now,
+			count:     status.RestartCount,
+			oomKilled: status.LastTerminationState.Terminated != nil && status.LastTerminationState.Terminated.Reason == podHealthReasonOOMKilled,
+		})
+	}
+	// keep the latest record before the window as the baseline
+	i := 0
+	for i < len(records)-1 && !records[i+1].at.After(windowStart) {
+		i++
+	}
+	records = records[i:]
+	p.restarts[key] = records
+
+	baseline := records[0].count
+	if records[0].at.After(windowStart) && pod.Status.StartTime != nil && pod.Status.StartTime.Time.After(windowStart) {
+		// all restarts happened within the window since the pod started within the window
+		baseline = 0
+	}
+	restarts = records[len(records)-1].count - baseline
+	for _, r := range records {
+		if r.oomKilled && r.at.After(windowStart) {
+			oomKills++
+		}
+	}
+	return
+}
+
+func (p *PodHealthRule) getWindow() time.Duration {
+	if p.resource.Spec.WindowSeconds > 0 {
+		return time.Duration(p.resource.Spec.WindowSeconds) * time.Second
+	}
+	return defaultPodHealthWindowSeconds * time.Second
+}
+
+func (p *PodHealthRule) getOOMKilledThreshold() int32 {
+	if p.resource.Spec.OOMKilledThreshold > 0 {
+		return p.resource.Spec.OOMKilledThreshold
+	}
+	return defaultPodHealthOOMKilledThreshold
+}
+
+// getPodWorkload returns the workload owning the pod by following the controller ownerReferences,
+// e.g. Pod -> ReplicaSet -> Deployment or Pod -> Job -> CronJob, a pod without controller is its own workload.
+// parents caches the owners of ReplicaSets and Jobs already looked up.
+func getPodWorkload(ctx context.Context, cli client.Client, pod *corev1.Pod, parents map[podWorkload]podWorkload) (workload podWorkload, err error) {
+	workload = podWorkload{Kind: getStructName(pod), Key: client.ObjectKey{Namespace: pod.Namespace, Name: pod.Name}}
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return
+	}
+	workload = podWorkload{Kind: owner.Kind, Key: client.ObjectKey{Namespace: pod.Namespace, Name: owner.Name}}
+	var parent runtime.Object
+	switch owner.Kind {
+	case "ReplicaSet":
+		parent = &appsv1.ReplicaSet{}
+	case "Job":
+		parent = &batchv1.Job{}
+	default:
+		return
+	}
+	if cached, ok := parents[workload]; ok {
+		return cached, nil
+	}
+	owned := workload
+	if err = cli.Get(ctx, workload.Key, parent); err != nil {
+		if !apierrs.IsNotFound(err) {
+			return
+		}
+		err = nil
+	} else if parentOwner := metav1.GetControllerOf(parent.(metav1.Object)); parentOwner != nil {
+		workload = podWorkload{Kind: parentOwner.Kind, Key: client.ObjectKey{Namespace: pod.Namespace, Name: parentOwner.Name}}
+	}
+	parents[owned] = workload
+	return
+}
+
+// listWorkloadPods returns the workload owning the pod, and all pods of the workload in the namespace
+func listWorkloadPods(ctx context.Context, cli client.Client, pod *corev1.Pod) (workload podWorkload, workloadPods []corev1.Pod, err error) {
+	parents := map[podWorkload]podWorkload{}
+	if workload, err = getPodWorkload(ctx, cli, pod, parents); err != nil {
+		return
+	}
+	pods := corev1.PodList{}
+	if err = cli.List(ctx, &pods, &client.ListOptions{Namespace: pod.Namespace}); err != nil && client.IgnoreNotFound(err) != nil {
+		return
+	}
+	err = nil
+	for _, item := range pods.Items {
+		var itemWorkload podWorkload
+		if itemWorkload, err = getPodWorkload(ctx, cli, &item, parents); err != nil {
+			return
+		}
+		if itemWorkload == workload {
+			workloadPods = append(workloadPods, item)
+		}
+	}
+	return
+}
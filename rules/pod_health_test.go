@@ -0,0 +1,361 @@
+package rules
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/zapr"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/mercari/merlin/alert"
+	merlinv1beta1 "github.com/mercari/merlin/api/v1beta1"
+	"github.com/mercari/merlin/mocks"
+)
+
+func newTestOwnedPod(name, ownerKind, ownerName string, statuses ...corev1.ContainerStatus) corev1.Pod {
+	isController := true
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: name},
+		Status:     corev1.PodStatus{ContainerStatuses: statuses},
+	}
+	if ownerKind != "" {
+		pod.OwnerReferences = []metav1.OwnerReference{{Kind: ownerKind, Name: ownerName, Controller: &isController}}
+	}
+	return pod
+}
+
+func Test_PodHealthRule_Basic(t *testing.T) {
+	notification := merlinv1beta1.Notification{
+		Notifiers:  []string{"testNotifier"},
+		Suppressed: true,
+	}
+
+	merlinv1beta1Rule := &merlinv1beta1.ClusterRulePodHealth{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-r"},
+		Spec: merlinv1beta1.ClusterRulePodHealthSpec{
+			Notification: notification,
+		},
+	}
+
+	r := &PodHealthRule{resource: merlinv1beta1Rule}
+	assert.Equal(t, merlinv1beta1Rule.ObjectMeta, r.GetObjectMeta())
+	assert.Equal(t, merlinv1beta1Rule, r.GetObject())
+	assert.Equal(t, notification, r.GetNotification())
+	assert.Equal(t, "ClusterRulePodHealth/test-r", r.GetName())
+	assert.Equal(t, 5*time.Minute, r.GetRecheckInterval())
+
+	finalizer := "test.finalizer"
+	r.SetFinalizer(finalizer)
+	assert.Equal(t, finalizer, r.resource.Finalizers[0])
+	r.RemoveFinalizer(finalizer)
+	assert.Empty(t, r.resource.Finalizers)
+	delay, err := r.GetDelaySeconds(&corev1.Pod{})
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), delay)
+}
+
+func Test_PodHealthRule_Evaluate(t *testing.T) {
+	ctx := context.Background()
+	log := zapr.NewLogger(zap.L())
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mocks.NewMockClient(mockCtrl)
+	isController := true
+	replicaSet := appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       "test",
+			Name:            "app-7d4b9",
+			OwnerReferences: []metav1.OwnerReference{{Kind: "Deployment", Name: "app", Controller: &isController}},
+		},
+	}
+	crashLooping := corev1.ContainerStatus{
+		Name:         "app",
+		RestartCount: 3,
+		State:        corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+	}
+	imagePull := corev1.ContainerStatus{
+		Name:  "sidecar",
+		State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ErrImagePull"}},
+	}
+	running := corev1.ContainerStatus{
+		Name:  "app",
+		State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+	}
+
+	cases := []struct {
+		desc      string
+		mockCalls []*gomock.Call
+		resource  interface{}
+		expect    alert.Alert
+		expectErr bool
+	}{
+		{
+			desc:      "non pod should have error",
+			resource:  &corev1.Service{},
+			expectErr: true,
+		},
+		{
+			desc:     "ignored namespace should not get violated alert",
+			resource: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ignoredNS", Name: "app-1"}},
+			expect: alert.Alert{
+				Message:      "namespace is ignored by the rule",
+				ResourceKind: "Pod",
+				ResourceName: "ignoredNS/app-1",
+			},
+		},
+		{
+			desc: "unhealthy pods should be aggregated to the deployment",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().
+					Get(ctx, client.ObjectKey{Namespace: "test", Name: "app-7d4b9"}, &appsv1.ReplicaSet{}).
+					SetArg(2, replicaSet).
+					Return(nil),
+				mockClient.EXPECT().
+					List(ctx, &corev1.PodList{}, &client.ListOptions{Namespace: "test"}).
+					SetArg(1, corev1.PodList{Items: []corev1.Pod{
+						newTestOwnedPod("app-7d4b9-a", "ReplicaSet", "app-7d4b9", crashLooping, imagePull),
+						newTestOwnedPod("app-7d4b9-b", "ReplicaSet", "app-7d4b9", running),
+						newTestOwnedPod("other", "", "", crashLooping),
+					}}).
+					Return(nil),
+			},
+			resource: func() *corev1.Pod {
+				pod := newTestOwnedPod("app-7d4b9-b", "ReplicaSet", "app-7d4b9", running)
+				return &pod
+			}(),
+			expect: alert.Alert{
+				Message:      "Deployment has unhealthy pods: pod `app-7d4b9-a`: container `app` is in CrashLoopBackOff, container `sidecar` is in ErrImagePull",
+				ResourceKind: "Deployment",
+				ResourceName: "test/app",
+				Violated:     true,
+			},
+		},
+		{
+			desc: "healthy pod without owner should not get violated alert",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().
+					List(ctx, &corev1.PodList{}, &client.ListOptions{Namespace: "test"}).
+					SetArg(1, corev1.PodList{Items: []corev1.Pod{
+						newTestOwnedPod("standalone", "", "", running),
+						newTestOwnedPod("app-7d4b9-a", "ReplicaSet", "app-7d4b9", crashLooping),
+					}}).
+					Return(nil),
+				mockClient.EXPECT().
+					Get(ctx, client.ObjectKey{Namespace: "test", Name: "app-7d4b9"}, &appsv1.ReplicaSet{}).
+					SetArg(2, replicaSet).
+					Return(nil),
+			},
+			resource: func() *corev1.Pod {
+				pod := newTestOwnedPod("standalone", "", "", running)
+				return &pod
+			}(),
+			expect: alert.Alert{
+				Message:      "Pod has healthy pods",
+				ResourceKind: "Pod",
+				ResourceName: "test/standalone",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(tt *testing.T) {
+			for _, call := range tc.mockCalls {
+				call.Times(1)
+			}
+			r := &PodHealthRule{
+				rule: rule{cli: mockClient, log: log, status: &Status{}},
+				resource: &merlinv1beta1.ClusterRulePodHealth{
+					Spec: merlinv1beta1.ClusterRulePodHealthSpec{IgnoreNamespaces: []string{"ignoredNS"}},
+				},
+			}
+			a, err := r.Evaluate(ctx, tc.resource)
+			if tc.expectErr {
+				assert.Error(tt, err)
+			} else {
+				assert.NoError(tt, err)
+				assert.Equal(tt, tc.expect, a)
+			}
+		})
+	}
+}
+
+func Test_PodHealthRule_CheckPod(t *testing.T) {
+	now := time.Now()
+	r := &PodHealthRule{
+		resource: &merlinv1beta1.ClusterRulePodHealth{
+			Spec: merlinv1beta1.ClusterRulePodHealthSpec{RestartThreshold: 3, WindowSeconds: 600},
+		},
+	}
+	oomKilled := corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled"}}
+	newPod := func(startedAt time.Time, restarts int32, lastState corev1.ContainerState) *corev1.Pod {
+		startTime := metav1.NewTime(startedAt)
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "app"},
+			Status: corev1.PodStatus{
+				StartTime:         &startTime,
+				ContainerStatuses: []corev1.ContainerStatus{{Name: "app", RestartCount: restarts, LastTerminationState: lastState}},
+			},
+		}
+	}
+
+	// the pod started long before the window, restarts before the first observation are not counted
+	assert.Empty(t, r.checkPod(newPod(now.Add(-time.Hour), 10, oomKilled), now))
+	assert.Empty(t, r.checkPod(newPod(now.Add(-time.Hour), 12, corev1.ContainerState{}), now.Add(time.Minute)))
+	assert.Equal(t, []string{
+		"container `app` was OOMKilled 2 times in 10m0s",
+		"container `app` restarted 4 times in 10m0s",
+	}, r.checkPod(newPod(now.Add(-time.Hour), 14, oomKilled), now.Add(2*time.Minute)))
+	// restarts and OOMKilled terminations out of the window are not counted anymore
+	assert.Empty(t, r.checkPod(newPod(now.Add(-time.Hour), 14, oomKilled), now.Add(20*time.Minute)))
+
+	// the pod re-created with the same name started within the window, all restarts are counted
+	assert.Equal(t, []string{
+		"container `app` restarted 5 times in 10m0s",
+	}, r.checkPod(newPod(now.Add(20*time.Minute), 5, corev1.ContainerState{}), now.Add(25*time.Minute)))
+}
+
+func Test_PodHealthRule_EvaluateAll(t *testing.T) {
+	ctx := context.Background()
+	log := zapr.NewLogger(zap.L())
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mocks.NewMockClient(mockCtrl)
+	r := &PodHealthRule{
+		rule:     rule{cli: mockClient, log: log, status: &Status{}},
+		resource: &merlinv1beta1.ClusterRulePodHealth{},
+	}
+	crashLooping := corev1.ContainerStatus{
+		Name:  "job",
+		State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+	}
+
+	cases := []struct {
+		desc      string
+		mockCalls []*gomock.Call
+		expect    []alert.Alert
+		expectErr bool
+	}{
+		{
+			desc: "no resources should returns nil alerts",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().
+					List(ctx, &corev1.PodList{}).
+					Return(nil),
+			},
+		},
+		{
+			desc: "pods should be evaluated per workload",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().
+					List(ctx, &corev1.PodList{}).
+					SetArg(1, corev1.PodList{Items: []corev1.Pod{
+						newTestOwnedPod("job-a", "Job", "job", crashLooping),
+						newTestOwnedPod("job-b", "Job", "job"),
+						newTestOwnedPod("ds-a", "DaemonSet", "ds"),
+					}}).
+					Return(nil),
+				mockClient.EXPECT().
+					Get(ctx, client.ObjectKey{Namespace: "test", Name: "job"}, gomock.Any()).
+					Return(nil),
+			},
+			expect: []alert.Alert{
+				{
+					Message:      "Job has unhealthy pods: pod `job-a`: container `job` is in CrashLoopBackOff",
+					ResourceKind: "Job",
+					ResourceName: "test/job",
+					Violated:     true,
+				},
+				{
+					Message:      "DaemonSet has healthy pods",
+					ResourceKind: "DaemonSet",
+					ResourceName: "test/ds",
+				},
+			},
+		},
+		{
+			desc: "unhealthy workloads without pods should recover",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().
+					List(ctx, &corev1.PodList{}).
+					Return(nil),
+			},
+			expect: []alert.Alert{
+				{
+					Message:      "Job has no pods",
+					ResourceKind: "Job",
+					ResourceName: "test/job",
+				},
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.desc, func(tt *testing.T) {
+			for _, call := range tc.mockCalls {
+				call.Times(1)
+			}
+			alerts, err := r.EvaluateAll(ctx)
+			if tc.expectErr {
+				assert.Error(tt, err)
+			} else {
+				assert.NoError(tt, err)
+				assert.Equal(tt, tc.expect, alerts)
+			}
+		})
+	}
+}
+
+func Test_PodHealthRule_RestoreViolations(t *testing.T) {
+	ctx := context.Background()
+	log := zapr.NewLogger(zap.L())
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mocks.NewMockClient(mockCtrl)
+	now := time.Now()
+	resource := &merlinv1beta1.ClusterRulePodHealth{
+		Spec: merlinv1beta1.ClusterRulePodHealthSpec{RestartThreshold: 3, WindowSeconds: 600},
+	}
+	r := &PodHealthRule{rule: rule{cli: mockClient, log: log, status: &Status{}}, resource: resource}
+	oomKilled := corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled"}}
+	pod := newTestOwnedPod("app", "", "", corev1.ContainerStatus{Name: "app", RestartCount: 10})
+	startTime := metav1.NewTime(now.Add(-time.Hour))
+	pod.Status.StartTime = &startTime
+	assert.Empty(t, r.checkPod(&pod, now.Add(-5*time.Minute)))
+	pod.Status.ContainerStatuses[0] = corev1.ContainerStatus{Name: "app", RestartCount: 12, LastTerminationState: oomKilled}
+	assert.Empty(t, r.checkPod(&pod, now.Add(-4*time.Minute)))
+	r.setViolation(podWorkload{Kind: "Deployment", Key: client.ObjectKey{Namespace: "test", Name: "web"}}, true)
+
+	states := r.GetViolationStates()
+	assert.Len(t, states, 3)
+	assert.Equal(t, merlinv1beta1.ViolationState{Key: "test/web", FirstSeen: states[0].FirstSeen, LastSeen: states[0].LastSeen, Kind: "Deployment"}, states[0])
+	assert.Equal(t, merlinv1beta1.ViolationState{
+		Key:       "test/app/app",
+		FirstSeen: metav1.NewTime(now.Add(-4 * time.Minute)),
+		LastSeen:  metav1.NewTime(now.Add(-4 * time.Minute)),
+		Pending:   true,
+		Count:     12,
+		Reason:    "OOMKilled",
+	}, states[2])
+
+	// the restarts observed before restarting are counted, and the unhealthy workloads without pods recover
+	restored := &PodHealthRule{rule: rule{cli: mockClient, log: log, status: &Status{}}, resource: resource}
+	restored.RestoreViolations(merlinv1beta1.RuleStatus{ViolationStates: states})
+	assert.Len(t, restored.GetViolations(), 1)
+	pod.Status.ContainerStatuses[0] = corev1.ContainerStatus{Name: "app", RestartCount: 14, LastTerminationState: oomKilled}
+	assert.Equal(t, []string{
+		"container `app` was OOMKilled 2 times in 10m0s",
+		"container `app` restarted 4 times in 10m0s",
+	}, restored.checkPod(&pod, now))
+
+	mockClient.EXPECT().List(ctx, &corev1.PodList{}).Return(nil).Times(1)
+	alerts, err := restored.EvaluateAll(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []alert.Alert{{Message: "Deployment has no pods", ResourceKind: "Deployment", ResourceName: "test/web"}}, alerts)
+	assert.Empty(t, restored.GetViolationStates())
+}
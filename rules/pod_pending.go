@@ -0,0 +1,200 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/mercari/merlin/alert"
+	merlinv1beta1 "github.com/mercari/merlin/api/v1beta1"
+)
+
+const defaultPodPendingSeconds = 300
+
+type PodPendingRule struct {
+	rule
+	resource *merlinv1beta1.ClusterRulePodPending
+}
+
+func (p *PodPendingRule) New(ctx context.Context, cli client.Client, logger logr.Logger, key client.ObjectKey) (Rule, error) {
+	p.cli = cli
+	p.log = logger
+	p.status = &Status{}
+	p.resource = &merlinv1beta1.ClusterRulePodPending{}
+	if err := p.cli.Get(ctx, key, p.resource); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *PodPendingRule) GetObject() runtime.Object {
+	return p.resource
+}
+
+func (p PodPendingRule) GetName() string {
+	return strings.Join([]string{getStructName(p.resource), p.resource.Name}, Separator)
+}
+
+func (p PodPendingRule) GetObjectMeta() metav1.ObjectMeta {
+	return p.resource.ObjectMeta
+}
+
+func (p PodPendingRule) GetNotification() merlinv1beta1.Notification {
+	return p.resource.Spec.Notification
+}
+
+func (p *PodPendingRule) SetFinalizer(finalizer string) {
+	p.resource.ObjectMeta.Finalizers = append(p.resource.ObjectMeta.Finalizers, finalizer)
+}
+
+func (p *PodPendingRule) RemoveFinalizer(finalizer string) {
+	p.resource.ObjectMeta.Finalizers = removeString(p.resource.ObjectMeta.Finalizers, finalizer)
+}
+
+func (p *PodPendingRule) EvaluateAll(ctx context.Context) (alerts []alert.Alert, err error) {
+	pods := &corev1.PodList{}
+	if err = p.cli.List(ctx, pods); err != nil {
+		return
+	}
+
+	if len(pods.Items) == 0 {
+		p.log.Info("no pod found")
+		return
+	}
+	var workloads []podWorkload
+	workloadPods := map[podWorkload][]corev1.Pod{}
+	parents := map[podWorkload]podWorkload{}
+	for _, pod := range pods.Items {
+		if isStringInSlice(p.resource.Spec.IgnoreNamespaces, pod.Namespace) {
+			continue
+		}
+		var workload podWorkload
+		if workload, err = getPodWorkload(ctx, p.cli, &pod, parents); err != nil {
+			return
+		}
+		if _, ok := workloadPods[workload]; !ok {
+			workloads = append(workloads, workload)
+		}
+		workloadPods[workload] = append(workloadPods[workload], pod)
+	}
+	now := time.Now()
+	for _, workload := range workloads {
+		p.log.V(1).Info("evaluating", "kind", workload.Kind, "workload", workload.Key)
+		a := p.checkWorkload(workload, workloadPods[workload], now)
+		p.status.setViolation(workload.Key, a.Violated)
+		alerts = append(alerts, a)
+	}
+	return
+}
+
+// Evaluate evaluates the workload owning the pod, with all pods of the workload.
+func (p *PodPendingRule) Evaluate(ctx context.Context, object interface{}) (a alert.Alert, err error) {
+	pod, ok := object.(*corev1.Pod)
+	if !ok {
+		err = fmt.Errorf("object being evaluated is not type %T", pod)
+		return
+	}
+	if isStringInSlice(p.resource.Spec.IgnoreNamespaces, pod.Namespace) {
+		a = p.newAlert(podWorkload{Kind: getStructName(pod), Key: client.ObjectKey{Namespace: pod.Namespace, Name: pod.Name}})
+		a.Message = "namespace is ignored by the rule"
+		return
+	}
+	workload, workloadPods, err := listWorkloadPods(ctx, p.cli, pod)
+	if err != nil {
+		return
+	}
+	a = p.checkWorkload(workload, workloadPods, time.Now())
+	p.status.setViolation(workload.Key, a.Violated)
+	return
+}
+
+// GetDelaySeconds returns the time left before the pending pod reaches the pending duration,
+// so the pod is evaluated again when it has been pending for too long.
+func (p *PodPendingRule) GetDelaySeconds(object interface{}) (time.Duration, error) {
+	pod, ok := object.(*corev1.Pod)
+	if !ok {
+		return 0, fmt.Errorf("object being evaluated is not type %T", pod)
+	}
+	if pod.Status.Phase != corev1.PodPending || isStringInSlice(p.resource.Spec.IgnoreNamespaces, pod.Namespace) {
+		return 0, nil
+	}
+	left := p.getPendingDuration() - time.Since(pod.CreationTimestamp.Time)
+	if left <= 0 {
+		return 0, nil
+	}
+	return left, nil
+}
+
+func (p *PodPendingRule) newAlert(workload podWorkload) alert.Alert {
+	return alert.Alert{
+		Suppressed:      p.resource.Spec.Notification.Suppressed,
+		Severity:        p.resource.Spec.Notification.Severity,
+		MessageTemplate: p.resource.Spec.Notification.CustomMessageTemplate,
+		ResourceName:    workload.Key.String(),
+		ResourceKind:    workload.Kind,
+		Violated:        false,
+	}
+}
+
+// checkWorkload checks the pods of the workload and aggregates the pods pending for too long into one alert
+func (p *PodPendingRule) checkWorkload(workload podWorkload, pods []corev1.Pod, now time.Time) alert.Alert {
+	pendingDuration := p.getPendingDuration()
+	a := p.newAlert(workload)
+	a.Message = fmt.Sprintf("%s has no pods pending for more than %s", workload.Kind, pendingDuration)
+	var pending []string
+	for _, pod := range pods {
+		if pod.Status.Phase != corev1.PodPending {
+			continue
+		}
+		pendingFor := now.Sub(pod.CreationTimestamp.Time)
+		if pendingFor < pendingDuration {
+			continue
+		}
+		pending = append(pending, fmt.Sprintf("pod `%s` (pending for %s): %s", pod.Name, pendingFor.Truncate(time.Second), getPendingReason(&pod)))
+	}
+	if len(pending) > 0 {
+		a.Violated = true
+		a.Message = fmt.Sprintf("%s has pods pending for more than %s: %s", workload.Kind, pendingDuration, strings.Join(pending, "; "))
+	}
+	return a
+}
+
+func (p *PodPendingRule) getPendingDuration() time.Duration {
+	if p.resource.Spec.PendingSeconds > 0 {
+		return time.Duration(p.resource.Spec.PendingSeconds) * time.Second
+	}
+	return defaultPodPendingSeconds * time.Second
+}
+
+// getPendingReason returns why the pod is pending, from the PodScheduled condition if the pod is not scheduled,
+// or from the waiting containers if it's scheduled.
+func getPendingReason(pod *corev1.Pod) string {
+	for _, c := range pod.Status.Conditions {
+		if c.Type != corev1.PodScheduled {
+			continue
+		}
+		if c.Status == corev1.ConditionFalse {
+			if c.Message == "" {
+				return c.Reason
+			}
+			return fmt.Sprintf("%s, %s", c.Reason, c.Message)
+		}
+		break
+	}
+	var statuses []corev1.ContainerStatus
+	statuses = append(statuses, pod.Status.InitContainerStatuses...)
+	statuses = append(statuses, pod.Status.ContainerStatuses...)
+	for _, s := range statuses {
+		if s.State.Waiting != nil && s.State.Waiting.Reason != "" {
+			return fmt.Sprintf("container `%s` is waiting, %s", s.Name, s.State.Waiting.Reason)
+		}
+	}
+	return "unknown reason"
+}
@@ -0,0 +1,247 @@
+package rules
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/zapr"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/mercari/merlin/alert"
+	merlinv1beta1 "github.com/mercari/merlin/api/v1beta1"
+	"github.com/mercari/merlin/mocks"
+)
+
+func newTestPendingPod(name string, createdAt time.Time, status corev1.PodStatus) corev1.Pod {
+	isController := true
+	status.Phase = corev1.PodPending
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:         "test",
+			Name:              name,
+			CreationTimestamp: metav1.NewTime(createdAt),
+			OwnerReferences:   []metav1.OwnerReference{{Kind: "StatefulSet", Name: "db", Controller: &isController}},
+		},
+		Status: status,
+	}
+}
+
+func Test_PodPendingRule_Basic(t *testing.T) {
+	notification := merlinv1beta1.Notification{
+		Notifiers:  []string{"testNotifier"},
+		Suppressed: true,
+	}
+
+	merlinv1beta1Rule := &merlinv1beta1.ClusterRulePodPending{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-r"},
+		Spec: merlinv1beta1.ClusterRulePodPendingSpec{
+			Notification: notification,
+		},
+	}
+
+	r := &PodPendingRule{resource: merlinv1beta1Rule}
+	assert.Equal(t, merlinv1beta1Rule.ObjectMeta, r.GetObjectMeta())
+	assert.Equal(t, merlinv1beta1Rule, r.GetObject())
+	assert.Equal(t, notification, r.GetNotification())
+	assert.Equal(t, "ClusterRulePodPending/test-r", r.GetName())
+
+	finalizer := "test.finalizer"
+	r.SetFinalizer(finalizer)
+	assert.Equal(t, finalizer, r.resource.Finalizers[0])
+	r.RemoveFinalizer(finalizer)
+	assert.Empty(t, r.resource.Finalizers)
+
+	_, err := r.GetDelaySeconds(&corev1.Service{})
+	assert.Error(t, err)
+	delay, err := r.GetDelaySeconds(&corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodRunning}})
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), delay)
+	pending := newTestPendingPod("db-0", time.Now().Add(-time.Minute), corev1.PodStatus{})
+	delay, err = r.GetDelaySeconds(&pending)
+	assert.NoError(t, err)
+	assert.True(t, delay > 3*time.Minute && delay <= 4*time.Minute)
+	pending = newTestPendingPod("db-0", time.Now().Add(-time.Hour), corev1.PodStatus{})
+	delay, err = r.GetDelaySeconds(&pending)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), delay)
+}
+
+func Test_PodPendingRule_Evaluate(t *testing.T) {
+	ctx := context.Background()
+	log := zapr.NewLogger(zap.L())
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mocks.NewMockClient(mockCtrl)
+	now := time.Now().Truncate(time.Second)
+	unschedulable := newTestPendingPod("db-0", now.Add(-10*time.Minute), corev1.PodStatus{
+		Conditions: []corev1.PodCondition{{
+			Type:    corev1.PodScheduled,
+			Status:  corev1.ConditionFalse,
+			Reason:  corev1.PodReasonUnschedulable,
+			Message: "0/3 nodes are available: 3 Insufficient cpu.",
+		}},
+	})
+	creating := newTestPendingPod("db-1", now.Add(-20*time.Minute), corev1.PodStatus{
+		Conditions: []corev1.PodCondition{{Type: corev1.PodScheduled, Status: corev1.ConditionTrue}},
+		ContainerStatuses: []corev1.ContainerStatus{{
+			Name:  "db",
+			State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ContainerCreating"}},
+		}},
+	})
+	recent := newTestPendingPod("db-2", now.Add(-time.Minute), corev1.PodStatus{})
+
+	cases := []struct {
+		desc      string
+		mockCalls []*gomock.Call
+		resource  interface{}
+		expect    alert.Alert
+		expectErr bool
+	}{
+		{
+			desc:      "non pod should have error",
+			resource:  &corev1.Service{},
+			expectErr: true,
+		},
+		{
+			desc:     "ignored namespace should not get violated alert",
+			resource: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ignoredNS", Name: "db-0"}},
+			expect: alert.Alert{
+				Message:      "namespace is ignored by the rule",
+				ResourceKind: "Pod",
+				ResourceName: "ignoredNS/db-0",
+			},
+		},
+		{
+			desc: "pods pending for too long should be aggregated to the workload with reasons",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().
+					List(ctx, &corev1.PodList{}, &client.ListOptions{Namespace: "test"}).
+					SetArg(1, corev1.PodList{Items: []corev1.Pod{unschedulable, creating, recent}}).
+					Return(nil),
+			},
+			resource: &recent,
+			expect: alert.Alert{
+				Message: "StatefulSet has pods pending for more than 5m0s: " +
+					"pod `db-0` (pending for 10m0s): Unschedulable, 0/3 nodes are available: 3 Insufficient cpu.; " +
+					"pod `db-1` (pending for 20m0s): container `db` is waiting, ContainerCreating",
+				ResourceKind: "StatefulSet",
+				ResourceName: "test/db",
+				Violated:     true,
+			},
+		},
+		{
+			desc: "pods pending for a short time should not get violated alert",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().
+					List(ctx, &corev1.PodList{}, &client.ListOptions{Namespace: "test"}).
+					SetArg(1, corev1.PodList{Items: []corev1.Pod{recent}}).
+					Return(nil),
+			},
+			resource: &recent,
+			expect: alert.Alert{
+				Message:      "StatefulSet has no pods pending for more than 5m0s",
+				ResourceKind: "StatefulSet",
+				ResourceName: "test/db",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(tt *testing.T) {
+			for _, call := range tc.mockCalls {
+				call.Times(1)
+			}
+			r := &PodPendingRule{
+				rule: rule{cli: mockClient, log: log, status: &Status{}},
+				resource: &merlinv1beta1.ClusterRulePodPending{
+					Spec: merlinv1beta1.ClusterRulePodPendingSpec{IgnoreNamespaces: []string{"ignoredNS"}},
+				},
+			}
+			a, err := r.Evaluate(ctx, tc.resource)
+			if tc.expectErr {
+				assert.Error(tt, err)
+			} else {
+				assert.NoError(tt, err)
+				assert.Equal(tt, tc.expect, a)
+			}
+		})
+	}
+}
+
+func Test_PodPendingRule_EvaluateAll(t *testing.T) {
+	ctx := context.Background()
+	log := zapr.NewLogger(zap.L())
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mocks.NewMockClient(mockCtrl)
+	r := &PodPendingRule{
+		rule: rule{cli: mockClient, log: log, status: &Status{}},
+		resource: &merlinv1beta1.ClusterRulePodPending{
+			Spec: merlinv1beta1.ClusterRulePodPendingSpec{PendingSeconds: 60},
+		},
+	}
+
+	cases := []struct {
+		desc      string
+		mockCalls []*gomock.Call
+		expect    []alert.Alert
+		expectErr bool
+	}{
+		{
+			desc: "no resources should returns nil alerts",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().
+					List(ctx, &corev1.PodList{}).
+					Return(nil),
+			},
+		},
+		{
+			desc: "pods should be evaluated per workload",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().
+					List(ctx, &corev1.PodList{}).
+					SetArg(1, corev1.PodList{Items: []corev1.Pod{
+						newTestPendingPod("db-0", time.Now().Add(-2*time.Minute), corev1.PodStatus{}),
+						{
+							ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "standalone"},
+							Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+						},
+					}}).
+					Return(nil),
+			},
+			expect: []alert.Alert{
+				{
+					Message:      "StatefulSet has pods pending for more than 1m0s: pod `db-0` (pending for 2m0s): unknown reason",
+					ResourceKind: "StatefulSet",
+					ResourceName: "test/db",
+					Violated:     true,
+				},
+				{
+					Message:      "Pod has no pods pending for more than 1m0s",
+					ResourceKind: "Pod",
+					ResourceName: "test/standalone",
+				},
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.desc, func(tt *testing.T) {
+			for _, call := range tc.mockCalls {
+				call.Times(1)
+			}
+			alerts, err := r.EvaluateAll(ctx)
+			if tc.expectErr {
+				assert.Error(tt, err)
+			} else {
+				assert.NoError(tt, err)
+				assert.Equal(tt, tc.expect, alerts)
+			}
+		})
+	}
+}
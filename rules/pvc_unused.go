@@ -0,0 +1,334 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/mercari/merlin/alert"
+	merlinv1beta1 "github.com/mercari/merlin/api/v1beta1"
+)
+
+type PVCUnusedRule struct {
+	// resource is the api resource this Rule uses
+	resource *merlinv1beta1.ClusterRulePVCUnused
+	rule
+}
+
+func (p *PVCUnusedRule) New(ctx context.Context, cli client.Client, logger logr.Logger, key client.ObjectKey) (Rule, error) {
+	p.cli = cli
+	p.log = logger
+	p.status = &Status{}
+	p.resource = &merlinv1beta1.ClusterRulePVCUnused{}
+	if err := p.cli.Get(ctx, key, p.resource); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *PVCUnusedRule) GetObject() runtime.Object {
+	return p.resource
+}
+
+func (p PVCUnusedRule) GetName() string {
+	return strings.Join([]string{getStructName(p.resource), p.resource.Name}, Separator)
+}
+
+func (p PVCUnusedRule) GetObjectMeta() metav1.ObjectMeta {
+	return p.resource.ObjectMeta
+}
+
+func (p PVCUnusedRule) GetNotification() merlinv1beta1.Notification {
+	return p.resource.Spec.Notification
+}
+
+func (p *PVCUnusedRule) SetFinalizer(finalizer string) {
+	p.resource.ObjectMeta.Finalizers = append(p.resource.ObjectMeta.Finalizers, finalizer)
+}
+
+func (p *PVCUnusedRule) RemoveFinalizer(finalizer string) {
+	p.resource.ObjectMeta.Finalizers = removeString(p.resource.ObjectMeta.Finalizers, finalizer)
+}
+
+func (p *PVCUnusedRule) EvaluateAll(ctx context.Context) (alerts []alert.Alert, err error) {
+	pvcs := &corev1.PersistentVolumeClaimList{}
+	if err = p.cli.List(ctx, pvcs); err != nil {
+		return
+	}
+	pvs := &corev1.PersistentVolumeList{}
+	if !p.resource.Spec.IgnoreReleasedPVs {
+		if err = p.cli.List(ctx, pvs); err != nil {
+			return
+		}
+	}
+
+	if len(pvcs.Items) == 0 && len(pvs.Items) == 0 {
+		p.log.Info("no resource found")
+		return
+	}
+	var owners podSpecOwners
+	if len(pvcs.Items) > 0 {
+		if owners, err = listPodSpecOwners(ctx, p.cli); err != nil {
+			return
+		}
+	}
+	now := time.Now()
+	for _, pvc := range pvcs.Items {
+		p.log.V(1).Info("evaluating", fmt.Sprintf("%T", pvc), pvc.Name)
+		alerts = append(alerts, p.checkPVC(&pvc, owners, now))
+	}
+	for _, pv := range pvs.Items {
+		p.log.V(1).Info("evaluating", fmt.Sprintf("%T", pv), pv.Name)
+		alerts = append(alerts, p.checkPV(&pv))
+	}
+	return
+}
+
+func (p *PVCUnusedRule) Evaluate(ctx context.Context, object interface{}) (a alert.Alert, err error) {
+	switch obj := object.(type) {
+	case *corev1.PersistentVolumeClaim:
+		var owners podSpecOwners
+		if !isStringInSlice(p.resource.Spec.IgnoreNamespaces, obj.Namespace) {
+			if owners, err = listPodSpecOwners(ctx, p.cli, &client.ListOptions{Namespace: obj.Namespace}); err != nil {
+				return
+			}
+		}
+		a = p.checkPVC(obj, owners, time.Now())
+	case *corev1.PersistentVolume:
+		a = p.checkPV(obj)
+	case *corev1.Pod:
+		a = p.evaluatePod(obj)
+	default:
+		err = fmt.Errorf("object being evaluated is not type %T, %T or %T", &corev1.PersistentVolumeClaim{}, &corev1.PersistentVolume{}, &corev1.Pod{})
+	}
+	return
+}
+
+// GetDelaySeconds returns the time left before the PersistentVolumeClaim can be unused,
+// so it's evaluated again after the initial delay.
+func (p *PVCUnusedRule) GetDelaySeconds(object interface{}) (time.Duration, error) {
+	switch obj := object.(type) {
+	case *corev1.PersistentVolumeClaim:
+		delay := obj.CreationTimestamp.Unix() + p.resource.Spec.InitialDelaySeconds - time.Now().Unix()
+		if delay > 0 {
+			return time.Duration(delay) * time.Second, nil
+		}
+		return 0, nil
+	case *corev1.PersistentVolume, *corev1.Pod:
+		return 0, nil
+	}
+	return 0, fmt.Errorf("unable to convert object to type %T, %T or %T", &corev1.PersistentVolumeClaim{}, &corev1.PersistentVolume{}, &corev1.Pod{})
+}
+
+func (p *PVCUnusedRule) newAlert(object interface{}, resourceName string) alert.Alert {
+	return alert.Alert{
+		Suppressed:      p.resource.Spec.Notification.Suppressed,
+		Severity:        p.resource.Spec.Notification.Severity,
+		MessageTemplate: p.resource.Spec.Notification.CustomMessageTemplate,
+		ResourceName:    resourceName,
+		ResourceKind:    getStructName(object),
+		Violated:        false,
+	}
+}
+
+// evaluatePod recovers the first violated PersistentVolumeClaim in the namespace that the new pod mounts
+func (p *PVCUnusedRule) evaluatePod(pod *corev1.Pod) (a alert.Alert) {
+	a = p.newAlert(corev1.PersistentVolumeClaim{}, "")
+	if p.status.checkedAt == nil {
+		return
+	}
+	for k := range p.status.getViolations(pod.Namespace) {
+		key := client.ObjectKey{Namespace: pod.Namespace, Name: strings.Split(k, Separator)[1]}
+		if volume := getPodSpecClaimVolume(&pod.Spec, key.Name); volume != "" {
+			a.ResourceName = key.String()
+			a.Message = fmt.Sprintf("PersistentVolumeClaim is being used by Pod '%s' volume '%s'", pod.Name, volume)
+			p.status.setViolation(key, a.Violated)
+			return
+		}
+	}
+	return
+}
+
+func (p *PVCUnusedRule) checkPVC(pvc *corev1.PersistentVolumeClaim, owners podSpecOwners, now time.Time) alert.Alert {
+	key := client.ObjectKey{Namespace: pvc.Namespace, Name: pvc.Name}
+	a := p.newAlert(pvc, key.String())
+	if isStringInSlice(p.resource.Spec.IgnoreNamespaces, key.Namespace) {
+		a.Message = "namespace is ignored by the rule"
+		return a
+	}
+	if user := findPVCUser(owners, pvc); user != "" {
+		a.Message = fmt.Sprintf("PersistentVolumeClaim is being used by %s", user)
+	} else if initialDelay := time.Duration(p.resource.Spec.InitialDelaySeconds) * time.Second; now.Sub(pvc.CreationTimestamp.Time) < initialDelay {
+		a.Message = fmt.Sprintf("PersistentVolumeClaim is not being used but created less than %s ago", initialDelay)
+	} else {
+		a.Violated = true
+		a.Message = "PersistentVolumeClaim is not being used"
+	}
+	p.status.setViolation(key, a.Violated)
+	return a
+}
+
+// checkPV checks if the PersistentVolume is stuck in Released or Failed phase, which keeps the disk but can't be bound again.
+// PersistentVolumes claimed from ignored namespaces are ignored.
+func (p *PVCUnusedRule) checkPV(pv *corev1.PersistentVolume) alert.Alert {
+	key := client.ObjectKey{Name: pv.Name}
+	a := p.newAlert(pv, key.String())
+	if pv.Spec.ClaimRef != nil && isStringInSlice(p.resource.Spec.IgnoreNamespaces, pv.Spec.ClaimRef.Namespace) {
+		a.Message = "namespace is ignored by the rule"
+		return a
+	}
+	switch pv.Status.Phase {
+	case corev1.VolumeReleased:
+		a.Violated = true
+		a.Message = fmt.Sprintf("PersistentVolume is Released and kept by reclaim policy %s", pv.Spec.PersistentVolumeReclaimPolicy)
+		if pv.Spec.ClaimRef != nil {
+			a.Message = fmt.Sprintf("%s, its claim '%s/%s' was deleted", a.Message, pv.Spec.ClaimRef.Namespace, pv.Spec.ClaimRef.Name)
+		}
+	case corev1.VolumeFailed:
+		a.Violated = true
+		a.Message = fmt.Sprintf("PersistentVolume is Failed: %s", pv.Status.Message)
+	default:
+		a.Message = fmt.Sprintf("PersistentVolume is %s", pv.Status.Phase)
+	}
+	p.status.setViolation(key, a.Violated)
+	return a
+}
+
+// podSpecOwners are the pods and workloads with pod templates, to find which of them refer to an object
+type podSpecOwners struct {
+	pods         []corev1.Pod
+	deployments  []appsv1.Deployment
+	statefulSets []appsv1.StatefulSet
+	daemonSets   []appsv1.DaemonSet
+	jobs         []batchv1.Job
+	cronJobs     []batchv1beta1.CronJob
+}
+
+func listPodSpecOwners(ctx context.Context, cli client.Client, opts ...client.ListOption) (owners podSpecOwners, err error) {
+	pods := &corev1.PodList{}
+	if err = cli.List(ctx, pods, opts...); err != nil {
+		return
+	}
+	deployments := &appsv1.DeploymentList{}
+	if err = cli.List(ctx, deployments, opts...); err != nil {
+		return
+	}
+	statefulSets := &appsv1.StatefulSetList{}
+	if err = cli.List(ctx, statefulSets, opts...); err != nil {
+		return
+	}
+	daemonSets := &appsv1.DaemonSetList{}
+	if err = cli.List(ctx, daemonSets, opts...); err != nil {
+		return
+	}
+	jobs := &batchv1.JobList{}
+	if err = cli.List(ctx, jobs, opts...); err != nil {
+		return
+	}
+	cronJobs := &batchv1beta1.CronJobList{}
+	if err = cli.List(ctx, cronJobs, opts...); err != nil {
+		return
+	}
+	owners = podSpecOwners{
+		pods:         pods.Items,
+		deployments:  deployments.Items,
+		statefulSets: statefulSets.Items,
+		daemonSets:   daemonSets.Items,
+		jobs:         jobs.Items,
+		cronJobs:     cronJobs.Items,
+	}
+	return
+}
+
+// find returns the description of the first owner in the namespace whose pod spec matches, or empty if there isn't any.
+// match returns if the pod spec refers to the object, with the detail of the reference if any, e.g. the volume name.
+func (o podSpecOwners) find(namespace string, match func(*corev1.PodSpec) (string, bool)) string {
+	describe := func(kind, name, detail string) string {
+		if detail == "" {
+			return fmt.Sprintf("%s '%s'", kind, name)
+		}
+		return fmt.Sprintf("%s '%s' %s", kind, name, detail)
+	}
+	for _, pod := range o.pods {
+		if detail, ok := match(&pod.Spec); ok && pod.Namespace == namespace {
+			return describe("Pod", pod.Name, detail)
+		}
+	}
+	for _, deployment := range o.deployments {
+		if detail, ok := match(&deployment.Spec.Template.Spec); ok && deployment.Namespace == namespace {
+			return describe("Deployment", deployment.Name, detail)
+		}
+	}
+	for _, statefulSet := range o.statefulSets {
+		if detail, ok := match(&statefulSet.Spec.Template.Spec); ok && statefulSet.Namespace == namespace {
+			return describe("StatefulSet", statefulSet.Name, detail)
+		}
+	}
+	for _, daemonSet := range o.daemonSets {
+		if detail, ok := match(&daemonSet.Spec.Template.Spec); ok && daemonSet.Namespace == namespace {
+			return describe("DaemonSet", daemonSet.Name, detail)
+		}
+	}
+	for _, job := range o.jobs {
+		if detail, ok := match(&job.Spec.Template.Spec); ok && job.Namespace == namespace {
+			return describe("Job", job.Name, detail)
+		}
+	}
+	for _, cronJob := range o.cronJobs {
+		if detail, ok := match(&cronJob.Spec.JobTemplate.Spec.Template.Spec); ok && cronJob.Namespace == namespace {
+			return describe("CronJob", cronJob.Name, detail)
+		}
+	}
+	return ""
+}
+
+// findPVCUser returns the description of the first owner mounting the PersistentVolumeClaim, or empty if there isn't any.
+func findPVCUser(owners podSpecOwners, pvc *corev1.PersistentVolumeClaim) string {
+	user := owners.find(pvc.Namespace, func(spec *corev1.PodSpec) (string, bool) {
+		if volume := getPodSpecClaimVolume(spec, pvc.Name); volume != "" {
+			return fmt.Sprintf("volume '%s'", volume), true
+		}
+		return "", false
+	})
+	if user != "" {
+		return user
+	}
+	// claims from volumeClaimTemplates are named <template>-<statefulset>-<ordinal>,
+	// and are kept for the replicas scaled down so they are still used by the statefulset.
+	for _, statefulSet := range owners.statefulSets {
+		if statefulSet.Namespace != pvc.Namespace {
+			continue
+		}
+		for _, template := range statefulSet.Spec.VolumeClaimTemplates {
+			prefix := fmt.Sprintf("%s-%s-", template.Name, statefulSet.Name)
+			if !strings.HasPrefix(pvc.Name, prefix) {
+				continue
+			}
+			if _, err := strconv.Atoi(strings.TrimPrefix(pvc.Name, prefix)); err == nil {
+				return fmt.Sprintf("StatefulSet '%s' volumeClaimTemplate '%s'", statefulSet.Name, template.Name)
+			}
+		}
+	}
+	return ""
+}
+
+// getPodSpecClaimVolume returns the name of the volume mounting the PersistentVolumeClaim, or empty if there isn't any.
+func getPodSpecClaimVolume(spec *corev1.PodSpec, claimName string) string {
+	for _, vol := range spec.Volumes {
+		if vol.PersistentVolumeClaim != nil && vol.PersistentVolumeClaim.ClaimName == claimName {
+			return vol.Name
+		}
+	}
+	return ""
+}
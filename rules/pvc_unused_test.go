@@ -0,0 +1,317 @@
+package rules
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/zapr"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/mercari/merlin/alert"
+	merlinv1beta1 "github.com/mercari/merlin/api/v1beta1"
+	"github.com/mercari/merlin/mocks"
+)
+
+func newTestPVCPodSpec(claimName string) corev1.PodSpec {
+	return corev1.PodSpec{Volumes: []corev1.Volume{{
+		Name:         "data",
+		VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: claimName}},
+	}}}
+}
+
+// expectPodSpecOwnerLists expects the lists of pods and workloads with pod templates
+func expectPodSpecOwnerLists(ctx context.Context, mockClient *mocks.MockClient, pods []corev1.Pod, statefulSets []appsv1.StatefulSet, opts ...interface{}) []*gomock.Call {
+	list := func(obj interface{}) *gomock.Call {
+		return mockClient.EXPECT().List(ctx, obj, opts...)
+	}
+	return []*gomock.Call{
+		list(&corev1.PodList{}).SetArg(1, corev1.PodList{Items: pods}).Return(nil),
+		list(&appsv1.DeploymentList{}).Return(nil),
+		list(&appsv1.StatefulSetList{}).SetArg(1, appsv1.StatefulSetList{Items: statefulSets}).Return(nil),
+		list(&appsv1.DaemonSetList{}).Return(nil),
+		list(&batchv1.JobList{}).Return(nil),
+		list(&batchv1beta1.CronJobList{}).Return(nil),
+	}
+}
+
+func Test_PVCUnusedRule_Basic(t *testing.T) {
+	notification := merlinv1beta1.Notification{
+		Notifiers:  []string{"testNotifier"},
+		Suppressed: true,
+	}
+
+	merlinv1beta1Rule := &merlinv1beta1.ClusterRulePVCUnused{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-r"},
+		Spec: merlinv1beta1.ClusterRulePVCUnusedSpec{
+			Notification:        notification,
+			InitialDelaySeconds: 30,
+		},
+	}
+
+	r := &PVCUnusedRule{resource: merlinv1beta1Rule}
+	assert.Equal(t, merlinv1beta1Rule.ObjectMeta, r.GetObjectMeta())
+	assert.Equal(t, merlinv1beta1Rule, r.GetObject())
+	assert.Equal(t, notification, r.GetNotification())
+	assert.Equal(t, "ClusterRulePVCUnused/test-r", r.GetName())
+
+	finalizer := "test.finalizer"
+	r.SetFinalizer(finalizer)
+	assert.Equal(t, finalizer, r.resource.Finalizers[0])
+	r.RemoveFinalizer(finalizer)
+	assert.Empty(t, r.resource.Finalizers)
+
+	delay, err := r.GetDelaySeconds(&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.Now()}})
+	assert.NoError(t, err)
+	assert.Equal(t, 30*time.Second, delay)
+	delay, err = r.GetDelaySeconds(&corev1.PersistentVolume{})
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), delay)
+	_, err = r.GetDelaySeconds(&corev1.Secret{})
+	assert.Error(t, err)
+}
+
+func Test_PVCUnusedRule_Evaluate(t *testing.T) {
+	ctx := context.Background()
+	log := zapr.NewLogger(zap.L())
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mocks.NewMockClient(mockCtrl)
+	listOpts := &client.ListOptions{Namespace: "test"}
+	oldPVC := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{
+		Namespace:         "test",
+		Name:              "data-db-2",
+		CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour)),
+	}}
+
+	cases := []struct {
+		desc      string
+		mockCalls []*gomock.Call
+		resource  interface{}
+		expect    alert.Alert
+		expectErr bool
+	}{
+		{
+			desc:      "non pvc, pv or pod should have error",
+			resource:  &corev1.Secret{},
+			expectErr: true,
+		},
+		{
+			desc:     "ignored namespace should not get violated alert",
+			resource: &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Namespace: "ignoredNS", Name: "data"}},
+			expect: alert.Alert{
+				Message:      "namespace is ignored by the rule",
+				ResourceKind: "PersistentVolumeClaim",
+				ResourceName: "ignoredNS/data",
+			},
+		},
+		{
+			desc: "pvc mounted by pod should not get violated alert",
+			mockCalls: expectPodSpecOwnerLists(ctx, mockClient,
+				[]corev1.Pod{{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "app"}, Spec: newTestPVCPodSpec("data-db-2")}},
+				nil, listOpts),
+			resource: oldPVC,
+			expect: alert.Alert{
+				Message:      "PersistentVolumeClaim is being used by Pod 'app' volume 'data'",
+				ResourceKind: "PersistentVolumeClaim",
+				ResourceName: "test/data-db-2",
+			},
+		},
+		{
+			desc: "pvc from statefulset volumeClaimTemplates should not get violated alert",
+			mockCalls: expectPodSpecOwnerLists(ctx, mockClient, nil,
+				[]appsv1.StatefulSet{{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "db"},
+					Spec: appsv1.StatefulSetSpec{VolumeClaimTemplates: []corev1.PersistentVolumeClaim{
+						{ObjectMeta: metav1.ObjectMeta{Name: "data"}},
+					}},
+				}}, listOpts),
+			resource: oldPVC,
+			expect: alert.Alert{
+				Message:      "PersistentVolumeClaim is being used by StatefulSet 'db' volumeClaimTemplate 'data'",
+				ResourceKind: "PersistentVolumeClaim",
+				ResourceName: "test/data-db-2",
+			},
+		},
+		{
+			desc:      "pvc not mounted should get violated alert",
+			mockCalls: expectPodSpecOwnerLists(ctx, mockClient, nil, nil, listOpts),
+			resource:  oldPVC,
+			expect: alert.Alert{
+				Message:      "PersistentVolumeClaim is not being used",
+				ResourceKind: "PersistentVolumeClaim",
+				ResourceName: "test/data-db-2",
+				Violated:     true,
+			},
+		},
+		{
+			desc: "released pv should get violated alert",
+			resource: &corev1.PersistentVolume{
+				ObjectMeta: metav1.ObjectMeta{Name: "pv-1"},
+				Spec: corev1.PersistentVolumeSpec{
+					PersistentVolumeReclaimPolicy: corev1.PersistentVolumeReclaimRetain,
+					ClaimRef:                      &corev1.ObjectReference{Namespace: "test", Name: "data"},
+				},
+				Status: corev1.PersistentVolumeStatus{Phase: corev1.VolumeReleased},
+			},
+			expect: alert.Alert{
+				Message:      "PersistentVolume is Released and kept by reclaim policy Retain, its claim 'test/data' was deleted",
+				ResourceKind: "PersistentVolume",
+				ResourceName: "/pv-1",
+				Violated:     true,
+			},
+		},
+		{
+			desc: "failed pv should get violated alert",
+			resource: &corev1.PersistentVolume{
+				ObjectMeta: metav1.ObjectMeta{Name: "pv-1"},
+				Status:     corev1.PersistentVolumeStatus{Phase: corev1.VolumeFailed, Message: "error deleting volume"},
+			},
+			expect: alert.Alert{
+				Message:      "PersistentVolume is Failed: error deleting volume",
+				ResourceKind: "PersistentVolume",
+				ResourceName: "/pv-1",
+				Violated:     true,
+			},
+		},
+		{
+			desc: "bound pv should not get violated alert",
+			resource: &corev1.PersistentVolume{
+				ObjectMeta: metav1.ObjectMeta{Name: "pv-1"},
+				Status:     corev1.PersistentVolumeStatus{Phase: corev1.VolumeBound},
+			},
+			expect: alert.Alert{
+				Message:      "PersistentVolume is Bound",
+				ResourceKind: "PersistentVolume",
+				ResourceName: "/pv-1",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(tt *testing.T) {
+			for _, call := range tc.mockCalls {
+				call.Times(1)
+			}
+			r := &PVCUnusedRule{
+				rule: rule{cli: mockClient, log: log, status: &Status{}},
+				resource: &merlinv1beta1.ClusterRulePVCUnused{
+					Spec: merlinv1beta1.ClusterRulePVCUnusedSpec{IgnoreNamespaces: []string{"ignoredNS"}, InitialDelaySeconds: 3600},
+				},
+			}
+			a, err := r.Evaluate(ctx, tc.resource)
+			if tc.expectErr {
+				assert.Error(tt, err)
+			} else {
+				assert.NoError(tt, err)
+				assert.Equal(tt, tc.expect, a)
+			}
+		})
+	}
+}
+
+func Test_PVCUnusedRule_EvaluatePod(t *testing.T) {
+	ctx := context.Background()
+	log := zapr.NewLogger(zap.L())
+	r := &PVCUnusedRule{
+		rule:     rule{log: log, status: &Status{}},
+		resource: &merlinv1beta1.ClusterRulePVCUnused{},
+	}
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "app"}, Spec: newTestPVCPodSpec("data")}
+
+	a, err := r.Evaluate(ctx, pod)
+	assert.NoError(t, err)
+	assert.Equal(t, alert.Alert{ResourceKind: "PersistentVolumeClaim"}, a)
+
+	r.status.setViolation(client.ObjectKey{Namespace: "test", Name: "data"}, true)
+	a, err = r.Evaluate(ctx, pod)
+	assert.NoError(t, err)
+	assert.Equal(t, alert.Alert{
+		Message:      "PersistentVolumeClaim is being used by Pod 'app' volume 'data'",
+		ResourceKind: "PersistentVolumeClaim",
+		ResourceName: "test/data",
+	}, a)
+	assert.False(t, r.status.isViolated(client.ObjectKey{Namespace: "test", Name: "data"}))
+}
+
+func Test_PVCUnusedRule_EvaluateAll(t *testing.T) {
+	ctx := context.Background()
+	log := zapr.NewLogger(zap.L())
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mocks.NewMockClient(mockCtrl)
+	r := &PVCUnusedRule{
+		rule:     rule{cli: mockClient, log: log, status: &Status{}},
+		resource: &merlinv1beta1.ClusterRulePVCUnused{Spec: merlinv1beta1.ClusterRulePVCUnusedSpec{InitialDelaySeconds: 3600}},
+	}
+
+	cases := []struct {
+		desc      string
+		mockCalls []*gomock.Call
+		expect    []alert.Alert
+		expectErr bool
+	}{
+		{
+			desc: "no resources should returns nil alerts",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().
+					List(ctx, &corev1.PersistentVolumeClaimList{}).
+					Return(nil),
+				mockClient.EXPECT().
+					List(ctx, &corev1.PersistentVolumeList{}).
+					Return(nil),
+			},
+		},
+		{
+			desc: "pvcs and pvs should be evaluated",
+			mockCalls: append([]*gomock.Call{
+				mockClient.EXPECT().
+					List(ctx, &corev1.PersistentVolumeClaimList{}).
+					SetArg(1, corev1.PersistentVolumeClaimList{Items: []corev1.PersistentVolumeClaim{
+						{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "data", CreationTimestamp: metav1.Now()}},
+					}}).
+					Return(nil),
+				mockClient.EXPECT().
+					List(ctx, &corev1.PersistentVolumeList{}).
+					SetArg(1, corev1.PersistentVolumeList{Items: []corev1.PersistentVolume{
+						{ObjectMeta: metav1.ObjectMeta{Name: "pv-1"}, Status: corev1.PersistentVolumeStatus{Phase: corev1.VolumeAvailable}},
+					}}).
+					Return(nil),
+			}, expectPodSpecOwnerLists(ctx, mockClient, nil, nil)...),
+			expect: []alert.Alert{
+				{
+					Message:      "PersistentVolumeClaim is not being used but created less than 1h0m0s ago",
+					ResourceKind: "PersistentVolumeClaim",
+					ResourceName: "test/data",
+				},
+				{
+					Message:      "PersistentVolume is Available",
+					ResourceKind: "PersistentVolume",
+					ResourceName: "/pv-1",
+				},
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.desc, func(tt *testing.T) {
+			for _, call := range tc.mockCalls {
+				call.Times(1)
+			}
+			alerts, err := r.EvaluateAll(ctx)
+			if tc.expectErr {
+				assert.Error(tt, err)
+			} else {
+				assert.NoError(tt, err)
+				assert.Equal(tt, tc.expect, alerts)
+			}
+		})
+	}
+}
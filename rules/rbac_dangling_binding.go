@@ -0,0 +1,330 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/mercari/merlin/alert"
+	merlinv1beta1 "github.com/mercari/merlin/api/v1beta1"
+)
+
+const defaultRBACDanglingBindingRecheckIntervalSeconds = 300
+
+type RBACDanglingBindingRule struct {
+	rule
+	resource *merlinv1beta1.ClusterRuleRBACDanglingBinding
+}
+
+// rbacBinding is the common part of RoleBindings and ClusterRoleBindings
+type rbacBinding struct {
+	object   interface{}
+	key      client.ObjectKey
+	roleRef  rbacv1.RoleRef
+	subjects []rbacv1.Subject
+}
+
+// rbacLookup returns if the Role, ClusterRole or ServiceAccount with the key exists
+type rbacLookup func(kind string, key client.ObjectKey) (bool, error)
+
+func (r *RBACDanglingBindingRule) New(ctx context.Context, cli client.Client, logger logr.Logger, key client.ObjectKey) (Rule, error) {
+	r.cli = cli
+	r.log = logger
+	r.status = &Status{}
+	r.resource = &merlinv1beta1.ClusterRuleRBACDanglingBinding{}
+	if err := r.cli.Get(ctx, key, r.resource); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *RBACDanglingBindingRule) GetObject() runtime.Object {
+	return r.resource
+}
+
+func (r RBACDanglingBindingRule) GetName() string {
+	return strings.Join([]string{getStructName(r.resource), r.resource.Name}, Separator)
+}
+
+func (r RBACDanglingBindingRule) GetObjectMeta() metav1.ObjectMeta {
+	return r.resource.ObjectMeta
+}
+
+func (r RBACDanglingBindingRule) GetNotification() merlinv1beta1.Notification {
+	return r.resource.Spec.Notification
+}
+
+func (r *RBACDanglingBindingRule) SetFinalizer(finalizer string) {
+	r.resource.ObjectMeta.Finalizers = append(r.resource.ObjectMeta.Finalizers, finalizer)
+}
+
+func (r *RBACDanglingBindingRule) RemoveFinalizer(finalizer string) {
+	r.resource.ObjectMeta.Finalizers = removeString(r.resource.ObjectMeta.Finalizers, finalizer)
+}
+
+// GetRecheckInterval returns the interval to re-evaluate all bindings, since deleting roles or service accounts
+// makes bindings dangling without any event of the bindings.
+func (r *RBACDanglingBindingRule) GetRecheckInterval() time.Duration {
+	if r.resource.Spec.RecheckIntervalSeconds > 0 {
+		return time.Duration(r.resource.Spec.RecheckIntervalSeconds) * time.Second
+	}
+	return defaultRBACDanglingBindingRecheckIntervalSeconds * time.Second
+}
+
+func (r *RBACDanglingBindingRule) EvaluateAll(ctx context.Context) (alerts []alert.Alert, err error) {
+	roleBindings := &rbacv1.RoleBindingList{}
+	if err = r.cli.List(ctx, roleBindings); err != nil {
+		return
+	}
+	clusterRoleBindings := &rbacv1.ClusterRoleBindingList{}
+	if err = r.cli.List(ctx, clusterRoleBindings); err != nil {
+		return
+	}
+
+	if len(roleBindings.Items) == 0 && len(clusterRoleBindings.Items) == 0 {
+		r.log.Info("no rolebinding or clusterrolebinding found")
+		return
+	}
+	lookup, err := r.listLookup(ctx)
+	if err != nil {
+		return
+	}
+	for _, roleBinding := range roleBindings.Items {
+		r.log.V(1).Info("evaluating", fmt.Sprintf("%T", roleBinding), roleBinding.Name)
+		var a alert.Alert
+		if a, err = r.checkBinding(newRoleBinding(&roleBinding), lookup); err != nil {
+			return
+		}
+		alerts = append(alerts, a)
+	}
+	for _, clusterRoleBinding := range clusterRoleBindings.Items {
+		r.log.V(1).Info("evaluating", fmt.Sprintf("%T", clusterRoleBinding), clusterRoleBinding.Name)
+		var a alert.Alert
+		if a, err = r.checkBinding(newClusterRoleBinding(&clusterRoleBinding), lookup); err != nil {
+			return
+		}
+		alerts = append(alerts, a)
+	}
+	return
+}
+
+func (r *RBACDanglingBindingRule) Evaluate(ctx context.Context, object interface{}) (a alert.Alert, err error) {
+	switch obj := object.(type) {
+	case *rbacv1.RoleBinding:
+		return r.checkBinding(newRoleBinding(obj), r.getLookup(ctx))
+	case *rbacv1.ClusterRoleBinding:
+		return r.checkBinding(newClusterRoleBinding(obj), r.getLookup(ctx))
+	case *rbacv1.Role:
+		return r.evaluateReferrers(ctx, obj.Namespace, func(b rbacBinding) bool {
+			return b.roleRef.Kind == "Role" && b.key.Namespace == obj.Namespace && b.roleRef.Name == obj.Name
+		})
+	case *rbacv1.ClusterRole:
+		return r.evaluateReferrers(ctx, "", func(b rbacBinding) bool {
+			return b.roleRef.Kind == "ClusterRole" && b.roleRef.Name == obj.Name
+		})
+	case *corev1.ServiceAccount:
+		return r.evaluateReferrers(ctx, "", func(b rbacBinding) bool {
+			for _, subject := range b.subjects {
+				if subject.Kind == rbacv1.ServiceAccountKind && subject.Name == obj.Name && getSubjectNamespace(b, subject) == obj.Namespace {
+					return true
+				}
+			}
+			return false
+		})
+	}
+	err = fmt.Errorf("object being evaluated is not type %T, %T, %T, %T or %T",
+		&rbacv1.RoleBinding{}, &rbacv1.ClusterRoleBinding{}, &rbacv1.Role{}, &rbacv1.ClusterRole{}, &corev1.ServiceAccount{})
+	return
+}
+
+func (r *RBACDanglingBindingRule) GetDelaySeconds(object interface{}) (time.Duration, error) {
+	return 0, nil
+}
+
+// evaluateReferrers re-checks the bindings referring to the created or updated Role, ClusterRole or ServiceAccount,
+// and returns the alert of the first binding whose violation state has changed.
+// Only RoleBindings in the namespace are checked if namespace is not empty.
+func (r *RBACDanglingBindingRule) evaluateReferrers(ctx context.Context, namespace string, isReferrer func(rbacBinding) bool) (a alert.Alert, err error) {
+	a = r.newAlert(rbacv1.RoleBinding{}, "")
+	if r.status.checkedAt == nil {
+		return
+	}
+	var bindings []rbacBinding
+	roleBindings := &rbacv1.RoleBindingList{}
+	if err = r.cli.List(ctx, roleBindings, &client.ListOptions{Namespace: namespace}); err != nil {
+		return
+	}
+	for i := range roleBindings.Items {
+		bindings = append(bindings, newRoleBinding(&roleBindings.Items[i]))
+	}
+	if namespace == "" {
+		clusterRoleBindings := &rbacv1.ClusterRoleBindingList{}
+		if err = r.cli.List(ctx, clusterRoleBindings); err != nil {
+			return
+		}
+		for i := range clusterRoleBindings.Items {
+			bindings = append(bindings, newClusterRoleBinding(&clusterRoleBindings.Items[i]))
+		}
+	}
+	lookup := r.getLookup(ctx)
+	for _, binding := range bindings {
+		if !isReferrer(binding) {
+			continue
+		}
+		wasViolated := r.status.isViolated(binding.key)
+		var bindingAlert alert.Alert
+		if bindingAlert, err = r.checkBinding(binding, lookup); err != nil {
+			return
+		}
+		if bindingAlert.Violated != wasViolated {
+			return bindingAlert, nil
+		}
+	}
+	return
+}
+
+func (r *RBACDanglingBindingRule) newAlert(object interface{}, resourceName string) alert.Alert {
+	return alert.Alert{
+		Suppressed:      r.resource.Spec.Notification.Suppressed,
+		Severity:        r.resource.Spec.Notification.Severity,
+		MessageTemplate: r.resource.Spec.Notification.CustomMessageTemplate,
+		ResourceName:    resourceName,
+		ResourceKind:    getStructName(object),
+		Violated:        false,
+	}
+}
+
+// checkBinding checks if the role and the ServiceAccount subjects of the binding exist.
+// ServiceAccounts in ignored namespaces are not checked.
+func (r *RBACDanglingBindingRule) checkBinding(binding rbacBinding, lookup rbacLookup) (a alert.Alert, err error) {
+	a = r.newAlert(binding.object, binding.key.String())
+	if binding.key.Namespace != "" && isStringInSlice(r.resource.Spec.IgnoreNamespaces, binding.key.Namespace) {
+		a.Message = "namespace is ignored by the rule"
+		return
+	}
+	var missing []string
+	roleKey := client.ObjectKey{Name: binding.roleRef.Name}
+	if binding.roleRef.Kind == "Role" {
+		roleKey.Namespace = binding.key.Namespace
+	}
+	var exists bool
+	if exists, err = lookup(binding.roleRef.Kind, roleKey); err != nil {
+		return
+	} else if !exists {
+		missing = append(missing, fmt.Sprintf("%s '%s'", binding.roleRef.Kind, binding.roleRef.Name))
+	}
+	for _, subject := range binding.subjects {
+		if subject.Kind != rbacv1.ServiceAccountKind {
+			continue
+		}
+		key := client.ObjectKey{Namespace: getSubjectNamespace(binding, subject), Name: subject.Name}
+		if isStringInSlice(r.resource.Spec.IgnoreNamespaces, key.Namespace) {
+			continue
+		}
+		if exists, err = lookup(rbacv1.ServiceAccountKind, key); err != nil {
+			return
+		} else if !exists {
+			missing = append(missing, fmt.Sprintf("%s '%s'", rbacv1.ServiceAccountKind, key))
+		}
+	}
+	if len(missing) > 0 {
+		a.Violated = true
+		a.Message = fmt.Sprintf("%s refers to missing objects: %s", a.ResourceKind, strings.Join(missing, ", "))
+	} else {
+		a.Message = fmt.Sprintf("%s refers to existing role and service accounts", a.ResourceKind)
+	}
+	r.status.setViolation(binding.key, a.Violated)
+	return
+}
+
+// getLookup returns the lookup getting the objects from the client
+func (r *RBACDanglingBindingRule) getLookup(ctx context.Context) rbacLookup {
+	return func(kind string, key client.ObjectKey) (bool, error) {
+		var object runtime.Object
+		switch kind {
+		case "Role":
+			object = &rbacv1.Role{}
+		case "ClusterRole":
+			object = &rbacv1.ClusterRole{}
+		case rbacv1.ServiceAccountKind:
+			object = &corev1.ServiceAccount{}
+		default:
+			return false, fmt.Errorf("unknown kind %s", kind)
+		}
+		if err := r.cli.Get(ctx, key, object); err != nil {
+			if apierrs.IsNotFound(err) {
+				return false, nil
+			}
+			return false, err
+		}
+		return true, nil
+	}
+}
+
+// listLookup lists all Roles, ClusterRoles and ServiceAccounts once, and returns the lookup with them
+func (r *RBACDanglingBindingRule) listLookup(ctx context.Context) (lookup rbacLookup, err error) {
+	existing := map[string]bool{}
+	add := func(kind string, key client.ObjectKey) {
+		existing[strings.Join([]string{kind, key.String()}, Separator)] = true
+	}
+	roles := &rbacv1.RoleList{}
+	if err = r.cli.List(ctx, roles); err != nil {
+		return
+	}
+	for _, role := range roles.Items {
+		add("Role", client.ObjectKey{Namespace: role.Namespace, Name: role.Name})
+	}
+	clusterRoles := &rbacv1.ClusterRoleList{}
+	if err = r.cli.List(ctx, clusterRoles); err != nil {
+		return
+	}
+	for _, clusterRole := range clusterRoles.Items {
+		add("ClusterRole", client.ObjectKey{Name: clusterRole.Name})
+	}
+	serviceAccounts := &corev1.ServiceAccountList{}
+	if err = r.cli.List(ctx, serviceAccounts); err != nil {
+		return
+	}
+	for _, serviceAccount := range serviceAccounts.Items {
+		add(rbacv1.ServiceAccountKind, client.ObjectKey{Namespace: serviceAccount.Namespace, Name: serviceAccount.Name})
+	}
+	lookup = func(kind string, key client.ObjectKey) (bool, error) {
+		return existing[strings.Join([]string{kind, key.String()}, Separator)], nil
+	}
+	return
+}
+
+func newRoleBinding(roleBinding *rbacv1.RoleBinding) rbacBinding {
+	return rbacBinding{
+		object:   roleBinding,
+		key:      client.ObjectKey{Namespace: roleBinding.Namespace, Name: roleBinding.Name},
+		roleRef:  roleBinding.RoleRef,
+		subjects: roleBinding.Subjects,
+	}
+}
+
+func newClusterRoleBinding(clusterRoleBinding *rbacv1.ClusterRoleBinding) rbacBinding {
+	return rbacBinding{
+		object:   clusterRoleBinding,
+		key:      client.ObjectKey{Name: clusterRoleBinding.Name},
+		roleRef:  clusterRoleBinding.RoleRef,
+		subjects: clusterRoleBinding.Subjects,
+	}
+}
+
+// getSubjectNamespace returns the namespace of the subject, which defaults to the namespace of the RoleBinding
+func getSubjectNamespace(binding rbacBinding, subject rbacv1.Subject) string {
+	if subject.Namespace != "" {
+		return subject.Namespace
+	}
+	return binding.key.Namespace
+}
@@ -0,0 +1,318 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-logr/zapr"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/mercari/merlin/alert"
+	merlinv1beta1 "github.com/mercari/merlin/api/v1beta1"
+	"github.com/mercari/merlin/mocks"
+)
+
+func newTestRoleBinding(roleKind string, subjects ...rbacv1.Subject) *rbacv1.RoleBinding {
+	return &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "reader"},
+		RoleRef:    rbacv1.RoleRef{Kind: roleKind, Name: "reader"},
+		Subjects:   subjects,
+	}
+}
+
+func Test_RBACDanglingBindingRule_Basic(t *testing.T) {
+	notification := merlinv1beta1.Notification{
+		Notifiers:  []string{"testNotifier"},
+		Suppressed: true,
+	}
+
+	merlinv1beta1Rule := &merlinv1beta1.ClusterRuleRBACDanglingBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-r"},
+		Spec: merlinv1beta1.ClusterRuleRBACDanglingBindingSpec{
+			Notification: notification,
+		},
+	}
+
+	r := &RBACDanglingBindingRule{resource: merlinv1beta1Rule}
+	assert.Equal(t, merlinv1beta1Rule.ObjectMeta, r.GetObjectMeta())
+	assert.Equal(t, merlinv1beta1Rule, r.GetObject())
+	assert.Equal(t, notification, r.GetNotification())
+	assert.Equal(t, "ClusterRuleRBACDanglingBinding/test-r", r.GetName())
+	assert.Equal(t, 5*time.Minute, r.GetRecheckInterval())
+
+	finalizer := "test.finalizer"
+	r.SetFinalizer(finalizer)
+	assert.Equal(t, finalizer, r.resource.Finalizers[0])
+	r.RemoveFinalizer(finalizer)
+	assert.Empty(t, r.resource.Finalizers)
+}
+
+func Test_RBACDanglingBindingRule_Evaluate(t *testing.T) {
+	ctx := context.Background()
+	log := zapr.NewLogger(zap.L())
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mocks.NewMockClient(mockCtrl)
+	notFound := func(name string) error {
+		return apierrs.NewNotFound(schema.GroupResource{}, name)
+	}
+
+	cases := []struct {
+		desc      string
+		mockCalls []*gomock.Call
+		resource  interface{}
+		expect    alert.Alert
+		expectErr bool
+	}{
+		{
+			desc:      "non binding, role or serviceaccount should have error",
+			resource:  &corev1.Pod{},
+			expectErr: true,
+		},
+		{
+			desc:     "ignored namespace should not get violated alert",
+			resource: &rbacv1.RoleBinding{ObjectMeta: metav1.ObjectMeta{Namespace: "ignoredNS", Name: "reader"}},
+			expect: alert.Alert{
+				Message:      "namespace is ignored by the rule",
+				ResourceKind: "RoleBinding",
+				ResourceName: "ignoredNS/reader",
+			},
+		},
+		{
+			desc: "rolebinding with existing role and serviceaccount should not get violated alert",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().
+					Get(ctx, client.ObjectKey{Namespace: "test", Name: "reader"}, &rbacv1.Role{}).
+					Return(nil),
+				mockClient.EXPECT().
+					Get(ctx, client.ObjectKey{Namespace: "test", Name: "ci"}, &corev1.ServiceAccount{}).
+					Return(nil),
+			},
+			resource: newTestRoleBinding("Role",
+				rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Name: "ci"},
+				rbacv1.Subject{Kind: rbacv1.UserKind, Name: "someone@example.com"},
+			),
+			expect: alert.Alert{
+				Message:      "RoleBinding refers to existing role and service accounts",
+				ResourceKind: "RoleBinding",
+				ResourceName: "test/reader",
+			},
+		},
+		{
+			desc: "rolebinding with missing role and serviceaccount should get violated alert",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().
+					Get(ctx, client.ObjectKey{Name: "reader"}, &rbacv1.ClusterRole{}).
+					Return(notFound("reader")),
+				mockClient.EXPECT().
+					Get(ctx, client.ObjectKey{Namespace: "test", Name: "ci"}, &corev1.ServiceAccount{}).
+					Return(notFound("ci")),
+				mockClient.EXPECT().
+					Get(ctx, client.ObjectKey{Namespace: "other", Name: "deployer"}, &corev1.ServiceAccount{}).
+					Return(nil),
+			},
+			resource: newTestRoleBinding("ClusterRole",
+				rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Name: "ci"},
+				rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Namespace: "other", Name: "deployer"},
+				rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Namespace: "ignoredNS", Name: "deployer"},
+			),
+			expect: alert.Alert{
+				Message:      "RoleBinding refers to missing objects: ClusterRole 'reader', ServiceAccount 'test/ci'",
+				ResourceKind: "RoleBinding",
+				ResourceName: "test/reader",
+				Violated:     true,
+			},
+		},
+		{
+			desc: "clusterrolebinding with missing clusterrole should get violated alert",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().
+					Get(ctx, client.ObjectKey{Name: "admin"}, &rbacv1.ClusterRole{}).
+					Return(notFound("admin")),
+			},
+			resource: &rbacv1.ClusterRoleBinding{
+				ObjectMeta: metav1.ObjectMeta{Name: "admin"},
+				RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "admin"},
+			},
+			expect: alert.Alert{
+				Message:      "ClusterRoleBinding refers to missing objects: ClusterRole 'admin'",
+				ResourceKind: "ClusterRoleBinding",
+				ResourceName: "/admin",
+				Violated:     true,
+			},
+		},
+		{
+			desc: "error getting role should have error",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().
+					Get(ctx, client.ObjectKey{Namespace: "test", Name: "reader"}, &rbacv1.Role{}).
+					Return(fmt.Errorf("unexpected error")),
+			},
+			resource:  newTestRoleBinding("Role"),
+			expectErr: true,
+		},
+		{
+			desc:     "role before the rule is evaluated should not get alert",
+			resource: &rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "reader"}},
+			expect:   alert.Alert{ResourceKind: "RoleBinding"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(tt *testing.T) {
+			for _, call := range tc.mockCalls {
+				call.Times(1)
+			}
+			r := &RBACDanglingBindingRule{
+				rule: rule{cli: mockClient, log: log, status: &Status{}},
+				resource: &merlinv1beta1.ClusterRuleRBACDanglingBinding{
+					Spec: merlinv1beta1.ClusterRuleRBACDanglingBindingSpec{IgnoreNamespaces: []string{"ignoredNS"}},
+				},
+			}
+			a, err := r.Evaluate(ctx, tc.resource)
+			if tc.expectErr {
+				assert.Error(tt, err)
+			} else {
+				assert.NoError(tt, err)
+				assert.Equal(tt, tc.expect, a)
+			}
+		})
+	}
+}
+
+func Test_RBACDanglingBindingRule_EvaluateReferrers(t *testing.T) {
+	ctx := context.Background()
+	log := zapr.NewLogger(zap.L())
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mocks.NewMockClient(mockCtrl)
+	r := &RBACDanglingBindingRule{
+		rule:     rule{cli: mockClient, log: log, status: &Status{}},
+		resource: &merlinv1beta1.ClusterRuleRBACDanglingBinding{},
+	}
+	r.status.setViolation(client.ObjectKey{Namespace: "test", Name: "reader"}, true)
+
+	mockClient.EXPECT().
+		List(ctx, &rbacv1.RoleBindingList{}, &client.ListOptions{Namespace: "test"}).
+		SetArg(1, rbacv1.RoleBindingList{Items: []rbacv1.RoleBinding{
+			*newTestRoleBinding("ClusterRole"),
+			*newTestRoleBinding("Role"),
+		}}).
+		Return(nil).
+		Times(1)
+	mockClient.EXPECT().
+		Get(ctx, client.ObjectKey{Namespace: "test", Name: "reader"}, &rbacv1.Role{}).
+		Return(nil).
+		Times(1)
+
+	a, err := r.Evaluate(ctx, &rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "reader"}})
+	assert.NoError(t, err)
+	assert.Equal(t, alert.Alert{
+		Message:      "RoleBinding refers to existing role and service accounts",
+		ResourceKind: "RoleBinding",
+		ResourceName: "test/reader",
+	}, a)
+	assert.False(t, r.status.isViolated(client.ObjectKey{Namespace: "test", Name: "reader"}))
+}
+
+func Test_RBACDanglingBindingRule_EvaluateAll(t *testing.T) {
+	ctx := context.Background()
+	log := zapr.NewLogger(zap.L())
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mocks.NewMockClient(mockCtrl)
+	r := &RBACDanglingBindingRule{
+		rule:     rule{cli: mockClient, log: log, status: &Status{}},
+		resource: &merlinv1beta1.ClusterRuleRBACDanglingBinding{},
+	}
+
+	cases := []struct {
+		desc      string
+		mockCalls []*gomock.Call
+		expect    []alert.Alert
+		expectErr bool
+	}{
+		{
+			desc: "no resources should returns nil alerts",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().
+					List(ctx, &rbacv1.RoleBindingList{}).
+					Return(nil),
+				mockClient.EXPECT().
+					List(ctx, &rbacv1.ClusterRoleBindingList{}).
+					Return(nil),
+			},
+		},
+		{
+			desc: "bindings should be evaluated with listed roles and serviceaccounts",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().
+					List(ctx, &rbacv1.RoleBindingList{}).
+					SetArg(1, rbacv1.RoleBindingList{Items: []rbacv1.RoleBinding{
+						*newTestRoleBinding("Role", rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Name: "ci"}),
+					}}).
+					Return(nil),
+				mockClient.EXPECT().
+					List(ctx, &rbacv1.ClusterRoleBindingList{}).
+					SetArg(1, rbacv1.ClusterRoleBindingList{Items: []rbacv1.ClusterRoleBinding{{
+						ObjectMeta: metav1.ObjectMeta{Name: "view"},
+						RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "view"},
+						Subjects:   []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Namespace: "test", Name: "ci"}},
+					}}}).
+					Return(nil),
+				mockClient.EXPECT().
+					List(ctx, &rbacv1.RoleList{}).
+					SetArg(1, rbacv1.RoleList{Items: []rbacv1.Role{
+						{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "reader"}},
+					}}).
+					Return(nil),
+				mockClient.EXPECT().
+					List(ctx, &rbacv1.ClusterRoleList{}).
+					Return(nil),
+				mockClient.EXPECT().
+					List(ctx, &corev1.ServiceAccountList{}).
+					SetArg(1, corev1.ServiceAccountList{Items: []corev1.ServiceAccount{
+						{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "ci"}},
+					}}).
+					Return(nil),
+			},
+			expect: []alert.Alert{
+				{
+					Message:      "RoleBinding refers to existing role and service accounts",
+					ResourceKind: "RoleBinding",
+					ResourceName: "test/reader",
+				},
+				{
+					Message:      "ClusterRoleBinding refers to missing objects: ClusterRole 'view'",
+					ResourceKind: "ClusterRoleBinding",
+					ResourceName: "/view",
+					Violated:     true,
+				},
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.desc, func(tt *testing.T) {
+			for _, call := range tc.mockCalls {
+				call.Times(1)
+			}
+			alerts, err := r.EvaluateAll(ctx)
+			if tc.expectErr {
+				assert.Error(tt, err)
+			} else {
+				assert.NoError(tt, err)
+				assert.Equal(tt, tc.expect, alerts)
+			}
+		})
+	}
+}
@@ -0,0 +1,256 @@
+package rules
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/open-policy-agent/opa/rego"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/mercari/merlin/alert"
+	merlinv1beta1 "github.com/mercari/merlin/api/v1beta1"
+)
+
+const defaultRegoRecheckIntervalSeconds = 300
+
+// regoModuleName is the file name of the module in the errors of the policy
+const regoModuleName = "policy.rego"
+
+// regoEvaluator evaluates the query of a Rego policy against objects as unstructured data, with the input in the same
+// structure as Gatekeeper, so the policies written for Gatekeeper can be used as is.
+type regoEvaluator struct {
+	query      rego.PreparedEvalQuery
+	parameters interface{}
+}
+
+func newRegoEvaluator(ctx context.Context, module, query string, parameters *runtime.RawExtension) (*regoEvaluator, error) {
+	e := &regoEvaluator{}
+	if parameters != nil && len(parameters.Raw) > 0 {
+		if err := json.Unmarshal(parameters.Raw, &e.parameters); err != nil {
+			return nil, fmt.Errorf("invalid parameters: %w", err)
+		}
+	}
+	var err error
+	if e.query, err = rego.New(rego.Query(query), rego.Module(regoModuleName, module)).PrepareForEval(ctx); err != nil {
+		return nil, fmt.Errorf("invalid policy: %w", err)
+	}
+	return e, nil
+}
+
+// ValidateRego returns error if the module can't be compiled with the query, or the parameters are invalid.
+func ValidateRego(ctx context.Context, module, query string, parameters *runtime.RawExtension) error {
+	_, err := newRegoEvaluator(ctx, module, query, parameters)
+	return err
+}
+
+// evaluate returns the messages of the violations of the object, results of the query are either messages as string,
+// or objects with the message as `msg`, other results are used as JSON.
+func (e *regoEvaluator) evaluate(ctx context.Context, object map[string]interface{}, gvk schema.GroupVersionKind, key client.ObjectKey) ([]string, error) {
+	input := map[string]interface{}{
+		"review": map[string]interface{}{
+			"kind":      map[string]interface{}{"group": gvk.Group, "version": gvk.Version, "kind": gvk.Kind},
+			"name":      key.Name,
+			"namespace": key.Namespace,
+			"object":    object,
+		},
+		"parameters": e.parameters,
+	}
+	results, err := e.query.Eval(ctx, rego.EvalInput(input))
+	if err != nil {
+		return nil, err
+	}
+	var messages []string
+	for _, result := range results {
+		for _, expression := range result.Expressions {
+			violations, ok := expression.Value.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("query returns %T instead of a set of violations", expression.Value)
+			}
+			for _, violation := range violations {
+				messages = append(messages, formatRegoViolation(violation))
+			}
+		}
+	}
+	sort.Strings(messages)
+	return messages, nil
+}
+
+func formatRegoViolation(violation interface{}) string {
+	switch v := violation.(type) {
+	case string:
+		return v
+	case map[string]interface{}:
+		if msg, ok := v["msg"].(string); ok {
+			return msg
+		}
+	}
+	b, err := json.Marshal(violation)
+	if err != nil {
+		return fmt.Sprint(violation)
+	}
+	return string(b)
+}
+
+type RegoRule struct {
+	rule
+	resource  *merlinv1beta1.ClusterRuleRego
+	evaluator *regoEvaluator
+}
+
+func (r *RegoRule) New(ctx context.Context, cli client.Client, logger logr.Logger, key client.ObjectKey) (Rule, error) {
+	// a new rule for each resource, since the factory is shared by all ClusterRuleRego resources
+	regoRule := &RegoRule{
+		rule:     rule{cli: cli, log: logger, status: &Status{}},
+		resource: &merlinv1beta1.ClusterRuleRego{},
+	}
+	if err := cli.Get(ctx, key, regoRule.resource); err != nil {
+		return nil, err
+	}
+	module, err := regoRule.getModule(ctx)
+	if err != nil {
+		return regoRule, &InvalidSpecError{Err: err}
+	}
+	if regoRule.evaluator, err = newRegoEvaluator(ctx, module, regoRule.resource.Spec.Query, regoRule.resource.Spec.Parameters); err != nil {
+		return regoRule, &InvalidSpecError{Err: err}
+	}
+	return regoRule, nil
+}
+
+// getModule returns the inline module, or the module in the ConfigMap
+func (r *RegoRule) getModule(ctx context.Context) (string, error) {
+	ref := r.resource.Spec.ModuleConfigMap
+	if ref == nil {
+		return r.resource.Spec.Module, nil
+	}
+	configMap := &corev1.ConfigMap{}
+	if err := r.cli.Get(ctx, client.ObjectKey{Namespace: ref.Namespace, Name: ref.Name}, configMap); err != nil {
+		return "", fmt.Errorf("unable to get module ConfigMap `%s/%s`: %w", ref.Namespace, ref.Name, err)
+	}
+	module, ok := configMap.Data[ref.Key]
+	if !ok {
+		return "", fmt.Errorf("module ConfigMap `%s/%s` has no key `%s`", ref.Namespace, ref.Name, ref.Key)
+	}
+	return module, nil
+}
+
+func (r *RegoRule) GetObject() runtime.Object {
+	return r.resource
+}
+
+func (r *RegoRule) GetName() string {
+	return strings.Join([]string{getStructName(r.resource), r.resource.Name}, Separator)
+}
+
+func (r *RegoRule) GetObjectMeta() metav1.ObjectMeta {
+	return r.resource.ObjectMeta
+}
+
+func (r *RegoRule) GetNotification() merlinv1beta1.Notification {
+	return r.resource.Spec.Notification
+}
+
+func (r *RegoRule) SetFinalizer(finalizer string) {
+	r.resource.ObjectMeta.Finalizers = append(r.resource.ObjectMeta.Finalizers, finalizer)
+}
+
+func (r *RegoRule) RemoveFinalizer(finalizer string) {
+	r.resource.ObjectMeta.Finalizers = removeString(r.resource.ObjectMeta.Finalizers, finalizer)
+}
+
+func (r *RegoRule) GetTargetKinds() (kinds []schema.GroupVersionKind) {
+	for _, kind := range r.resource.Spec.TargetKinds {
+		kinds = append(kinds, schema.GroupVersionKind(kind))
+	}
+	return
+}
+
+// GetRecheckInterval returns the interval to evaluate all resources again, the module in ConfigMap is also loaded again
+// since the rule is created again.
+func (r *RegoRule) GetRecheckInterval() time.Duration {
+	if r.resource.Spec.RecheckIntervalSeconds > 0 {
+		return time.Duration(r.resource.Spec.RecheckIntervalSeconds) * time.Second
+	}
+	return defaultRegoRecheckIntervalSeconds * time.Second
+}
+
+func (r *RegoRule) EvaluateAll(ctx context.Context) (alerts []alert.Alert, err error) {
+	for _, kind := range r.resource.Spec.TargetKinds {
+		list, err := listTargetKind(ctx, r.cli, kind, getListOptions(r.resource.Spec.Selector, ""))
+		if err != nil {
+			return nil, err
+		}
+
+		if len(list.Items) == 0 {
+			r.log.Info("no object found", "kind", kind.String())
+			continue
+		}
+		for _, obj := range list.Items {
+			a, err := r.Evaluate(ctx, &obj)
+			if err != nil {
+				return nil, err
+			}
+			alerts = append(alerts, a)
+		}
+	}
+	return
+}
+
+func (r *RegoRule) Evaluate(ctx context.Context, object interface{}) (a alert.Alert, err error) {
+	obj, content, gvk, err := toUnstructuredObject(object)
+	if err != nil {
+		return
+	}
+	r.log.V(1).Info("evaluating", gvk.Kind, obj.GetName())
+	key := client.ObjectKey{Namespace: obj.GetNamespace(), Name: obj.GetName()}
+	a = alert.Alert{
+		Suppressed:      r.resource.Spec.Notification.Suppressed,
+		Severity:        r.resource.Spec.Notification.Severity,
+		MessageTemplate: r.resource.Spec.Notification.CustomMessageTemplate,
+		ResourceName:    key.String(),
+		ResourceKind:    gvk.Kind,
+		Violated:        false,
+	}
+	if !r.isTargetKind(gvk) || !isObjectSelected(r.resource.Spec.Selector, obj) {
+		a.Message = "object is not selected by the rule"
+		return
+	}
+	if isStringInSlice(r.resource.Spec.IgnoreNamespaces, key.Namespace) {
+		a.Message = "namespace is ignored by the rule"
+		return
+	}
+	// errors of the policy are violations since the object can't be checked
+	messages, evalErr := r.evaluator.evaluate(ctx, content, gvk, key)
+	if evalErr != nil {
+		a.Violated = true
+		a.Message = fmt.Sprintf("failed to evaluate the policy: %s", evalErr)
+	} else if len(messages) > 0 {
+		a.Violated = true
+		a.Message = strings.Join(messages, "; ")
+	} else {
+		a.Message = "satisfies the policy"
+	}
+	r.status.setViolation(key, a.Violated)
+	return
+}
+
+func (r *RegoRule) GetDelaySeconds(object interface{}) (time.Duration, error) {
+	return 0, nil
+}
+
+func (r *RegoRule) isTargetKind(gvk schema.GroupVersionKind) bool {
+	for _, kind := range r.resource.Spec.TargetKinds {
+		if isKindMatched(kind, gvk) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,444 @@
+package rules
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-logr/zapr"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/mercari/merlin/alert"
+	merlinv1beta1 "github.com/mercari/merlin/api/v1beta1"
+	"github.com/mercari/merlin/mocks"
+)
+
+// testRegoModule has the violations as messages and as Gatekeeper's objects, with the min replicas from the parameters
+const testRegoModule = `package replicas
+
+violation[msg] {
+	input.review.object.spec.replicas < input.parameters.minReplicas
+	msg := sprintf("%s has %d replicas, expect at least %d", [input.review.name, input.review.object.spec.replicas, input.parameters.minReplicas])
+}
+
+violation[{"msg": msg}] {
+	not input.review.object.metadata.labels.team
+	msg := sprintf("%s has no team label", [input.review.kind.kind])
+}
+`
+
+func Test_RegoRule_Basic(t *testing.T) {
+	notification := merlinv1beta1.Notification{
+		Notifiers:  []string{"testNotifier"},
+		Suppressed: true,
+	}
+	merlinv1beta1Rule := &merlinv1beta1.ClusterRuleRego{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-r"},
+		Spec: merlinv1beta1.ClusterRuleRegoSpec{
+			TargetKinds: []metav1.GroupVersionKind{
+				{Group: "apps", Version: "v1", Kind: "Deployment"},
+				{Group: "apps", Version: "v1", Kind: "StatefulSet"},
+			},
+			Notification: notification,
+		},
+	}
+
+	r := &RegoRule{resource: merlinv1beta1Rule}
+	assert.Equal(t, merlinv1beta1Rule.ObjectMeta, r.GetObjectMeta())
+	assert.Equal(t, merlinv1beta1Rule, r.GetObject())
+	assert.Equal(t, notification, r.GetNotification())
+	assert.Equal(t, "ClusterRuleRego/test-r", r.GetName())
+	assert.Equal(t, []schema.GroupVersionKind{
+		{Group: "apps", Version: "v1", Kind: "Deployment"},
+		{Group: "apps", Version: "v1", Kind: "StatefulSet"},
+	}, r.GetTargetKinds())
+	assert.Equal(t, 300*time.Second, r.GetRecheckInterval())
+
+	finalizer := "test.finalizer"
+	r.SetFinalizer(finalizer)
+	assert.Equal(t, finalizer, r.resource.Finalizers[0])
+	r.RemoveFinalizer(finalizer)
+	assert.Empty(t, r.resource.Finalizers)
+	delay, err := r.GetDelaySeconds(&appsv1.Deployment{})
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), delay)
+}
+
+func Test_RegoRule_New(t *testing.T) {
+	ctx := context.Background()
+	log := zapr.NewLogger(zap.L())
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mocks.NewMockClient(mockCtrl)
+	key := client.ObjectKey{Name: "test-r"}
+	configMapKey := client.ObjectKey{Namespace: "merlin", Name: "policies"}
+
+	cases := []struct {
+		desc      string
+		mockCalls []*gomock.Call
+		expectErr bool
+		invalid   bool
+	}{
+		{
+			desc: "rule not found should have error",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().
+					Get(ctx, key, &merlinv1beta1.ClusterRuleRego{}).
+					Return(apierrs.NewNotFound(schema.GroupResource{}, key.Name)),
+			},
+			expectErr: true,
+		},
+		{
+			desc: "inline module should be compiled",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().
+					Get(ctx, key, &merlinv1beta1.ClusterRuleRego{}).
+					SetArg(2, merlinv1beta1.ClusterRuleRego{Spec: merlinv1beta1.ClusterRuleRegoSpec{
+						Module: testRegoModule,
+						Query:  "data.replicas.violation",
+					}}).
+					Return(nil),
+			},
+		},
+		{
+			desc: "module failing to compile should have invalid spec error",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().
+					Get(ctx, key, &merlinv1beta1.ClusterRuleRego{}).
+					SetArg(2, merlinv1beta1.ClusterRuleRego{Spec: merlinv1beta1.ClusterRuleRegoSpec{
+						Module: "package replicas\nviolation[msg] {",
+						Query:  "data.replicas.violation",
+					}}).
+					Return(nil),
+			},
+			expectErr: true,
+			invalid:   true,
+		},
+		{
+			desc: "module in configmap should be compiled",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().
+					Get(ctx, key, &merlinv1beta1.ClusterRuleRego{}).
+					SetArg(2, merlinv1beta1.ClusterRuleRego{Spec: merlinv1beta1.ClusterRuleRegoSpec{
+						ModuleConfigMap: &merlinv1beta1.RegoModuleConfigMap{Namespace: "merlin", Name: "policies", Key: "replicas.rego"},
+						Query:           "data.replicas.violation",
+					}}).
+					Return(nil),
+				mockClient.EXPECT().
+					Get(ctx, configMapKey, &corev1.ConfigMap{}).
+					SetArg(2, corev1.ConfigMap{Data: map[string]string{"replicas.rego": testRegoModule}}).
+					Return(nil),
+			},
+		},
+		{
+			desc: "configmap without the key should have invalid spec error",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().
+					Get(ctx, key, &merlinv1beta1.ClusterRuleRego{}).
+					SetArg(2, merlinv1beta1.ClusterRuleRego{Spec: merlinv1beta1.ClusterRuleRegoSpec{
+						ModuleConfigMap: &merlinv1beta1.RegoModuleConfigMap{Namespace: "merlin", Name: "policies", Key: "replicas.rego"},
+						Query:           "data.replicas.violation",
+					}}).
+					Return(nil),
+				mockClient.EXPECT().
+					Get(ctx, configMapKey, &corev1.ConfigMap{}).
+					Return(nil),
+			},
+			expectErr: true,
+			invalid:   true,
+		},
+		{
+			desc: "invalid parameters should have invalid spec error",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().
+					Get(ctx, key, &merlinv1beta1.ClusterRuleRego{}).
+					SetArg(2, merlinv1beta1.ClusterRuleRego{Spec: merlinv1beta1.ClusterRuleRegoSpec{
+						Module:     testRegoModule,
+						Query:      "data.replicas.violation",
+						Parameters: &runtime.RawExtension{Raw: []byte(`{"minReplicas":`)},
+					}}).
+					Return(nil),
+			},
+			expectErr: true,
+			invalid:   true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(tt *testing.T) {
+			for _, call := range tc.mockCalls {
+				call.Times(1)
+			}
+			r, err := (&RegoRule{}).New(ctx, mockClient, log, key)
+			if tc.expectErr {
+				assert.Error(tt, err)
+				var invalidSpecErr *InvalidSpecError
+				assert.Equal(tt, tc.invalid, errors.As(err, &invalidSpecErr))
+				assert.Equal(tt, tc.invalid, r != nil)
+			} else {
+				assert.NoError(tt, err)
+				assert.NotNil(tt, r.(*RegoRule).evaluator)
+			}
+		})
+	}
+}
+
+func Test_RegoRule_New_independentRules(t *testing.T) {
+	ctx := context.Background()
+	log := zapr.NewLogger(zap.L())
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mocks.NewMockClient(mockCtrl)
+	for _, name := range []string{"replicas", "team"} {
+		mockClient.EXPECT().
+			Get(ctx, client.ObjectKey{Name: name}, &merlinv1beta1.ClusterRuleRego{}).
+			SetArg(2, merlinv1beta1.ClusterRuleRego{
+				ObjectMeta: metav1.ObjectMeta{Name: name},
+				Spec:       merlinv1beta1.ClusterRuleRegoSpec{Module: testRegoModule, Query: "data.replicas.violation"},
+			}).
+			Return(nil)
+	}
+
+	factory := &RegoRule{}
+	replicas, err := factory.New(ctx, mockClient, log, client.ObjectKey{Name: "replicas"})
+	assert.NoError(t, err)
+	team, err := factory.New(ctx, mockClient, log, client.ObjectKey{Name: "team"})
+	assert.NoError(t, err)
+	assert.Equal(t, "ClusterRuleRego/replicas", replicas.GetName())
+	assert.Equal(t, "ClusterRuleRego/team", team.GetName())
+	replicas.SetFinalizer("test.finalizer")
+	assert.Empty(t, team.GetObjectMeta().Finalizers)
+}
+
+func Test_RegoRule_Evaluate(t *testing.T) {
+	ctx := context.Background()
+	log := zapr.NewLogger(zap.L())
+	evaluator, err := newRegoEvaluator(ctx, testRegoModule, "data.replicas.violation", &runtime.RawExtension{Raw: []byte(`{"minReplicas": 2}`)})
+	assert.NoError(t, err)
+	// the query returns the replicas instead of a set of violations
+	objectEvaluator, err := newRegoEvaluator(ctx, "package replicas\nviolation = input.review.object.spec.replicas", "data.replicas.violation", nil)
+	assert.NoError(t, err)
+	newDeployment := func(namespace string, replicas int32, labels map[string]string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "test", Labels: labels},
+			Spec:       appsv1.DeploymentSpec{Replicas: pointer.Int32Ptr(replicas)},
+		}
+	}
+
+	cases := []struct {
+		desc      string
+		evaluator *regoEvaluator
+		selector  merlinv1beta1.Selector
+		resource  interface{}
+		expect    alert.Alert
+		expectErr bool
+	}{
+		{
+			desc:      "non kubernetes object should have error",
+			evaluator: evaluator,
+			resource:  "test",
+			expectErr: true,
+		},
+		{
+			desc:      "object of other kinds should not get violated alert",
+			evaluator: evaluator,
+			resource:  &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test"}},
+			expect: alert.Alert{
+				Message:      "object is not selected by the rule",
+				ResourceKind: "Service",
+				ResourceName: "default/test",
+			},
+		},
+		{
+			desc:      "object not matching selector should not get violated alert",
+			evaluator: evaluator,
+			selector:  merlinv1beta1.Selector{MatchLabels: map[string]string{"app": "other"}},
+			resource:  newDeployment("default", 1, nil),
+			expect: alert.Alert{
+				Message:      "object is not selected by the rule",
+				ResourceKind: "Deployment",
+				ResourceName: "default/test",
+			},
+		},
+		{
+			desc:      "ignored namespace should not get violated alert",
+			evaluator: evaluator,
+			resource:  newDeployment("ignoredNS", 1, nil),
+			expect: alert.Alert{
+				Message:      "namespace is ignored by the rule",
+				ResourceKind: "Deployment",
+				ResourceName: "ignoredNS/test",
+			},
+		},
+		{
+			desc:      "object satisfying the policy should not get violated alert",
+			evaluator: evaluator,
+			resource:  newDeployment("default", 2, map[string]string{"team": "a"}),
+			expect: alert.Alert{
+				Message:      "satisfies the policy",
+				ResourceKind: "Deployment",
+				ResourceName: "default/test",
+			},
+		},
+		{
+			desc:      "all violations of the policy should be in violated alert",
+			evaluator: evaluator,
+			resource:  newDeployment("default", 1, nil),
+			expect: alert.Alert{
+				Message:      "Deployment has no team label; test has 1 replicas, expect at least 2",
+				ResourceKind: "Deployment",
+				ResourceName: "default/test",
+				Violated:     true,
+			},
+		},
+		{
+			desc:      "unstructured statefulset should be evaluated",
+			evaluator: evaluator,
+			resource: &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "apps/v1",
+				"kind":       "StatefulSet",
+				"metadata":   map[string]interface{}{"namespace": "default", "name": "db", "labels": map[string]interface{}{"team": "a"}},
+				"spec":       map[string]interface{}{"replicas": int64(1)},
+			}},
+			expect: alert.Alert{
+				Message:      "db has 1 replicas, expect at least 2",
+				ResourceKind: "StatefulSet",
+				ResourceName: "default/db",
+				Violated:     true,
+			},
+		},
+		{
+			desc:      "query not returning violations should get violated alert",
+			evaluator: objectEvaluator,
+			resource:  newDeployment("default", 1, nil),
+			expect: alert.Alert{
+				Message:      "failed to evaluate the policy: query returns json.Number instead of a set of violations",
+				ResourceKind: "Deployment",
+				ResourceName: "default/test",
+				Violated:     true,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(tt *testing.T) {
+			r := &RegoRule{
+				rule: rule{log: log, status: &Status{}},
+				resource: &merlinv1beta1.ClusterRuleRego{
+					Spec: merlinv1beta1.ClusterRuleRegoSpec{
+						IgnoreNamespaces: []string{"ignoredNS"},
+						Selector:         tc.selector,
+						TargetKinds: []metav1.GroupVersionKind{
+							{Group: "apps", Version: "v1", Kind: "Deployment"},
+							{Group: "apps", Version: "v1", Kind: "StatefulSet"},
+						},
+					},
+				},
+				evaluator: tc.evaluator,
+			}
+			a, err := r.Evaluate(ctx, tc.resource)
+			if tc.expectErr {
+				assert.Error(tt, err)
+			} else {
+				assert.NoError(tt, err)
+				assert.Equal(tt, tc.expect, a)
+			}
+		})
+	}
+}
+
+func Test_RegoRule_EvaluateAll(t *testing.T) {
+	ctx := context.Background()
+	log := zapr.NewLogger(zap.L())
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mocks.NewMockClient(mockCtrl)
+	evaluator, err := newRegoEvaluator(ctx, `package annotations
+violation["no annotations"] { not input.review.object.metadata.annotations }`, "data.annotations.violation", nil)
+	assert.NoError(t, err)
+	r := &RegoRule{
+		rule: rule{cli: mockClient, log: log, status: &Status{}},
+		resource: &merlinv1beta1.ClusterRuleRego{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-r"},
+			Spec: merlinv1beta1.ClusterRuleRegoSpec{
+				TargetKinds: []metav1.GroupVersionKind{{Version: "v1", Kind: "Service"}, {Version: "v1", Kind: "ConfigMap"}},
+			},
+		},
+		evaluator: evaluator,
+	}
+	newList := func(kind string, items ...unstructured.Unstructured) *unstructured.UnstructuredList {
+		list := &unstructured.UnstructuredList{Items: items}
+		list.SetAPIVersion("v1")
+		list.SetKind(kind + "List")
+		return list
+	}
+	listOpts := getListOptions(r.resource.Spec.Selector, "")
+	service := unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Service",
+		"metadata":   map[string]interface{}{"namespace": "default", "name": "test"},
+	}}
+
+	cases := []struct {
+		desc      string
+		mockCalls []*gomock.Call
+		expect    []alert.Alert
+		expectErr bool
+	}{
+		{
+			desc: "list error should have error",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().List(ctx, newList("Service"), listOpts).Return(assert.AnError),
+			},
+			expectErr: true,
+		},
+		{
+			desc: "no object should returns nil alerts",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().List(ctx, newList("Service"), listOpts).Return(nil),
+				mockClient.EXPECT().List(ctx, newList("ConfigMap"), listOpts).Return(nil),
+			},
+		},
+		{
+			desc: "listed objects of all kinds should be evaluated",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().List(ctx, newList("Service"), listOpts).SetArg(1, *newList("Service", service)).Return(nil),
+				mockClient.EXPECT().List(ctx, newList("ConfigMap"), listOpts).Return(nil),
+			},
+			expect: []alert.Alert{
+				{
+					Message:      "no annotations",
+					ResourceKind: "Service",
+					ResourceName: "default/test",
+					Violated:     true,
+				},
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.desc, func(tt *testing.T) {
+			for _, call := range tc.mockCalls {
+				call.Times(1)
+			}
+			alerts, err := r.EvaluateAll(ctx)
+			if tc.expectErr {
+				assert.Error(tt, err)
+			} else {
+				assert.NoError(tt, err)
+				assert.Equal(tt, tc.expect, alerts)
+			}
+		})
+	}
+}
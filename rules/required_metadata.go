@@ -0,0 +1,199 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	"github.com/mercari/merlin/alert"
+	merlinv1beta1 "github.com/mercari/merlin/api/v1beta1"
+)
+
+const defaultRequiredMetadataRecheckIntervalSeconds = 300
+
+type RequiredMetadataRule struct {
+	rule
+	resource *merlinv1beta1.ClusterRuleRequiredMetadata
+}
+
+func (r *RequiredMetadataRule) New(ctx context.Context, cli client.Client, logger logr.Logger, key client.ObjectKey) (Rule, error) {
+	// a new rule for each resource, since the enforcement webhook evaluates the cached rules
+	requiredMetadataRule := &RequiredMetadataRule{
+		rule:     rule{cli: cli, log: logger, status: &Status{}},
+		resource: &merlinv1beta1.ClusterRuleRequiredMetadata{},
+	}
+	if err := cli.Get(ctx, key, requiredMetadataRule.resource); err != nil {
+		return nil, err
+	}
+	return requiredMetadataRule, nil
+}
+
+func (r *RequiredMetadataRule) GetObject() runtime.Object {
+	return r.resource
+}
+
+func (r RequiredMetadataRule) GetName() string {
+	return strings.Join([]string{getStructName(r.resource), r.resource.Name}, Separator)
+}
+
+func (r RequiredMetadataRule) GetObjectMeta() metav1.ObjectMeta {
+	return r.resource.ObjectMeta
+}
+
+func (r RequiredMetadataRule) GetNotification() merlinv1beta1.Notification {
+	return r.resource.Spec.Notification
+}
+
+func (r RequiredMetadataRule) GetEnforcement() merlinv1beta1.Enforcement {
+	return r.resource.Spec.Enforcement
+}
+
+func (r *RequiredMetadataRule) SetFinalizer(finalizer string) {
+	r.resource.ObjectMeta.Finalizers = append(r.resource.ObjectMeta.Finalizers, finalizer)
+}
+
+func (r *RequiredMetadataRule) RemoveFinalizer(finalizer string) {
+	r.resource.ObjectMeta.Finalizers = removeString(r.resource.ObjectMeta.Finalizers, finalizer)
+}
+
+// GetRecheckInterval returns the interval to re-evaluate all resources, since only some of the kinds have reconcilers to
+// evaluate their changes.
+func (r *RequiredMetadataRule) GetRecheckInterval() time.Duration {
+	if r.resource.Spec.RecheckIntervalSeconds > 0 {
+		return time.Duration(r.resource.Spec.RecheckIntervalSeconds) * time.Second
+	}
+	return defaultRequiredMetadataRecheckIntervalSeconds * time.Second
+}
+
+func (r *RequiredMetadataRule) EvaluateAll(ctx context.Context) (alerts []alert.Alert, err error) {
+	for _, kind := range r.resource.Spec.Kinds {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(schema.GroupVersionKind{Group: kind.Group, Version: kind.Version, Kind: kind.Kind + "List"})
+		if err = r.cli.List(ctx, list); err != nil {
+			return
+		}
+		if len(list.Items) == 0 {
+			r.log.Info("no object found", "kind", kind.String())
+			continue
+		}
+		for _, obj := range list.Items {
+			var a alert.Alert
+			if a, err = r.Evaluate(ctx, &obj); err != nil {
+				return
+			}
+			alerts = append(alerts, a)
+		}
+	}
+	return
+}
+
+// Evaluate checks the required labels and annotations of the object, all missing or incorrect keys are reported in one alert.
+// Objects can be unstructured from EvaluateAll or typed from the resource reconcilers, and the ones not in the kinds are skipped.
+func (r *RequiredMetadataRule) Evaluate(ctx context.Context, object interface{}) (alert.Alert, error) {
+	return r.evaluate(object, r.status)
+}
+
+func (r *RequiredMetadataRule) EvaluateAdmission(ctx context.Context, object interface{}) (alert.Alert, error) {
+	return r.evaluate(object, nil)
+}
+
+func (r *RequiredMetadataRule) IsEnforcedKind(gvk schema.GroupVersionKind) bool {
+	return r.isKindSelected(gvk)
+}
+
+// evaluate evaluates the object and sets its violation to the status, nil status for evaluations at admission
+func (r *RequiredMetadataRule) evaluate(object interface{}, status *Status) (a alert.Alert, err error) {
+	runtimeObj, ok := object.(runtime.Object)
+	if !ok {
+		err = fmt.Errorf("object being evaluated is not a kubernetes object: %T", object)
+		return
+	}
+	obj, ok := object.(metav1.Object)
+	if !ok {
+		err = fmt.Errorf("object being evaluated is not a kubernetes object: %T", object)
+		return
+	}
+	var gvk schema.GroupVersionKind
+	if gvk, err = apiutil.GVKForObject(runtimeObj, scheme.Scheme); err != nil {
+		return
+	}
+	r.log.V(1).Info("evaluating", gvk.Kind, obj.GetName())
+
+	key := client.ObjectKey{Namespace: obj.GetNamespace(), Name: obj.GetName()}
+	a = r.newAlert(gvk.Kind, key.String())
+	if !r.isKindSelected(gvk) {
+		a.Message = "kind is not selected by the rule"
+		return
+	}
+	namespace := obj.GetNamespace()
+	if gvk.Group == "" && gvk.Kind == "Namespace" {
+		namespace = obj.GetName()
+	}
+	if isStringInSlice(r.resource.Spec.IgnoreNamespaces, namespace) {
+		a.Message = "namespace is ignored by the rule"
+		return
+	}
+
+	var messages []string
+	for _, l := range r.resource.Spec.Labels {
+		var message string
+		if message, err = validateRequiredMetadata(l, "label", obj.GetLabels()); err != nil {
+			return
+		} else if message != "" {
+			messages = append(messages, message)
+		}
+	}
+	for _, annotation := range r.resource.Spec.Annotations {
+		var message string
+		if message, err = validateRequiredMetadata(annotation, "annotation", obj.GetAnnotations()); err != nil {
+			return
+		} else if message != "" {
+			messages = append(messages, message)
+		}
+	}
+	if len(messages) > 0 {
+		a.Violated = true
+		a.Message = strings.Join(messages, ", ")
+	} else {
+		a.Message = fmt.Sprintf("%s has the required labels and annotations", gvk.Kind)
+	}
+	if status != nil {
+		status.setViolation(key, a.Violated)
+	}
+	return
+}
+
+func (r *RequiredMetadataRule) GetDelaySeconds(object interface{}) (time.Duration, error) {
+	return 0, nil
+}
+
+func (r *RequiredMetadataRule) newAlert(kind string, resourceName string) alert.Alert {
+	return alert.Alert{
+		Suppressed:      r.resource.Spec.Notification.Suppressed,
+		Severity:        r.resource.Spec.Notification.Severity,
+		MessageTemplate: r.resource.Spec.Notification.CustomMessageTemplate,
+		ResourceName:    resourceName,
+		ResourceKind:    kind,
+		Violated:        false,
+	}
+}
+
+// isKindSelected checks if the group and kind are in the kinds of the rule, versions of the same kind are the same object.
+func (r *RequiredMetadataRule) isKindSelected(gvk schema.GroupVersionKind) bool {
+	for _, kind := range r.resource.Spec.Kinds {
+		if kind.Group == gvk.Group && kind.Kind == gvk.Kind {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,246 @@
+package rules
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/zapr"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/mercari/merlin/alert"
+	merlinv1beta1 "github.com/mercari/merlin/api/v1beta1"
+	"github.com/mercari/merlin/mocks"
+)
+
+func Test_RequiredMetadataRule_Basic(t *testing.T) {
+	notification := merlinv1beta1.Notification{
+		Notifiers:  []string{"testNotifier"},
+		Suppressed: true,
+	}
+
+	merlinv1beta1Rule := &merlinv1beta1.ClusterRuleRequiredMetadata{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-r"},
+		Spec: merlinv1beta1.ClusterRuleRequiredMetadataSpec{
+			Notification: notification,
+		},
+	}
+
+	r := &RequiredMetadataRule{resource: merlinv1beta1Rule}
+	assert.Equal(t, merlinv1beta1Rule.ObjectMeta, r.GetObjectMeta())
+	assert.Equal(t, merlinv1beta1Rule, r.GetObject())
+	assert.Equal(t, notification, r.GetNotification())
+	assert.Equal(t, "ClusterRuleRequiredMetadata/test-r", r.GetName())
+	assert.Equal(t, 5*time.Minute, r.GetRecheckInterval())
+
+	finalizer := "test.finalizer"
+	r.SetFinalizer(finalizer)
+	assert.Equal(t, finalizer, r.resource.Finalizers[0])
+	r.RemoveFinalizer(finalizer)
+	assert.Empty(t, r.resource.Finalizers)
+}
+
+func Test_RequiredMetadataRule_Evaluate(t *testing.T) {
+	ctx := context.Background()
+	log := zapr.NewLogger(zap.L())
+
+	cases := []struct {
+		desc      string
+		resource  interface{}
+		expect    alert.Alert
+		expectErr bool
+	}{
+		{
+			desc:      "non kubernetes object should have error",
+			resource:  "test",
+			expectErr: true,
+		},
+		{
+			desc:     "kind not in the rule should not get violated alert",
+			resource: &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test"}},
+			expect: alert.Alert{
+				Message:      "kind is not selected by the rule",
+				ResourceKind: "ConfigMap",
+				ResourceName: "default/test",
+			},
+		},
+		{
+			desc:     "ignored namespace should not get violated alert",
+			resource: &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "ignoredNS", Name: "test"}},
+			expect: alert.Alert{
+				Message:      "namespace is ignored by the rule",
+				ResourceKind: "Deployment",
+				ResourceName: "ignoredNS/test",
+			},
+		},
+		{
+			desc:     "ignored namespace object should not get violated alert",
+			resource: &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ignoredNS"}},
+			expect: alert.Alert{
+				Message:      "namespace is ignored by the rule",
+				ResourceKind: "Namespace",
+				ResourceName: "/ignoredNS",
+			},
+		},
+		{
+			desc: "missing and incorrect keys should be reported in one alert",
+			resource: &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      "test",
+				Labels:    map[string]string{"tier": "database"},
+			}},
+			expect: alert.Alert{
+				Message:      "doenst have required label `team`, has incorrect label value `database` (expect one of `frontend`, `backend`) for label `tier`, doenst have required annotation `prometheus.io/scrape`",
+				ResourceKind: "Deployment",
+				ResourceName: "default/test",
+				Violated:     true,
+			},
+		},
+		{
+			desc: "typed object with required metadata should not get violated alert",
+			resource: &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "default",
+				Name:        "test",
+				Labels:      map[string]string{"team": "merlin", "tier": "backend"},
+				Annotations: map[string]string{"prometheus.io/scrape": "true"},
+			}},
+			expect: alert.Alert{
+				Message:      "Deployment has the required labels and annotations",
+				ResourceKind: "Deployment",
+				ResourceName: "default/test",
+			},
+		},
+		{
+			desc: "unstructured object with incorrect annotation should get violated alert",
+			resource: &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Namespace",
+				"metadata": map[string]interface{}{
+					"name":        "test",
+					"labels":      map[string]interface{}{"team": "merlin", "tier": "frontend"},
+					"annotations": map[string]interface{}{"prometheus.io/scrape": "yes"},
+				},
+			}},
+			expect: alert.Alert{
+				Message:      "has incorrect annotation value `yes` (regex match `^(true|false)$`) for annotation `prometheus.io/scrape`",
+				ResourceKind: "Namespace",
+				ResourceName: "/test",
+				Violated:     true,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(tt *testing.T) {
+			r := &RequiredMetadataRule{
+				rule: rule{log: log, status: &Status{}},
+				resource: &merlinv1beta1.ClusterRuleRequiredMetadata{
+					Spec: merlinv1beta1.ClusterRuleRequiredMetadataSpec{
+						IgnoreNamespaces: []string{"ignoredNS"},
+						Kinds: []metav1.GroupVersionKind{
+							{Group: "apps", Version: "v1", Kind: "Deployment"},
+							{Version: "v1", Kind: "Namespace"},
+						},
+						Labels: []merlinv1beta1.RequiredLabel{
+							{Key: "team", Value: ".+", Match: "regexp"},
+							{Key: "tier", OneOf: []string{"frontend", "backend"}},
+						},
+						Annotations: []merlinv1beta1.RequiredLabel{
+							{Key: "prometheus.io/scrape", Value: "^(true|false)$", Match: "regexp"},
+						},
+					},
+				},
+			}
+			a, err := r.Evaluate(ctx, tc.resource)
+			if tc.expectErr {
+				assert.Error(tt, err)
+			} else {
+				assert.NoError(tt, err)
+				assert.Equal(tt, tc.expect, a)
+			}
+		})
+	}
+}
+
+func Test_RequiredMetadataRule_EvaluateAll(t *testing.T) {
+	ctx := context.Background()
+	log := zapr.NewLogger(zap.L())
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mocks.NewMockClient(mockCtrl)
+	r := &RequiredMetadataRule{
+		rule: rule{cli: mockClient, log: log, status: &Status{}},
+		resource: &merlinv1beta1.ClusterRuleRequiredMetadata{
+			Spec: merlinv1beta1.ClusterRuleRequiredMetadataSpec{
+				Kinds: []metav1.GroupVersionKind{
+					{Version: "v1", Kind: "Service"},
+					{Group: "apps", Version: "v1", Kind: "Deployment"},
+				},
+				Annotations: []merlinv1beta1.RequiredLabel{{Key: "prometheus.io/scrape", Value: "true"}},
+			},
+		},
+	}
+	newList := func(apiVersion, kind string, items ...unstructured.Unstructured) *unstructured.UnstructuredList {
+		list := &unstructured.UnstructuredList{Items: items}
+		list.SetAPIVersion(apiVersion)
+		list.SetKind(kind)
+		return list
+	}
+	service := unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Service",
+		"metadata":   map[string]interface{}{"namespace": "default", "name": "test"},
+	}}
+
+	cases := []struct {
+		desc      string
+		mockCalls []*gomock.Call
+		expect    []alert.Alert
+		expectErr bool
+	}{
+		{
+			desc: "list error should have error",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().List(ctx, newList("v1", "ServiceList")).Return(assert.AnError),
+			},
+			expectErr: true,
+		},
+		{
+			desc: "objects of all kinds should be evaluated",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().List(ctx, newList("v1", "ServiceList")).
+					SetArg(1, *newList("v1", "ServiceList", service)).
+					Return(nil),
+				mockClient.EXPECT().List(ctx, newList("apps/v1", "DeploymentList")).Return(nil),
+			},
+			expect: []alert.Alert{
+				{
+					Message:      "doenst have required annotation `prometheus.io/scrape`",
+					ResourceKind: "Service",
+					ResourceName: "default/test",
+					Violated:     true,
+				},
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.desc, func(tt *testing.T) {
+			for _, call := range tc.mockCalls {
+				call.Times(1)
+			}
+			alerts, err := r.EvaluateAll(ctx)
+			if tc.expectErr {
+				assert.Error(tt, err)
+			} else {
+				assert.NoError(tt, err)
+				assert.Equal(tt, tc.expect, alerts)
+			}
+		})
+	}
+}
@@ -0,0 +1,231 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/mercari/merlin/alert"
+	merlinv1beta1 "github.com/mercari/merlin/api/v1beta1"
+)
+
+const defaultRolloutProgressThresholdSeconds = 600
+
+// RolloutProgressRule keeps the time when rollouts are first seen in progress as the pending states of the status, keyed
+// by kind/namespace/name, since the workload status doesn't tell when the rollout started.
+type RolloutProgressRule struct {
+	rule
+	resource *merlinv1beta1.ClusterRuleRolloutProgress
+}
+
+func (r *RolloutProgressRule) New(ctx context.Context, cli client.Client, logger logr.Logger, key client.ObjectKey) (Rule, error) {
+	r.cli = cli
+	r.log = logger
+	// keep the rollouts in progress when the rule is re-created
+	r.status = r.status.keepPending()
+	r.resource = &merlinv1beta1.ClusterRuleRolloutProgress{}
+	if err := r.cli.Get(ctx, key, r.resource); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *RolloutProgressRule) GetObject() runtime.Object {
+	return r.resource
+}
+
+func (r *RolloutProgressRule) GetName() string {
+	return strings.Join([]string{getStructName(r.resource), r.resource.Name}, Separator)
+}
+
+func (r *RolloutProgressRule) GetObjectMeta() metav1.ObjectMeta {
+	return r.resource.ObjectMeta
+}
+
+func (r *RolloutProgressRule) GetNotification() merlinv1beta1.Notification {
+	return r.resource.Spec.Notification
+}
+
+func (r *RolloutProgressRule) SetFinalizer(finalizer string) {
+	r.resource.ObjectMeta.Finalizers = append(r.resource.ObjectMeta.Finalizers, finalizer)
+}
+
+func (r *RolloutProgressRule) RemoveFinalizer(finalizer string) {
+	r.resource.ObjectMeta.Finalizers = removeString(r.resource.ObjectMeta.Finalizers, finalizer)
+}
+
+func (r *RolloutProgressRule) EvaluateAll(ctx context.Context) (alerts []alert.Alert, err error) {
+	deployments := &appsv1.DeploymentList{}
+	if err = r.cli.List(ctx, deployments); err != nil {
+		return
+	}
+	statefulSets := &appsv1.StatefulSetList{}
+	if err = r.cli.List(ctx, statefulSets); err != nil {
+		return
+	}
+
+	if len(deployments.Items) == 0 && len(statefulSets.Items) == 0 {
+		r.log.Info("no deployment or statefulset found")
+		return
+	}
+	for _, deployment := range deployments.Items {
+		r.log.V(1).Info("evaluating", fmt.Sprintf("%T", deployment), deployment.Name)
+		var a alert.Alert
+		a, err = r.Evaluate(ctx, &deployment)
+		if err != nil {
+			return
+		}
+		alerts = append(alerts, a)
+	}
+	for _, statefulSet := range statefulSets.Items {
+		r.log.V(1).Info("evaluating", fmt.Sprintf("%T", statefulSet), statefulSet.Name)
+		var a alert.Alert
+		a, err = r.Evaluate(ctx, &statefulSet)
+		if err != nil {
+			return
+		}
+		alerts = append(alerts, a)
+	}
+	return
+}
+
+func (r *RolloutProgressRule) Evaluate(ctx context.Context, object interface{}) (a alert.Alert, err error) {
+	deployment, isDeployment := object.(*appsv1.Deployment)
+	statefulSet, isStatefulSet := object.(*appsv1.StatefulSet)
+	var meta metav1.Object
+	if isDeployment {
+		meta = deployment
+	} else if isStatefulSet {
+		meta = statefulSet
+	} else {
+		err = fmt.Errorf("object being evaluated is not type %T or %T", deployment, statefulSet)
+		return
+	}
+	key := client.ObjectKey{Namespace: meta.GetNamespace(), Name: meta.GetName()}
+	a = alert.Alert{
+		Suppressed:      r.resource.Spec.Notification.Suppressed,
+		Severity:        r.resource.Spec.Notification.Severity,
+		MessageTemplate: r.resource.Spec.Notification.CustomMessageTemplate,
+		ResourceName:    key.String(),
+		ResourceKind:    getStructName(object),
+		Violated:        false,
+	}
+	if isStringInSlice(r.resource.Spec.IgnoreNamespaces, key.Namespace) {
+		a.Message = "namespace is ignored by the rule"
+		return
+	}
+
+	now := time.Now()
+	var inProgress bool
+	var progress, failure string
+	if isDeployment {
+		inProgress, progress, failure = getDeploymentRollout(deployment)
+	} else {
+		inProgress, progress = getStatefulSetRollout(statefulSet)
+	}
+	elapsed := now.Sub(r.status.setPending(getRolloutKey(a.ResourceKind, key), inProgress, now))
+
+	if failure != "" {
+		a.Violated = true
+		a.Message = fmt.Sprintf("%s rollout failed: %s", a.ResourceKind, failure)
+	} else if inProgress && elapsed >= r.getThreshold() {
+		a.Violated = true
+		a.Message = fmt.Sprintf("%s rollout is stuck for %s: %s", a.ResourceKind, elapsed.Truncate(time.Second), progress)
+	} else if inProgress {
+		a.Message = fmt.Sprintf("%s rollout is in progress: %s", a.ResourceKind, progress)
+	} else {
+		a.Message = fmt.Sprintf("%s rollout is complete", a.ResourceKind)
+	}
+	r.status.setViolation(key, a.Violated)
+	return
+}
+
+// GetDelaySeconds returns the time left before the rollout in progress reaches the threshold,
+// so the object is evaluated again even if its status doesn't change.
+func (r *RolloutProgressRule) GetDelaySeconds(object interface{}) (time.Duration, error) {
+	meta, ok := object.(metav1.Object)
+	if !ok {
+		return 0, fmt.Errorf("object being evaluated is not type %T", meta)
+	}
+	since, ok := r.status.getPendingSince(getRolloutKey(getStructName(object), client.ObjectKey{Namespace: meta.GetNamespace(), Name: meta.GetName()}))
+	if !ok {
+		return 0, nil
+	}
+	left := r.getThreshold() - time.Since(since)
+	if left <= 0 {
+		return 0, nil
+	}
+	return left, nil
+}
+
+// getRolloutKey returns the key of the rollout in progress, with the kind since deployments and statefulsets can have the same names
+func getRolloutKey(kind string, key client.ObjectKey) string {
+	return strings.Join([]string{kind, key.Namespace, key.Name}, Separator)
+}
+
+func (r *RolloutProgressRule) getThreshold() time.Duration {
+	if r.resource.Spec.ThresholdSeconds > 0 {
+		return time.Duration(r.resource.Spec.ThresholdSeconds) * time.Second
+	}
+	return defaultRolloutProgressThresholdSeconds * time.Second
+}
+
+// getDeploymentRollout returns if the deployment rollout is in progress with its progress,
+// and the failure reported by the deployment controller when the progress deadline is exceeded.
+func getDeploymentRollout(deployment *appsv1.Deployment) (inProgress bool, progress, failure string) {
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+	status := deployment.Status
+	// updated replicas need to be available to complete the rollout, e.g., crash looping pods after the old ones are gone
+	// with the Recreate strategy, but unavailable replicas after the deployment controller reports the rollout complete
+	// are not part of the rollout, e.g., pods evicted from a node.
+	inProgress = status.ObservedGeneration < deployment.Generation ||
+		status.UpdatedReplicas < replicas ||
+		status.Replicas > status.UpdatedReplicas ||
+		(status.AvailableReplicas < replicas && !isDeploymentComplete(status))
+	progress = fmt.Sprintf("%d/%d replicas updated and %d/%d available", status.UpdatedReplicas, replicas, status.AvailableReplicas, replicas)
+	for _, c := range status.Conditions {
+		if c.Type == appsv1.DeploymentProgressing && c.Status == corev1.ConditionFalse && c.Reason == "ProgressDeadlineExceeded" {
+			failure = fmt.Sprintf("%s, %s", c.Reason, c.Message)
+		}
+	}
+	return
+}
+
+// isDeploymentComplete returns if the deployment controller reported the rollout complete, the Progressing condition
+// keeps the reason until the next rollout.
+func isDeploymentComplete(status appsv1.DeploymentStatus) bool {
+	for _, c := range status.Conditions {
+		if c.Type == appsv1.DeploymentProgressing && c.Status == corev1.ConditionTrue && c.Reason == "NewReplicaSetAvailable" {
+			return true
+		}
+	}
+	return false
+}
+
+// getStatefulSetRollout returns if the statefulset rollout is in progress with its progress,
+// statefulsets with OnDelete update strategy are not rolled out by the controller so they are never in progress.
+func getStatefulSetRollout(statefulSet *appsv1.StatefulSet) (inProgress bool, progress string) {
+	if statefulSet.Spec.UpdateStrategy.Type == appsv1.OnDeleteStatefulSetStrategyType {
+		return
+	}
+	replicas := int32(1)
+	if statefulSet.Spec.Replicas != nil {
+		replicas = *statefulSet.Spec.Replicas
+	}
+	status := statefulSet.Status
+	inProgress = status.ObservedGeneration < statefulSet.Generation ||
+		(status.UpdateRevision != "" && status.CurrentRevision != status.UpdateRevision)
+	progress = fmt.Sprintf("revision `%s` to `%s`, %d/%d replicas updated", status.CurrentRevision, status.UpdateRevision, status.UpdatedReplicas, replicas)
+	return
+}
@@ -0,0 +1,362 @@
+package rules
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/zapr"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/mercari/merlin/alert"
+	merlinv1beta1 "github.com/mercari/merlin/api/v1beta1"
+	"github.com/mercari/merlin/mocks"
+)
+
+func newTestDeployment(replicas int32, status appsv1.DeploymentStatus) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "app", Generation: 2},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		Status:     status,
+	}
+}
+
+func Test_RolloutProgressRule_Basic(t *testing.T) {
+	notification := merlinv1beta1.Notification{
+		Notifiers:  []string{"testNotifier"},
+		Suppressed: true,
+	}
+
+	merlinv1beta1Rule := &merlinv1beta1.ClusterRuleRolloutProgress{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-r"},
+		Spec: merlinv1beta1.ClusterRuleRolloutProgressSpec{
+			Notification: notification,
+		},
+	}
+
+	r := &RolloutProgressRule{resource: merlinv1beta1Rule}
+	assert.Equal(t, merlinv1beta1Rule.ObjectMeta, r.GetObjectMeta())
+	assert.Equal(t, merlinv1beta1Rule, r.GetObject())
+	assert.Equal(t, notification, r.GetNotification())
+	assert.Equal(t, "ClusterRuleRolloutProgress/test-r", r.GetName())
+
+	finalizer := "test.finalizer"
+	r.SetFinalizer(finalizer)
+	assert.Equal(t, finalizer, r.resource.Finalizers[0])
+	r.RemoveFinalizer(finalizer)
+	assert.Empty(t, r.resource.Finalizers)
+	_, err := r.GetDelaySeconds("non-object")
+	assert.Error(t, err)
+	delay, err := r.GetDelaySeconds(&appsv1.Deployment{})
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), delay)
+}
+
+func Test_RolloutProgressRule_Evaluate(t *testing.T) {
+	ctx := context.Background()
+	log := zapr.NewLogger(zap.L())
+
+	cases := []struct {
+		desc       string
+		inProgress map[string]time.Time
+		resource   interface{}
+		expect     alert.Alert
+		expectErr  bool
+	}{
+		{
+			desc:      "non deployment or statefulset should have error",
+			resource:  &corev1.Pod{},
+			expectErr: true,
+		},
+		{
+			desc:     "ignored namespace should not get violated alert",
+			resource: &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "ignoredNS", Name: "app"}},
+			expect: alert.Alert{
+				Message:      "namespace is ignored by the rule",
+				ResourceKind: "Deployment",
+				ResourceName: "ignoredNS/app",
+			},
+		},
+		{
+			desc:     "completed deployment should not get violated alert",
+			resource: newTestDeployment(3, appsv1.DeploymentStatus{ObservedGeneration: 2, UpdatedReplicas: 3, AvailableReplicas: 3}),
+			expect: alert.Alert{
+				Message:      "Deployment rollout is complete",
+				ResourceKind: "Deployment",
+				ResourceName: "test/app",
+			},
+		},
+		{
+			desc:       "deployment rolled out with unavailable replicas should not get violated alert",
+			inProgress: map[string]time.Time{"Deployment/test/app": time.Now().Add(-20 * time.Minute)},
+			resource: newTestDeployment(3, appsv1.DeploymentStatus{
+				ObservedGeneration: 2,
+				Replicas:           3,
+				UpdatedReplicas:    3,
+				AvailableReplicas:  1,
+				Conditions: []appsv1.DeploymentCondition{
+					{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionTrue, Reason: "NewReplicaSetAvailable"},
+				},
+			}),
+			expect: alert.Alert{
+				Message:      "Deployment rollout is complete",
+				ResourceKind: "Deployment",
+				ResourceName: "test/app",
+			},
+		},
+		{
+			desc:       "deployment with updated replicas unavailable for too long should get violated alert",
+			inProgress: map[string]time.Time{"Deployment/test/app": time.Now().Add(-20 * time.Minute)},
+			resource: newTestDeployment(3, appsv1.DeploymentStatus{
+				ObservedGeneration: 2,
+				Replicas:           3,
+				UpdatedReplicas:    3,
+				AvailableReplicas:  0,
+				Conditions: []appsv1.DeploymentCondition{
+					{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionTrue, Reason: "ReplicaSetUpdated"},
+				},
+			}),
+			expect: alert.Alert{
+				Message:      "Deployment rollout is stuck for 20m0s: 3/3 replicas updated and 0/3 available",
+				ResourceKind: "Deployment",
+				ResourceName: "test/app",
+				Violated:     true,
+			},
+		},
+		{
+			desc:       "deployment with unobserved generation for too long should get violated alert",
+			inProgress: map[string]time.Time{"Deployment/test/app": time.Now().Add(-20 * time.Minute)},
+			resource:   newTestDeployment(3, appsv1.DeploymentStatus{ObservedGeneration: 1, Replicas: 3, UpdatedReplicas: 3, AvailableReplicas: 3}),
+			expect: alert.Alert{
+				Message:      "Deployment rollout is stuck for 20m0s: 3/3 replicas updated and 3/3 available",
+				ResourceKind: "Deployment",
+				ResourceName: "test/app",
+				Violated:     true,
+			},
+		},
+		{
+			desc:     "deployment rollout just started should not get violated alert",
+			resource: newTestDeployment(3, appsv1.DeploymentStatus{ObservedGeneration: 2, UpdatedReplicas: 1, AvailableReplicas: 3}),
+			expect: alert.Alert{
+				Message:      "Deployment rollout is in progress: 1/3 replicas updated and 3/3 available",
+				ResourceKind: "Deployment",
+				ResourceName: "test/app",
+			},
+		},
+		{
+			desc:       "deployment rollout in progress for too long should get violated alert",
+			inProgress: map[string]time.Time{"Deployment/test/app": time.Now().Add(-20 * time.Minute)},
+			resource:   newTestDeployment(3, appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 4, UpdatedReplicas: 3, AvailableReplicas: 2}),
+			expect: alert.Alert{
+				Message:      "Deployment rollout is stuck for 20m0s: 3/3 replicas updated and 2/3 available",
+				ResourceKind: "Deployment",
+				ResourceName: "test/app",
+				Violated:     true,
+			},
+		},
+		{
+			desc: "deployment exceeded progress deadline should get violated alert",
+			resource: newTestDeployment(3, appsv1.DeploymentStatus{
+				ObservedGeneration: 2,
+				UpdatedReplicas:    1,
+				AvailableReplicas:  3,
+				Conditions: []appsv1.DeploymentCondition{{
+					Type:    appsv1.DeploymentProgressing,
+					Status:  corev1.ConditionFalse,
+					Reason:  "ProgressDeadlineExceeded",
+					Message: `ReplicaSet "app-5d8f" has timed out progressing.`,
+				}},
+			}),
+			expect: alert.Alert{
+				Message:      `Deployment rollout failed: ProgressDeadlineExceeded, ReplicaSet "app-5d8f" has timed out progressing.`,
+				ResourceKind: "Deployment",
+				ResourceName: "test/app",
+				Violated:     true,
+			},
+		},
+		{
+			desc:       "statefulset with revisions differing for too long should get violated alert",
+			inProgress: map[string]time.Time{"StatefulSet/test/db": time.Now().Add(-time.Hour)},
+			resource: &appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "db"},
+				Status: appsv1.StatefulSetStatus{
+					CurrentRevision: "db-1",
+					UpdateRevision:  "db-2",
+					UpdatedReplicas: 1,
+				},
+			},
+			expect: alert.Alert{
+				Message:      "StatefulSet rollout is stuck for 1h0m0s: revision `db-1` to `db-2`, 1/1 replicas updated",
+				ResourceKind: "StatefulSet",
+				ResourceName: "test/db",
+				Violated:     true,
+			},
+		},
+		{
+			desc:       "statefulset with OnDelete strategy should not get violated alert",
+			inProgress: map[string]time.Time{"StatefulSet/test/db": time.Now().Add(-time.Hour)},
+			resource: &appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "db"},
+				Spec:       appsv1.StatefulSetSpec{UpdateStrategy: appsv1.StatefulSetUpdateStrategy{Type: appsv1.OnDeleteStatefulSetStrategyType}},
+				Status:     appsv1.StatefulSetStatus{CurrentRevision: "db-1", UpdateRevision: "db-2"},
+			},
+			expect: alert.Alert{
+				Message:      "StatefulSet rollout is complete",
+				ResourceKind: "StatefulSet",
+				ResourceName: "test/db",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(tt *testing.T) {
+			status := &Status{}
+			for key, since := range tc.inProgress {
+				status.setPending(key, true, since)
+			}
+			r := &RolloutProgressRule{
+				rule: rule{log: log, status: status},
+				resource: &merlinv1beta1.ClusterRuleRolloutProgress{
+					Spec: merlinv1beta1.ClusterRuleRolloutProgressSpec{IgnoreNamespaces: []string{"ignoredNS"}},
+				},
+			}
+			a, err := r.Evaluate(ctx, tc.resource)
+			if tc.expectErr {
+				assert.Error(tt, err)
+			} else {
+				assert.NoError(tt, err)
+				assert.Equal(tt, tc.expect, a)
+			}
+		})
+	}
+}
+
+func Test_RolloutProgressRule_GetDelaySeconds(t *testing.T) {
+	ctx := context.Background()
+	log := zapr.NewLogger(zap.L())
+	r := &RolloutProgressRule{
+		rule:     rule{log: log, status: &Status{}},
+		resource: &merlinv1beta1.ClusterRuleRolloutProgress{},
+	}
+	deployment := newTestDeployment(3, appsv1.DeploymentStatus{ObservedGeneration: 1})
+
+	_, err := r.Evaluate(ctx, deployment)
+	assert.NoError(t, err)
+	delay, err := r.GetDelaySeconds(deployment)
+	assert.NoError(t, err)
+	assert.True(t, delay > 9*time.Minute && delay <= 10*time.Minute)
+
+	deployment.Status = appsv1.DeploymentStatus{ObservedGeneration: 2, UpdatedReplicas: 3, AvailableReplicas: 3}
+	_, err = r.Evaluate(ctx, deployment)
+	assert.NoError(t, err)
+	delay, err = r.GetDelaySeconds(deployment)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), delay)
+}
+
+func Test_RolloutProgressRule_RestoreViolations(t *testing.T) {
+	ctx := context.Background()
+	log := zapr.NewLogger(zap.L())
+	r := &RolloutProgressRule{
+		rule:     rule{log: log, status: &Status{}},
+		resource: &merlinv1beta1.ClusterRuleRolloutProgress{},
+	}
+	deployment := newTestDeployment(3, appsv1.DeploymentStatus{ObservedGeneration: 1})
+	since := metav1.NewTime(time.Now().Add(-20 * time.Minute).Truncate(time.Second))
+
+	// rollouts in progress before restarting are stuck from the persisted first seen time
+	r.RestoreViolations(merlinv1beta1.RuleStatus{ViolationStates: []merlinv1beta1.ViolationState{
+		{Key: "Deployment/test/app", FirstSeen: since, LastSeen: since, Pending: true},
+	}})
+	a, err := r.Evaluate(ctx, deployment)
+	assert.NoError(t, err)
+	assert.True(t, a.Violated)
+	assert.Contains(t, a.Message, "Deployment rollout is stuck for 20m")
+	states := r.GetViolationStates()
+	assert.Len(t, states, 2)
+	assert.Equal(t, "test/app", states[0].Key)
+	assert.Equal(t, "Deployment/test/app", states[1].Key)
+	assert.Equal(t, since, states[1].FirstSeen)
+}
+
+func Test_RolloutProgressRule_EvaluateAll(t *testing.T) {
+	ctx := context.Background()
+	log := zapr.NewLogger(zap.L())
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mocks.NewMockClient(mockCtrl)
+	r := &RolloutProgressRule{
+		rule:     rule{cli: mockClient, log: log, status: &Status{}},
+		resource: &merlinv1beta1.ClusterRuleRolloutProgress{},
+	}
+
+	cases := []struct {
+		desc      string
+		mockCalls []*gomock.Call
+		expect    []alert.Alert
+		expectErr bool
+	}{
+		{
+			desc: "no resources should returns nil alerts",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().
+					List(ctx, &appsv1.DeploymentList{}).
+					Return(nil),
+				mockClient.EXPECT().
+					List(ctx, &appsv1.StatefulSetList{}).
+					Return(nil),
+			},
+		},
+		{
+			desc: "deployments and statefulsets should be evaluated",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().
+					List(ctx, &appsv1.DeploymentList{}).
+					SetArg(1, appsv1.DeploymentList{Items: []appsv1.Deployment{
+						*newTestDeployment(1, appsv1.DeploymentStatus{ObservedGeneration: 2, UpdatedReplicas: 1, AvailableReplicas: 1}),
+					}}).
+					Return(nil),
+				mockClient.EXPECT().
+					List(ctx, &appsv1.StatefulSetList{}).
+					SetArg(1, appsv1.StatefulSetList{Items: []appsv1.StatefulSet{
+						{
+							ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "db"},
+							Status:     appsv1.StatefulSetStatus{CurrentRevision: "db-1", UpdateRevision: "db-1"},
+						},
+					}}).
+					Return(nil),
+			},
+			expect: []alert.Alert{
+				{
+					Message:      "Deployment rollout is complete",
+					ResourceKind: "Deployment",
+					ResourceName: "test/app",
+				},
+				{
+					Message:      "StatefulSet rollout is complete",
+					ResourceKind: "StatefulSet",
+					ResourceName: "test/db",
+				},
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.desc, func(tt *testing.T) {
+			for _, call := range tc.mockCalls {
+				call.Times(1)
+			}
+			alerts, err := r.EvaluateAll(ctx)
+			if tc.expectErr {
+				assert.Error(tt, err)
+			} else {
+				assert.NoError(tt, err)
+				assert.Equal(tt, tc.expect, alerts)
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"reflect"
 	"regexp"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -14,6 +15,7 @@ import (
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -27,24 +29,159 @@ type Status struct {
 	sync.Mutex
 	// checkedAt is the latest time this status was updated
 	checkedAt *time.Time
-	// violations is the resources violated the rule, with object key as names.
+	// violations is the resources violated the rule, with object key as names and the last seen time as values.
 	violations map[string]time.Time
+	// firstSeen is the first time the resources were seen violating the rule, with object key as names.
+	firstSeen map[string]time.Time
+	// pending is the resources in the grace periods of the rule, e.g., rollouts in progress, with the keys given by the
+	// rule as names, they're persisted with the violations so the grace periods continue after restarts.
+	pending map[string]pendingState
+}
+
+// pendingState is the start and the last seen time of a grace period
+type pendingState struct {
+	since    time.Time
+	lastSeen time.Time
 }
 
 func (r *Status) setViolation(key client.ObjectKey, isViolated bool) {
 	now := time.Now()
+	r.Lock()
 	if r.violations == nil {
 		r.violations = map[string]time.Time{}
 	}
-	r.Lock()
-	delete(r.violations, key.String())
+	if r.firstSeen == nil {
+		r.firstSeen = map[string]time.Time{}
+	}
 	if isViolated {
-		r.violations[key.String()] = time.Now()
+		if _, ok := r.violations[key.String()]; !ok {
+			r.firstSeen[key.String()] = now
+		}
+		r.violations[key.String()] = now
+	} else {
+		delete(r.violations, key.String())
+		delete(r.firstSeen, key.String())
 	}
 	r.Unlock()
 	r.checkedAt = &now
 }
 
+// setPending records if the resource is in the grace period of the rule, and returns the start of the grace period,
+// or now if it's not pending.
+func (r *Status) setPending(key string, isPending bool, now time.Time) time.Time {
+	r.Lock()
+	defer r.Unlock()
+	if r.pending == nil {
+		r.pending = map[string]pendingState{}
+	}
+	if !isPending {
+		delete(r.pending, key)
+		return now
+	}
+	state, ok := r.pending[key]
+	if !ok {
+		state.since = now
+	}
+	state.lastSeen = now
+	r.pending[key] = state
+	return state.since
+}
+
+// getPendingSince returns the start of the grace period of the resource, false if it's not pending.
+func (r *Status) getPendingSince(key string) (time.Time, bool) {
+	if r == nil {
+		return time.Time{}, false
+	}
+	r.Lock()
+	defer r.Unlock()
+	state, ok := r.pending[key]
+	return state.since, ok
+}
+
+// getViolationStates returns the first and last seen times of the violations sorted by keys, followed by the grace
+// periods sorted by keys, to be persisted in the rule's status.
+func (r *Status) getViolationStates() []merlinv1beta1.ViolationState {
+	var states, pendingStates []merlinv1beta1.ViolationState
+	r.Lock()
+	for k, lastSeen := range r.violations {
+		firstSeen, ok := r.firstSeen[k]
+		if !ok {
+			firstSeen = lastSeen
+		}
+		states = append(states, merlinv1beta1.ViolationState{Key: k, FirstSeen: metav1.NewTime(firstSeen), LastSeen: metav1.NewTime(lastSeen)})
+	}
+	for k, state := range r.pending {
+		pendingStates = append(pendingStates, merlinv1beta1.ViolationState{
+			Key:       k,
+			FirstSeen: metav1.NewTime(state.since),
+			LastSeen:  metav1.NewTime(state.lastSeen),
+			Pending:   true,
+		})
+	}
+	r.Unlock()
+	sort.Slice(states, func(i, j int) bool { return states[i].Key < states[j].Key })
+	sort.Slice(pendingStates, func(i, j int) bool { return pendingStates[i].Key < pendingStates[j].Key })
+	return append(states, pendingStates...)
+}
+
+// restore restores the violations, the grace periods and the checked time persisted in the rule's status. Grace periods
+// already recorded are kept, since they're recorded after the status was persisted.
+func (r *Status) restore(states []merlinv1beta1.ViolationState, checkedAt *metav1.Time) {
+	r.Lock()
+	r.violations = map[string]time.Time{}
+	r.firstSeen = map[string]time.Time{}
+	if r.pending == nil {
+		r.pending = map[string]pendingState{}
+	}
+	for _, s := range states {
+		if s.Pending {
+			if _, ok := r.pending[s.Key]; !ok {
+				r.pending[s.Key] = pendingState{since: s.FirstSeen.Time, lastSeen: s.LastSeen.Time}
+			}
+			continue
+		}
+		r.violations[s.Key] = s.LastSeen.Time
+		r.firstSeen[s.Key] = s.FirstSeen.Time
+	}
+	r.Unlock()
+	if checkedAt != nil {
+		t := checkedAt.Time
+		r.checkedAt = &t
+	}
+}
+
+// prune removes the violations and the grace periods last seen before the time, i.e., the restored ones of resources
+// not evaluated again.
+func (r *Status) prune(before time.Time) {
+	r.Lock()
+	for k, lastSeen := range r.violations {
+		if lastSeen.Before(before) {
+			delete(r.violations, k)
+			delete(r.firstSeen, k)
+		}
+	}
+	for k, state := range r.pending {
+		if state.lastSeen.Before(before) {
+			delete(r.pending, k)
+		}
+	}
+	r.Unlock()
+}
+
+// keepPending returns a new status with the grace periods of the status, for rules re-created for periodic checks.
+func (r *Status) keepPending() *Status {
+	s := &Status{pending: map[string]pendingState{}}
+	if r == nil {
+		return s
+	}
+	r.Lock()
+	for k, state := range r.pending {
+		s.pending[k] = state
+	}
+	r.Unlock()
+	return s
+}
+
 func (r *Status) getViolations(namespace string) map[string]time.Time {
 	violations := map[string]time.Time{}
 	r.Lock()
@@ -57,6 +194,37 @@ func (r *Status) getViolations(namespace string) map[string]time.Time {
 	return violations
 }
 
+func (r *Status) getAllViolations() map[string]time.Time {
+	violations := map[string]time.Time{}
+	r.Lock()
+	for k, v := range r.violations {
+		violations[k] = v
+	}
+	r.Unlock()
+	return violations
+}
+
+func (r *Status) isViolated(key client.ObjectKey) bool {
+	r.Lock()
+	_, ok := r.violations[key.String()]
+	r.Unlock()
+	return ok
+}
+
+// InvalidSpecError is the error of rules with specs that can't be used, e.g., expressions failing to compile, RuleFactory
+// returns the rule with the error, so the error is reported in the rule's status instead of retrying to create the rule.
+type InvalidSpecError struct {
+	Err error
+}
+
+func (e *InvalidSpecError) Error() string {
+	return fmt.Sprintf("invalid rule spec: %s", e.Err)
+}
+
+func (e *InvalidSpecError) Unwrap() error {
+	return e.Err
+}
+
 // RuleFactory is the factory that creates rule
 type RuleFactory interface {
 	New(context.Context, client.Client, logr.Logger, client.ObjectKey) (Rule, error)
@@ -86,6 +254,63 @@ type Rule interface {
 	RemoveFinalizer(finalizer string)
 	//GetDelaySeconds returns the delayed time before the rule should be evaluated
 	GetDelaySeconds(object interface{}) (time.Duration, error)
+	// GetViolations returns the resources violating the rule, with object keys as names and the latest evaluated time
+	GetViolations() map[string]time.Time
+	// GetViolationStates returns the first and last seen times of the resources violating the rule
+	GetViolationStates() []merlinv1beta1.ViolationState
+	// RestoreViolations restores the violations persisted in the rule's status, RuleReconciler restores them before running evaluations
+	RestoreViolations(status merlinv1beta1.RuleStatus)
+	// PruneViolations removes the violations not evaluated since the time, e.g., restored violations of deleted resources
+	PruneViolations(before time.Time)
+}
+
+// PeriodicRule is the interface for rules that depend on time and need to be re-evaluated periodically,
+// RuleReconciler runs EvaluateAll again after the recheck interval.
+type PeriodicRule interface {
+	// GetRecheckInterval returns the interval between evaluations of all applicable resources
+	GetRecheckInterval() time.Duration
+}
+
+// DynamicRule is the interface for rules of resource kinds specified in the rule itself,
+// RuleReconciler starts watching the kinds when the rule is created.
+type DynamicRule interface {
+	// GetTargetKinds returns the group, version and kind of the resources the rule evaluates
+	GetTargetKinds() []schema.GroupVersionKind
+}
+
+// MultiAlertRule is the interface for rules where a change of a resource can change the violations of other resources,
+// e.g., the ingresses referencing a service, ResourceReconciler sets the alerts of all the evaluated resources.
+type MultiAlertRule interface {
+	// EvaluateRelated evaluates the resources affected by the watched resource, it's called by ResourceReconciler instead of Evaluate
+	EvaluateRelated(ctx context.Context, watchedResource interface{}) ([]alert.Alert, error)
+}
+
+// OverridingRule is the interface for namespaced rules that only replace the cluster rules they override in their
+// namespace, other namespaced rules replace all the cluster rules of the same kind.
+type OverridingRule interface {
+	// Overrides returns if the rule replaces the cluster rule for the resources in the rule's namespace
+	Overrides(clusterRule Rule) bool
+}
+
+// IsOverridden returns if the cluster rule is replaced by any of the namespaced rules of the same kind in a namespace.
+func IsOverridden(clusterRule Rule, namespacedRules []Rule) bool {
+	for _, r := range namespacedRules {
+		if overridingRule, ok := r.(OverridingRule); !ok || overridingRule.Overrides(clusterRule) {
+			return true
+		}
+	}
+	return false
+}
+
+// EnforceableRule is the interface for rules that only need the object itself to evaluate it, so the admission webhook
+// can evaluate the incoming objects and warn or deny the violating ones by the rule's enforcement.
+type EnforceableRule interface {
+	// GetEnforcement returns the action for the objects violating the rule at admission
+	GetEnforcement() merlinv1beta1.Enforcement
+	// IsEnforcedKind returns if the rule evaluates the objects of the kind at admission
+	IsEnforcedKind(gvk schema.GroupVersionKind) bool
+	// EvaluateAdmission evaluates the incoming object same as Evaluate, without changing the violations of the rule
+	EvaluateAdmission(ctx context.Context, object interface{}) (alert.Alert, error)
 }
 
 type rule struct {
@@ -107,6 +332,38 @@ func (r *rule) SetReady(isReady bool) {
 	r.isReady = isReady
 }
 
+// GetViolations returns the resources violating the rule
+func (r *rule) GetViolations() map[string]time.Time {
+	if r.status == nil {
+		return map[string]time.Time{}
+	}
+	return r.status.getAllViolations()
+}
+
+// GetViolationStates returns the first and last seen times of the resources violating the rule
+func (r *rule) GetViolationStates() []merlinv1beta1.ViolationState {
+	if r.status == nil {
+		return nil
+	}
+	return r.status.getViolationStates()
+}
+
+// RestoreViolations restores the violations and the evaluated time persisted in the rule's status
+func (r *rule) RestoreViolations(status merlinv1beta1.RuleStatus) {
+	if r.status == nil {
+		r.status = &Status{}
+	}
+	r.status.restore(status.ViolationStates, status.LastEvaluatedAt)
+}
+
+// PruneViolations removes the violations last seen before the time
+func (r *rule) PruneViolations(before time.Time) {
+	if r.status == nil {
+		return
+	}
+	r.status.prune(before)
+}
+
 // removeString removes a string from a slice of string
 func removeString(slice []string, s string) (result []string) {
 	for _, item := range slice {
@@ -128,6 +385,19 @@ func isStringInSlice(slice []string, val string) bool {
 	return false
 }
 
+// uniqueStrings returns the strings without duplicates, keeps the original order
+func uniqueStrings(slice []string) (result []string) {
+	seen := map[string]bool{}
+	for _, item := range slice {
+		if seen[item] {
+			continue
+		}
+		seen[item] = true
+		result = append(result, item)
+	}
+	return
+}
+
 // getStrutName returns the name of the struct, handles pointer struct too.
 func getStructName(v interface{}) string {
 	if t := reflect.TypeOf(v); t.Kind() == reflect.Ptr {
@@ -138,13 +408,22 @@ func getStructName(v interface{}) string {
 }
 
 func validateRequiredLabel(r merlinv1beta1.RequiredLabel, labels map[string]string) (message string, err error) {
-	v, ok := labels[r.Key]
+	return validateRequiredMetadata(r, "label", labels)
+}
+
+// validateRequiredMetadata validates the required key and value in labels or annotations, metadataType is used in the message.
+func validateRequiredMetadata(r merlinv1beta1.RequiredLabel, metadataType string, values map[string]string) (message string, err error) {
+	v, ok := values[r.Key]
 	if !ok {
-		return fmt.Sprintf("doenst have required label `%s`", r.Key), nil
+		return fmt.Sprintf("doenst have required %s `%s`", metadataType, r.Key), nil
 	}
-	if r.Match == "" || r.Match == "exact" {
+	if len(r.OneOf) > 0 {
+		if !isStringInSlice(r.OneOf, v) {
+			return fmt.Sprintf("has incorrect %s value `%s` (expect one of `%s`) for %s `%s`", metadataType, v, strings.Join(r.OneOf, "`, `"), metadataType, r.Key), nil
+		}
+	} else if r.Match == "" || r.Match == "exact" {
 		if v != r.Value {
-			return fmt.Sprintf("has incorrect label value `%s` (expect `%s`) for label `%s`", v, r.Value, r.Key), nil
+			return fmt.Sprintf("has incorrect %s value `%s` (expect `%s`) for %s `%s`", metadataType, v, r.Value, metadataType, r.Key), nil
 		}
 	} else if r.Match == "regexp" {
 		var re *regexp.Regexp
@@ -153,11 +432,12 @@ func validateRequiredLabel(r merlinv1beta1.RequiredLabel, labels map[string]stri
 			return
 		}
 		if len(re.FindAllString(v, -1)) <= 0 {
-			return fmt.Sprintf("has incorrect label value `%s` (regex match `%s`) for label `%s`", v, r.Value, r.Key), nil
+			return fmt.Sprintf("has incorrect %s value `%s` (regex match `%s`) for %s `%s`", metadataType, v, r.Value, metadataType, r.Key), nil
 		}
 	}
 	return
 }
+
 func getListOptions(s merlinv1beta1.Selector, namespace string) (opts *client.ListOptions) {
 	opts = &client.ListOptions{Namespace: namespace}
 	if s.Name != "" {
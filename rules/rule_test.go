@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/kubernetes/scheme"
@@ -22,6 +23,34 @@ func Test_Status(t *testing.T) {
 	assert.Less(t, time.Now().Sub(s.violations["ns/test"]).Seconds(), float64(1))
 }
 
+func Test_Status_restore(t *testing.T) {
+	firstSeen := metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second))
+	lastSeen := metav1.NewTime(time.Now().Add(-time.Minute).Truncate(time.Second))
+	s := Status{}
+	s.restore([]merlinv1beta1.ViolationState{
+		{Key: "ns/test", FirstSeen: firstSeen, LastSeen: lastSeen},
+		{Key: "ns/deleted", FirstSeen: firstSeen, LastSeen: lastSeen},
+	}, &lastSeen)
+	assert.Equal(t, lastSeen.Time, *s.checkedAt)
+	assert.Len(t, s.getViolations("ns"), 2)
+
+	evaluatedAt := time.Now()
+	s.setViolation(client.ObjectKey{Namespace: "ns", Name: "test"}, true)
+	s.setViolation(client.ObjectKey{Namespace: "ns", Name: "new"}, true)
+	s.prune(evaluatedAt)
+	states := s.getViolationStates()
+	assert.Len(t, states, 2)
+	assert.Equal(t, "ns/new", states[0].Key)
+	assert.Equal(t, states[0].FirstSeen, states[0].LastSeen)
+	assert.Equal(t, "ns/test", states[1].Key)
+	assert.Equal(t, firstSeen, states[1].FirstSeen)
+	assert.False(t, states[1].LastSeen.Time.Before(evaluatedAt))
+
+	s.setViolation(client.ObjectKey{Namespace: "ns", Name: "test"}, false)
+	s.setViolation(client.ObjectKey{Namespace: "ns", Name: "test"}, true)
+	assert.False(t, s.getViolationStates()[1].FirstSeen.Time.Before(evaluatedAt))
+}
+
 func Test_Rule(t *testing.T) {
 	testEnv := &envtest.Environment{}
 	cfg, err := testEnv.Start()
@@ -185,6 +214,17 @@ func Test_validateRequiredLabel(t *testing.T) {
 			requiredLabels: merlinv1beta1.RequiredLabel{Key: "test", Value: "t[a-z]+", Match: "regexp"},
 			labels:         map[string]string{"test": "test"},
 		},
+		{
+			desc:           "incorrect value should get message with oneOf",
+			requiredLabels: merlinv1beta1.RequiredLabel{Key: "test", Value: "blah", OneOf: []string{"test", "prod"}},
+			labels:         map[string]string{"test": "blah"},
+			message:        "has incorrect label value `blah` (expect one of `test`, `prod`) for label `test`",
+		},
+		{
+			desc:           "correct value should not get message with oneOf",
+			requiredLabels: merlinv1beta1.RequiredLabel{Key: "test", OneOf: []string{"test", "prod"}},
+			labels:         map[string]string{"test": "prod"},
+		},
 	}
 	for _, tc := range cases {
 		t.Run(tc.desc, func(tt *testing.T) {
@@ -199,3 +239,36 @@ func Test_validateRequiredLabel(t *testing.T) {
 		})
 	}
 }
+
+func Test_Status_pending(t *testing.T) {
+	now := time.Now()
+	since := metav1.NewTime(now.Add(-time.Hour).Truncate(time.Second))
+	s := Status{}
+	assert.Equal(t, now, s.setPending("Deployment/ns/test", true, now))
+	s.setViolation(client.ObjectKey{Namespace: "ns", Name: "test"}, true)
+	states := s.getViolationStates()
+	assert.Len(t, states, 2)
+	assert.Equal(t, "ns/test", states[0].Key)
+	assert.False(t, states[0].Pending)
+	assert.Equal(t, merlinv1beta1.ViolationState{Key: "Deployment/ns/test", FirstSeen: metav1.NewTime(now), LastSeen: metav1.NewTime(now), Pending: true}, states[1])
+
+	// grace periods are restored from the first seen times, unless they're recorded already
+	restored := s.keepPending()
+	restored.restore([]merlinv1beta1.ViolationState{
+		{Key: "Deployment/ns/test", FirstSeen: since, LastSeen: since, Pending: true},
+		{Key: "Deployment/ns/restored", FirstSeen: since, LastSeen: since, Pending: true},
+		{Key: "Deployment/ns/deleted", FirstSeen: since, LastSeen: since, Pending: true},
+	}, nil)
+	assert.Empty(t, restored.getAllViolations())
+	evaluatedAt := time.Now()
+	assert.Equal(t, now, restored.setPending("Deployment/ns/test", true, evaluatedAt))
+	assert.Equal(t, since.Time, restored.setPending("Deployment/ns/restored", true, evaluatedAt))
+	restored.prune(evaluatedAt)
+	_, ok := restored.getPendingSince("Deployment/ns/deleted")
+	assert.False(t, ok)
+
+	assert.Equal(t, evaluatedAt, restored.setPending("Deployment/ns/test", false, evaluatedAt))
+	_, ok = restored.getPendingSince("Deployment/ns/test")
+	assert.False(t, ok)
+	assert.Len(t, restored.getViolationStates(), 1)
+}
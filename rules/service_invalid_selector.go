@@ -100,11 +100,8 @@ func (s *ServiceInvalidSelectorRule) Evaluate(ctx context.Context, object interf
 		a.Message = "namespace is ignored by the rule"
 		return
 	}
-	pods := corev1.PodList{}
-	if err = s.cli.List(ctx, &pods, &client.ListOptions{
-		Namespace:     svc.Namespace,
-		LabelSelector: labels.Set(svc.Spec.Selector).AsSelector(),
-	}); err != nil && client.IgnoreNotFound(err) != nil {
+	var pods corev1.PodList
+	if pods, err = listServicePods(ctx, s.cli, svc); err != nil {
 		return
 	}
 	if len(pods.Items) <= 0 {
@@ -120,3 +117,22 @@ func (s *ServiceInvalidSelectorRule) Evaluate(ctx context.Context, object interf
 func (s *ServiceInvalidSelectorRule) GetDelaySeconds(object interface{}) (time.Duration, error) {
 	return 0, nil
 }
+
+// listServicePods lists the pods in the service namespace that are selected by the service
+func listServicePods(ctx context.Context, cli client.Client, svc *corev1.Service) (pods corev1.PodList, err error) {
+	if err = cli.List(ctx, &pods, &client.ListOptions{
+		Namespace:     svc.Namespace,
+		LabelSelector: labels.Set(svc.Spec.Selector).AsSelector(),
+	}); err != nil && client.IgnoreNotFound(err) == nil {
+		err = nil
+	}
+	return
+}
+
+// serviceSelectsLabels checks if the pods with the labels are selected by the service, services without selector select no pods
+func serviceSelectsLabels(svc *corev1.Service, podLabels map[string]string) bool {
+	if len(svc.Spec.Selector) == 0 {
+		return false
+	}
+	return labels.Set(svc.Spec.Selector).AsSelector().Matches(labels.Set(podLabels))
+}
@@ -0,0 +1,232 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/mercari/merlin/alert"
+	merlinv1beta1 "github.com/mercari/merlin/api/v1beta1"
+)
+
+const defaultServiceOverlapRecheckIntervalSeconds = 300
+
+type ServiceOverlapRule struct {
+	rule
+	resource *merlinv1beta1.ClusterRuleServiceOverlap
+}
+
+func (s *ServiceOverlapRule) New(ctx context.Context, cli client.Client, logger logr.Logger, key client.ObjectKey) (Rule, error) {
+	s.cli = cli
+	s.log = logger
+	s.status = &Status{}
+	s.resource = &merlinv1beta1.ClusterRuleServiceOverlap{}
+	if err := s.cli.Get(ctx, key, s.resource); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *ServiceOverlapRule) GetObject() runtime.Object {
+	return s.resource
+}
+
+func (s ServiceOverlapRule) GetName() string {
+	return strings.Join([]string{getStructName(s.resource), s.resource.Name}, Separator)
+}
+
+func (s ServiceOverlapRule) GetObjectMeta() metav1.ObjectMeta {
+	return s.resource.ObjectMeta
+}
+
+func (s ServiceOverlapRule) GetNotification() merlinv1beta1.Notification {
+	return s.resource.Spec.Notification
+}
+
+func (s *ServiceOverlapRule) SetFinalizer(finalizer string) {
+	s.resource.ObjectMeta.Finalizers = append(s.resource.ObjectMeta.Finalizers, finalizer)
+}
+
+func (s *ServiceOverlapRule) RemoveFinalizer(finalizer string) {
+	s.resource.ObjectMeta.Finalizers = removeString(s.resource.ObjectMeta.Finalizers, finalizer)
+}
+
+// GetRecheckInterval returns the interval to re-evaluate all services, since deleting services or pods
+// resolves overlaps without any event of the other services.
+func (s *ServiceOverlapRule) GetRecheckInterval() time.Duration {
+	if s.resource.Spec.RecheckIntervalSeconds > 0 {
+		return time.Duration(s.resource.Spec.RecheckIntervalSeconds) * time.Second
+	}
+	return defaultServiceOverlapRecheckIntervalSeconds * time.Second
+}
+
+func (s *ServiceOverlapRule) EvaluateAll(ctx context.Context) (alerts []alert.Alert, err error) {
+	services := &corev1.ServiceList{}
+	if err = s.cli.List(ctx, services); err != nil {
+		return
+	}
+	pods := &corev1.PodList{}
+	if err = s.cli.List(ctx, pods); err != nil {
+		return
+	}
+
+	if len(services.Items) == 0 {
+		s.log.Info("no service found")
+		return
+	}
+	namespacePods := map[string][]corev1.Pod{}
+	for _, pod := range pods.Items {
+		namespacePods[pod.Namespace] = append(namespacePods[pod.Namespace], pod)
+	}
+	namespaceServices := map[string][]corev1.Service{}
+	for _, svc := range services.Items {
+		namespaceServices[svc.Namespace] = append(namespaceServices[svc.Namespace], svc)
+	}
+	for _, svc := range services.Items {
+		s.log.V(1).Info("evaluating", fmt.Sprintf("%T", svc), svc.Name)
+		alerts = append(alerts, s.evaluateService(&svc, namespaceServices[svc.Namespace], namespacePods[svc.Namespace]))
+	}
+	return
+}
+
+// Evaluate evaluates the service, or the first service in the namespace of the pod being changed,
+// ResourceReconciler uses EvaluateRelated for the alerts of all services in the namespace.
+func (s *ServiceOverlapRule) Evaluate(ctx context.Context, object interface{}) (a alert.Alert, err error) {
+	var alerts []alert.Alert
+	if alerts, err = s.EvaluateRelated(ctx, object); err != nil {
+		return
+	}
+	svc, isService := object.(*corev1.Service)
+	for _, serviceAlert := range alerts {
+		if !isService || serviceAlert.ResourceName == (client.ObjectKey{Namespace: svc.Namespace, Name: svc.Name}).String() {
+			return serviceAlert, nil
+		}
+	}
+	return s.newAlert(""), nil
+}
+
+// EvaluateRelated evaluates all services in the namespace of the service or pod being changed, since changes of a
+// service or pod labels can change the overlaps of the other services.
+func (s *ServiceOverlapRule) EvaluateRelated(ctx context.Context, object interface{}) (alerts []alert.Alert, err error) {
+	var namespace string
+	switch obj := object.(type) {
+	case *corev1.Service:
+		namespace = obj.Namespace
+	case *corev1.Pod:
+		namespace = obj.Namespace
+	default:
+		err = fmt.Errorf("object being evaluated is not type %T or %T", &corev1.Pod{}, &corev1.Service{})
+		return
+	}
+	if isStringInSlice(s.resource.Spec.IgnoreNamespaces, namespace) {
+		if svc, ok := object.(*corev1.Service); ok {
+			alerts = append(alerts, s.evaluateService(svc, nil, nil))
+		}
+		return
+	}
+	services := corev1.ServiceList{}
+	if err = s.cli.List(ctx, &services, &client.ListOptions{Namespace: namespace}); err != nil && client.IgnoreNotFound(err) != nil {
+		return
+	}
+	pods := corev1.PodList{}
+	if err = s.cli.List(ctx, &pods, &client.ListOptions{Namespace: namespace}); err != nil && client.IgnoreNotFound(err) != nil {
+		return
+	}
+	err = nil
+	for _, svc := range services.Items {
+		alerts = append(alerts, s.evaluateService(&svc, services.Items, pods.Items))
+	}
+	return
+}
+
+func (s *ServiceOverlapRule) GetDelaySeconds(object interface{}) (time.Duration, error) {
+	return 0, nil
+}
+
+func (s *ServiceOverlapRule) newAlert(resourceName string) alert.Alert {
+	return alert.Alert{
+		Suppressed:      s.resource.Spec.Notification.Suppressed,
+		Severity:        s.resource.Spec.Notification.Severity,
+		MessageTemplate: s.resource.Spec.Notification.CustomMessageTemplate,
+		Message:         "Service does not select the same pods as other services with the same port",
+		ResourceName:    resourceName,
+		ResourceKind:    getStructName(corev1.Service{}),
+		Violated:        false,
+	}
+}
+
+// evaluateService checks the service against the other services in the namespace, each overlapping pair is only
+// reported by the service with the smaller name, so a pair of services has a single alert.
+func (s *ServiceOverlapRule) evaluateService(svc *corev1.Service, services []corev1.Service, pods []corev1.Pod) alert.Alert {
+	key := client.ObjectKey{Namespace: svc.Namespace, Name: svc.Name}
+	a := s.newAlert(key.String())
+	if isStringInSlice(s.resource.Spec.IgnoreNamespaces, key.Namespace) {
+		a.Message = "namespace is ignored by the rule"
+		return a
+	}
+	var issues []string
+	if isLoadBalancedService(svc) {
+		for _, other := range services {
+			if other.Name <= svc.Name || !isLoadBalancedService(&other) {
+				continue
+			}
+			ports := getSharedServicePorts(svc, &other)
+			if len(ports) > 0 && servicesSelectSamePod(svc, &other, pods) {
+				issues = append(issues, fmt.Sprintf("`%s` on port %s", other.Name, strings.Join(ports, ", ")))
+			}
+		}
+	}
+	if len(issues) > 0 {
+		a.Violated = true
+		a.Message = "Service selects the same pods as other services with the same port: " + strings.Join(issues, "; ")
+	}
+	s.status.setViolation(key, a.Violated)
+	return a
+}
+
+// isLoadBalancedService checks if the service load balances the pods it selects, headless services are usually
+// created along with the ClusterIP services of the same pods, e.g., for StatefulSets, so they're not overlaps.
+func isLoadBalancedService(svc *corev1.Service) bool {
+	return len(svc.Spec.Selector) > 0 && svc.Spec.ClusterIP != corev1.ClusterIPNone
+}
+
+// getSharedServicePorts returns the ports as <port>/<protocol> exposed by both services
+func getSharedServicePorts(svc, other *corev1.Service) (ports []string) {
+	servicePorts := map[string]bool{}
+	for _, p := range svc.Spec.Ports {
+		servicePorts[getServicePort(p)] = true
+	}
+	for _, p := range other.Spec.Ports {
+		if port := getServicePort(p); servicePorts[port] {
+			ports = append(ports, port)
+		}
+	}
+	sort.Strings(ports)
+	return uniqueStrings(ports)
+}
+
+func getServicePort(p corev1.ServicePort) string {
+	protocol := p.Protocol
+	if protocol == "" {
+		protocol = corev1.ProtocolTCP
+	}
+	return fmt.Sprintf("%d/%s", p.Port, protocol)
+}
+
+// servicesSelectSamePod checks if any of the pods is selected by both services
+func servicesSelectSamePod(svc, other *corev1.Service, pods []corev1.Pod) bool {
+	for _, pod := range pods {
+		if serviceSelectsLabels(svc, pod.Labels) && serviceSelectsLabels(other, pod.Labels) {
+			return true
+		}
+	}
+	return false
+}
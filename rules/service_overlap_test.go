@@ -0,0 +1,307 @@
+package rules
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/zapr"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/mercari/merlin/alert"
+	merlinv1beta1 "github.com/mercari/merlin/api/v1beta1"
+	"github.com/mercari/merlin/mocks"
+)
+
+func newTestService(name string, selector map[string]string, ports ...corev1.ServicePort) corev1.Service {
+	return corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: name},
+		Spec:       corev1.ServiceSpec{Selector: selector, Ports: ports},
+	}
+}
+
+func Test_ServiceOverlapRule_Basic(t *testing.T) {
+	notification := merlinv1beta1.Notification{
+		Notifiers:  []string{"testNotifier"},
+		Suppressed: true,
+	}
+
+	merlinv1beta1Rule := &merlinv1beta1.ClusterRuleServiceOverlap{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-r"},
+		Spec: merlinv1beta1.ClusterRuleServiceOverlapSpec{
+			Notification: notification,
+		},
+	}
+
+	r := &ServiceOverlapRule{resource: merlinv1beta1Rule}
+	assert.Equal(t, merlinv1beta1Rule.ObjectMeta, r.GetObjectMeta())
+	assert.Equal(t, merlinv1beta1Rule, r.GetObject())
+	assert.Equal(t, notification, r.GetNotification())
+	assert.Equal(t, "ClusterRuleServiceOverlap/test-r", r.GetName())
+
+	finalizer := "test.finalizer"
+	r.SetFinalizer(finalizer)
+	assert.Equal(t, finalizer, r.resource.Finalizers[0])
+	r.RemoveFinalizer(finalizer)
+	assert.Empty(t, r.resource.Finalizers)
+	delay, err := r.GetDelaySeconds(&corev1.Pod{})
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), delay)
+	assert.Equal(t, 300*time.Second, r.GetRecheckInterval())
+}
+
+func Test_ServiceOverlapRule_Evaluate(t *testing.T) {
+	ctx := context.Background()
+	log := zapr.NewLogger(zap.L())
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mocks.NewMockClient(mockCtrl)
+	pods := corev1.PodList{Items: []corev1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "app-1", Labels: map[string]string{"app": "app", "tier": "web"}}},
+	}}
+	http := corev1.ServicePort{Port: 80}
+	grpc := corev1.ServicePort{Port: 8080, Protocol: corev1.ProtocolTCP}
+	dns := corev1.ServicePort{Port: 53, Protocol: corev1.ProtocolUDP}
+	headless := newTestService("app-headless", map[string]string{"app": "app"}, http)
+	headless.Spec.ClusterIP = corev1.ClusterIPNone
+
+	cases := []struct {
+		desc      string
+		mockCalls []*gomock.Call
+		resource  interface{}
+		expect    alert.Alert
+		expectErr bool
+	}{
+		{
+			desc:      "non pod or service should have error",
+			resource:  &corev1.Namespace{},
+			expectErr: true,
+		},
+		{
+			desc:     "ignored namespace should not get violated alert",
+			resource: &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "ignoredNS", Name: "app"}},
+			expect: alert.Alert{
+				Message:      "namespace is ignored by the rule",
+				ResourceKind: "Service",
+				ResourceName: "ignoredNS/app",
+			},
+		},
+		{
+			desc: "services with different ports, headless or without selector should not get violated alert",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().
+					List(ctx, &corev1.ServiceList{}, &client.ListOptions{Namespace: "test"}).
+					SetArg(1, corev1.ServiceList{Items: []corev1.Service{
+						newTestService("app", map[string]string{"app": "app"}, http),
+						headless,
+						newTestService("app-grpc", map[string]string{"tier": "web"}, grpc),
+						newTestService("external", nil, http),
+						newTestService("other", map[string]string{"app": "other"}, http),
+					}}).
+					Return(nil),
+				mockClient.EXPECT().
+					List(ctx, &corev1.PodList{}, &client.ListOptions{Namespace: "test"}).
+					SetArg(1, pods).
+					Return(nil),
+			},
+			resource: &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "app"}},
+			expect: alert.Alert{
+				Message:      "Service does not select the same pods as other services with the same port",
+				ResourceKind: "Service",
+				ResourceName: "test/app",
+			},
+		},
+		{
+			desc: "services with same port and protocol should get violated alert",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().
+					List(ctx, &corev1.ServiceList{}, &client.ListOptions{Namespace: "test"}).
+					SetArg(1, corev1.ServiceList{Items: []corev1.Service{
+						newTestService("app", map[string]string{"app": "app"}, http, dns),
+						newTestService("web", map[string]string{"tier": "web"}, grpc, corev1.ServicePort{Port: 80, Protocol: corev1.ProtocolTCP}),
+						newTestService("dns", map[string]string{"app": "app"}, corev1.ServicePort{Port: 53, Protocol: corev1.ProtocolTCP}),
+					}}).
+					Return(nil),
+				mockClient.EXPECT().
+					List(ctx, &corev1.PodList{}, &client.ListOptions{Namespace: "test"}).
+					SetArg(1, pods).
+					Return(nil),
+			},
+			resource: &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "app"}},
+			expect: alert.Alert{
+				Message:      "Service selects the same pods as other services with the same port: `web` on port 80/TCP",
+				ResourceKind: "Service",
+				ResourceName: "test/app",
+				Violated:     true,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(tt *testing.T) {
+			for _, call := range tc.mockCalls {
+				call.Times(1)
+			}
+			r := &ServiceOverlapRule{
+				rule: rule{cli: mockClient, log: log, status: &Status{}},
+				resource: &merlinv1beta1.ClusterRuleServiceOverlap{
+					Spec: merlinv1beta1.ClusterRuleServiceOverlapSpec{IgnoreNamespaces: []string{"ignoredNS"}},
+				},
+			}
+			a, err := r.Evaluate(ctx, tc.resource)
+			if tc.expectErr {
+				assert.Error(tt, err)
+			} else {
+				assert.NoError(tt, err)
+				assert.Equal(tt, tc.expect, a)
+			}
+		})
+	}
+}
+
+func Test_ServiceOverlapRule_EvaluateRelated(t *testing.T) {
+	ctx := context.Background()
+	log := zapr.NewLogger(zap.L())
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mocks.NewMockClient(mockCtrl)
+	pod := corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "app-1", Labels: map[string]string{"app": "app"}}}
+	services := corev1.ServiceList{Items: []corev1.Service{
+		newTestService("a", map[string]string{"app": "app"}, corev1.ServicePort{Port: 80}),
+		newTestService("b", map[string]string{"app": "app"}, corev1.ServicePort{Port: 80}),
+		newTestService("c", map[string]string{"app": "app"}, corev1.ServicePort{Port: 80}),
+	}}
+	r := &ServiceOverlapRule{
+		rule:     rule{cli: mockClient, log: log, status: &Status{}},
+		resource: &merlinv1beta1.ClusterRuleServiceOverlap{},
+	}
+
+	// each pair of overlapping services is reported once by the service with the smaller name
+	mockClient.EXPECT().
+		List(ctx, &corev1.ServiceList{}, &client.ListOptions{Namespace: "test"}).
+		SetArg(1, services).
+		Return(nil).
+		Times(1)
+	mockClient.EXPECT().
+		List(ctx, &corev1.PodList{}, &client.ListOptions{Namespace: "test"}).
+		SetArg(1, corev1.PodList{Items: []corev1.Pod{pod}}).
+		Return(nil).
+		Times(1)
+	alerts, err := r.EvaluateRelated(ctx, &pod)
+	assert.NoError(t, err)
+	assert.Equal(t, []alert.Alert{
+		{
+			Message:      "Service selects the same pods as other services with the same port: `b` on port 80/TCP; `c` on port 80/TCP",
+			ResourceKind: "Service",
+			ResourceName: "test/a",
+			Violated:     true,
+		},
+		{
+			Message:      "Service selects the same pods as other services with the same port: `c` on port 80/TCP",
+			ResourceKind: "Service",
+			ResourceName: "test/b",
+			Violated:     true,
+		},
+		{
+			Message:      "Service does not select the same pods as other services with the same port",
+			ResourceKind: "Service",
+			ResourceName: "test/c",
+		},
+	}, alerts)
+	assert.True(t, r.status.isViolated(client.ObjectKey{Namespace: "test", Name: "a"}))
+
+	// pods not selected by the services anymore should recover the services
+	mockClient.EXPECT().
+		List(ctx, &corev1.ServiceList{}, &client.ListOptions{Namespace: "test"}).
+		SetArg(1, services).
+		Return(nil).
+		Times(1)
+	mockClient.EXPECT().
+		List(ctx, &corev1.PodList{}, &client.ListOptions{Namespace: "test"}).
+		Return(nil).
+		Times(1)
+	alerts, err = r.EvaluateRelated(ctx, &pod)
+	assert.NoError(t, err)
+	assert.Len(t, alerts, 3)
+	assert.Empty(t, r.status.getAllViolations())
+}
+
+func Test_ServiceOverlapRule_EvaluateAll(t *testing.T) {
+	ctx := context.Background()
+	log := zapr.NewLogger(zap.L())
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mocks.NewMockClient(mockCtrl)
+	r := &ServiceOverlapRule{
+		rule:     rule{cli: mockClient, log: log, status: &Status{}},
+		resource: &merlinv1beta1.ClusterRuleServiceOverlap{},
+	}
+
+	cases := []struct {
+		desc      string
+		mockCalls []*gomock.Call
+		expect    []alert.Alert
+		expectErr bool
+	}{
+		{
+			desc: "no resources should returns nil alerts",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().
+					List(ctx, &corev1.ServiceList{}).
+					Return(nil),
+				mockClient.EXPECT().
+					List(ctx, &corev1.PodList{}).
+					Return(nil),
+			},
+		},
+		{
+			desc: "services should be evaluated with the pods in their namespaces",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().
+					List(ctx, &corev1.ServiceList{}).
+					SetArg(1, corev1.ServiceList{Items: []corev1.Service{
+						newTestService("a", map[string]string{"app": "app"}, corev1.ServicePort{Port: 80}),
+						newTestService("b", map[string]string{"app": "app"}, corev1.ServicePort{Port: 80}),
+					}}).
+					Return(nil),
+				mockClient.EXPECT().
+					List(ctx, &corev1.PodList{}).
+					SetArg(1, corev1.PodList{Items: []corev1.Pod{
+						{ObjectMeta: metav1.ObjectMeta{Namespace: "other", Name: "app-1", Labels: map[string]string{"app": "app"}}},
+					}}).
+					Return(nil),
+			},
+			expect: []alert.Alert{
+				{
+					Message:      "Service does not select the same pods as other services with the same port",
+					ResourceKind: "Service",
+					ResourceName: "test/a",
+				},
+				{
+					Message:      "Service does not select the same pods as other services with the same port",
+					ResourceKind: "Service",
+					ResourceName: "test/b",
+				},
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.desc, func(tt *testing.T) {
+			for _, call := range tc.mockCalls {
+				call.Times(1)
+			}
+			alerts, err := r.EvaluateAll(ctx)
+			if tc.expectErr {
+				assert.Error(tt, err)
+			} else {
+				assert.NoError(tt, err)
+				assert.Equal(tt, tc.expect, alerts)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/mercari/merlin/alert"
+	merlinv1beta1 "github.com/mercari/merlin/api/v1beta1"
+)
+
+// defaultServiceAccountName is the service account created in every namespace and used by pods without service account
+const defaultServiceAccountName = "default"
+
+type ServiceAccountUnusedRule struct {
+	// resource is the api resource this Rule uses
+	resource *merlinv1beta1.ClusterRuleServiceAccountUnused
+	rule
+}
+
+func (s *ServiceAccountUnusedRule) New(ctx context.Context, cli client.Client, logger logr.Logger, key client.ObjectKey) (Rule, error) {
+	s.cli = cli
+	s.log = logger
+	s.status = &Status{}
+	s.resource = &merlinv1beta1.ClusterRuleServiceAccountUnused{}
+	if err := s.cli.Get(ctx, key, s.resource); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *ServiceAccountUnusedRule) GetObject() runtime.Object {
+	return s.resource
+}
+
+func (s ServiceAccountUnusedRule) GetName() string {
+	return strings.Join([]string{getStructName(s.resource), s.resource.Name}, Separator)
+}
+
+func (s ServiceAccountUnusedRule) GetObjectMeta() metav1.ObjectMeta {
+	return s.resource.ObjectMeta
+}
+
+func (s ServiceAccountUnusedRule) GetNotification() merlinv1beta1.Notification {
+	return s.resource.Spec.Notification
+}
+
+func (s *ServiceAccountUnusedRule) SetFinalizer(finalizer string) {
+	s.resource.ObjectMeta.Finalizers = append(s.resource.ObjectMeta.Finalizers, finalizer)
+}
+
+func (s *ServiceAccountUnusedRule) RemoveFinalizer(finalizer string) {
+	s.resource.ObjectMeta.Finalizers = removeString(s.resource.ObjectMeta.Finalizers, finalizer)
+}
+
+func (s *ServiceAccountUnusedRule) EvaluateAll(ctx context.Context) (alerts []alert.Alert, err error) {
+	serviceAccounts := &corev1.ServiceAccountList{}
+	if err = s.cli.List(ctx, serviceAccounts); err != nil {
+		return
+	}
+
+	if len(serviceAccounts.Items) == 0 {
+		s.log.Info("no resource found")
+		return
+	}
+	owners, err := listPodSpecOwners(ctx, s.cli)
+	if err != nil {
+		return
+	}
+	now := time.Now()
+	for _, serviceAccount := range serviceAccounts.Items {
+		s.log.V(1).Info("evaluating", fmt.Sprintf("%T", serviceAccount), serviceAccount.Name)
+		alerts = append(alerts, s.checkServiceAccount(&serviceAccount, owners, now))
+	}
+	return
+}
+
+func (s *ServiceAccountUnusedRule) Evaluate(ctx context.Context, object interface{}) (a alert.Alert, err error) {
+	switch obj := object.(type) {
+	case *corev1.ServiceAccount:
+		var owners podSpecOwners
+		if !isStringInSlice(s.resource.Spec.IgnoreNamespaces, obj.Namespace) && obj.Name != defaultServiceAccountName {
+			if owners, err = listPodSpecOwners(ctx, s.cli, &client.ListOptions{Namespace: obj.Namespace}); err != nil {
+				return
+			}
+		}
+		a = s.checkServiceAccount(obj, owners, time.Now())
+	case *corev1.Pod:
+		a = s.evaluatePod(obj)
+	default:
+		err = fmt.Errorf("object being evaluated is not type %T or %T", &corev1.ServiceAccount{}, &corev1.Pod{})
+	}
+	return
+}
+
+// GetDelaySeconds returns the time left before the ServiceAccount can be unused,
+// so it's evaluated again after the initial delay.
+func (s *ServiceAccountUnusedRule) GetDelaySeconds(object interface{}) (time.Duration, error) {
+	switch obj := object.(type) {
+	case *corev1.ServiceAccount:
+		delay := obj.CreationTimestamp.Unix() + s.resource.Spec.InitialDelaySeconds - time.Now().Unix()
+		if delay > 0 {
+			return time.Duration(delay) * time.Second, nil
+		}
+		return 0, nil
+	case *corev1.Pod:
+		return 0, nil
+	}
+	return 0, fmt.Errorf("unable to convert object to type %T or %T", &corev1.ServiceAccount{}, &corev1.Pod{})
+}
+
+func (s *ServiceAccountUnusedRule) newAlert(resourceName string) alert.Alert {
+	return alert.Alert{
+		Suppressed:      s.resource.Spec.Notification.Suppressed,
+		Severity:        s.resource.Spec.Notification.Severity,
+		MessageTemplate: s.resource.Spec.Notification.CustomMessageTemplate,
+		ResourceName:    resourceName,
+		ResourceKind:    getStructName(corev1.ServiceAccount{}),
+		Violated:        false,
+	}
+}
+
+// evaluatePod recovers the violated ServiceAccount that the new pod runs as
+func (s *ServiceAccountUnusedRule) evaluatePod(pod *corev1.Pod) (a alert.Alert) {
+	a = s.newAlert("")
+	if s.status.checkedAt == nil {
+		return
+	}
+	key := client.ObjectKey{Namespace: pod.Namespace, Name: getPodSpecServiceAccount(&pod.Spec)}
+	if s.status.isViolated(key) {
+		a.ResourceName = key.String()
+		a.Message = fmt.Sprintf("ServiceAccount is being used by Pod '%s'", pod.Name)
+		s.status.setViolation(key, a.Violated)
+	}
+	return
+}
+
+func (s *ServiceAccountUnusedRule) checkServiceAccount(serviceAccount *corev1.ServiceAccount, owners podSpecOwners, now time.Time) alert.Alert {
+	key := client.ObjectKey{Namespace: serviceAccount.Namespace, Name: serviceAccount.Name}
+	a := s.newAlert(key.String())
+	if isStringInSlice(s.resource.Spec.IgnoreNamespaces, key.Namespace) {
+		a.Message = "namespace is ignored by the rule"
+		return a
+	}
+	if serviceAccount.Name == defaultServiceAccountName {
+		a.Message = "default ServiceAccount is ignored by the rule"
+		return a
+	}
+	user := owners.find(serviceAccount.Namespace, func(spec *corev1.PodSpec) (string, bool) {
+		return "", getPodSpecServiceAccount(spec) == serviceAccount.Name
+	})
+	if user != "" {
+		a.Message = fmt.Sprintf("ServiceAccount is being used by %s", user)
+	} else if initialDelay := time.Duration(s.resource.Spec.InitialDelaySeconds) * time.Second; now.Sub(serviceAccount.CreationTimestamp.Time) < initialDelay {
+		a.Message = fmt.Sprintf("ServiceAccount is not being used but created less than %s ago", initialDelay)
+	} else {
+		a.Violated = true
+		a.Message = "ServiceAccount is not being used"
+	}
+	s.status.setViolation(key, a.Violated)
+	return a
+}
+
+// getPodSpecServiceAccount returns the service account the pod runs as, including the deprecated field and the default
+func getPodSpecServiceAccount(spec *corev1.PodSpec) string {
+	if spec.ServiceAccountName != "" {
+		return spec.ServiceAccountName
+	}
+	if spec.DeprecatedServiceAccount != "" {
+		return spec.DeprecatedServiceAccount
+	}
+	return defaultServiceAccountName
+}
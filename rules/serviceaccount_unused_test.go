@@ -0,0 +1,245 @@
+package rules
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/zapr"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/mercari/merlin/alert"
+	merlinv1beta1 "github.com/mercari/merlin/api/v1beta1"
+	"github.com/mercari/merlin/mocks"
+)
+
+func Test_ServiceAccountUnusedRule_Basic(t *testing.T) {
+	notification := merlinv1beta1.Notification{
+		Notifiers:  []string{"testNotifier"},
+		Suppressed: true,
+	}
+
+	merlinv1beta1Rule := &merlinv1beta1.ClusterRuleServiceAccountUnused{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-r"},
+		Spec: merlinv1beta1.ClusterRuleServiceAccountUnusedSpec{
+			Notification:        notification,
+			InitialDelaySeconds: 30,
+		},
+	}
+
+	r := &ServiceAccountUnusedRule{resource: merlinv1beta1Rule}
+	assert.Equal(t, merlinv1beta1Rule.ObjectMeta, r.GetObjectMeta())
+	assert.Equal(t, merlinv1beta1Rule, r.GetObject())
+	assert.Equal(t, notification, r.GetNotification())
+	assert.Equal(t, "ClusterRuleServiceAccountUnused/test-r", r.GetName())
+
+	finalizer := "test.finalizer"
+	r.SetFinalizer(finalizer)
+	assert.Equal(t, finalizer, r.resource.Finalizers[0])
+	r.RemoveFinalizer(finalizer)
+	assert.Empty(t, r.resource.Finalizers)
+
+	delay, err := r.GetDelaySeconds(&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.Now()}})
+	assert.NoError(t, err)
+	assert.Equal(t, 30*time.Second, delay)
+	delay, err = r.GetDelaySeconds(&corev1.Pod{})
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), delay)
+	_, err = r.GetDelaySeconds(&corev1.Secret{})
+	assert.Error(t, err)
+}
+
+func Test_ServiceAccountUnusedRule_Evaluate(t *testing.T) {
+	ctx := context.Background()
+	log := zapr.NewLogger(zap.L())
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mocks.NewMockClient(mockCtrl)
+	listOpts := &client.ListOptions{Namespace: "test"}
+	serviceAccount := &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{
+		Namespace:         "test",
+		Name:              "app",
+		CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour)),
+	}}
+
+	cases := []struct {
+		desc      string
+		mockCalls []*gomock.Call
+		resource  interface{}
+		expect    alert.Alert
+		expectErr bool
+	}{
+		{
+			desc:      "non serviceaccount or pod should have error",
+			resource:  &corev1.Secret{},
+			expectErr: true,
+		},
+		{
+			desc:     "ignored namespace should not get violated alert",
+			resource: &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Namespace: "ignoredNS", Name: "app"}},
+			expect: alert.Alert{
+				Message:      "namespace is ignored by the rule",
+				ResourceKind: "ServiceAccount",
+				ResourceName: "ignoredNS/app",
+			},
+		},
+		{
+			desc:     "default serviceaccount should not get violated alert",
+			resource: &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "default"}},
+			expect: alert.Alert{
+				Message:      "default ServiceAccount is ignored by the rule",
+				ResourceKind: "ServiceAccount",
+				ResourceName: "test/default",
+			},
+		},
+		{
+			desc: "serviceaccount used by workload template should not get violated alert",
+			mockCalls: expectPodSpecOwnerLists(ctx, mockClient, nil,
+				[]appsv1.StatefulSet{{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "app"},
+					Spec: appsv1.StatefulSetSpec{Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{ServiceAccountName: "app"},
+					}},
+				}}, listOpts),
+			resource: serviceAccount,
+			expect: alert.Alert{
+				Message:      "ServiceAccount is being used by StatefulSet 'app'",
+				ResourceKind: "ServiceAccount",
+				ResourceName: "test/app",
+			},
+		},
+		{
+			desc: "serviceaccount not used should get violated alert",
+			mockCalls: expectPodSpecOwnerLists(ctx, mockClient,
+				[]corev1.Pod{{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "other"}}},
+				nil, listOpts),
+			resource: serviceAccount,
+			expect: alert.Alert{
+				Message:      "ServiceAccount is not being used",
+				ResourceKind: "ServiceAccount",
+				ResourceName: "test/app",
+				Violated:     true,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(tt *testing.T) {
+			for _, call := range tc.mockCalls {
+				call.Times(1)
+			}
+			r := &ServiceAccountUnusedRule{
+				rule: rule{cli: mockClient, log: log, status: &Status{}},
+				resource: &merlinv1beta1.ClusterRuleServiceAccountUnused{
+					Spec: merlinv1beta1.ClusterRuleServiceAccountUnusedSpec{IgnoreNamespaces: []string{"ignoredNS"}},
+				},
+			}
+			a, err := r.Evaluate(ctx, tc.resource)
+			if tc.expectErr {
+				assert.Error(tt, err)
+			} else {
+				assert.NoError(tt, err)
+				assert.Equal(tt, tc.expect, a)
+			}
+		})
+	}
+}
+
+func Test_ServiceAccountUnusedRule_EvaluatePod(t *testing.T) {
+	ctx := context.Background()
+	log := zapr.NewLogger(zap.L())
+	r := &ServiceAccountUnusedRule{
+		rule:     rule{log: log, status: &Status{}},
+		resource: &merlinv1beta1.ClusterRuleServiceAccountUnused{},
+	}
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "app-1"}, Spec: corev1.PodSpec{ServiceAccountName: "app"}}
+
+	a, err := r.Evaluate(ctx, pod)
+	assert.NoError(t, err)
+	assert.Equal(t, alert.Alert{ResourceKind: "ServiceAccount"}, a)
+
+	r.status.setViolation(client.ObjectKey{Namespace: "test", Name: "app"}, true)
+	a, err = r.Evaluate(ctx, pod)
+	assert.NoError(t, err)
+	assert.Equal(t, alert.Alert{
+		Message:      "ServiceAccount is being used by Pod 'app-1'",
+		ResourceKind: "ServiceAccount",
+		ResourceName: "test/app",
+	}, a)
+	assert.False(t, r.status.isViolated(client.ObjectKey{Namespace: "test", Name: "app"}))
+}
+
+func Test_ServiceAccountUnusedRule_EvaluateAll(t *testing.T) {
+	ctx := context.Background()
+	log := zapr.NewLogger(zap.L())
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mocks.NewMockClient(mockCtrl)
+	r := &ServiceAccountUnusedRule{
+		rule:     rule{cli: mockClient, log: log, status: &Status{}},
+		resource: &merlinv1beta1.ClusterRuleServiceAccountUnused{},
+	}
+
+	cases := []struct {
+		desc      string
+		mockCalls []*gomock.Call
+		expect    []alert.Alert
+		expectErr bool
+	}{
+		{
+			desc: "no resources should returns nil alerts",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().
+					List(ctx, &corev1.ServiceAccountList{}).
+					Return(nil),
+			},
+		},
+		{
+			desc: "serviceaccounts should be evaluated with pods in the same namespace",
+			mockCalls: append([]*gomock.Call{
+				mockClient.EXPECT().
+					List(ctx, &corev1.ServiceAccountList{}).
+					SetArg(1, corev1.ServiceAccountList{Items: []corev1.ServiceAccount{
+						{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "app"}},
+						{ObjectMeta: metav1.ObjectMeta{Namespace: "other", Name: "app"}},
+					}}).
+					Return(nil),
+			}, expectPodSpecOwnerLists(ctx, mockClient,
+				[]corev1.Pod{{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "app-1"}, Spec: corev1.PodSpec{ServiceAccountName: "app"}}},
+				nil)...),
+			expect: []alert.Alert{
+				{
+					Message:      "ServiceAccount is being used by Pod 'app-1'",
+					ResourceKind: "ServiceAccount",
+					ResourceName: "test/app",
+				},
+				{
+					Message:      "ServiceAccount is not being used",
+					ResourceKind: "ServiceAccount",
+					ResourceName: "other/app",
+					Violated:     true,
+				},
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.desc, func(tt *testing.T) {
+			for _, call := range tc.mockCalls {
+				call.Times(1)
+			}
+			alerts, err := r.EvaluateAll(ctx)
+			if tc.expectErr {
+				assert.Error(tt, err)
+			} else {
+				assert.NoError(tt, err)
+				assert.Equal(tt, tc.expect, alerts)
+			}
+		})
+	}
+}
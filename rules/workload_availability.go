@@ -0,0 +1,251 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/mercari/merlin/alert"
+	merlinv1beta1 "github.com/mercari/merlin/api/v1beta1"
+)
+
+type WorkloadAvailabilityRule struct {
+	rule
+	resource *merlinv1beta1.ClusterRuleWorkloadAvailability
+}
+
+func (w *WorkloadAvailabilityRule) New(ctx context.Context, cli client.Client, logger logr.Logger, key client.ObjectKey) (Rule, error) {
+	w.cli = cli
+	w.log = logger
+	w.status = &Status{}
+	w.resource = &merlinv1beta1.ClusterRuleWorkloadAvailability{}
+	if err := w.cli.Get(ctx, key, w.resource); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *WorkloadAvailabilityRule) GetObject() runtime.Object {
+	return w.resource
+}
+
+func (w WorkloadAvailabilityRule) GetName() string {
+	return strings.Join([]string{getStructName(w.resource), w.resource.Name}, Separator)
+}
+
+func (w WorkloadAvailabilityRule) GetObjectMeta() metav1.ObjectMeta {
+	return w.resource.ObjectMeta
+}
+
+func (w WorkloadAvailabilityRule) GetNotification() merlinv1beta1.Notification {
+	return w.resource.Spec.Notification
+}
+
+func (w *WorkloadAvailabilityRule) SetFinalizer(finalizer string) {
+	w.resource.ObjectMeta.Finalizers = append(w.resource.ObjectMeta.Finalizers, finalizer)
+}
+
+func (w *WorkloadAvailabilityRule) RemoveFinalizer(finalizer string) {
+	w.resource.ObjectMeta.Finalizers = removeString(w.resource.ObjectMeta.Finalizers, finalizer)
+}
+
+func (w *WorkloadAvailabilityRule) EvaluateAll(ctx context.Context) (alerts []alert.Alert, err error) {
+	deployments := &appsv1.DeploymentList{}
+	if err = w.cli.List(ctx, deployments); err != nil {
+		return
+	}
+
+	if len(deployments.Items) == 0 {
+		w.log.Info("no deployment found")
+		return
+	}
+	for _, deployment := range deployments.Items {
+		w.log.Info("evaluating", fmt.Sprintf("%T", deployment), deployment.Name)
+		var a alert.Alert
+		a, err = w.evaluateDeployment(ctx, &deployment)
+		if err != nil {
+			return
+		}
+		alerts = append(alerts, a)
+	}
+	return
+}
+
+// Evaluate evaluates the deployment, or the deployments in the same namespace covered by the PDB or targeted by the HPA being changed.
+func (w *WorkloadAvailabilityRule) Evaluate(ctx context.Context, object interface{}) (a alert.Alert, err error) {
+	deployment, isDeployment := object.(*appsv1.Deployment)
+	pdb, isPDB := object.(*policyv1beta1.PodDisruptionBudget)
+	hpa, isHPA := object.(*autoscalingv1.HorizontalPodAutoscaler)
+	if isDeployment {
+		return w.evaluateDeployment(ctx, deployment)
+	} else if isPDB {
+		var selector labels.Selector
+		if selector, err = getPDBSelector(pdb); err != nil {
+			return
+		}
+		return w.evaluateCovered(ctx, pdb.Namespace, func(d *appsv1.Deployment) bool {
+			return selector.Matches(labels.Set(d.Spec.Template.Labels))
+		})
+	} else if isHPA {
+		return w.evaluateCovered(ctx, hpa.Namespace, func(d *appsv1.Deployment) bool {
+			return scaleTargetRefMatches(hpa.Spec.ScaleTargetRef, "Deployment", d.Name)
+		})
+	}
+	err = fmt.Errorf("object being evaluated is not type %T, %T or %T", deployment, pdb, hpa)
+	return
+}
+
+// evaluateCovered re-checks the deployments covered by the changed PDB or HPA, and returns the alert
+// of the first deployment whose violation state has changed.
+func (w *WorkloadAvailabilityRule) evaluateCovered(ctx context.Context, namespace string, isCovered func(*appsv1.Deployment) bool) (a alert.Alert, err error) {
+	a = alert.Alert{
+		Suppressed:      w.resource.Spec.Notification.Suppressed,
+		Severity:        w.resource.Spec.Notification.Severity,
+		MessageTemplate: w.resource.Spec.Notification.CustomMessageTemplate,
+		ResourceKind:    getStructName(appsv1.Deployment{}),
+		Violated:        false,
+	}
+	if w.status.checkedAt == nil || isStringInSlice(w.resource.Spec.IgnoreNamespaces, namespace) {
+		return
+	}
+	deployments := appsv1.DeploymentList{}
+	if err = w.cli.List(ctx, &deployments, &client.ListOptions{Namespace: namespace}); err != nil && client.IgnoreNotFound(err) != nil {
+		return
+	}
+	for _, deployment := range deployments.Items {
+		if !isCovered(&deployment) {
+			continue
+		}
+		key := client.ObjectKey{Namespace: deployment.Namespace, Name: deployment.Name}
+		var deploymentAlert alert.Alert
+		deploymentAlert, err = w.checkDeployment(ctx, &deployment)
+		if err != nil {
+			return
+		}
+		if deploymentAlert.Violated != w.status.isViolated(key) {
+			w.status.setViolation(key, deploymentAlert.Violated)
+			return deploymentAlert, nil
+		}
+	}
+	return
+}
+
+func (w *WorkloadAvailabilityRule) evaluateDeployment(ctx context.Context, deployment *appsv1.Deployment) (a alert.Alert, err error) {
+	a, err = w.checkDeployment(ctx, deployment)
+	if err != nil {
+		return
+	}
+	if !isStringInSlice(w.resource.Spec.IgnoreNamespaces, deployment.Namespace) {
+		w.status.setViolation(client.ObjectKey{Namespace: deployment.Namespace, Name: deployment.Name}, a.Violated)
+	}
+	return
+}
+
+// checkDeployment runs the enabled checks for the deployment without updating the rule status.
+func (w *WorkloadAvailabilityRule) checkDeployment(ctx context.Context, deployment *appsv1.Deployment) (a alert.Alert, err error) {
+	key := client.ObjectKey{Namespace: deployment.Namespace, Name: deployment.Name}
+	a = alert.Alert{
+		Suppressed:      w.resource.Spec.Notification.Suppressed,
+		Severity:        w.resource.Spec.Notification.Severity,
+		MessageTemplate: w.resource.Spec.Notification.CustomMessageTemplate,
+		Message:         "Deployment passes the availability checks",
+		ResourceName:    key.String(),
+		ResourceKind:    getStructName(deployment),
+		Violated:        false,
+	}
+	if isStringInSlice(w.resource.Spec.IgnoreNamespaces, key.Namespace) {
+		a.Message = "namespace is ignored by the rule"
+		return
+	}
+	var selected bool
+	if selected, err = w.isNamespaceSelected(ctx, key.Namespace); err != nil {
+		return
+	} else if !selected {
+		a.Message = "namespace is not selected by the rule"
+		return
+	}
+
+	var issues []string
+	if w.resource.Spec.CheckSingleReplica {
+		if deployment.Spec.Replicas == nil || *deployment.Spec.Replicas == 1 {
+			issues = append(issues, "it has only 1 replica")
+		}
+	}
+	if w.resource.Spec.CheckPDB {
+		pdbs := policyv1beta1.PodDisruptionBudgetList{}
+		if err = w.cli.List(ctx, &pdbs, &client.ListOptions{Namespace: key.Namespace}); err != nil && client.IgnoreNotFound(err) != nil {
+			return
+		}
+		err = nil
+		var covered bool
+		for _, pdb := range pdbs.Items {
+			if covered, err = pdbCoversLabels(&pdb, deployment.Spec.Template.Labels); err != nil {
+				return
+			} else if covered {
+				break
+			}
+		}
+		if !covered {
+			issues = append(issues, "no PodDisruptionBudget covers its pods")
+		}
+	}
+	if w.resource.Spec.CheckHPA {
+		hpas := autoscalingv1.HorizontalPodAutoscalerList{}
+		if err = w.cli.List(ctx, &hpas, &client.ListOptions{Namespace: key.Namespace}); err != nil && client.IgnoreNotFound(err) != nil {
+			return
+		}
+		err = nil
+		var targeted bool
+		for _, hpa := range hpas.Items {
+			if scaleTargetRefMatches(hpa.Spec.ScaleTargetRef, "Deployment", deployment.Name) {
+				targeted = true
+				break
+			}
+		}
+		if !targeted {
+			issues = append(issues, "no HorizontalPodAutoscaler targets it")
+		}
+	}
+
+	if len(issues) > 0 {
+		a.Violated = true
+		a.Message = "Deployment is not highly available: " + strings.Join(issues, ", ")
+	}
+	return
+}
+
+// isNamespaceSelected checks if the namespace matches the namespace selector of the rule
+func (w *WorkloadAvailabilityRule) isNamespaceSelected(ctx context.Context, namespace string) (bool, error) {
+	if w.resource.Spec.NamespaceSelector == nil {
+		return true, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(w.resource.Spec.NamespaceSelector)
+	if err != nil {
+		return false, err
+	}
+	ns := &corev1.Namespace{}
+	if err := w.cli.Get(ctx, client.ObjectKey{Name: namespace}, ns); err != nil {
+		return false, err
+	}
+	return selector.Matches(labels.Set(ns.Labels)), nil
+}
+
+func (w *WorkloadAvailabilityRule) GetDelaySeconds(object interface{}) (time.Duration, error) {
+	return 0, nil
+}
+
+// scaleTargetRefMatches checks if the HPA scale target ref points to the object of the kind and name
+func scaleTargetRefMatches(ref autoscalingv1.CrossVersionObjectReference, kind, name string) bool {
+	return ref.Kind == kind && ref.Name == name
+}
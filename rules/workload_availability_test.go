@@ -0,0 +1,362 @@
+package rules
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/zapr"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/mercari/merlin/alert"
+	merlinv1beta1 "github.com/mercari/merlin/api/v1beta1"
+	"github.com/mercari/merlin/mocks"
+)
+
+func Test_WorkloadAvailabilityRule_Basic(t *testing.T) {
+	notification := merlinv1beta1.Notification{
+		Notifiers:  []string{"testNotifier"},
+		Suppressed: true,
+	}
+
+	merlinv1beta1Rule := &merlinv1beta1.ClusterRuleWorkloadAvailability{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-r"},
+		Spec: merlinv1beta1.ClusterRuleWorkloadAvailabilitySpec{
+			Notification: notification,
+		},
+	}
+
+	r := &WorkloadAvailabilityRule{resource: merlinv1beta1Rule}
+	assert.Equal(t, merlinv1beta1Rule.ObjectMeta, r.GetObjectMeta())
+	assert.Equal(t, merlinv1beta1Rule, r.GetObject())
+	assert.Equal(t, notification, r.GetNotification())
+	assert.Equal(t, "ClusterRuleWorkloadAvailability/test-r", r.GetName())
+
+	finalizer := "test.finalizer"
+	r.SetFinalizer(finalizer)
+	assert.Equal(t, finalizer, r.resource.Finalizers[0])
+	r.RemoveFinalizer(finalizer)
+	assert.Empty(t, r.resource.Finalizers)
+	delay, err := r.GetDelaySeconds(&appsv1.Deployment{})
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), delay)
+}
+
+func Test_WorkloadAvailabilityRule_Evaluate(t *testing.T) {
+	ctx := context.Background()
+	log := zapr.NewLogger(zap.L())
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mocks.NewMockClient(mockCtrl)
+	one := int32(1)
+	three := int32(3)
+	newDeployment := func(namespace string, replicas *int32) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "app"},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: replicas,
+				Template: corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "app"}}},
+			},
+		}
+	}
+	allChecks := merlinv1beta1.ClusterRuleWorkloadAvailabilitySpec{
+		IgnoreNamespaces:   []string{"ignoredNS"},
+		CheckSingleReplica: true,
+		CheckPDB:           true,
+		CheckHPA:           true,
+		Notification:       merlinv1beta1.Notification{Notifiers: []string{"testNotifier"}},
+	}
+	coveringPDB := policyv1beta1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "pdb"},
+		Spec:       policyv1beta1.PodDisruptionBudgetSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "app"}}},
+	}
+	targetingHPA := autoscalingv1.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "hpa"},
+		Spec: autoscalingv1.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv1.CrossVersionObjectReference{Kind: "Deployment", Name: "app"},
+		},
+	}
+
+	cases := []struct {
+		desc      string
+		spec      merlinv1beta1.ClusterRuleWorkloadAvailabilitySpec
+		mockCalls []*gomock.Call
+		resource  interface{}
+		expect    alert.Alert
+		expectErr bool
+	}{
+		{
+			desc:      "non deployment should have error",
+			spec:      allChecks,
+			resource:  &corev1.Pod{},
+			expectErr: true,
+		},
+		{
+			desc:     "ignored namespace should not get violated alert",
+			spec:     allChecks,
+			resource: newDeployment("ignoredNS", &one),
+			expect: alert.Alert{
+				Message:      "namespace is ignored by the rule",
+				ResourceKind: "Deployment",
+				ResourceName: "ignoredNS/app",
+			},
+		},
+		{
+			desc: "namespace not selected should not get violated alert",
+			spec: merlinv1beta1.ClusterRuleWorkloadAvailabilitySpec{
+				NamespaceSelector:  &metav1.LabelSelector{MatchLabels: map[string]string{"env": "production"}},
+				CheckSingleReplica: true,
+			},
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().
+					Get(ctx, client.ObjectKey{Name: "test"}, &corev1.Namespace{}).
+					SetArg(2, corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test", Labels: map[string]string{"env": "staging"}}}).
+					Return(nil),
+			},
+			resource: newDeployment("test", &one),
+			expect: alert.Alert{
+				Message:      "namespace is not selected by the rule",
+				ResourceKind: "Deployment",
+				ResourceName: "test/app",
+			},
+		},
+		{
+			desc: "single replica in selected namespace should get violated alert",
+			spec: merlinv1beta1.ClusterRuleWorkloadAvailabilitySpec{
+				NamespaceSelector:  &metav1.LabelSelector{MatchLabels: map[string]string{"env": "production"}},
+				CheckSingleReplica: true,
+			},
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().
+					Get(ctx, client.ObjectKey{Name: "test"}, &corev1.Namespace{}).
+					SetArg(2, corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test", Labels: map[string]string{"env": "production"}}}).
+					Return(nil),
+			},
+			resource: newDeployment("test", nil),
+			expect: alert.Alert{
+				Message:      "Deployment is not highly available: it has only 1 replica",
+				ResourceKind: "Deployment",
+				ResourceName: "test/app",
+				Violated:     true,
+			},
+		},
+		{
+			desc: "missing PDB and HPA should be named in the alert",
+			spec: allChecks,
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().
+					List(ctx, &policyv1beta1.PodDisruptionBudgetList{}, &client.ListOptions{Namespace: "test"}).
+					SetArg(1, policyv1beta1.PodDisruptionBudgetList{Items: []policyv1beta1.PodDisruptionBudget{
+						{
+							ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "other"},
+							Spec:       policyv1beta1.PodDisruptionBudgetSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "other"}}},
+						},
+						{
+							ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "expressions"},
+							Spec: policyv1beta1.PodDisruptionBudgetSpec{Selector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+								{Key: "app", Operator: metav1.LabelSelectorOpIn, Values: []string{"other", "another"}},
+							}}},
+						},
+					}}).
+					Return(nil),
+				mockClient.EXPECT().
+					List(ctx, &autoscalingv1.HorizontalPodAutoscalerList{}, &client.ListOptions{Namespace: "test"}).
+					Return(nil),
+			},
+			resource: newDeployment("test", &three),
+			expect: alert.Alert{
+				Message:      "Deployment is not highly available: no PodDisruptionBudget covers its pods, no HorizontalPodAutoscaler targets it",
+				ResourceKind: "Deployment",
+				ResourceName: "test/app",
+				Violated:     true,
+			},
+		},
+		{
+			desc: "covered deployment should not get violated alert",
+			spec: allChecks,
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().
+					List(ctx, &policyv1beta1.PodDisruptionBudgetList{}, &client.ListOptions{Namespace: "test"}).
+					SetArg(1, policyv1beta1.PodDisruptionBudgetList{Items: []policyv1beta1.PodDisruptionBudget{coveringPDB}}).
+					Return(nil),
+				mockClient.EXPECT().
+					List(ctx, &autoscalingv1.HorizontalPodAutoscalerList{}, &client.ListOptions{Namespace: "test"}).
+					SetArg(1, autoscalingv1.HorizontalPodAutoscalerList{Items: []autoscalingv1.HorizontalPodAutoscaler{targetingHPA}}).
+					Return(nil),
+			},
+			resource: newDeployment("test", &three),
+			expect: alert.Alert{
+				Message:      "Deployment passes the availability checks",
+				ResourceKind: "Deployment",
+				ResourceName: "test/app",
+			},
+		},
+		{
+			desc: "PDB with only match expressions should cover deployment",
+			spec: merlinv1beta1.ClusterRuleWorkloadAvailabilitySpec{CheckPDB: true},
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().
+					List(ctx, &policyv1beta1.PodDisruptionBudgetList{}, &client.ListOptions{Namespace: "test"}).
+					SetArg(1, policyv1beta1.PodDisruptionBudgetList{Items: []policyv1beta1.PodDisruptionBudget{
+						{
+							ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "expressions"},
+							Spec: policyv1beta1.PodDisruptionBudgetSpec{Selector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+								{Key: "app", Operator: metav1.LabelSelectorOpExists},
+							}}},
+						},
+					}}).
+					Return(nil),
+			},
+			resource: newDeployment("test", &three),
+			expect: alert.Alert{
+				Message:      "Deployment passes the availability checks",
+				ResourceKind: "Deployment",
+				ResourceName: "test/app",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(tt *testing.T) {
+			for _, call := range tc.mockCalls {
+				call.Times(1)
+			}
+			r := &WorkloadAvailabilityRule{
+				rule:     rule{cli: mockClient, log: log, status: &Status{}},
+				resource: &merlinv1beta1.ClusterRuleWorkloadAvailability{Spec: tc.spec},
+			}
+			a, err := r.Evaluate(ctx, tc.resource)
+			if tc.expectErr {
+				assert.Error(tt, err)
+			} else {
+				assert.NoError(tt, err)
+				assert.Equal(tt, tc.expect, a)
+			}
+		})
+	}
+}
+
+func Test_WorkloadAvailabilityRule_EvaluateCovered(t *testing.T) {
+	ctx := context.Background()
+	log := zapr.NewLogger(zap.L())
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mocks.NewMockClient(mockCtrl)
+	three := int32(3)
+	deployment := appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "app"},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &three,
+			Template: corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "app"}}},
+		},
+	}
+	pdb := &policyv1beta1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "pdb"},
+		Spec:       policyv1beta1.PodDisruptionBudgetSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "app"}}},
+	}
+	checkedAt := time.Now()
+	r := &WorkloadAvailabilityRule{
+		rule: rule{cli: mockClient, log: log, status: &Status{
+			checkedAt:  &checkedAt,
+			violations: map[string]time.Time{"test/app": checkedAt},
+		}},
+		resource: &merlinv1beta1.ClusterRuleWorkloadAvailability{
+			Spec: merlinv1beta1.ClusterRuleWorkloadAvailabilitySpec{CheckPDB: true},
+		},
+	}
+
+	mockClient.EXPECT().
+		List(ctx, &appsv1.DeploymentList{}, &client.ListOptions{Namespace: "test"}).
+		SetArg(1, appsv1.DeploymentList{Items: []appsv1.Deployment{deployment}}).
+		Return(nil).
+		Times(1)
+	mockClient.EXPECT().
+		List(ctx, &policyv1beta1.PodDisruptionBudgetList{}, &client.ListOptions{Namespace: "test"}).
+		SetArg(1, policyv1beta1.PodDisruptionBudgetList{Items: []policyv1beta1.PodDisruptionBudget{*pdb}}).
+		Return(nil).
+		Times(1)
+
+	a, err := r.Evaluate(ctx, pdb)
+	assert.NoError(t, err)
+	assert.Equal(t, alert.Alert{
+		Message:      "Deployment passes the availability checks",
+		ResourceKind: "Deployment",
+		ResourceName: "test/app",
+	}, a)
+	assert.False(t, r.status.isViolated(client.ObjectKey{Namespace: "test", Name: "app"}))
+}
+
+func Test_WorkloadAvailabilityRule_EvaluateAll(t *testing.T) {
+	ctx := context.Background()
+	log := zapr.NewLogger(zap.L())
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockClient := mocks.NewMockClient(mockCtrl)
+	one := int32(1)
+	r := &WorkloadAvailabilityRule{
+		rule: rule{cli: mockClient, log: log, status: &Status{}},
+		resource: &merlinv1beta1.ClusterRuleWorkloadAvailability{
+			Spec: merlinv1beta1.ClusterRuleWorkloadAvailabilitySpec{CheckSingleReplica: true},
+		},
+	}
+
+	cases := []struct {
+		desc      string
+		mockCalls []*gomock.Call
+		expect    []alert.Alert
+		expectErr bool
+	}{
+		{
+			desc: "no resources should returns nil alerts",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().
+					List(ctx, &appsv1.DeploymentList{}).
+					Return(nil),
+			},
+		},
+		{
+			desc: "single replica deployment should get violated alert",
+			mockCalls: []*gomock.Call{
+				mockClient.EXPECT().
+					List(ctx, &appsv1.DeploymentList{}).
+					SetArg(1, appsv1.DeploymentList{Items: []appsv1.Deployment{
+						{
+							ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "app"},
+							Spec:       appsv1.DeploymentSpec{Replicas: &one},
+						},
+					}}).
+					Return(nil),
+			},
+			expect: []alert.Alert{
+				{
+					Message:      "Deployment is not highly available: it has only 1 replica",
+					ResourceKind: "Deployment",
+					ResourceName: "test/app",
+					Violated:     true,
+				},
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.desc, func(tt *testing.T) {
+			for _, call := range tc.mockCalls {
+				call.Times(1)
+			}
+			alerts, err := r.EvaluateAll(ctx)
+			if tc.expectErr {
+				assert.Error(tt, err)
+			} else {
+				assert.NoError(tt, err)
+				assert.Equal(tt, tc.expect, alerts)
+			}
+		})
+	}
+}